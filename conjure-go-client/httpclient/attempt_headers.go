@@ -0,0 +1,46 @@
+// Copyright (c) 2026 Palantir Technologies. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpclient
+
+import (
+	"net/http"
+	"strconv"
+)
+
+// Conventional header names for WithAttemptHeaders.
+const (
+	DefaultAttemptHeader  = "X-Attempt"
+	DefaultFailoverHeader = "X-Failover"
+)
+
+// attemptHeadersMiddleware sets headers describing the attempt stored in the request context by clientImpl.Do.
+type attemptHeadersMiddleware struct {
+	attemptHeader  string
+	failoverHeader string
+}
+
+func (m attemptHeadersMiddleware) RoundTrip(req *http.Request, next http.RoundTripper) (*http.Response, error) {
+	attempt, ok := getAttempt(req.Context())
+	if !ok {
+		return next.RoundTrip(req)
+	}
+	if m.attemptHeader != "" {
+		req.Header.Set(m.attemptHeader, strconv.Itoa(attempt.Number+1))
+	}
+	if m.failoverHeader != "" && attempt.Number > 0 {
+		req.Header.Set(m.failoverHeader, "true")
+	}
+	return next.RoundTrip(req)
+}
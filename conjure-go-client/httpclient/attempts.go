@@ -0,0 +1,102 @@
+// Copyright (c) 2026 Palantir Technologies. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpclient
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	werror "github.com/palantir/witchcraft-go-error"
+)
+
+const attemptsParamKey = "attempts"
+
+// Attempt error classes describe why an attempt failed.
+const (
+	AttemptErrorClassStatus  = "status"
+	AttemptErrorClassTimeout = "timeout"
+	AttemptErrorClassNetwork = "network"
+)
+
+// Attempt describes a single attempt to execute a request.
+type Attempt struct {
+	// Number is the zero-based position of the attempt among all attempts made for the request.
+	Number int `json:"number"`
+	// URI is the base URI used for the attempt. It is omitted when attempts are serialized, e.g. as error params.
+	URI string `json:"-"`
+	// URIIndex is the index of the attempted URI in the client's base URIs, or -1 if the request was sent to a
+	// relocated URI.
+	URIIndex int `json:"uriIndex"`
+	// StatusCode is the status code of the response, or 0 if no response was received or the attempt has not
+	// finished.
+	StatusCode int `json:"statusCode,omitempty"`
+	// ErrorClass is one of the AttemptErrorClass constants, or empty if the attempt succeeded or has not finished.
+	ErrorClass string `json:"errorClass,omitempty"`
+	// Backoff is the time waited before the attempt was made.
+	Backoff time.Duration `json:"backoff"`
+}
+
+// AttemptsFromError returns the outcome of each attempt made before a request failed. If the error does not
+// contain attempts, ok is false. Attempts are only recorded if more than one attempt was made.
+func AttemptsFromError(err error) (attempts []Attempt, ok bool) {
+	attemptsI, _ := werror.ParamFromError(err, attemptsParamKey)
+	if attemptsI == nil {
+		return nil, false
+	}
+	attempts, ok = attemptsI.([]Attempt)
+	return attempts, ok
+}
+
+// attemptHooks are invoked around each attempt to execute a request. See WithAttemptHooks.
+type attemptHooks struct {
+	onStart  func(ctx context.Context, attempt Attempt)
+	onFinish func(ctx context.Context, attempt Attempt, resp *http.Response, err error)
+}
+
+func newAttempt(number int, uris []string, uri string, backoff time.Duration) Attempt {
+	attempt := Attempt{
+		Number:   number,
+		URI:      uri,
+		URIIndex: -1,
+		Backoff:  backoff,
+	}
+	for i := range uris {
+		if uris[i] == uri {
+			attempt.URIIndex = i
+			break
+		}
+	}
+	return attempt
+}
+
+// finish returns the attempt updated with the outcome of its response.
+func (attempt Attempt) finish(resp *http.Response, respErr error) Attempt {
+	if resp != nil {
+		attempt.StatusCode = resp.StatusCode
+	} else if statusCode, ok := StatusCodeFromError(respErr); ok {
+		attempt.StatusCode = statusCode
+	}
+	switch {
+	case respErr == nil:
+	case attempt.StatusCode != 0:
+		attempt.ErrorClass = AttemptErrorClassStatus
+	case isTimeoutError(respErr):
+		attempt.ErrorClass = AttemptErrorClassTimeout
+	default:
+		attempt.ErrorClass = AttemptErrorClassNetwork
+	}
+	return attempt
+}
@@ -19,9 +19,13 @@ import (
 	"encoding/base64"
 	"fmt"
 	"net/http"
+	"net/url"
 
+	"github.com/palantir/conjure-go-runtime/v2/conjure-go-client/httpclient/internal"
 	"github.com/palantir/conjure-go-runtime/v2/conjure-go-client/httpclient/internal/refreshingclient"
+	"github.com/palantir/conjure-go-runtime/v2/conjure-go-contract/httpheaders"
 	"github.com/palantir/pkg/refreshable"
+	refreshablev2 "github.com/palantir/pkg/refreshable/v2"
 )
 
 // TokenProvider accepts a context and returns either:
@@ -33,23 +37,112 @@ import (
 // A good implementation will request and cache an ephemeral client token.
 type TokenProvider func(context.Context) (string, error)
 
+// RefreshingTokenProvider provides bearer tokens which can be invalidated and re-issued.
+type RefreshingTokenProvider interface {
+	// Token returns a token with the same semantics as TokenProvider.
+	Token(ctx context.Context) (string, error)
+	// RefreshToken discards any cached token and returns a newly issued one with the same semantics as TokenProvider.
+	// It is called when the server rejects a request with 401 Unauthorized.
+	RefreshToken(ctx context.Context) (string, error)
+}
+
 type authTokenMiddleware struct {
 	provideToken TokenProvider
+	// refreshToken, if non-nil, is invoked when a request fails with 401 Unauthorized. The request is then
+	// retried once with the refreshed token.
+	refreshToken TokenProvider
 }
 
 // RoundTrip wraps an existing round tripper with a token providing round tripper.
 // It sets the Authorization header using a newly provided token for each request.
 func (h *authTokenMiddleware) RoundTrip(req *http.Request, next http.RoundTripper) (*http.Response, error) {
+	if hasRequestAuthOverride(req.Context()) {
+		return next.RoundTrip(req)
+	}
+	token, err := h.provideToken(req.Context())
+	if err != nil {
+		return nil, err
+	}
+	if token != "" {
+		req.Header.Set(httpheaders.Authorization, fmt.Sprintf("Bearer %s", token))
+	}
+	resp, err := next.RoundTrip(req)
+	if h.refreshToken == nil || !isUnauthorized(resp, err) {
+		return resp, err
+	}
+	retryReq, ok := replayableRequest(req)
+	if !ok {
+		return resp, err
+	}
+	newToken, refreshErr := h.refreshToken(req.Context())
+	if refreshErr != nil {
+		// surface the original 401 rather than the refresh failure
+		return resp, err
+	}
+	internal.DrainBody(req.Context(), resp)
+	retryReq.Header.Set(httpheaders.Authorization, fmt.Sprintf("Bearer %s", newToken))
+	return next.RoundTrip(retryReq)
+}
+
+func isUnauthorized(resp *http.Response, err error) bool {
+	if err != nil {
+		statusCode, _ := StatusCodeFromError(err)
+		return statusCode == http.StatusUnauthorized
+	}
+	return resp != nil && resp.StatusCode == http.StatusUnauthorized
+}
+
+// replayableRequest returns a copy of req which can be sent again. If the request has a body which can not be
+// re-read, ok is false.
+func replayableRequest(req *http.Request) (*http.Request, bool) {
+	newReq := req.Clone(req.Context())
+	if req.Body == nil || req.Body == http.NoBody {
+		return newReq, true
+	}
+	if req.GetBody == nil {
+		return nil, false
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, false
+	}
+	newReq.Body = body
+	return newReq, true
+}
+
+// cookieAuthTokenMiddleware sends the token as the value of a cookie rather than in the Authorization header, as
+// required by conjure endpoints using cookie auth.
+type cookieAuthTokenMiddleware struct {
+	cookieName   string
+	provideToken TokenProvider
+}
+
+func (h *cookieAuthTokenMiddleware) RoundTrip(req *http.Request, next http.RoundTripper) (*http.Response, error) {
+	if hasRequestAuthOverride(req.Context()) {
+		return next.RoundTrip(req)
+	}
 	token, err := h.provideToken(req.Context())
 	if err != nil {
 		return nil, err
 	}
 	if token != "" {
-		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+		setCookie(req, &http.Cookie{Name: h.cookieName, Value: token})
 	}
 	return next.RoundTrip(req)
 }
 
+// setCookie adds cookie to req, replacing any existing cookie with the same name.
+func setCookie(req *http.Request, cookie *http.Cookie) {
+	existing := req.Cookies()
+	req.Header.Del("Cookie")
+	for _, c := range existing {
+		if c.Name != cookie.Name {
+			req.AddCookie(c)
+		}
+	}
+	req.AddCookie(cookie)
+}
+
 func newAuthTokenMiddlewareFromRefreshable(token refreshable.StringPtr) Middleware {
 	return &authTokenMiddleware{
 		provideToken: func(ctx context.Context) (string, error) {
@@ -77,9 +170,12 @@ type BasicAuthProvider func(context.Context) (BasicAuth, error)
 // (3) a nil BasicAuth and a non-nil error.
 type BasicAuthOptionalProvider func(context.Context) (*BasicAuth, error)
 
-func newBasicAuthMiddlewareFromRefreshable(auth refreshingclient.RefreshableBasicAuthPtr) Middleware {
+func newBasicAuthMiddlewareFromRefreshable(auth refreshablev2.Refreshable[*refreshingclient.BasicAuth]) Middleware {
 	return MiddlewareFunc(func(req *http.Request, next http.RoundTripper) (*http.Response, error) {
-		if basicAuth := auth.CurrentBasicAuthPtr(); basicAuth != nil {
+		if hasRequestAuthOverride(req.Context()) {
+			return next.RoundTrip(req)
+		}
+		if basicAuth := auth.Current(); basicAuth != nil {
 			setBasicAuth(req.Header, basicAuth.User, basicAuth.Password)
 		}
 		return next.RoundTrip(req)
@@ -88,5 +184,20 @@ func newBasicAuthMiddlewareFromRefreshable(auth refreshingclient.RefreshableBasi
 
 func setBasicAuth(h http.Header, username, password string) {
 	basicAuthBytes := []byte(username + ":" + password)
-	h.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString(basicAuthBytes))
+	h.Set(httpheaders.Authorization, "Basic "+base64.StdEncoding.EncodeToString(basicAuthBytes))
+}
+
+// stripAuthOnFailoverMiddleware removes the Authorization header from requests sent to a different host than the
+// initial URI stored in the request context by clientImpl.Do. See AuthFailoverOriginalHostOnly.
+type stripAuthOnFailoverMiddleware struct{}
+
+func (stripAuthOnFailoverMiddleware) RoundTrip(req *http.Request, next http.RoundTripper) (*http.Response, error) {
+	initialURI, ok := getInitialURI(req.Context())
+	if !ok {
+		return next.RoundTrip(req)
+	}
+	if initial, err := url.Parse(initialURI); err != nil || initial.Host != req.URL.Host {
+		req.Header.Del(httpheaders.Authorization)
+	}
+	return next.RoundTrip(req)
 }
@@ -16,12 +16,15 @@ package httpclient_test
 
 import (
 	"context"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/palantir/conjure-go-runtime/v2/conjure-go-client/httpclient"
+	"github.com/palantir/pkg/refreshable"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -51,6 +54,35 @@ func TestRoundTripperWithToken(t *testing.T) {
 	assert.True(t, wrappedRTInvoked)
 }
 
+func TestRoundTripperWithRefreshableToken(t *testing.T) {
+	var expectedHeader string
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, expectedHeader, req.Header.Get("Authorization"))
+		rw.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	token := refreshable.NewDefaultRefreshable("foo")
+	client, err := httpclient.NewClient(
+		httpclient.WithRefreshableAuthToken(refreshable.NewString(token)),
+		httpclient.WithBaseURLs([]string{server.URL}))
+	require.NoError(t, err)
+
+	expectedHeader = "Bearer foo"
+	_, err = client.Get(context.Background())
+	require.NoError(t, err)
+
+	require.NoError(t, token.Update("bar"))
+	expectedHeader = "Bearer bar"
+	_, err = client.Get(context.Background())
+	require.NoError(t, err)
+
+	require.NoError(t, token.Update(""))
+	expectedHeader = ""
+	_, err = client.Get(context.Background())
+	require.NoError(t, err)
+}
+
 func TestRoundTripperWithBasicAuth(t *testing.T) {
 	var wrappedRTInvoked bool
 	expected := httpclient.BasicAuth{
@@ -115,3 +147,136 @@ func TestRoundTripperWithBasicAuthProvider(t *testing.T) {
 	assert.True(t, wrappedRTInvoked)
 
 }
+
+func TestRequestAuthOverride(t *testing.T) {
+	var authHeader string
+	var hasAuthHeader bool
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		_, hasAuthHeader = req.Header["Authorization"]
+		authHeader = req.Header.Get("Authorization")
+		rw.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client, err := httpclient.NewClient(
+		httpclient.WithAuthToken("client-token"),
+		httpclient.WithBaseURLs([]string{server.URL}))
+	require.NoError(t, err)
+
+	t.Run("client token", func(t *testing.T) {
+		_, err := client.Get(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, "Bearer client-token", authHeader)
+	})
+	t.Run("request token", func(t *testing.T) {
+		_, err := client.Get(context.Background(), httpclient.WithRequestAuthToken("request-token"))
+		require.NoError(t, err)
+		assert.Equal(t, "Bearer request-token", authHeader)
+	})
+	t.Run("request basic auth", func(t *testing.T) {
+		_, err := client.Get(context.Background(), httpclient.WithRequestBasicAuth("user", "password"))
+		require.NoError(t, err)
+		assert.Equal(t, "Basic dXNlcjpwYXNzd29yZA==", authHeader)
+	})
+	t.Run("no auth", func(t *testing.T) {
+		_, err := client.Get(context.Background(), httpclient.WithNoAuth())
+		require.NoError(t, err)
+		assert.False(t, hasAuthHeader)
+	})
+}
+
+type testRefreshingTokenProvider struct {
+	token     string
+	refreshed string
+	refreshes int
+}
+
+func (p *testRefreshingTokenProvider) Token(context.Context) (string, error) {
+	return p.token, nil
+}
+
+func (p *testRefreshingTokenProvider) RefreshToken(context.Context) (string, error) {
+	p.refreshes++
+	p.token = p.refreshed
+	return p.token, nil
+}
+
+func TestRoundTripperWithRefreshingToken(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		requests++
+		if req.Header.Get("Authorization") != "Bearer valid" {
+			rw.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		body, err := ioutil.ReadAll(req.Body)
+		assert.NoError(t, err)
+		assert.Equal(t, `"body"`, strings.TrimSpace(string(body)))
+		rw.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	t.Run("refresh succeeds", func(t *testing.T) {
+		requests = 0
+		provider := &testRefreshingTokenProvider{token: "expired", refreshed: "valid"}
+		client, err := httpclient.NewClient(
+			httpclient.WithRefreshingAuthTokenProvider(provider),
+			httpclient.WithBaseURLs([]string{server.URL}))
+		require.NoError(t, err)
+
+		_, err = client.Post(context.Background(), httpclient.WithJSONRequest("body"))
+		require.NoError(t, err)
+		assert.Equal(t, 2, requests)
+		assert.Equal(t, 1, provider.refreshes)
+
+		_, err = client.Post(context.Background(), httpclient.WithJSONRequest("body"))
+		require.NoError(t, err)
+		assert.Equal(t, 3, requests)
+		assert.Equal(t, 1, provider.refreshes)
+	})
+	t.Run("refresh does not help", func(t *testing.T) {
+		requests = 0
+		provider := &testRefreshingTokenProvider{token: "expired", refreshed: "still-expired"}
+		client, err := httpclient.NewClient(
+			httpclient.WithRefreshingAuthTokenProvider(provider),
+			httpclient.WithBaseURLs([]string{server.URL}))
+		require.NoError(t, err)
+
+		_, err = client.Post(context.Background(), httpclient.WithJSONRequest("body"))
+		require.Error(t, err)
+		statusCode, ok := httpclient.StatusCodeFromError(err)
+		require.True(t, ok, "%+v", err)
+		assert.Equal(t, http.StatusUnauthorized, statusCode)
+		assert.Equal(t, 2, requests)
+		assert.Equal(t, 1, provider.refreshes)
+	})
+}
+
+func TestRoundTripperWithCookieAuthToken(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		assert.Empty(t, req.Header.Get("Authorization"))
+		cookie, err := req.Cookie("SESSION")
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, "foo", cookie.Value)
+		other, err := req.Cookie("other")
+		if assert.NoError(t, err) {
+			assert.Equal(t, "value", other.Value)
+		}
+		assert.Len(t, req.Cookies(), 2)
+		rw.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client, err := httpclient.NewClient(
+		httpclient.WithBaseURLs([]string{server.URL}),
+		httpclient.WithCookieAuthToken("SESSION", func(context.Context) (string, error) {
+			return "foo", nil
+		}),
+	)
+	require.NoError(t, err)
+
+	_, err = client.Get(context.Background(), httpclient.WithHeader("Cookie", "SESSION=stale; other=value"))
+	require.NoError(t, err)
+}
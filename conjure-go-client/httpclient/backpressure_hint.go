@@ -0,0 +1,100 @@
+// Copyright (c) 2026 Palantir Technologies. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpclient
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/palantir/conjure-go-runtime/v2/conjure-go-contract/httpheaders"
+)
+
+// BackpressureHint is the pacing requested by a server in the headers of a response. The following headers are
+// understood:
+//   - X-Backoff-Millis: the number of milliseconds the client should wait before sending its next request.
+//   - Retry-After: the number of seconds, or the HTTP date, before which the client should not send requests.
+//   - X-RateLimit-Remaining: the number of requests the server will accept before its rate limit resets.
+//   - X-RateLimit-Reset: the number of seconds until the server's rate limit resets.
+type BackpressureHint struct {
+	// Remaining is the number of requests the server will accept before its rate limit resets, or nil if the server
+	// did not say.
+	Remaining *int
+	// Reset is when the server's rate limit resets, or the zero time if the server did not say.
+	Reset time.Time
+	// NextAllowed is the time before which the server asks the client not to send requests, or the zero time if the
+	// server did not ask the client to wait. It is the latest of the times requested by X-Backoff-Millis and
+	// Retry-After, and Reset if no requests remain.
+	NextAllowed time.Time
+}
+
+// Delay returns how long the client should wait from now before sending its next request, or 0 if it need not wait.
+func (h BackpressureHint) Delay(now time.Time) time.Duration {
+	if delay := h.NextAllowed.Sub(now); delay > 0 {
+		return delay
+	}
+	return 0
+}
+
+// BackpressureHintFromResponse returns the pacing requested by the headers of resp, or false if resp has none of
+// the headers described by BackpressureHint. Malformed header values are ignored. Clients built with
+// WithServerBackoffHints apply the hints of successful responses automatically.
+func BackpressureHintFromResponse(resp *http.Response) (BackpressureHint, bool) {
+	if resp == nil {
+		return BackpressureHint{}, false
+	}
+	return backpressureHint(resp.Header, time.Now())
+}
+
+func backpressureHint(header http.Header, now time.Time) (BackpressureHint, bool) {
+	var hint BackpressureHint
+	found := false
+	if remaining, ok := parseNonNegativeInt(header.Get(httpheaders.RateLimitRemaining)); ok {
+		hint.Remaining = &remaining
+		found = true
+	}
+	if seconds, ok := parseNonNegativeInt(header.Get(httpheaders.RateLimitReset)); ok {
+		hint.Reset = now.Add(time.Duration(seconds) * time.Second)
+		found = true
+		if hint.Remaining != nil && *hint.Remaining == 0 {
+			hint.NextAllowed = hint.Reset
+		}
+	}
+	if millis, ok := parseNonNegativeInt(header.Get(httpheaders.BackoffMillis)); ok {
+		found = true
+		if next := now.Add(time.Duration(millis) * time.Millisecond); next.After(hint.NextAllowed) {
+			hint.NextAllowed = next
+		}
+	}
+	if retryAfter, ok := httpheaders.ParseRetryAfter(header.Get(httpheaders.RetryAfter), now); ok {
+		found = true
+		if next := now.Add(retryAfter); next.After(hint.NextAllowed) {
+			hint.NextAllowed = next
+		}
+	}
+	return hint, found
+}
+
+func parseNonNegativeInt(value string) (int, bool) {
+	if value == "" {
+		return 0, false
+	}
+	i, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || i < 0 {
+		return 0, false
+	}
+	return i, true
+}
@@ -0,0 +1,88 @@
+// Copyright (c) 2026 Palantir Technologies. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpclient_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/palantir/conjure-go-runtime/v2/conjure-go-client/httpclient"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackpressureHintFromResponse(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		header    http.Header
+		ok        bool
+		remaining *int
+		reset     time.Duration
+		delay     time.Duration
+	}{
+		{
+			name:   "no hints",
+			header: http.Header{},
+		},
+		{
+			name:   "malformed hints",
+			header: http.Header{"X-Backoff-Millis": []string{"soon"}, "X-Ratelimit-Remaining": []string{"-1"}},
+		},
+		{
+			name:   "backoff millis",
+			header: http.Header{"X-Backoff-Millis": []string{"1500"}},
+			ok:     true,
+			delay:  1500 * time.Millisecond,
+		},
+		{
+			name:   "retry after",
+			header: http.Header{"Retry-After": []string{"30"}},
+			ok:     true,
+			delay:  30 * time.Second,
+		},
+		{
+			name:      "remaining quota",
+			header:    http.Header{"X-Ratelimit-Remaining": []string{"5"}, "X-Ratelimit-Reset": []string{"60"}},
+			ok:        true,
+			remaining: &[]int{5}[0],
+			reset:     time.Minute,
+		},
+		{
+			name:      "exhausted quota",
+			header:    http.Header{"X-Ratelimit-Remaining": []string{"0"}, "X-Ratelimit-Reset": []string{"60"}, "X-Backoff-Millis": []string{"100"}},
+			ok:        true,
+			remaining: &[]int{0}[0],
+			reset:     time.Minute,
+			delay:     time.Minute,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			now := time.Now()
+			hint, ok := httpclient.BackpressureHintFromResponse(&http.Response{Header: tc.header})
+			require.Equal(t, tc.ok, ok)
+			assert.Equal(t, tc.remaining, hint.Remaining)
+			if tc.reset == 0 {
+				assert.True(t, hint.Reset.IsZero())
+			} else {
+				assert.WithinDuration(t, now.Add(tc.reset), hint.Reset, time.Second)
+			}
+			assert.InDelta(t, tc.delay, hint.Delay(now), float64(time.Second))
+		})
+	}
+
+	_, ok := httpclient.BackpressureHintFromResponse(nil)
+	assert.False(t, ok)
+}
@@ -16,44 +16,97 @@ package httpclient
 
 import (
 	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
 	"io"
 	"io/ioutil"
+	"mime"
 	"net/http"
+	"strings"
 
 	"github.com/palantir/conjure-go-runtime/v2/conjure-go-contract/codecs"
+	"github.com/palantir/conjure-go-runtime/v2/conjure-go-contract/errors"
+	"github.com/palantir/conjure-go-runtime/v2/conjure-go-contract/httpheaders"
 	"github.com/palantir/pkg/bytesbuffers"
 	werror "github.com/palantir/witchcraft-go-error"
 )
 
+// maxContentTypeMismatchPreviewBytes bounds the response body included in content type mismatch errors.
+const maxContentTypeMismatchPreviewBytes = 256
+
+// ErrResponseContentTypeMismatch is returned when WithResponseContentTypeCheck is used and the Content-Type of a
+// response does not match the decoder provided by WithResponseBody, for example when an intermediary returns an
+// HTML error page. The error includes the expected and actual content types and a preview of the response body.
+var ErrResponseContentTypeMismatch = fmt.Errorf("response content type does not match decoder")
+
 type bodyMiddleware struct {
 	requestInput   interface{}
 	requestEncoder codecs.Encoder
 
 	// if rawOutput is true, the body of the response is not drained before returning -- it is the responsibility of the
 	// caller to read from and properly close the response body.
-	rawOutput       bool
-	responseOutput  interface{}
-	responseDecoder codecs.Decoder
+	rawOutput bool
+	// if decompressRawOutput is true, a raw response body with a gzip Content-Encoding is decompressed as it is read
+	// by the gzipResponseMiddleware.
+	decompressRawOutput bool
+	responseOutput      interface{}
+	responseDecoder     codecs.Decoder
+	// statusResponseOutputs override responseOutput and responseDecoder for responses with specific status codes. See
+	// WithStatusResponseBody.
+	statusResponseOutputs map[int]responseOutput
+	// if responseWriter is set, the raw response body is copied to it once the request succeeds. See WithResponseWriter.
+	responseWriter io.Writer
+
+	bufferPool           bytesbuffers.Pool
+	validator            Validator
+	responseBodyWrappers []ResponseBodyWrapper
+	// compressionThreshold, if positive, is the encoded body size at or above which the request body is compressed
+	// with the coding negotiated by requestEncodings.
+	compressionThreshold int
+	requestEncodings     *requestEncodings
+	// requestEncoding is the coding the request body was compressed with because of compressionThreshold, and
+	// uncompressedBody is the body before compression.
+	requestEncoding  string
+	uncompressedBody []byte
+	// if checkContentType is true, the response Content-Type must match the decoder's Accept header.
+	checkContentType bool
+	// if checksum is set, response bodies are verified against the checksum header sent by the server.
+	checksum *responseChecksum
+}
 
-	bufferPool bytesbuffers.Pool
+// responseOutput is the value a response body is decoded into and the decoder used to decode it.
+type responseOutput struct {
+	output  interface{}
+	decoder codecs.Decoder
 }
 
 func (b *bodyMiddleware) RoundTrip(req *http.Request, next http.RoundTripper) (*http.Response, error) {
 	cleanup, err := b.setRequestBody(req)
 	if err != nil {
+		b.closeRequestBody()
 		return nil, err
 	}
 
 	resp, respErr := next.RoundTrip(req)
 	cleanup()
 
-	if err := b.readResponse(resp, respErr); err != nil {
+	if err := b.readResponse(req.Context(), resp, respErr); err != nil {
+		b.closeRequestBody()
 		return nil, err
 	}
 
 	return resp, nil
 }
 
+// closeRequestBody closes the streamed request body after a failed attempt, which may have returned before the
+// transport read or closed it.
+func (b *bodyMiddleware) closeRequestBody() {
+	if body, ok := b.requestInput.(*multipartRequestBody); ok {
+		body.close()
+	}
+}
+
 // setRequestBody returns a function that should be called once the request has been completed.
 func (b *bodyMiddleware) setRequestBody(req *http.Request) (func(), error) {
 	cleanup := func() {}
@@ -62,6 +115,19 @@ func (b *bodyMiddleware) setRequestBody(req *http.Request) (func(), error) {
 		return cleanup, nil
 	}
 
+	if body, ok := b.requestInput.(*readerRequestBody); ok {
+		body.setRequestBody(req)
+		return cleanup, nil
+	}
+
+	if body, ok := b.requestInput.(*chanRequestBody); ok {
+		return cleanup, body.setRequestBody(req)
+	}
+
+	if body, ok := b.requestInput.(*multipartRequestBody); ok {
+		return cleanup, body.setRequestBody(req)
+	}
+
 	// Special case: if the requestInput is an io.ReadCloser and the requestEncoder is nil,
 	// use the provided input directly as the request body.
 	if bodyReadCloser, ok := b.requestInput.(io.ReadCloser); ok && b.requestEncoder == nil {
@@ -73,6 +139,12 @@ func (b *bodyMiddleware) setRequestBody(req *http.Request) (func(), error) {
 		return cleanup, nil
 	}
 
+	if b.validator != nil {
+		if err := b.validator.ValidateRequest(req.Context(), b.requestInput); err != nil {
+			return cleanup, errors.WrapWithInvalidArgument(err)
+		}
+	}
+
 	var buf *bytes.Buffer
 	if b.bufferPool != nil {
 		buf = b.bufferPool.Get()
@@ -86,21 +158,52 @@ func (b *bodyMiddleware) setRequestBody(req *http.Request) (func(), error) {
 	if err := b.requestEncoder.Encode(buf, b.requestInput); err != nil {
 		return cleanup, werror.Wrap(err, "failed to encode request object")
 	}
+	body := buf
+	if b.compressionThreshold > 0 && buf.Len() >= b.compressionThreshold && req.Header.Get(httpheaders.ContentEncoding) == "" {
+		if encoding := b.requestEncodings.Current(); encoding != "" {
+			compressed, err := compressRequestBody(encoding, buf.Bytes())
+			if err != nil {
+				return cleanup, werror.Wrap(err, "failed to compress request body")
+			}
+			b.requestEncoding, b.uncompressedBody = encoding, buf.Bytes()
+			body = compressed
+			req.Header.Set(httpheaders.ContentEncoding, encoding)
+		}
+	}
+	setBufferedRequestBody(req, body)
+	return cleanup, nil
+}
 
-	if buf.Len() != 0 {
-		req.Body = ioutil.NopCloser(buf)
-		req.ContentLength = int64(buf.Len())
+// setBufferedRequestBody sets the body of req to the contents of body.
+func setBufferedRequestBody(req *http.Request, body *bytes.Buffer) {
+	if body.Len() != 0 {
+		// capture the encoded bytes before the buffer is read so GetBody can replay the full body
+		bodyBytes := body.Bytes()
+		req.Body = ioutil.NopCloser(body)
+		req.ContentLength = int64(body.Len())
 		req.GetBody = func() (io.ReadCloser, error) {
-			return ioutil.NopCloser(bytes.NewReader(buf.Bytes())), nil
+			return ioutil.NopCloser(bytes.NewReader(bodyBytes)), nil
 		}
 	} else {
 		req.Body = http.NoBody
 		req.GetBody = func() (io.ReadCloser, error) { return http.NoBody, nil }
 	}
-	return cleanup, nil
 }
 
-func (b *bodyMiddleware) readResponse(resp *http.Response, respErr error) error {
+func (b *bodyMiddleware) readResponse(ctx context.Context, resp *http.Response, respErr error) error {
+	var checksum *checksumBody
+	var truncation *truncationCheckBody
+	if respErr == nil {
+		truncation = wrapTruncationCheck(resp)
+		if b.checksum != nil {
+			var err error
+			if checksum, err = b.checksum.wrap(resp); err != nil {
+				return err
+			}
+		}
+		wrapResponseBody(resp, b.responseBodyWrappers)
+	}
+
 	// If rawOutput is true, return response directly without draining or closing body
 	if b.rawOutput && respErr == nil {
 		return nil
@@ -110,16 +213,181 @@ func (b *bodyMiddleware) readResponse(resp *http.Response, respErr error) error
 		return respErr
 	}
 
+	output, decoder := b.responseOutput, b.responseDecoder
+	if resp != nil {
+		if o, ok := b.statusResponseOutputs[resp.StatusCode]; ok {
+			output, decoder = o.output, o.decoder
+		}
+	}
+
 	// Verify we have a body to unmarshal. If the request was unsuccessful, the errorMiddleware will
 	// set a non-nil error and return no response.
-	if b.responseOutput == nil || resp == nil || resp.Body == nil || resp.ContentLength == 0 {
+	if output == nil || resp == nil || resp.Body == nil || resp.ContentLength == 0 {
+		if checksum != nil {
+			return checksum.verify(resp.Body)
+		}
 		return nil
 	}
 
-	decErr := b.responseDecoder.Decode(resp.Body, b.responseOutput)
+	if b.checkContentType {
+		if err := checkResponseContentType(resp, decoder.Accept()); err != nil {
+			return err
+		}
+	}
+
+	if _, ok := output.(*jsonStreamOutput); ok {
+		markResponseStreamed(ctx)
+	}
+	decErr := decoder.Decode(resp.Body, output)
 	if decErr != nil {
+		if truncation != nil && truncation.err != nil {
+			// report the truncation rather than the resulting decode failure, which may be a syntax error.
+			_ = resp.Body.Close()
+			return truncation.err
+		}
 		return decErr
 	}
+	if checksum != nil {
+		// decoders may not read to EOF, so read the remainder of the body to complete the checksum.
+		if err := checksum.verify(resp.Body); err != nil {
+			return err
+		}
+	}
 
+	if b.validator != nil {
+		if err := b.validator.ValidateResponse(ctx, output); err != nil {
+			return errors.WrapWithInternal(err)
+		}
+	}
 	return nil
 }
+
+// checkResponseContentType returns an error wrapping ErrResponseContentTypeMismatch if the response has a
+// Content-Type which is not one of the media types in accept. Responses without a Content-Type are accepted.
+// On mismatch, a preview of the body is included in the error and the body is closed.
+func checkResponseContentType(resp *http.Response, accept string) error {
+	contentType := resp.Header.Get(httpheaders.ContentType)
+	if contentType == "" || accept == "" {
+		return nil
+	}
+	actual, _, err := mime.ParseMediaType(contentType)
+	if err == nil && mediaTypeAccepted(actual, accept) {
+		return nil
+	}
+	preview := make([]byte, maxContentTypeMismatchPreviewBytes)
+	n, _ := io.ReadFull(resp.Body, preview)
+	_ = resp.Body.Close()
+	return werror.Wrap(ErrResponseContentTypeMismatch, "",
+		werror.SafeParam("expectedContentType", accept),
+		werror.SafeParam("actualContentType", contentType),
+		werror.UnsafeParam("responseBodyPreview", strings.ToValidUTF8(string(preview[:n]), "\uFFFD")))
+}
+
+// mediaTypeAccepted returns true if mediaType matches one of the media ranges in the Accept header value accept.
+func mediaTypeAccepted(mediaType, accept string) bool {
+	for _, mediaRange := range strings.Split(accept, ",") {
+		accepted, _, err := mime.ParseMediaType(strings.TrimSpace(mediaRange))
+		if err != nil {
+			continue
+		}
+		if accepted == "*/*" || accepted == mediaType {
+			return true
+		}
+		if prefix, ok := strings.CutSuffix(accepted, "/*"); ok && strings.HasPrefix(mediaType, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// gzipResponseMiddleware decompresses gzip-encoded responses which the transport returned compressed. The transport
+// only decompresses responses automatically when it added the Accept-Encoding header itself, which is not the case
+// if compression is disabled or the header was set explicitly.
+type gzipResponseMiddleware struct{}
+
+func (gzipResponseMiddleware) RoundTrip(req *http.Request, next http.RoundTripper) (*http.Response, error) {
+	resp, err := next.RoundTrip(req)
+	if err != nil {
+		return resp, err
+	}
+	if err := decompressResponseBody(resp); err != nil {
+		return nil, werror.WrapWithContextParams(req.Context(), err, "")
+	}
+	return resp, nil
+}
+
+// decompressResponseBody replaces the body of a gzip-encoded response with a reader which decompresses the body as
+// it is read. Closing the new body closes the original body.
+func decompressResponseBody(resp *http.Response) error {
+	if resp == nil || resp.Body == nil || resp.Body == http.NoBody || !strings.EqualFold(resp.Header.Get(httpheaders.ContentEncoding), "gzip") {
+		return nil
+	}
+	body := resp.Body
+	gzipReader, err := gzip.NewReader(body)
+	if err != nil {
+		_ = body.Close()
+		return werror.Wrap(err, "failed to read gzip response body")
+	}
+	resp.Body = &gzipResponseBody{Reader: gzipReader, body: body}
+	resp.Header.Del(httpheaders.ContentEncoding)
+	resp.Header.Del(httpheaders.ContentLength)
+	resp.ContentLength = -1
+	resp.Uncompressed = true
+	return nil
+}
+
+type gzipResponseBody struct {
+	*gzip.Reader
+	body io.ReadCloser
+}
+
+func (r *gzipResponseBody) Close() error {
+	_ = r.Reader.Close()
+	return r.body.Close()
+}
+
+// readerRequestBody is the request body configured by WithRequestBodyReader.
+type readerRequestBody struct {
+	reader        io.Reader
+	contentLength int64
+	// seeker is non-nil if reader implements io.Seeker, in which case start is the offset to rewind to.
+	seeker  io.Seeker
+	start   int64
+	seekErr error
+}
+
+func (r *readerRequestBody) rewind() error {
+	if r.seeker == nil {
+		return nil
+	}
+	if _, err := r.seeker.Seek(r.start, io.SeekStart); err != nil {
+		return werror.Wrap(err, "failed to rewind request body reader")
+	}
+	return nil
+}
+
+func (r *readerRequestBody) setRequestBody(req *http.Request) {
+	if r.contentLength == 0 {
+		req.Body = http.NoBody
+		req.GetBody = func() (io.ReadCloser, error) { return http.NoBody, nil }
+		req.ContentLength = 0
+		return
+	}
+	// N.B. the reader is never closed by the client so that it may be rewound for subsequent attempts.
+	req.Body = ioutil.NopCloser(r.reader)
+	req.ContentLength = r.contentLength
+	if r.contentLength < 0 {
+		req.ContentLength = -1
+	}
+	if r.seeker == nil {
+		// The reader can not be rewound, so the request must not be retried.
+		markBodyNotReplayable(req.Context())
+		return
+	}
+	req.GetBody = func() (io.ReadCloser, error) {
+		if err := r.rewind(); err != nil {
+			return nil, err
+		}
+		return ioutil.NopCloser(r.reader), nil
+	}
+}
@@ -16,16 +16,27 @@ package httpclient_test
 
 import (
 	"bytes"
+	"compress/gzip"
+	"compress/zlib"
 	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
 	"io"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"strings"
+	"sync/atomic"
 	"testing"
 
 	"github.com/palantir/conjure-go-runtime/v2/conjure-go-client/httpclient"
 	"github.com/palantir/conjure-go-runtime/v2/conjure-go-contract/codecs"
+	conjureerrors "github.com/palantir/conjure-go-runtime/v2/conjure-go-contract/errors"
 	"github.com/palantir/pkg/bytesbuffers"
+	werror "github.com/palantir/witchcraft-go-error"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -64,6 +75,62 @@ func TestJSONBody(t *testing.T) {
 	assert.Equal(t, respVar, actualRespVar)
 }
 
+func TestStatusResponseBody(t *testing.T) {
+	type result struct {
+		Value string `json:"value"`
+	}
+	type job struct {
+		JobID string `json:"jobId"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, []string{"application/json", "text/plain"}, req.Header.Values("Accept"))
+		switch req.URL.Path {
+		case "/sync":
+			_ = codecs.JSON.Encode(rw, result{Value: "done"})
+		case "/async":
+			rw.WriteHeader(http.StatusAccepted)
+			_ = codecs.JSON.Encode(rw, job{JobID: "123"})
+		case "/created":
+			rw.WriteHeader(http.StatusCreated)
+			_, _ = rw.Write([]byte("created"))
+		}
+	}))
+	defer server.Close()
+
+	client, err := httpclient.NewClient(httpclient.WithBaseURLs([]string{server.URL}))
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		path           string
+		expectedStatus int
+		expectedResult result
+		expectedJob    job
+		expectedText   string
+	}{
+		{path: "/sync", expectedStatus: http.StatusOK, expectedResult: result{Value: "done"}},
+		{path: "/async", expectedStatus: http.StatusAccepted, expectedJob: job{JobID: "123"}},
+		{path: "/created", expectedStatus: http.StatusCreated, expectedText: "created"},
+	} {
+		t.Run(tc.path, func(t *testing.T) {
+			var actualResult result
+			var actualJob job
+			var actualText string
+			resp, err := client.Do(context.Background(),
+				httpclient.WithRequestMethod(http.MethodPost),
+				httpclient.WithPath(tc.path),
+				httpclient.WithJSONResponse(&actualResult),
+				httpclient.WithStatusResponseBody(http.StatusAccepted, &actualJob, codecs.JSON),
+				httpclient.WithStatusResponseBody(http.StatusCreated, &actualText, codecs.Plain),
+			)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedStatus, resp.StatusCode)
+			assert.Equal(t, tc.expectedResult, actualResult)
+			assert.Equal(t, tc.expectedJob, actualJob)
+			assert.Equal(t, tc.expectedText, actualText)
+		})
+	}
+}
+
 func TestRawBody(t *testing.T) {
 	reqVar := []byte{0x01, 0x00}
 	respVar := []byte{0x00, 0x01}
@@ -103,6 +170,471 @@ func TestRawBody(t *testing.T) {
 	assert.Equal(t, respVar, gotRespBytes)
 }
 
+func TestDecompressedRawResponseBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.URL.Path == "/plain" {
+			_, _ = rw.Write([]byte("plain content"))
+			return
+		}
+		assert.Equal(t, "gzip", req.Header.Get("Accept-Encoding"))
+		rw.Header().Set("Content-Encoding", "gzip")
+		gzipWriter := gzip.NewWriter(rw)
+		_, _ = gzipWriter.Write([]byte("compressed content"))
+		assert.NoError(t, gzipWriter.Close())
+	}))
+	defer server.Close()
+
+	client, err := httpclient.NewClient(
+		httpclient.WithBaseURLs([]string{server.URL}),
+		httpclient.WithDisableHTTP2(),
+	)
+	require.NoError(t, err)
+
+	t.Run("gzip", func(t *testing.T) {
+		resp, err := client.Do(context.Background(), httpclient.WithRequestMethod(http.MethodGet), httpclient.WithDecompressedRawResponseBody())
+		require.NoError(t, err)
+		defer func() { _ = resp.Body.Close() }()
+		body, err := ioutil.ReadAll(resp.Body)
+		require.NoError(t, err)
+		assert.Equal(t, "compressed content", string(body))
+		assert.Empty(t, resp.Header.Get("Content-Encoding"))
+		assert.True(t, resp.Uncompressed)
+	})
+	t.Run("not compressed", func(t *testing.T) {
+		resp, err := client.Do(context.Background(), httpclient.WithRequestMethod(http.MethodGet), httpclient.WithPath("/plain"), httpclient.WithDecompressedRawResponseBody())
+		require.NoError(t, err)
+		defer func() { _ = resp.Body.Close() }()
+		body, err := ioutil.ReadAll(resp.Body)
+		require.NoError(t, err)
+		assert.Equal(t, "plain content", string(body))
+	})
+}
+
+func TestGzipRequestAndResponse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		body := req.Body
+		if req.Header.Get("Content-Encoding") == "gzip" {
+			gzipReader, err := gzip.NewReader(req.Body)
+			if !assert.NoError(t, err) {
+				return
+			}
+			body = gzipReader
+		}
+		input, err := io.ReadAll(body)
+		assert.NoError(t, err)
+
+		rw.Header().Set("Content-Type", "application/json")
+		rw.Header().Set("Content-Encoding", "gzip")
+		if req.URL.Path == "/error" {
+			rw.WriteHeader(http.StatusBadRequest)
+			input = []byte(`{"errorCode":"INVALID_ARGUMENT","errorName":"Default:InvalidArgument","errorInstanceId":"00000000-0000-0000-0000-000000000000","parameters":{}}`)
+		}
+		gzipWriter := gzip.NewWriter(rw)
+		_, _ = gzipWriter.Write(input)
+		assert.NoError(t, gzipWriter.Close())
+	}))
+	defer server.Close()
+
+	client, err := httpclient.NewClient(httpclient.WithBaseURLs([]string{server.URL}))
+	require.NoError(t, err)
+
+	t.Run("decodes response when Accept-Encoding is set explicitly", func(t *testing.T) {
+		var output map[string]string
+		resp, err := client.Post(context.Background(),
+			httpclient.WithHeader("Accept-Encoding", "gzip"),
+			httpclient.WithGzipRequest(map[string]string{"key": "value"}, codecs.JSON),
+			httpclient.WithJSONResponse(&output))
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{"key": "value"}, output)
+		assert.Empty(t, resp.Header.Get("Content-Encoding"))
+	})
+	t.Run("decodes error response", func(t *testing.T) {
+		_, err := client.Post(context.Background(),
+			httpclient.WithPath("/error"),
+			httpclient.WithHeader("Accept-Encoding", "gzip"),
+			httpclient.WithJSONRequest(map[string]string{}))
+		require.Error(t, err)
+		conjureErr := conjureerrors.GetConjureError(err)
+		require.NotNil(t, conjureErr, "expected conjure error, got %v", err)
+		assert.Equal(t, conjureerrors.InvalidArgument, conjureErr.Code())
+	})
+	t.Run("raw response is not decompressed", func(t *testing.T) {
+		resp, err := client.Post(context.Background(),
+			httpclient.WithHeader("Accept-Encoding", "gzip"),
+			httpclient.WithRawRequestBodyProvider(func() io.ReadCloser { return io.NopCloser(strings.NewReader("raw")) }),
+			httpclient.WithRawResponseBody())
+		require.NoError(t, err)
+		defer func() { _ = resp.Body.Close() }()
+		assert.Equal(t, "gzip", resp.Header.Get("Content-Encoding"))
+		gzipReader, err := gzip.NewReader(resp.Body)
+		require.NoError(t, err)
+		body, err := io.ReadAll(gzipReader)
+		require.NoError(t, err)
+		assert.Equal(t, "raw", string(body))
+	})
+}
+
+func TestResponseWriter(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		requests++
+		assert.Equal(t, "application/octet-stream", req.Header.Get("Accept"))
+		_, _ = rw.Write([]byte(strings.Repeat("a", 1024)))
+	}))
+	defer server.Close()
+
+	client, err := httpclient.NewClient(httpclient.WithBaseURLs([]string{server.URL}))
+	require.NoError(t, err)
+
+	t.Run("success", func(t *testing.T) {
+		requests = 0
+		var buf bytes.Buffer
+		resp, err := client.Get(context.Background(), httpclient.WithResponseWriter(&buf))
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, strings.Repeat("a", 1024), buf.String())
+		assert.Equal(t, 1, requests)
+	})
+	t.Run("write error", func(t *testing.T) {
+		requests = 0
+		_, err := client.Get(context.Background(), httpclient.WithResponseWriter(&failingWriter{limit: 100}))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to write response body")
+		safeParams, _ := werror.ParamsFromError(err)
+		assert.Equal(t, int64(100), safeParams["responseBytesWritten"])
+		assert.Equal(t, 1, requests, "partially written responses should not be retried")
+	})
+}
+
+// failingWriter accepts limit bytes and then fails.
+type failingWriter struct {
+	limit   int
+	written int
+}
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	if w.written+len(p) > w.limit {
+		n := w.limit - w.written
+		w.written = w.limit
+		return n, errors.New("disk full")
+	}
+	w.written += len(p)
+	return len(p), nil
+}
+
+func TestRequestCompressionThreshold(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		var body io.Reader = req.Body
+		if req.Header.Get("Content-Encoding") == "gzip" {
+			gzipReader, err := gzip.NewReader(req.Body)
+			require.NoError(t, err)
+			body = gzipReader
+		}
+		decoded, err := ioutil.ReadAll(body)
+		require.NoError(t, err)
+		rw.Header().Set("X-Content-Encoding", req.Header.Get("Content-Encoding"))
+		_, _ = rw.Write(decoded)
+	}))
+	defer server.Close()
+
+	client, err := httpclient.NewClient(
+		httpclient.WithBaseURLs([]string{server.URL}),
+		httpclient.WithRequestCompressionThreshold(100),
+	)
+	require.NoError(t, err)
+
+	for _, test := range []struct {
+		name             string
+		params           []httpclient.RequestParam
+		expectedEncoding string
+		expectedBody     string
+	}{
+		{
+			name:             "below threshold",
+			params:           []httpclient.RequestParam{httpclient.WithJSONRequest("small")},
+			expectedEncoding: "",
+			expectedBody:     `"small"` + "\n",
+		},
+		{
+			name:             "above threshold",
+			params:           []httpclient.RequestParam{httpclient.WithJSONRequest(strings.Repeat("a", 200))},
+			expectedEncoding: "gzip",
+			expectedBody:     `"` + strings.Repeat("a", 200) + `"` + "\n",
+		},
+		{
+			name:             "explicit encoding",
+			params:           []httpclient.RequestParam{httpclient.WithRequestBody(strings.Repeat("a", 200), codecs.Plain), httpclient.WithHeader("Content-Encoding", "identity")},
+			expectedEncoding: "identity",
+			expectedBody:     strings.Repeat("a", 200),
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			resp, err := client.Do(context.Background(), append([]httpclient.RequestParam{
+				httpclient.WithRequestMethod(http.MethodPost),
+				httpclient.WithRawResponseBody(),
+			}, test.params...)...)
+			require.NoError(t, err)
+			defer func() { _ = resp.Body.Close() }()
+			body, err := ioutil.ReadAll(resp.Body)
+			require.NoError(t, err)
+			assert.Equal(t, test.expectedEncoding, resp.Header.Get("X-Content-Encoding"))
+			assert.Equal(t, test.expectedBody, string(body))
+		})
+	}
+
+	_, err = httpclient.NewClient(httpclient.WithRequestCompressionThreshold(-1))
+	assert.EqualError(t, err, "request compression threshold must not be negative")
+}
+
+func TestRequestCompressionNegotiation(t *testing.T) {
+	var acceptEncoding atomic.Value
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		requests.Add(1)
+		accepted := acceptEncoding.Load().(string)
+		var body io.Reader = req.Body
+		switch encoding := req.Header.Get("Content-Encoding"); {
+		case encoding == "":
+		case encoding != accepted:
+			rw.Header().Set("Accept-Encoding", accepted)
+			rw.WriteHeader(http.StatusUnsupportedMediaType)
+			return
+		case encoding == "gzip":
+			gzipReader, err := gzip.NewReader(req.Body)
+			if err != nil {
+				rw.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			body = gzipReader
+		case encoding == "deflate":
+			zlibReader, err := zlib.NewReader(req.Body)
+			if err != nil {
+				rw.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			body = zlibReader
+		}
+		decoded, err := ioutil.ReadAll(body)
+		if err != nil {
+			rw.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		rw.Header().Set("X-Content-Encoding", req.Header.Get("Content-Encoding"))
+		_, _ = rw.Write(decoded)
+	}))
+	defer server.Close()
+
+	client, err := httpclient.NewClient(
+		httpclient.WithBaseURLs([]string{server.URL}),
+		httpclient.WithRequestCompressionThreshold(100),
+	)
+	require.NoError(t, err)
+
+	for _, test := range []struct {
+		name             string
+		acceptEncoding   string
+		expectedEncoding string
+		expectedRequests int32
+	}{
+		{
+			name:             "rejected coding is renegotiated",
+			acceptEncoding:   "deflate",
+			expectedEncoding: "deflate",
+			expectedRequests: 2,
+		},
+		{
+			name:             "negotiated coding is used",
+			acceptEncoding:   "deflate",
+			expectedEncoding: "deflate",
+			expectedRequests: 1,
+		},
+		{
+			name:             "no accepted coding",
+			acceptEncoding:   "identity",
+			expectedEncoding: "",
+			expectedRequests: 2,
+		},
+		{
+			name:             "uncompressed after no accepted coding",
+			acceptEncoding:   "identity",
+			expectedEncoding: "",
+			expectedRequests: 1,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			acceptEncoding.Store(test.acceptEncoding)
+			requests.Store(0)
+			input := strings.Repeat("a", 200)
+			resp, err := client.Do(context.Background(),
+				httpclient.WithRequestMethod(http.MethodPost),
+				httpclient.WithJSONRequest(input),
+				httpclient.WithRawResponseBody())
+			require.NoError(t, err)
+			defer func() { _ = resp.Body.Close() }()
+			body, err := ioutil.ReadAll(resp.Body)
+			require.NoError(t, err)
+			assert.Equal(t, test.expectedEncoding, resp.Header.Get("X-Content-Encoding"))
+			assert.Equal(t, `"`+input+`"`+"\n", string(body))
+			assert.Equal(t, test.expectedRequests, requests.Load())
+		})
+	}
+}
+
+func TestResponseContentTypeCheck(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/json":
+			rw.Header().Set("Content-Type", "application/json; charset=utf-8")
+			_, _ = rw.Write([]byte(`{"key":"value"}`))
+		case "/html":
+			rw.Header().Set("Content-Type", "text/html")
+			_, _ = rw.Write([]byte("<html>" + strings.Repeat("a", 1000) + "</html>"))
+		}
+	}))
+	defer server.Close()
+
+	client, err := httpclient.NewClient(
+		httpclient.WithBaseURLs([]string{server.URL}),
+		httpclient.WithResponseContentTypeCheck(),
+	)
+	require.NoError(t, err)
+
+	var out map[string]string
+	_, err = client.Get(context.Background(), httpclient.WithPath("/json"), httpclient.WithJSONResponse(&out))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"key": "value"}, out)
+
+	_, err = client.Get(context.Background(), httpclient.WithPath("/html"), httpclient.WithJSONResponse(&out))
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, httpclient.ErrResponseContentTypeMismatch), "expected content type mismatch, got %v", err)
+	safeParams, unsafeParams := werror.ParamsFromError(err)
+	assert.Equal(t, "application/json", safeParams["expectedContentType"])
+	assert.Equal(t, "text/html", safeParams["actualContentType"])
+	assert.Equal(t, "<html>"+strings.Repeat("a", 250), unsafeParams["responseBodyPreview"])
+}
+
+type testValidator struct{}
+
+func (testValidator) ValidateRequest(_ context.Context, input interface{}) error {
+	if input.(map[string]string)["name"] == "" {
+		return errors.New("name is required")
+	}
+	return nil
+}
+
+func (testValidator) ValidateResponse(_ context.Context, output interface{}) error {
+	if (*output.(*map[string]string))["id"] == "" {
+		return errors.New("id is required")
+	}
+	return nil
+}
+
+func TestValidator(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		requests++
+		var input map[string]string
+		assert.NoError(t, codecs.JSON.Decode(req.Body, &input))
+		assert.NoError(t, codecs.JSON.Encode(rw, map[string]string{"id": input["id"]}))
+	}))
+	defer server.Close()
+
+	client, err := httpclient.NewClient(
+		httpclient.WithBaseURLs([]string{server.URL}),
+		httpclient.WithValidator(testValidator{}),
+	)
+	require.NoError(t, err)
+
+	doRequest := func(input map[string]string) error {
+		var output map[string]string
+		_, err := client.Post(context.Background(), httpclient.WithJSONRequest(input), httpclient.WithJSONResponse(&output))
+		return err
+	}
+
+	t.Run("valid", func(t *testing.T) {
+		require.NoError(t, doRequest(map[string]string{"name": "foo", "id": "1"}))
+	})
+	t.Run("invalid request", func(t *testing.T) {
+		requests = 0
+		err := doRequest(map[string]string{"id": "1"})
+		require.Error(t, err)
+		assert.True(t, conjureerrors.IsInvalidArgument(err), "expected InvalidArgument error, got %v", err)
+		assert.Zero(t, requests, "expected invalid request not to be sent")
+	})
+	t.Run("invalid response", func(t *testing.T) {
+		err := doRequest(map[string]string{"name": "foo"})
+		require.Error(t, err)
+		assert.True(t, conjureerrors.IsInternal(err), "expected Internal error, got %v", err)
+	})
+}
+
+type countingBody struct {
+	io.ReadCloser
+	read   *int
+	closes *int
+}
+
+func (b countingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	*b.read += n
+	return n, err
+}
+
+func (b countingBody) Close() error {
+	*b.closes++
+	return b.ReadCloser.Close()
+}
+
+func TestResponseBodyWrapper(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.URL.Path == "/error" {
+			rw.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		_, _ = rw.Write([]byte(`"response"`))
+	}))
+	defer server.Close()
+
+	var read, closes, wrapped int
+	client, err := httpclient.NewClient(
+		httpclient.WithBaseURLs([]string{server.URL}),
+		httpclient.WithResponseBodyWrapper(httpclient.ResponseBodyWrapperFunc(func(resp *http.Response, body io.ReadCloser) io.ReadCloser {
+			wrapped++
+			return countingBody{ReadCloser: body, read: &read, closes: &closes}
+		})),
+	)
+	require.NoError(t, err)
+
+	t.Run("decoded", func(t *testing.T) {
+		read, closes, wrapped = 0, 0, 0
+		var output string
+		_, err := client.Get(context.Background(), httpclient.WithJSONResponse(&output))
+		require.NoError(t, err)
+		assert.Equal(t, "response", output)
+		assert.Equal(t, 1, wrapped)
+		assert.Equal(t, len(`"response"`), read)
+		assert.Equal(t, 1, closes)
+	})
+	t.Run("raw", func(t *testing.T) {
+		read, closes, wrapped = 0, 0, 0
+		resp, err := client.Get(context.Background(), httpclient.WithRawResponseBody())
+		require.NoError(t, err)
+		assert.Equal(t, 0, closes)
+		body, err := ioutil.ReadAll(resp.Body)
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+		assert.Equal(t, `"response"`, string(body))
+		assert.Equal(t, len(body), read)
+		assert.Equal(t, 1, closes)
+	})
+	t.Run("error", func(t *testing.T) {
+		read, closes, wrapped = 0, 0, 0
+		_, err := client.Get(context.Background(), httpclient.WithPath("/error"))
+		require.Error(t, err)
+		assert.Equal(t, 0, wrapped)
+	})
+}
+
 func TestRawRequestRetry(t *testing.T) {
 	count := 0
 	requestBytes := []byte{12, 13}
@@ -132,6 +664,96 @@ func TestRawRequestRetry(t *testing.T) {
 	assert.Equal(t, 2, count)
 }
 
+func TestRequestBodyReaderRetry(t *testing.T) {
+	count := 0
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		gotReqBytes, err := ioutil.ReadAll(req.Body)
+		assert.NoError(t, err)
+		assert.Equal(t, "body", string(gotReqBytes))
+		assert.Equal(t, int64(4), req.ContentLength)
+		assert.Equal(t, "text/plain", req.Header.Get("Content-Type"))
+		if count == 0 {
+			rw.WriteHeader(http.StatusInternalServerError)
+		}
+		count++
+	}))
+	defer server.Close()
+
+	client, err := httpclient.NewClient(httpclient.WithBaseURLs([]string{server.URL}))
+	require.NoError(t, err)
+
+	// advance the reader to verify that retries rewind to the initial position rather than the start of the reader
+	reader := strings.NewReader("--body")
+	_, err = reader.Seek(2, io.SeekStart)
+	require.NoError(t, err)
+
+	_, err = client.Post(context.Background(), httpclient.WithRequestBodyReader(reader, 4, "text/plain"))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+}
+
+func TestRequestBodyReaderNotSeekable(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		gotReqBytes, err := ioutil.ReadAll(req.Body)
+		assert.NoError(t, err)
+		assert.Equal(t, "body", string(gotReqBytes))
+		assert.Equal(t, "application/octet-stream", req.Header.Get("Content-Type"))
+		rw.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client, err := httpclient.NewClient(httpclient.WithBaseURLs([]string{server.URL}))
+	require.NoError(t, err)
+
+	_, err = client.Post(context.Background(), httpclient.WithRequestBodyReader(io.MultiReader(strings.NewReader("body")), -1, ""))
+	assert.NoError(t, err)
+}
+
+func TestRequestBodyReaderNotSeekableRetry(t *testing.T) {
+	var count atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		count.Add(1)
+		gotReqBytes, err := ioutil.ReadAll(req.Body)
+		assert.NoError(t, err)
+		assert.Equal(t, "hello", string(gotReqBytes))
+		rw.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	client, err := httpclient.NewClient(httpclient.WithBaseURLs([]string{server.URL}))
+	require.NoError(t, err)
+
+	// the reader can not be rewound, so the 503 is returned rather than retried with an empty body.
+	_, err = client.Post(context.Background(), httpclient.WithRequestBodyReader(io.MultiReader(strings.NewReader("hello")), 5, ""))
+	require.Error(t, err)
+	statusCode, ok := httpclient.StatusCodeFromError(err)
+	require.True(t, ok, "expected status code in error: %v", err)
+	assert.Equal(t, http.StatusServiceUnavailable, statusCode)
+	assert.Equal(t, int32(1), count.Load())
+}
+
+func TestRequestBodyReaderNotSeekableSharedAnnotations(t *testing.T) {
+	var count atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		count.Add(1)
+		_, _ = io.Copy(ioutil.Discard, req.Body)
+	}))
+	defer server.Close()
+
+	client, err := httpclient.NewClient(httpclient.WithBaseURLs([]string{server.URL}))
+	require.NoError(t, err)
+
+	// a body which can not be rewound only prevents retries of the call which sent it, not of later calls which
+	// share the request annotations.
+	ctx := httpclient.ContextWithRequestAnnotations(context.Background())
+	for i := 0; i < 2; i++ {
+		resp, err := client.Post(ctx, httpclient.WithRequestBodyReader(io.MultiReader(strings.NewReader("hello")), 5, ""))
+		require.NoError(t, err)
+		require.NotNil(t, resp)
+	}
+	assert.Equal(t, int32(2), count.Load())
+}
+
 func TestRedirectWithBodyAndBytesBuffer(t *testing.T) {
 	reqVar := map[string]string{"1": "2"}
 	respVar := map[string]string{"3": "4"}
@@ -172,3 +794,124 @@ func TestRedirectWithBodyAndBytesBuffer(t *testing.T) {
 	assert.Equal(t, resp.StatusCode, 200)
 	assert.Equal(t, respVar, actualRespVar)
 }
+
+func TestResponseChecksumVerification(t *testing.T) {
+	const body = `{"key":"value"}`
+	digest := sha256.Sum256([]byte(body))
+	validChecksum := hex.EncodeToString(digest[:])
+	badChecksum := strings.Repeat("0", len(validChecksum))
+
+	var requests int
+	checksums := []string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		checksum := checksums[requests%len(checksums)]
+		requests++
+		if checksum != "" {
+			w.Header().Set("X-Checksum-Sha256", checksum)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	client, err := httpclient.NewClient(
+		httpclient.WithBaseURLs([]string{server.URL}),
+		httpclient.WithResponseChecksumVerification("X-Checksum-Sha256", sha256.New),
+		httpclient.WithMaxRetries(1))
+	require.NoError(t, err)
+
+	t.Run("valid checksum", func(t *testing.T) {
+		requests, checksums = 0, []string{validChecksum}
+		var out map[string]string
+		_, err := client.Get(context.Background(), httpclient.WithJSONResponse(&out))
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{"key": "value"}, out)
+		assert.Equal(t, 1, requests)
+	})
+
+	t.Run("base64 checksum", func(t *testing.T) {
+		requests, checksums = 0, []string{base64.StdEncoding.EncodeToString(digest[:])}
+		_, err := client.Get(context.Background())
+		require.NoError(t, err)
+	})
+
+	t.Run("missing checksum is not verified", func(t *testing.T) {
+		requests, checksums = 0, []string{""}
+		_, err := client.Get(context.Background())
+		require.NoError(t, err)
+	})
+
+	t.Run("mismatch is retried", func(t *testing.T) {
+		requests, checksums = 0, []string{badChecksum, validChecksum}
+		var out map[string]string
+		_, err := client.Get(context.Background(), httpclient.WithJSONResponse(&out))
+		require.NoError(t, err)
+		assert.Equal(t, 2, requests)
+	})
+
+	t.Run("mismatch", func(t *testing.T) {
+		requests, checksums = 0, []string{badChecksum}
+		var out map[string]string
+		_, err := client.Get(context.Background(), httpclient.WithJSONResponse(&out))
+		require.Error(t, err)
+		assert.True(t, errors.Is(err, httpclient.ErrResponseChecksumMismatch), "expected checksum mismatch, got %v", err)
+		assert.Equal(t, 2, requests)
+	})
+
+	t.Run("raw body mismatch", func(t *testing.T) {
+		requests, checksums = 0, []string{badChecksum}
+		resp, err := client.Get(context.Background(), httpclient.WithRawResponseBody())
+		require.NoError(t, err)
+		defer func() {
+			_ = resp.Body.Close()
+		}()
+		content, err := ioutil.ReadAll(resp.Body)
+		assert.True(t, errors.Is(err, httpclient.ErrResponseChecksumMismatch), "expected checksum mismatch, got %v", err)
+		assert.Equal(t, body, string(content))
+		safeParams, _ := werror.ParamsFromError(err)
+		assert.Equal(t, badChecksum, safeParams["expectedChecksum"])
+		assert.Equal(t, validChecksum, safeParams["actualChecksum"])
+	})
+}
+
+func TestTruncatedResponse(t *testing.T) {
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		calls++
+		body := `{"key":"value"}`
+		rw.Header().Set("Content-Type", "application/json")
+		rw.Header().Set("Content-Length", "100")
+		if req.URL.Path == "/retry" && calls > 1 {
+			rw.Header().Set("Content-Length", fmt.Sprint(len(body)))
+		} else {
+			// the server closes the connection after the handler writes fewer bytes than the Content-Length.
+			body = body[:8]
+		}
+		_, _ = rw.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	client, err := httpclient.NewClient(httpclient.WithBaseURLs([]string{server.URL}))
+	require.NoError(t, err)
+
+	t.Run("retried", func(t *testing.T) {
+		calls = 0
+		var actual map[string]string
+		_, err := client.Get(context.Background(), httpclient.WithPath("/retry"), httpclient.WithJSONResponse(&actual))
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{"key": "value"}, actual)
+		assert.Equal(t, 2, calls)
+	})
+	t.Run("typed error", func(t *testing.T) {
+		calls = 0
+		var actual map[string]string
+		_, err := client.Get(context.Background(), httpclient.WithPath("/truncated"), httpclient.WithJSONResponse(&actual))
+		require.Error(t, err)
+		assert.True(t, errors.Is(err, httpclient.ErrResponseTruncated), "%v", err)
+		assert.True(t, errors.Is(err, io.ErrUnexpectedEOF), "%v", err)
+		safeParams, _ := werror.ParamsFromError(err)
+		assert.Equal(t, int64(100), safeParams["contentLength"])
+		assert.Equal(t, int64(8), safeParams["responseBytesRead"])
+		assert.Greater(t, calls, 1)
+	})
+}
@@ -0,0 +1,117 @@
+// Copyright (c) 2026 Palantir Technologies. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpclient
+
+import (
+	"encoding/json"
+	"math"
+	"strconv"
+	"strings"
+
+	werror "github.com/palantir/witchcraft-go-error"
+)
+
+// ByteSize is a size in bytes used by ClientConfig fields. In JSON and YAML it may be written either as an integer
+// number of bytes or as a string with a unit suffix, e.g. "512", "64KB" or "1.5MiB". Units are case-insensitive:
+// B, KB, MB, GB and TB are powers of 1000 and KiB, MiB, GiB and TiB are powers of 1024. A ByteSize is always
+// marshaled as an integer number of bytes. Sizes must not be negative.
+type ByteSize int64
+
+// byteSizeUnits is ordered so that longer suffixes are matched before the "b" suffix they end with.
+var byteSizeUnits = []struct {
+	suffix     string
+	multiplier float64
+}{
+	{"kib", 1 << 10},
+	{"mib", 1 << 20},
+	{"gib", 1 << 30},
+	{"tib", 1 << 40},
+	{"kb", 1e3},
+	{"mb", 1e6},
+	{"gb", 1e9},
+	{"tb", 1e12},
+	{"b", 1},
+}
+
+// ParseByteSize parses a size such as "100MB" or "4096". See ByteSize for the supported units.
+func ParseByteSize(s string) (ByteSize, error) {
+	value := strings.ToLower(strings.TrimSpace(s))
+	multiplier := 1.0
+	for _, unit := range byteSizeUnits {
+		if strings.HasSuffix(value, unit.suffix) {
+			value = strings.TrimSpace(strings.TrimSuffix(value, unit.suffix))
+			multiplier = unit.multiplier
+			break
+		}
+	}
+	if n, err := strconv.ParseInt(value, 10, 64); err == nil && multiplier == 1 {
+		if n < 0 {
+			return 0, werror.Error("byte size must not be negative", werror.SafeParam("byteSize", s))
+		}
+		return ByteSize(n), nil
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+		return 0, werror.Error("invalid byte size", werror.SafeParam("byteSize", s))
+	}
+	size := f * multiplier
+	if size != math.Trunc(size) || size > math.MaxInt64 || size < 0 {
+		return 0, werror.Error("byte size must be a non-negative whole number of bytes within range", werror.SafeParam("byteSize", s))
+	}
+	return ByteSize(size), nil
+}
+
+func (b ByteSize) MarshalJSON() ([]byte, error) {
+	return json.Marshal(int64(b))
+}
+
+func (b *ByteSize) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		// Not a string: only a JSON integer, which is a number of bytes, is accepted.
+		var n int64
+		if err := json.Unmarshal(data, &n); err != nil {
+			return werror.Error("byte size must be a string or a whole number of bytes", werror.SafeParam("byteSize", string(data)))
+		}
+		if n < 0 {
+			return werror.Error("byte size must not be negative", werror.SafeParam("byteSize", n))
+		}
+		*b = ByteSize(n)
+		return nil
+	}
+	size, err := ParseByteSize(s)
+	if err != nil {
+		return err
+	}
+	*b = size
+	return nil
+}
+
+func (b ByteSize) MarshalYAML() (interface{}, error) {
+	return int64(b), nil
+}
+
+func (b *ByteSize) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var s string
+	if err := unmarshal(&s); err != nil {
+		return err
+	}
+	size, err := ParseByteSize(s)
+	if err != nil {
+		return err
+	}
+	*b = size
+	return nil
+}
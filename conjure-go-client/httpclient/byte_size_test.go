@@ -0,0 +1,80 @@
+// Copyright (c) 2026 Palantir Technologies. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpclient
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+)
+
+func TestParseByteSize(t *testing.T) {
+	for _, test := range []struct {
+		Input    string
+		Expected ByteSize
+	}{
+		{Input: "0", Expected: 0},
+		{Input: "4096", Expected: 4096},
+		{Input: "512B", Expected: 512},
+		{Input: "64KB", Expected: 64000},
+		{Input: "64 kb", Expected: 64000},
+		{Input: "100MB", Expected: 100000000},
+		{Input: "2GB", Expected: 2000000000},
+		{Input: "1KiB", Expected: 1024},
+		{Input: "1.5MiB", Expected: 1572864},
+		{Input: "1gib", Expected: 1 << 30},
+	} {
+		t.Run(test.Input, func(t *testing.T) {
+			actual, err := ParseByteSize(test.Input)
+			require.NoError(t, err)
+			assert.Equal(t, test.Expected, actual)
+		})
+	}
+	for _, input := range []string{"", "MB", "ten", "1.5B", "10XB", "1e30GB", "-1", "-2KB"} {
+		_, err := ParseByteSize(input)
+		assert.Error(t, err, input)
+	}
+}
+
+func TestByteSizeConfig(t *testing.T) {
+	var yamlConf ClientConfig
+	require.NoError(t, yaml.UnmarshalStrict([]byte("request-compression-threshold: 64KiB\n"), &yamlConf))
+	require.NotNil(t, yamlConf.RequestCompressionThreshold)
+	assert.Equal(t, ByteSize(65536), *yamlConf.RequestCompressionThreshold)
+
+	require.NoError(t, yaml.UnmarshalStrict([]byte("request-compression-threshold: 1024\n"), &yamlConf))
+	assert.Equal(t, ByteSize(1024), *yamlConf.RequestCompressionThreshold)
+
+	assert.Error(t, yaml.UnmarshalStrict([]byte("request-compression-threshold: lots\n"), &yamlConf))
+
+	var jsonConf ClientConfig
+	require.NoError(t, json.Unmarshal([]byte(`{"request-compression-threshold":"2MB"}`), &jsonConf))
+	assert.Equal(t, ByteSize(2000000), *jsonConf.RequestCompressionThreshold)
+
+	require.NoError(t, json.Unmarshal([]byte(`{"request-compression-threshold":2048}`), &jsonConf))
+	assert.Equal(t, ByteSize(2048), *jsonConf.RequestCompressionThreshold)
+
+	for _, input := range []string{`-1`, `1.5`, `true`, `{}`, `[]`} {
+		var size ByteSize
+		assert.Error(t, json.Unmarshal([]byte(input), &size), input)
+	}
+
+	out, err := json.Marshal(jsonConf)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"request-compression-threshold":2048,"metrics":{},"security":{}}`, string(out))
+}
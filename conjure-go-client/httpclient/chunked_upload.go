@@ -0,0 +1,197 @@
+// Copyright (c) 2026 Palantir Technologies. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpclient
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"sync"
+
+	werror "github.com/palantir/witchcraft-go-error"
+)
+
+const (
+	defaultUploadChunkSize   = 64 * 1024 * 1024
+	defaultUploadParallelism = 4
+)
+
+// UploadChunk describes a single ranged request made by UploadChunked.
+type UploadChunk struct {
+	// Index is the zero-based position of the chunk in the source.
+	Index int
+	// Offset is the position of the first byte of the chunk in the source.
+	Offset int64
+	// Length is the number of bytes in the chunk.
+	Length int64
+	// Total is the size of the entire source.
+	Total int64
+}
+
+// ContentRange returns the value of the Content-Range header for the chunk, e.g. "bytes 0-1023/4096".
+func (c UploadChunk) ContentRange() string {
+	return fmt.Sprintf("bytes %d-%d/%d", c.Offset, c.Offset+c.Length-1, c.Total)
+}
+
+// ChunkedUploadParam configures UploadChunked.
+type ChunkedUploadParam interface {
+	applyChunkedUpload(*chunkedUploader) error
+}
+
+type chunkedUploadParamFunc func(*chunkedUploader) error
+
+func (f chunkedUploadParamFunc) applyChunkedUpload(u *chunkedUploader) error {
+	return f(u)
+}
+
+// WithUploadChunkSize sets the maximum number of bytes sent in each request. Defaults to 64MiB.
+func WithUploadChunkSize(chunkSize int64) ChunkedUploadParam {
+	return chunkedUploadParamFunc(func(u *chunkedUploader) error {
+		if chunkSize <= 0 {
+			return werror.Error("upload chunk size must be positive", werror.SafeParam("chunkSize", chunkSize))
+		}
+		u.chunkSize = chunkSize
+		return nil
+	})
+}
+
+// WithUploadParallelism sets the maximum number of chunks uploaded concurrently. Defaults to 4.
+func WithUploadParallelism(parallelism int) ChunkedUploadParam {
+	return chunkedUploadParamFunc(func(u *chunkedUploader) error {
+		if parallelism <= 0 {
+			return werror.Error("upload parallelism must be positive", werror.SafeParam("parallelism", parallelism))
+		}
+		u.parallelism = parallelism
+		return nil
+	})
+}
+
+// WithUploadChunkChecksum computes a digest of each chunk using newHash and sends it base64-encoded in the
+// provided header, e.g. WithUploadChunkChecksum("Content-MD5", md5.New).
+func WithUploadChunkChecksum(header string, newHash func() hash.Hash) ChunkedUploadParam {
+	return chunkedUploadParamFunc(func(u *chunkedUploader) error {
+		u.checksumHeader = header
+		u.newHash = newHash
+		return nil
+	})
+}
+
+// WithUploadChunkRequestParams provides the request params used for each chunk, e.g. the path of the part being
+// uploaded. These are applied after the defaults (a PUT request with the Content-Range header set), so they may
+// override the request method or headers.
+func WithUploadChunkRequestParams(chunkParams func(chunk UploadChunk) []RequestParam) ChunkedUploadParam {
+	return chunkedUploadParamFunc(func(u *chunkedUploader) error {
+		u.chunkParams = chunkParams
+		return nil
+	})
+}
+
+type chunkedUploader struct {
+	chunkSize      int64
+	parallelism    int
+	checksumHeader string
+	newHash        func() hash.Hash
+	chunkParams    func(chunk UploadChunk) []RequestParam
+}
+
+// UploadChunked splits the first size bytes of source into chunks and uploads each one using a separate ranged
+// request executed by client. Because each chunk is a separate call to client.Do, every chunk benefits from the
+// client's URI selection, retries and metrics. Chunks are uploaded concurrently; the first failure cancels any
+// in-progress chunks and is returned.
+func UploadChunked(ctx context.Context, client Client, source io.ReaderAt, size int64, params ...ChunkedUploadParam) error {
+	u := &chunkedUploader{
+		chunkSize:   defaultUploadChunkSize,
+		parallelism: defaultUploadParallelism,
+	}
+	for _, p := range params {
+		if p == nil {
+			continue
+		}
+		if err := p.applyChunkedUpload(u); err != nil {
+			return err
+		}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		firstErr error
+	)
+	sem := make(chan struct{}, u.parallelism)
+	for i, offset := 0, int64(0); offset < size; i, offset = i+1, offset+u.chunkSize {
+		chunk := UploadChunk{
+			Index:  i,
+			Offset: offset,
+			Length: min(u.chunkSize, size-offset),
+			Total:  size,
+		}
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			if err := u.uploadChunk(ctx, client, source, chunk); err != nil {
+				errOnce.Do(func() {
+					firstErr = err
+					cancel()
+				})
+			}
+		}()
+	}
+	wg.Wait()
+	if firstErr != nil {
+		return firstErr
+	}
+	return ctx.Err()
+}
+
+func (u *chunkedUploader) uploadChunk(ctx context.Context, client Client, source io.ReaderAt, chunk UploadChunk) error {
+	chunkParams := []werror.Param{
+		werror.SafeParam("chunkIndex", chunk.Index),
+		werror.SafeParam("chunkOffset", chunk.Offset),
+		werror.SafeParam("chunkLength", chunk.Length),
+	}
+	params := []RequestParam{
+		WithRequestMethod(http.MethodPut),
+		WithHeader("Content-Range", chunk.ContentRange()),
+	}
+	if u.newHash != nil {
+		h := u.newHash()
+		if _, err := io.Copy(h, io.NewSectionReader(source, chunk.Offset, chunk.Length)); err != nil {
+			return werror.WrapWithContextParams(ctx, err, "failed to compute upload chunk checksum", chunkParams...)
+		}
+		params = append(params, WithHeader(u.checksumHeader, base64.StdEncoding.EncodeToString(h.Sum(nil))))
+	}
+	params = append(params, WithRequestBodyReader(io.NewSectionReader(source, chunk.Offset, chunk.Length), chunk.Length, ""))
+	if u.chunkParams != nil {
+		params = append(params, u.chunkParams(chunk)...)
+	}
+	if _, err := client.Do(ctx, params...); err != nil {
+		return werror.WrapWithContextParams(ctx, err, "failed to upload chunk", chunkParams...)
+	}
+	return nil
+}
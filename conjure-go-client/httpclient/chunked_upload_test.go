@@ -0,0 +1,97 @@
+// Copyright (c) 2026 Palantir Technologies. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpclient_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/palantir/conjure-go-runtime/v2/conjure-go-client/httpclient"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUploadChunked(t *testing.T) {
+	var mu sync.Mutex
+	parts := map[string]string{}
+	failed := map[string]bool{}
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, http.MethodPut, req.Method)
+		body, err := ioutil.ReadAll(req.Body)
+		require.NoError(t, err)
+		sum := sha256.Sum256(body)
+		assert.Equal(t, base64.StdEncoding.EncodeToString(sum[:]), req.Header.Get("X-Checksum"))
+
+		mu.Lock()
+		defer mu.Unlock()
+		// fail each part once to exercise retries
+		if !failed[req.URL.Path] {
+			failed[req.URL.Path] = true
+			rw.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		parts[req.URL.Path] = req.Header.Get("Content-Range") + " " + string(body)
+	}))
+	defer server.Close()
+
+	client, err := httpclient.NewClient(httpclient.WithBaseURLs([]string{server.URL}))
+	require.NoError(t, err)
+
+	source := "abcdefghij"
+	err = httpclient.UploadChunked(context.Background(), client, strings.NewReader(source), int64(len(source)),
+		httpclient.WithUploadChunkSize(4),
+		httpclient.WithUploadParallelism(2),
+		httpclient.WithUploadChunkChecksum("X-Checksum", sha256.New),
+		httpclient.WithUploadChunkRequestParams(func(chunk httpclient.UploadChunk) []httpclient.RequestParam {
+			return []httpclient.RequestParam{httpclient.WithPathf("/parts/%d", chunk.Index)}
+		}))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"/parts/0": "bytes 0-3/10 abcd",
+		"/parts/1": "bytes 4-7/10 efgh",
+		"/parts/2": "bytes 8-9/10 ij",
+	}, parts)
+}
+
+func TestUploadChunkedError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.URL.Path == "/parts/1" {
+			rw.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	client, err := httpclient.NewClient(httpclient.WithBaseURLs([]string{server.URL}))
+	require.NoError(t, err)
+
+	err = httpclient.UploadChunked(context.Background(), client, strings.NewReader("abcdefghij"), 10,
+		httpclient.WithUploadChunkSize(4),
+		httpclient.WithUploadChunkRequestParams(func(chunk httpclient.UploadChunk) []httpclient.RequestParam {
+			return []httpclient.RequestParam{httpclient.WithPath(fmt.Sprintf("/parts/%d", chunk.Index))}
+		}))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to upload chunk")
+	statusCode, ok := httpclient.StatusCodeFromError(err)
+	require.True(t, ok)
+	assert.Equal(t, http.StatusBadRequest, statusCode)
+}
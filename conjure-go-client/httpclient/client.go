@@ -16,16 +16,22 @@ package httpclient
 
 import (
 	"context"
+	"errors"
+	"io"
 	"net/http"
 	"net/url"
 	"strings"
+	"sync/atomic"
+	"time"
 
 	"github.com/palantir/conjure-go-runtime/v2/conjure-go-client/httpclient/internal"
 	"github.com/palantir/conjure-go-runtime/v2/conjure-go-client/httpclient/internal/refreshingclient"
 	"github.com/palantir/pkg/bytesbuffers"
 	"github.com/palantir/pkg/refreshable"
+	refreshablev2 "github.com/palantir/pkg/refreshable/v2"
 	werror "github.com/palantir/witchcraft-go-error"
 	"github.com/palantir/witchcraft-go-logging/wlog/svclog/svc1log"
+	wparams "github.com/palantir/witchcraft-go-params"
 )
 
 // A Client executes requests to a configured service.
@@ -57,9 +63,57 @@ type clientImpl struct {
 	recoveryMiddleware     Middleware
 
 	uriScorer      internal.RefreshableURIScoringMiddleware
-	maxAttempts    refreshable.IntPtr // 0 means no limit. If nil, uses 2*len(uris).
-	backoffOptions refreshingclient.RefreshableRetryParams
+	uriGroups      *uriGroupSelector     // nil unless URI groups are configured.
+	uriSelector    URISelector           // nil unless set by WithURISelector.
+	uriDrainer     *uriDrainer           // shared with the clients derived by DeriveClient.
+	retryBudget    *internal.RetryBudget // shared with the clients derived by DeriveClient.
+	maxAttempts    refreshable.IntPtr    // 0 means no limit. If nil, uses 2*len(uris).
+	backoffOptions refreshablev2.Refreshable[refreshingclient.RetryParams]
 	bufferPool     bytesbuffers.Pool
+	validator      Validator
+	bodyWrappers   []ResponseBodyWrapper
+	// checkContentType is set by WithResponseContentTypeCheck.
+	checkContentType bool
+	// responseChecksum is set by WithResponseChecksumVerification.
+	responseChecksum   *responseChecksum
+	unavailablePolicy  AllNodesUnavailablePolicy
+	authFailoverPolicy AuthFailoverPolicy
+	attemptHooks       []attemptHooks
+	retryObservers     []RetryObserver
+	// retryPredicate combines the predicates set by WithRetryPredicate. It is nil if there are none.
+	retryPredicate func(resp *http.Response, err error) (retry bool, decided bool)
+	// safeMethodRetriesOnly is set by WithSafeMethodRetriesOnly.
+	safeMethodRetriesOnly bool
+	// fallback is set by WithFallbackClient.
+	fallback *fallbackClient
+	// hedging is set by WithHedgedRequests.
+	hedging *hedgingParams
+	// retryAfter is set by WithRetryAfter.
+	retryAfter *internal.RetryAfterParams
+	// contextTransformers are applied in order to the context of every request. See WithContextTransformer.
+	contextTransformers []func(context.Context) context.Context
+	// metricsFallback attaches the registry set by WithFallbackMetricsRegistry to contexts without one.
+	metricsFallback *metricsRegistryFallback
+
+	// compressionThreshold is the encoded request body size at or above which bodies are compressed with the coding
+	// negotiated by requestEncodings. 0 disables compression.
+	compressionThreshold refreshable.Int
+	requestEncodings     *requestEncodings
+	classPolicies        map[RequestClass]RequestClassPolicy
+	endpointTimeouts     refreshablev2.Refreshable[refreshingclient.EndpointTimeouts]
+	// endpointMaxRetries overrides the maximum number of retries of requests to each endpoint.
+	endpointMaxRetries refreshablev2.Refreshable[refreshingclient.EndpointMaxRetries]
+	// endpoints is set by WithServiceDefinition.
+	endpoints *registeredEndpoints
+
+	// registryEntry is nil unless the client was built with WithClientRegistry.
+	registryEntry *clientRegistryEntry
+	// callMetrics records the client.call metric.
+	callMetrics *metricsMiddleware
+
+	// builder and transport are retained to derive new clients in DeriveClient.
+	builder   *clientBuilder
+	transport http.RoundTripper
 }
 
 func (c *clientImpl) Get(ctx context.Context, params ...RequestParam) (*http.Response, error) {
@@ -82,39 +136,295 @@ func (c *clientImpl) Delete(ctx context.Context, params ...RequestParam) (*http.
 	return c.Do(ctx, append(params, WithRequestMethod(http.MethodDelete))...)
 }
 
+// DeriveClient returns a new Client derived from client with params applied on top of its configuration, e.g. to
+// create an "admin" variant of a client without opening a second set of connections. The derived client shares
+// client's transport and connection pool, so params may change middleware, authentication, timeouts, retries and
+// error handling, but may not change TLS, dialer, proxy or other transport settings; an error is returned if they do.
+// Middlewares added by params see the request after client's middlewares, so headers they set (e.g. Authorization)
+// take precedence. An error is returned if client was not built by this package.
+func DeriveClient(client Client, params ...ClientParam) (Client, error) {
+	switch c := client.(type) {
+	case *clientImpl:
+		return c.withOverrides(params...)
+	case *dualModeClient:
+		return c.withOverrides(params...)
+	default:
+		return nil, werror.Error("client can not be derived because it was not built by this package")
+	}
+}
+
+func (c *clientImpl) withOverrides(params ...ClientParam) (Client, error) {
+	b := c.builder.clone()
+	b.HTTP.Middlewares = nil
+	for _, p := range params {
+		if p == nil {
+			continue
+		}
+		if err := p.apply(b); err != nil {
+			return nil, err
+		}
+	}
+	if b.HTTP.TLSConfig != c.builder.HTTP.TLSConfig ||
+		b.HTTP.RefreshableTLSConfig != c.builder.HTTP.RefreshableTLSConfig ||
+		b.HTTP.TransportParams != c.builder.HTTP.TransportParams ||
+		b.HTTP.DialerParams != c.builder.HTTP.DialerParams {
+		return nil, werror.Error("client overrides can not modify transport configuration",
+			werror.SafeParam("serviceName", b.HTTP.ServiceName.CurrentString()))
+	}
+	if err := b.validate(context.TODO()); err != nil {
+		return nil, err
+	}
+	// Middlewares are applied in order with the first innermost, so overrides run after the parent's middlewares.
+	b.HTTP.Middlewares = append(b.HTTP.Middlewares[:len(b.HTTP.Middlewares):len(b.HTTP.Middlewares)], c.builder.HTTP.Middlewares...)
+	return newClientFromTransport(b, c.transport), nil
+}
+
 func (c *clientImpl) Do(ctx context.Context, params ...RequestParam) (*http.Response, error) {
-	uris := c.uriScorer.CurrentURIScoringMiddleware().GetURIsInOrderOfIncreasingScore()
+	if c.registryEntry != nil {
+		c.registryEntry.inFlight.Add(1)
+		defer c.registryEntry.inFlight.Add(-1)
+	}
+	for _, transform := range c.contextTransformers {
+		ctx = transform(ctx)
+	}
+	ctx = c.metricsFallback.apply(ctx)
+	ctx = ContextWithRequestAnnotations(ctx)
+	ctx = contextWithCallState(ctx)
+	ctx = contextWithRequestClassPolicy(ctx, c.classPolicies)
+	ctx, call := contextWithCallMetricsRecord(ctx)
+	start := time.Now()
+	resp, err := c.doWithRetries(ctx, params...)
+	if c.fallback != nil && c.fallback.shouldFallback(ctx, params, resp, err) {
+		resp, err = c.fallback.do(ctx, c.serviceName.CurrentString(), err, params)
+	}
+	// calls which fail before a request is built, e.g. because of an invalid param, are not recorded.
+	if req := call.request(); req != nil {
+		c.callMetrics.recordCall(ctx, req, resp, err, time.Since(start))
+	}
+	return resp, err
+}
+
+// doWithRetries sends the request to the client's URIs, retrying failed attempts.
+func (c *clientImpl) doWithRetries(ctx context.Context, params ...RequestParam) (*http.Response, error) {
+	scorer := c.uriScorer.CurrentURIScoringMiddleware()
+	uris := scorer.GetURIsInOrderOfIncreasingScore()
 	if len(uris) == 0 {
 		return nil, werror.WrapWithContextParams(ctx, ErrEmptyURIs, "", werror.SafeParam("serviceName", c.serviceName.CurrentString()))
 	}
 
-	attempts := 2 * len(uris)
-	if c.maxAttempts != nil {
-		if confMaxAttempts := c.maxAttempts.CurrentIntPtr(); confMaxAttempts != nil {
-			attempts = *confMaxAttempts
+	if err := c.checkNodesAvailable(ctx, scorer); err != nil {
+		return nil, err
+	}
+	if c.uriGroups != nil {
+		uris = c.uriGroups.orderURIs(uris, scorer)
+	} else if sessionScorer, ok := scorer.(internal.SessionURIScorer); ok {
+		if key := getSessionKey(ctx); key != "" {
+			uris = sessionScorer.GetURIsForSession(key)
 		}
 	}
+	if c.uriSelector != nil {
+		uris = selectURIs(ctx, c.uriSelector, scorer, uris)
+	}
+
+	attempts := c.currentMaxAttempts(len(uris))
+	if policy, ok := getRequestClassPolicy(ctx); ok && policy.MaxRetries != nil {
+		attempts = *policy.MaxRetries + 1
+	}
+	rpcMethodName, err := c.requestRPCMethodName(ctx, params)
+	if errors.Is(err, errEndpointNotRegistered) {
+		// the endpoint of the request can not be resolved on any attempt, so do not retry.
+		return nil, werror.WrapWithContextParams(ctx, err, "", werror.SafeParam("serviceName", c.serviceName.CurrentString()))
+	}
+	if maxRetries, ok := c.endpointMaxRetries.Current()[rpcMethodName]; ok {
+		attempts = maxRetries + 1
+	}
 
-	var err error
 	var resp *http.Response
+	var attemptOutcomes []Attempt
 
-	retrier := internal.NewRequestRetrier(uris, c.backoffOptions.CurrentRetryParams().Start(ctx), attempts)
+	retryParams := c.backoffOptions.Current()
+	backoff := internal.NewBackoffRetrier(ctx, retryParams.InitialBackoff, retryParams.MaxBackoff, backoffObserver(ctx, c.retryObservers))
+	retrier := internal.NewRequestRetrier(uris, backoff, attempts)
+	if c.retryAfter != nil {
+		retrier.HonorRetryAfter(*c.retryAfter)
+	}
+	predicate := c.retryPredicate
+	if c.safeMethodRetriesOnly && !isIdempotentMethod(requestMethod(params)) {
+		predicate = safeMethodRetryPredicate(predicate)
+	}
+	if predicate != nil {
+		retrier.UseRetryPredicate(predicate)
+	}
+	if retryParams.RetryBudgetRatio > 0 || retryParams.MinRetriesPerSecond > 0 {
+		retrier.UseRetryBudget(c.retryBudget, internal.RetryBudgetParams{
+			Ratio:               retryParams.RetryBudgetRatio,
+			MinRetriesPerSecond: retryParams.MinRetriesPerSecond,
+		})
+	}
+	state := getCallState(ctx)
 	for {
+		if isAcceptedRedirect(resp, err) {
+			// The caller handles redirects, so the retrier must not follow them.
+			break
+		}
+		if state != nil && state.bodyNotReplayable.Load() {
+			// The request body can not be sent again.
+			break
+		}
+		if state != nil && state.responseStreamed.Load() {
+			// Part of the response may already have been processed by the caller.
+			break
+		}
+		waitStart := time.Now()
 		uri, isRelocated := retrier.GetNextURI(resp, err)
 		if uri == "" {
 			break
 		}
+		var backoff time.Duration
+		if len(attemptOutcomes) > 0 {
+			backoff = time.Since(waitStart)
+		}
 		if err != nil {
 			svc1log.FromContext(ctx).Debug("Retrying request", svc1log.Stacktrace(err))
 		}
-		resp, err = c.doOnce(ctx, uri, isRelocated, params...)
+		if len(attemptOutcomes) == 0 && c.authFailoverPolicy == AuthFailoverOriginalHostOnly {
+			ctx = contextWithInitialURI(ctx, uri)
+		}
+		attempt := newAttempt(len(attemptOutcomes), c.builder.URIs.CurrentStringSlice(), uri, backoff)
+		for _, hooks := range c.attemptHooks {
+			if hooks.onStart != nil {
+				hooks.onStart(ctx, attempt)
+			}
+		}
+		if c.hedging != nil && !isRelocated {
+			resp, err = c.doHedged(contextWithAttempt(ctx, attempt), uri, uris, params...)
+		} else {
+			resp, err = c.doOnce(contextWithAttempt(ctx, attempt), uri, isRelocated, params...)
+		}
+		attempt = attempt.finish(resp, err)
+		for _, hooks := range c.attemptHooks {
+			if hooks.onFinish != nil {
+				hooks.onFinish(ctx, attempt, resp, err)
+			}
+		}
+		attemptOutcomes = append(attemptOutcomes, attempt)
+	}
+	if retrier.RetryBudgetExhausted() {
+		markRetryBudgetExhausted(ctx, c.serviceName, c.builder.HTTP.DisableMetrics)
+	}
+	if len(attemptOutcomes) == 0 && err == nil {
+		// Do must never return a nil response without an error.
+		return nil, werror.ErrorWithContextParams(ctx, "request was not sent", werror.SafeParam("serviceName", c.serviceName.CurrentString()))
 	}
 	if err != nil {
+		if len(attemptOutcomes) > 1 {
+			err = werror.WrapWithContextParams(ctx, err, "", werror.SafeParam(attemptsParamKey, attemptOutcomes))
+		} else {
+			err = withContextParams(ctx, err)
+		}
 		return nil, err
 	}
 	return resp, nil
 }
 
+// callState holds the state of a single call to Do. Unlike RequestAnnotations, which callers may attach to a
+// context used for several calls, a new callState is created by every call to Do.
+type callState struct {
+	// bodyNotReplayable is set once a request body which can not be sent again has been attached to an attempt.
+	bodyNotReplayable atomic.Bool
+	// responseStreamed is set once the body of a response has been passed to the handler of WithJSONStreamResponse,
+	// so that the request is not retried and the same values are not delivered again.
+	responseStreamed atomic.Bool
+}
+
+func contextWithCallState(ctx context.Context) context.Context {
+	return context.WithValue(ctx, requestCallState, &callState{})
+}
+
+func getCallState(ctx context.Context) *callState {
+	state, _ := ctx.Value(requestCallState).(*callState)
+	return state
+}
+
+// markBodyNotReplayable records that the request body of the call to Do made with ctx can not be sent again, so
+// that the request is not retried.
+func markBodyNotReplayable(ctx context.Context) {
+	if state := getCallState(ctx); state != nil {
+		state.bodyNotReplayable.Store(true)
+	}
+}
+
+// markResponseStreamed records that a response body of the call to Do made with ctx has been passed to the caller,
+// so that the request is not retried.
+func markResponseStreamed(ctx context.Context) {
+	if state := getCallState(ctx); state != nil {
+		state.responseStreamed.Store(true)
+	}
+}
+
+// withContextParams attaches the wparams safe and unsafe params stored on ctx to err. Errors created by the error
+// decoders and body handlers do not have access to the request context, so this ensures every error returned from Do
+// carries the caller's params.
+func withContextParams(ctx context.Context, err error) error {
+	if wparams.ParamStorerFromContext(ctx) == nil {
+		return err
+	}
+	return werror.WrapWithContextParams(ctx, err, "")
+}
+
+// isAcceptedRedirect returns true if resp is a 3xx response to a request made using WithAcceptRedirects.
+func isAcceptedRedirect(resp *http.Response, err error) bool {
+	return err == nil && resp != nil && resp.Request != nil && isRedirectStatus(resp.StatusCode) && acceptsRedirects(resp.Request.Context())
+}
+
+// checkNodesAvailable applies the client's AllNodesUnavailablePolicy if scorer reports that every URI has failed
+// recently.
+func (c *clientImpl) checkNodesAvailable(ctx context.Context, scorer internal.URIScoringMiddleware) error {
+	tracker, ok := scorer.(internal.URIAvailabilityTracker)
+	if !ok || !tracker.AllURIsUnavailable() {
+		return nil
+	}
+	markAllNodesUnavailable(ctx, c.serviceName, c.builder.HTTP.DisableMetrics)
+	if c.unavailablePolicy == AllNodesUnavailableFailFast {
+		return werror.WrapWithContextParams(ctx, ErrAllNodesUnavailable, "", werror.SafeParam("serviceName", c.serviceName.CurrentString()))
+	}
+	return nil
+}
+
+// currentMaxAttempts returns the configured maximum number of attempts for a request, defaulting to twice the number
+// of URIs. 0 means no limit.
+func (c *clientImpl) currentMaxAttempts(numURIs int) int {
+	if c.maxAttempts != nil {
+		if confMaxAttempts := c.maxAttempts.CurrentIntPtr(); confMaxAttempts != nil {
+			return *confMaxAttempts
+		}
+	}
+	return 2 * numURIs
+}
+
+// requestRPCMethodName returns the RPC method name of the request made with ctx and params, which may be set by the
+// params, e.g. with WithEndpoint. An error is returned if the params are invalid.
+func (c *clientImpl) requestRPCMethodName(ctx context.Context, params []RequestParam) (string, error) {
+	b := &requestBuilder{
+		headers:        make(http.Header),
+		query:          make(url.Values),
+		bodyMiddleware: &bodyMiddleware{},
+		endpoints:      c.endpoints,
+	}
+	for _, p := range params {
+		if p == nil {
+			continue
+		}
+		if err := p.apply(b); err != nil {
+			return "", err
+		}
+	}
+	for _, configure := range b.configureCtx {
+		ctx = configure(ctx)
+	}
+	return getRPCMethodName(ctx), nil
+}
+
 func (c *clientImpl) doOnce(
 	ctx context.Context,
 	baseURI string,
@@ -124,9 +434,18 @@ func (c *clientImpl) doOnce(
 
 	// 1. create the request
 	b := &requestBuilder{
-		headers:        make(http.Header),
-		query:          make(url.Values),
-		bodyMiddleware: &bodyMiddleware{bufferPool: c.bufferPool},
+		headers: make(http.Header),
+		query:   make(url.Values),
+		bodyMiddleware: &bodyMiddleware{
+			bufferPool:           c.bufferPool,
+			validator:            c.validator,
+			responseBodyWrappers: c.bodyWrappers,
+			compressionThreshold: c.compressionThreshold.CurrentInt(),
+			requestEncodings:     c.requestEncodings,
+			checkContentType:     c.checkContentType,
+			checksum:             c.responseChecksum,
+		},
+		endpoints: c.endpoints,
 	}
 
 	for _, p := range params {
@@ -141,9 +460,25 @@ func (c *clientImpl) doOnce(
 		b.path = ""
 	}
 
+	gate, hedged := getHedgeGate(ctx)
+	if hedged {
+		gate.attempt.inspect(b)
+	}
+
 	for _, c := range b.configureCtx {
 		ctx = c(ctx)
 	}
+	// resolved after the request params have set the RPC method name, e.g. with WithEndpoint.
+	if timeout, ok := c.endpointTimeouts.Current()[getRPCMethodName(ctx)]; ok && b.requestTimeout == nil {
+		b.requestTimeout = &timeout
+	}
+	if policy, ok := getRequestClassPolicy(ctx); ok && policy.Timeout > 0 && b.requestTimeout == nil {
+		b.requestTimeout = &policy.Timeout
+	}
+	if c.endpoints != nil {
+		c.endpoints.check(ctx)
+	}
+	ctx = contextWithBaseURI(ctx, baseURI)
 
 	if b.method == "" {
 		return nil, werror.ErrorWithContextParams(ctx, "httpclient: use WithRequestMethod() to specify HTTP method")
@@ -155,6 +490,8 @@ func (c *clientImpl) doOnce(
 	}
 
 	req.Header = b.headers
+	req.Close = b.connectionClose
+	setCallRequest(ctx, req)
 	if q := b.query.Encode(); q != "" {
 		req.URL.RawQuery = q
 	}
@@ -167,16 +504,41 @@ func (c *clientImpl) doOnce(
 	if b.requestTimeout != nil {
 		clientCopy.Timeout = *b.requestTimeout
 	}
+	if b.acceptRedirects {
+		clientCopy.CheckRedirect = func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		}
+	}
 
 	transport := clientCopy.Transport // start with the client's transport configured with default middleware
 
 	// must precede the error decoders to read the status code of the raw response.
 	transport = wrapTransport(transport, c.uriScorer.CurrentURIScoringMiddleware())
+	if c.uriDrainer != nil {
+		transport = wrapTransport(transport, c.uriDrainer)
+	}
+	if !b.bodyMiddleware.rawOutput || b.bodyMiddleware.decompressRawOutput {
+		// must precede the error decoders and the body middleware so that they read decompressed bodies.
+		// Raw response bodies are returned as sent unless WithDecompressedRawResponseBody is used.
+		transport = wrapTransport(transport, gzipResponseMiddleware{})
+	}
+	if c.authFailoverPolicy == AuthFailoverOriginalHostOnly {
+		// must follow the client middlewares which set the Authorization header.
+		transport = wrapTransport(transport, stripAuthOnFailoverMiddleware{})
+	}
+	if b.bodyMiddleware.compressionThreshold > 0 {
+		// must precede the error decoders to read the status code and headers of the raw response.
+		transport = wrapTransport(transport, &requestEncodingMiddleware{encodings: c.requestEncodings, body: b.bodyMiddleware})
+	}
 	// request decoder must precede the client decoder
 	// must precede the body middleware to read the response body
 	transport = wrapTransport(transport, b.errorDecoderMiddleware, c.errorDecoderMiddleware)
 	// must precede the body middleware to read the request body
 	transport = wrapTransport(transport, c.middlewares...)
+	if hedged {
+		// must precede the body middleware so that only the winning send reads the response body
+		transport = wrapTransport(transport, gate)
+	}
 	// must wrap inner middlewares to mutate the return values
 	transport = wrapTransport(transport, b.bodyMiddleware)
 	// must be the outermost middleware to recover panics in the rest of the request flow
@@ -187,7 +549,23 @@ func (c *clientImpl) doOnce(
 	clientCopy.Transport = transport
 
 	// 3. execute the request using the client to get and handle the response
+	start := time.Now()
 	resp, respErr := clientCopy.Do(req)
+	if b.requestTimeout != nil && isRequestTimeout(ctx, respErr, time.Since(start), *b.requestTimeout) {
+		markRequestTimeout(ctx, c.serviceName, c.builder.HTTP.DisableMetrics, *b.requestTimeout)
+	}
+
+	if respErr == nil && b.bodyMiddleware.responseWriter != nil {
+		written, err := io.Copy(b.bodyMiddleware.responseWriter, resp.Body)
+		internal.DrainBody(ctx, resp)
+		if err != nil {
+			// Part of the body may already have been written, so return the response along with the error to
+			// prevent the request from being retried.
+			return resp, werror.WrapWithContextParams(ctx, err, "failed to write response body",
+				werror.SafeParam("responseBytesWritten", written))
+		}
+		return resp, nil
+	}
 
 	// unless this is exactly the scenario where the caller has opted into being responsible for draining and closing
 	// the response body, be sure to do so here.
@@ -198,6 +576,12 @@ func (c *clientImpl) doOnce(
 	return resp, unwrapURLError(ctx, respErr)
 }
 
+// isRequestTimeout returns true if respErr was caused by the request exceeding timeout rather than the
+// caller's context being done or an earlier network timeout.
+func isRequestTimeout(ctx context.Context, respErr error, elapsed, timeout time.Duration) bool {
+	return ctx.Err() == nil && elapsed >= timeout && isTimeoutError(respErr)
+}
+
 // unwrapURLError converts a *url.Error to a werror. We need this because all
 // errors from the stdlib's client.Do are wrapped in *url.Error, and if we
 // were to blindly return that we would lose any werror params stored on the
@@ -223,10 +607,66 @@ func unwrapURLError(ctx context.Context, respErr error) error {
 	return werror.WrapWithContextParams(ctx, urlErr.Err, "httpclient request failed", params...)
 }
 
+// joinURIAndPath appends reqPath to the path of baseURI. Both the base URI's path and reqPath are treated as already
+// escaped, so encoded segments such as %2F are preserved rather than being decoded into path separators.
+// Characters in reqPath which are not valid in an escaped path are escaped. A query or fragment in reqPath, starting
+// at the first '?' or '#', is appended as is.
 func joinURIAndPath(baseURI, reqPath string) string {
-	fullURI := strings.TrimRight(baseURI, "/")
+	base, err := url.Parse(baseURI)
+	if err != nil {
+		// base URIs are validated when the client is configured, so this should not occur
+		fullURI := strings.TrimRight(baseURI, "/")
+		if reqPath != "" {
+			fullURI += "/" + strings.TrimLeft(reqPath, "/")
+		}
+		return fullURI
+	}
+	var suffix string
+	if i := strings.IndexAny(reqPath, "?#"); i >= 0 {
+		reqPath, suffix = reqPath[:i], reqPath[i:]
+	}
+	rawPath := strings.TrimRight(base.EscapedPath(), "/")
 	if reqPath != "" {
-		fullURI += "/" + strings.TrimLeft(reqPath, "/")
+		rawPath += "/" + escapeInvalidPathChars(strings.TrimLeft(reqPath, "/"))
+	}
+	path, err := url.PathUnescape(rawPath)
+	if err != nil {
+		// escapeInvalidPathChars only emits valid escape sequences
+		path = rawPath
+	}
+	base.Path, base.RawPath = path, rawPath
+	return base.String() + suffix
+}
+
+// escapeInvalidPathChars percent-encodes the bytes of path which may not appear in an escaped URL path, leaving
+// existing valid escape sequences intact.
+func escapeInvalidPathChars(path string) string {
+	const upperhex = "0123456789ABCDEF"
+	var sb strings.Builder
+	for i := 0; i < len(path); i++ {
+		c := path[i]
+		switch {
+		case c == '%' && i+2 < len(path) && isHex(path[i+1]) && isHex(path[i+2]):
+			sb.WriteByte(c)
+		case isValidPathChar(c):
+			sb.WriteByte(c)
+		default:
+			sb.WriteByte('%')
+			sb.WriteByte(upperhex[c>>4])
+			sb.WriteByte(upperhex[c&15])
+		}
+	}
+	return sb.String()
+}
+
+func isValidPathChar(c byte) bool {
+	switch {
+	case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9':
+		return true
 	}
-	return fullURI
+	return strings.IndexByte("-._~!$&'()*+,;=:@/", c) >= 0
+}
+
+func isHex(c byte) bool {
+	return '0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F'
 }
@@ -18,6 +18,7 @@ package httpclient
 import (
 	"context"
 	"crypto/tls"
+	"errors"
 	"fmt"
 	"net/http"
 	"time"
@@ -27,6 +28,7 @@ import (
 	"github.com/palantir/pkg/bytesbuffers"
 	"github.com/palantir/pkg/metrics"
 	"github.com/palantir/pkg/refreshable"
+	refreshablev2 "github.com/palantir/pkg/refreshable/v2"
 	werror "github.com/palantir/witchcraft-go-error"
 )
 
@@ -43,6 +45,7 @@ const (
 	defaultHTTP2PingTimeout      = 15 * time.Second
 	defaultInitialBackoff        = 250 * time.Millisecond
 	defaultMaxBackoff            = 2 * time.Second
+	defaultMinRetriesPerSecond   = 10
 )
 
 var (
@@ -50,6 +53,10 @@ var (
 	// This check occurs in two places: when the client is constructed and when a request is executed.
 	// To avoid the construction validation, use WithAllowCreateWithEmptyURIs().
 	ErrEmptyURIs = fmt.Errorf("httpclient URLs must not be empty")
+
+	// ErrAllNodesUnavailable is returned when every URI has failed recently and the client was configured with
+	// WithAllNodesUnavailablePolicy(AllNodesUnavailableFailFast).
+	ErrAllNodesUnavailable = errors.New("httpclient all URIs are unavailable")
 )
 
 type clientBuilder struct {
@@ -57,34 +64,103 @@ type clientBuilder struct {
 
 	URIs             refreshable.StringSlice
 	URIScorerBuilder func([]string) internal.URIScoringMiddleware
+	// URISelector, if set, chooses the URIs to try for each request from those ordered by the URI scorer.
+	URISelector URISelector
+	// URIGroups, if set and non-empty, splits requests between groups of URIs by percentage.
+	URIGroups refreshablev2.Refreshable[[]refreshingclient.URIGroup]
+	// AllNodesUnavailablePolicy applies when the URI scorer reports that every URI has failed recently.
+	AllNodesUnavailablePolicy AllNodesUnavailablePolicy
+	// AuthFailoverPolicy applies when a retry is sent to a different host than the first attempt.
+	AuthFailoverPolicy AuthFailoverPolicy
 
 	// If false, NewClient() will return an error when URIs.Current() is empty.
 	// This allows for a refreshable URI slice to be populated after construction but before use.
 	AllowEmptyURIs bool
 
 	ErrorDecoder ErrorDecoder
+	// AdditionalErrorDecoders are consulted in order before ErrorDecoder.
+	AdditionalErrorDecoders []ErrorDecoder
 
 	BytesBufferPool bytesbuffers.Pool
-	MaxAttempts     refreshable.IntPtr
-	RetryParams     refreshingclient.RefreshableRetryParams
+	Validator       Validator
+	BodyWrappers    []ResponseBodyWrapper
+	// If true, response Content-Types are checked against the decoder before decoding.
+	CheckResponseContentType bool
+	// If set, response bodies are verified against a checksum header.
+	ResponseChecksum *responseChecksum
+	AttemptHooks     []attemptHooks
+	RetryObservers   []RetryObserver
+	RetryPredicates  []RetryPredicate
+	MaxAttempts      refreshable.IntPtr
+	RetryParams      refreshablev2.Refreshable[refreshingclient.RetryParams]
+
+	// If set, failed idempotent requests may be sent to a secondary client.
+	Fallback *fallbackClient
+	// If set, slow idempotent requests are also sent to other URIs.
+	Hedging *hedgingParams
+	// If set, the Retry-After durations of throttle responses are honored.
+	RetryAfter *internal.RetryAfterParams
+	// If true, non-idempotent requests are only retried when they are known not to have been processed.
+	SafeMethodRetriesOnly bool
+	// If set, metrics of requests whose context has no registry are recorded in FallbackMetricsRegistry.
+	FallbackMetricsRegistry metrics.Registry
+	// ContextTransformers are applied in order to the context of every request.
+	ContextTransformers []func(context.Context) context.Context
+	// RemovedURIGracePeriod, if positive, is the time after which in-flight requests to URIs removed from the
+	// configuration are canceled.
+	RemovedURIGracePeriod time.Duration
+	// uriDrainer is shared by the clients derived from this builder with DeriveClient. It is nil unless
+	// RemovedURIGracePeriod is positive.
+	uriDrainer *uriDrainer
+	// retryBudget is shared by the clients derived from this builder with DeriveClient.
+	retryBudget *internal.RetryBudget
+
+	RequestCompressionThreshold refreshable.Int
+
+	// EndpointTimeouts maps RPC method names to the timeout of each attempt of requests to that endpoint.
+	EndpointTimeouts refreshablev2.Refreshable[refreshingclient.EndpointTimeouts]
+	// EndpointMaxRetries maps RPC method names to the maximum number of retries of requests to that endpoint.
+	EndpointMaxRetries refreshablev2.Refreshable[refreshingclient.EndpointMaxRetries]
+
+	// Endpoints, if set, are the endpoints registered with WithServiceDefinition.
+	Endpoints *registeredEndpoints
+
+	// RequestClassPolicies overrides the client's behavior for requests of each RequestClass.
+	RequestClassPolicies map[RequestClass]RequestClassPolicy
+
+	// If true, clients are added to the process-wide registry returned by ClientSnapshots.
+	ClientRegistry bool
 }
 
 type httpClientBuilder struct {
-	ServiceName     refreshable.String
-	Timeout         refreshable.Duration
-	DialerParams    refreshingclient.RefreshableDialerParams
-	TLSConfig       *tls.Config // If unset, config in TransportParams will be used.
-	TransportParams refreshingclient.RefreshableTransportParams
-	Middlewares     []Middleware
+	ServiceName  refreshable.String
+	Timeout      refreshable.Duration
+	DialerParams refreshablev2.Refreshable[refreshingclient.DialerParams]
+	TLSConfig    *tls.Config // If unset, config in TransportParams will be used.
+	// If set, takes precedence over TLSConfig and the config in TransportParams.
+	RefreshableTLSConfig *refreshableTLSConfig
+	TransportParams      refreshablev2.Refreshable[refreshingclient.TransportParams]
+	Middlewares          []Middleware
 
 	DisableMetrics      refreshable.Bool
 	MetricsTagProviders []TagsProvider
+	ResponseMetrics     responseMetricsParams
+	LatencyBudgets      refreshablev2.Refreshable[refreshingclient.LatencyBudgets]
+	// If true, in-flight requests to each base URI are bounded by an adaptive limit.
+	ConcurrencyLimiter bool
+	// If set, GET responses are cached in ResponseCache. See WithResponseCache.
+	ResponseCache Cache
+	// CookieJar, if set, stores the cookies of every request. It takes precedence over EnableCookies.
+	CookieJar http.CookieJar
+	// EnableCookies, if set and true, stores cookies in an in-memory jar owned by the client.
+	EnableCookies refreshable.Bool
 
 	// These middleware options are not refreshed anywhere because they are not in ClientConfig,
 	// but they could be made refreshable if ever needed.
 	DisableRequestSpan  bool
 	DisableRecovery     bool
 	DisableTraceHeaders bool
+	NormalizeHeaders    bool
 }
 
 func (b *httpClientBuilder) Build(ctx context.Context, params ...HTTPClientParam) (RefreshableHTTPClient, error) {
@@ -96,12 +172,29 @@ func (b *httpClientBuilder) Build(ctx context.Context, params ...HTTPClientParam
 			return nil, err
 		}
 	}
+	transport, err := b.buildTransport(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return b.buildHTTPClient(transport, b.Middlewares...), nil
+}
 
+// buildTransport returns the base transport, which owns the dialer and connection pool.
+func (b *httpClientBuilder) buildTransport(ctx context.Context) (http.RoundTripper, error) {
 	var tlsProvider refreshingclient.TLSProvider
-	if b.TLSConfig != nil {
+	if b.RefreshableTLSConfig != nil {
+		subscribableProvider, err := refreshingclient.NewSubscribableTLSConfig(ctx, refreshable.ToV2[interface{}](b.RefreshableTLSConfig.r), tlsConfigFromRefreshableValue)
+		if err != nil {
+			return nil, err
+		}
+		tlsProvider = subscribableProvider
+	} else if b.TLSConfig != nil {
 		tlsProvider = refreshingclient.NewStaticTLSConfigProvider(b.TLSConfig)
 	} else {
-		refreshableProvider, err := refreshingclient.NewRefreshableTLSConfig(ctx, b.TransportParams.TLS())
+		tlsParams, _ := refreshablev2.Map(b.TransportParams, func(p refreshingclient.TransportParams) refreshingclient.TLSParams {
+			return p.TLS
+		})
+		refreshableProvider, err := refreshingclient.NewRefreshableTLSConfig(ctx, tlsParams)
 		if err != nil {
 			return nil, err
 		}
@@ -109,15 +202,36 @@ func (b *httpClientBuilder) Build(ctx context.Context, params ...HTTPClientParam
 	}
 
 	dialer := refreshingclient.NewRefreshableDialer(ctx, b.DialerParams)
-	transport := refreshingclient.NewRefreshableTransport(ctx, b.TransportParams, tlsProvider, dialer)
-	transport = wrapTransport(transport, newMetricsMiddleware(b.ServiceName, b.MetricsTagProviders, b.DisableMetrics))
+	return refreshingclient.NewRefreshableTransport(ctx, b.TransportParams, tlsProvider, dialer), nil
+}
+
+// buildHTTPClient wraps transport with the header normalization, latency budget, metrics, tracing and recovery middlewares followed by middlewares.
+func (b *httpClientBuilder) buildHTTPClient(transport http.RoundTripper, middlewares ...Middleware) RefreshableHTTPClient {
+	if b.NormalizeHeaders {
+		// must be innermost to see the headers set by every other middleware.
+		transport = wrapTransport(transport, headerNormalizationMiddleware{})
+	}
+	if jar := b.cookieJar(); jar != nil {
+		// must be inside the error decoders so that cookies set by error responses, e.g. before a retry, are stored.
+		transport = wrapTransport(transport, cookieJarMiddleware{jar: jar})
+	}
+	transport = wrapTransport(transport, &latencyBudgetMiddleware{serviceName: b.ServiceName, budgets: b.LatencyBudgets, disabled: b.DisableMetrics})
+	transport = wrapTransport(transport, newMetricsMiddleware(b.ServiceName, b.MetricsTagProviders, b.DisableMetrics, b.ResponseMetrics))
 	transport = wrapTransport(transport, newTraceMiddleware(b.ServiceName, b.DisableRequestSpan, b.DisableTraceHeaders))
+	if b.ConcurrencyLimiter {
+		// must follow the metrics middleware so that time spent waiting for a slot is not recorded as request latency.
+		transport = wrapTransport(transport, newConcurrencyLimiterMiddleware(b.ServiceName, b.DisableMetrics))
+	}
+	if b.ResponseCache != nil {
+		// must wrap the metrics, tracing and concurrency limiter middlewares so that responses served from the cache
+		// are not recorded as requests.
+		transport = wrapTransport(transport, newResponseCacheMiddleware(b.ServiceName, b.DisableMetrics, b.ResponseCache))
+	}
 	if !b.DisableRecovery {
 		transport = wrapTransport(transport, recoveryMiddleware{})
 	}
-	transport = wrapTransport(transport, b.Middlewares...)
-
-	return refreshingclient.NewRefreshableHTTPClient(transport, b.Timeout), nil
+	transport = wrapTransport(transport, middlewares...)
+	return refreshingclient.NewRefreshableHTTPClient(transport, b.Timeout)
 }
 
 // NewClient returns a configured client ready for use.
@@ -137,6 +251,12 @@ func NewClientFromRefreshableConfig(ctx context.Context, config RefreshableClien
 	return newClient(ctx, b, params...)
 }
 
+// NewClientFromRefreshableV2Config is like NewClientFromRefreshableConfig but accepts a generics-based
+// refreshable from github.com/palantir/pkg/refreshable/v2.
+func NewClientFromRefreshableV2Config(ctx context.Context, config refreshablev2.Refreshable[ClientConfig], params ...ClientParam) (Client, error) {
+	return NewClientFromRefreshableConfig(ctx, NewRefreshingClientConfig(refreshable.FromV2(config)), params...)
+}
+
 func newClient(ctx context.Context, b *clientBuilder, params ...ClientParam) (Client, error) {
 	for _, p := range params {
 		if p == nil {
@@ -146,47 +266,127 @@ func newClient(ctx context.Context, b *clientBuilder, params ...ClientParam) (Cl
 			return nil, err
 		}
 	}
+	if err := b.validate(ctx); err != nil {
+		return nil, err
+	}
+	if b.URIGroups != nil {
+		b.HTTP.MetricsTagProviders = append(b.HTTP.MetricsTagProviders, uriGroupTagsProvider{groups: b.URIGroups})
+	}
+	transport, err := b.HTTP.buildTransport(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if b.RemovedURIGracePeriod > 0 {
+		b.uriDrainer = newURIDrainer(b.URIs, transport, b.RemovedURIGracePeriod)
+	}
+	b.retryBudget = internal.NewRetryBudget()
+	return newClientFromTransport(b, transport), nil
+}
+
+func (b *clientBuilder) validate(ctx context.Context) error {
 	if b.URIs == nil {
-		return nil, werror.ErrorWithContextParams(ctx, "httpclient URLs must be set in configuration or by constructor param", werror.SafeParam("serviceName", b.HTTP.ServiceName.CurrentString()))
+		return werror.ErrorWithContextParams(ctx, "httpclient URLs must be set in configuration or by constructor param", werror.SafeParam("serviceName", b.HTTP.ServiceName.CurrentString()))
 	}
 	if !b.AllowEmptyURIs && len(b.URIs.CurrentStringSlice()) == 0 {
-		return nil, werror.WrapWithContextParams(ctx, ErrEmptyURIs, "", werror.SafeParam("serviceName", b.HTTP.ServiceName.CurrentString()))
+		return werror.WrapWithContextParams(ctx, ErrEmptyURIs, "", werror.SafeParam("serviceName", b.HTTP.ServiceName.CurrentString()))
 	}
+	return nil
+}
+
+// clone returns a copy of the builder which can be modified without affecting b.
+func (b *clientBuilder) clone() *clientBuilder {
+	httpBuilder := *b.HTTP
+	httpBuilder.Middlewares = httpBuilder.Middlewares[:len(httpBuilder.Middlewares):len(httpBuilder.Middlewares)]
+	httpBuilder.MetricsTagProviders = httpBuilder.MetricsTagProviders[:len(httpBuilder.MetricsTagProviders):len(httpBuilder.MetricsTagProviders)]
+	clientBuilder := *b
+	clientBuilder.BodyWrappers = clientBuilder.BodyWrappers[:len(clientBuilder.BodyWrappers):len(clientBuilder.BodyWrappers)]
+	clientBuilder.AttemptHooks = clientBuilder.AttemptHooks[:len(clientBuilder.AttemptHooks):len(clientBuilder.AttemptHooks)]
+	clientBuilder.RetryObservers = clientBuilder.RetryObservers[:len(clientBuilder.RetryObservers):len(clientBuilder.RetryObservers)]
+	clientBuilder.RetryPredicates = clientBuilder.RetryPredicates[:len(clientBuilder.RetryPredicates):len(clientBuilder.RetryPredicates)]
+	clientBuilder.AdditionalErrorDecoders = clientBuilder.AdditionalErrorDecoders[:len(clientBuilder.AdditionalErrorDecoders):len(clientBuilder.AdditionalErrorDecoders)]
+	clientBuilder.ContextTransformers = clientBuilder.ContextTransformers[:len(clientBuilder.ContextTransformers):len(clientBuilder.ContextTransformers)]
+	clientBuilder.HTTP = &httpBuilder
+	return &clientBuilder
+}
 
+// newClientFromTransport returns a client using the provided base transport. The builder is retained by the client
+// so that it may be derived using DeriveClient and must not be modified afterwards.
+func newClientFromTransport(b *clientBuilder, transport http.RoundTripper) *clientImpl {
 	var edm Middleware
+	decoders := b.AdditionalErrorDecoders[:len(b.AdditionalErrorDecoders):len(b.AdditionalErrorDecoders)]
 	if b.ErrorDecoder != nil {
-		edm = errorDecoderMiddleware{errorDecoder: b.ErrorDecoder}
+		decoders = append(decoders, b.ErrorDecoder)
 	}
-
-	middleware := b.HTTP.Middlewares
-	b.HTTP.Middlewares = nil
-
-	httpClient, err := b.HTTP.Build(ctx)
-	if err != nil {
-		return nil, err
+	switch len(decoders) {
+	case 0:
+	case 1:
+		edm = errorDecoderMiddleware{errorDecoder: decoders[0]}
+	default:
+		edm = errorDecoderMiddleware{errorDecoder: errorDecoderChain(decoders)}
 	}
 
+	// client middlewares are applied per-request by clientImpl rather than by the http client
+	httpClient := b.HTTP.buildHTTPClient(transport)
+
 	var recovery Middleware
 	if !b.HTTP.DisableRecovery {
 		recovery = recoveryMiddleware{}
 	}
+	// the subscription to the URIs must not reference the builder, which would keep its uriDrainer reachable.
+	scorerBuilder := b.URIScorerBuilder
 	uriScorer := internal.NewRefreshableURIScoringMiddleware(b.URIs, func(uris []string) internal.URIScoringMiddleware {
-		if b.URIScorerBuilder == nil {
+		if scorerBuilder == nil {
 			return internal.NewBalancedURIScoringMiddleware(uris, func() int64 { return time.Now().UnixNano() })
 		}
-		return b.URIScorerBuilder(uris)
+		return scorerBuilder(uris)
 	})
-	return &clientImpl{
+	var uriGroups *uriGroupSelector
+	if b.URIGroups != nil {
+		uriGroups = newURIGroupSelector(b.URIGroups)
+	}
+	c := &clientImpl{
 		serviceName:            b.HTTP.ServiceName,
 		client:                 httpClient,
 		uriScorer:              uriScorer,
+		uriGroups:              uriGroups,
+		uriSelector:            b.URISelector,
 		maxAttempts:            b.MaxAttempts,
 		backoffOptions:         b.RetryParams,
-		middlewares:            middleware,
+		middlewares:            b.HTTP.Middlewares,
 		errorDecoderMiddleware: edm,
 		recoveryMiddleware:     recovery,
 		bufferPool:             b.BytesBufferPool,
-	}, nil
+		validator:              b.Validator,
+		bodyWrappers:           b.BodyWrappers,
+		checkContentType:       b.CheckResponseContentType,
+		responseChecksum:       b.ResponseChecksum,
+		unavailablePolicy:      b.AllNodesUnavailablePolicy,
+		authFailoverPolicy:     b.AuthFailoverPolicy,
+		attemptHooks:           b.AttemptHooks,
+		retryObservers:         b.RetryObservers,
+		retryPredicate:         retryPredicates(b.RetryPredicates),
+		safeMethodRetriesOnly:  b.SafeMethodRetriesOnly,
+		fallback:               b.Fallback,
+		hedging:                b.Hedging,
+		retryAfter:             b.RetryAfter,
+		contextTransformers:    b.ContextTransformers,
+		metricsFallback:        newMetricsRegistryFallback(b.HTTP.ServiceName, b.HTTP.DisableMetrics, b.FallbackMetricsRegistry),
+		uriDrainer:             b.uriDrainer,
+		retryBudget:            b.retryBudget,
+		compressionThreshold:   b.RequestCompressionThreshold,
+		requestEncodings:       newRequestEncodings(),
+		classPolicies:          b.RequestClassPolicies,
+		endpointTimeouts:       b.EndpointTimeouts,
+		endpointMaxRetries:     b.EndpointMaxRetries,
+		endpoints:              b.Endpoints,
+		callMetrics:            newMetricsMiddleware(b.HTTP.ServiceName, b.HTTP.MetricsTagProviders, b.HTTP.DisableMetrics, b.HTTP.ResponseMetrics),
+		builder:                b,
+		transport:              transport,
+	}
+	if b.ClientRegistry {
+		defaultClientRegistry.register(c)
+	}
+	return c
 }
 
 // NewHTTPClient returns a configured http client ready for use.
@@ -213,17 +413,23 @@ func NewHTTPClientFromRefreshableConfig(ctx context.Context, config RefreshableC
 	return b.HTTP.Build(ctx, params...)
 }
 
+// NewHTTPClientFromRefreshableV2Config is like NewHTTPClientFromRefreshableConfig but accepts a generics-based
+// refreshable from github.com/palantir/pkg/refreshable/v2. The returned client is a v1 refreshable.
+func NewHTTPClientFromRefreshableV2Config(ctx context.Context, config refreshablev2.Refreshable[ClientConfig], params ...HTTPClientParam) (RefreshableHTTPClient, error) {
+	return NewHTTPClientFromRefreshableConfig(ctx, NewRefreshingClientConfig(refreshable.FromV2(config)), params...)
+}
+
 func newClientBuilder() *clientBuilder {
 	return &clientBuilder{
 		HTTP: &httpClientBuilder{
 			ServiceName: refreshable.NewString(refreshable.NewDefaultRefreshable("")),
 			Timeout:     refreshable.NewDuration(refreshable.NewDefaultRefreshable(defaultHTTPTimeout)),
-			DialerParams: refreshingclient.NewRefreshingDialerParams(refreshable.NewDefaultRefreshable(refreshingclient.DialerParams{
+			DialerParams: refreshablev2.New(refreshingclient.DialerParams{
 				DialTimeout:   defaultDialTimeout,
 				KeepAlive:     defaultKeepAlive,
 				SocksProxyURL: nil,
-			})),
-			TransportParams: refreshingclient.NewRefreshingTransportParams(refreshable.NewDefaultRefreshable(refreshingclient.TransportParams{
+			}),
+			TransportParams: refreshablev2.New(refreshingclient.TransportParams{
 				MaxIdleConns:          defaultMaxIdleConns,
 				MaxIdleConnsPerHost:   defaultMaxIdleConnsPerHost,
 				DisableHTTP2:          false,
@@ -236,10 +442,11 @@ func newClientBuilder() *clientBuilder {
 				ProxyFromEnvironment:  true,
 				HTTP2ReadIdleTimeout:  defaultHTTP2ReadIdleTimeout,
 				HTTP2PingTimeout:      defaultHTTP2PingTimeout,
-			})),
+			}),
 			Middlewares:         nil,
 			DisableMetrics:      refreshable.NewBool(refreshable.NewDefaultRefreshable(false)),
 			MetricsTagProviders: nil,
+			LatencyBudgets:      refreshablev2.New(refreshingclient.LatencyBudgets{}),
 			DisableRecovery:     false,
 			DisableRequestSpan:  false,
 			DisableTraceHeaders: false,
@@ -248,41 +455,112 @@ func newClientBuilder() *clientBuilder {
 		BytesBufferPool: nil,
 		ErrorDecoder:    restErrorDecoder{},
 		MaxAttempts:     nil,
-		RetryParams: refreshingclient.NewRefreshingRetryParams(refreshable.NewDefaultRefreshable(refreshingclient.RetryParams{
+		RetryParams: refreshablev2.New(refreshingclient.RetryParams{
 			InitialBackoff: defaultInitialBackoff,
 			MaxBackoff:     defaultMaxBackoff,
-		})),
+		}),
+		RequestCompressionThreshold: refreshable.NewInt(refreshable.NewDefaultRefreshable(0)),
+		EndpointTimeouts:            refreshablev2.New(refreshingclient.EndpointTimeouts{}),
+		EndpointMaxRetries:          refreshablev2.New(refreshingclient.EndpointMaxRetries{}),
 	}
 }
 
 func newClientBuilderFromRefreshableConfig(ctx context.Context, config RefreshableClientConfig, b *clientBuilder, reloadErrorSubmitter func(error)) error {
-	refreshingParams, err := refreshable.NewMapValidatingRefreshable(config, func(i interface{}) (interface{}, error) {
-		p, err := newValidatedClientParamsFromConfig(ctx, i.(ClientConfig))
+	tracker := &configRefreshTracker{}
+	validatedParams, _, err := refreshablev2.MapWithError(refreshable.ToV2[ClientConfig](config), func(c ClientConfig) (refreshingclient.ValidatedClientParams, error) {
+		tracker.observe()
+		p, err := newValidatedClientParamsFromConfig(ctx, c)
 		if reloadErrorSubmitter != nil {
 			reloadErrorSubmitter(err)
 		}
+		if err == nil {
+			reportConfigWarnings(ctx, p)
+		}
 		return p, err
 	})
 	if err != nil {
 		return err
 	}
-	validParams := refreshingclient.NewRefreshingValidatedClientParams(refreshingParams)
+	// subscribers of validatedParams are also notified of invalid updates, which leave the params unchanged.
+	validParams, _ := refreshablev2.Map(validatedParams, func(p refreshingclient.ValidatedClientParams) refreshingclient.ValidatedClientParams {
+		return p
+	})
+	tracker.params = validParams
+	// called with the initial params, which are the first generation.
+	validParams.Subscribe(func(refreshingclient.ValidatedClientParams) { tracker.changed() })
+	tracker.applied(ctx)
+	// subscribed after the validating refreshable, so this runs once every value derived from the params is updated.
+	config.Subscribe(func(interface{}) { tracker.applied(ctx) })
 
-	b.HTTP.ServiceName = validParams.ServiceName()
-	b.HTTP.DialerParams = validParams.Dialer()
-	b.HTTP.TransportParams = validParams.Transport()
-	b.HTTP.Timeout = validParams.Timeout()
-	b.HTTP.DisableMetrics = validParams.DisableMetrics()
+	b.HTTP.ServiceName = refreshable.NewString(mapValidParams(validParams, func(p refreshingclient.ValidatedClientParams) string {
+		return p.ServiceName
+	}))
+	b.HTTP.DialerParams, _ = refreshablev2.Map(validParams, func(p refreshingclient.ValidatedClientParams) refreshingclient.DialerParams {
+		return p.Dialer
+	})
+	b.HTTP.TransportParams, _ = refreshablev2.Map(validParams, func(p refreshingclient.ValidatedClientParams) refreshingclient.TransportParams {
+		return p.Transport
+	})
+	b.HTTP.Timeout = refreshable.NewDuration(mapValidParams(validParams, func(p refreshingclient.ValidatedClientParams) time.Duration {
+		return p.Timeout
+	}))
+	b.HTTP.DisableMetrics = refreshable.NewBool(mapValidParams(validParams, func(p refreshingclient.ValidatedClientParams) bool {
+		return p.DisableMetrics
+	}))
+	b.HTTP.LatencyBudgets, _ = refreshablev2.Map(validParams, func(p refreshingclient.ValidatedClientParams) refreshingclient.LatencyBudgets {
+		return p.LatencyBudgets
+	})
+	if validParams.Current().EnableCookies {
+		// the jar is only installed if cookies are enabled when the client is built; refreshes can then toggle it.
+		b.HTTP.EnableCookies = refreshable.NewBool(mapValidParams(validParams, func(p refreshingclient.ValidatedClientParams) bool {
+			return p.EnableCookies
+		}))
+	}
 	b.HTTP.MetricsTagProviders = append(b.HTTP.MetricsTagProviders,
 		TagsProviderFunc(func(*http.Request, *http.Response, error) metrics.Tags {
-			return validParams.CurrentValidatedClientParams().MetricsTags
+			return validParams.Current().MetricsTags
 		}))
+	apiToken := refreshable.NewStringPtr(mapValidParams(validParams, func(p refreshingclient.ValidatedClientParams) *string {
+		return p.APIToken
+	}))
+	basicAuth, _ := refreshablev2.Map(validParams, func(p refreshingclient.ValidatedClientParams) *refreshingclient.BasicAuth {
+		return p.BasicAuth
+	})
+	staticHeaders, _ := refreshablev2.Map(validParams, func(p refreshingclient.ValidatedClientParams) refreshingclient.StaticHeaders {
+		return p.StaticHeaders
+	})
 	b.HTTP.Middlewares = append(b.HTTP.Middlewares,
-		newAuthTokenMiddlewareFromRefreshable(validParams.APIToken()),
-		newBasicAuthMiddlewareFromRefreshable(validParams.BasicAuth()))
+		newAuthTokenMiddlewareFromRefreshable(apiToken),
+		newBasicAuthMiddlewareFromRefreshable(basicAuth),
+		&staticHeadersMiddleware{headers: staticHeaders})
 
-	b.URIs = validParams.URIs()
-	b.MaxAttempts = validParams.MaxAttempts()
-	b.RetryParams = validParams.Retry()
+	b.URIs = refreshable.NewStringSlice(mapValidParams(validParams, func(p refreshingclient.ValidatedClientParams) []string {
+		return p.URIs
+	}))
+	b.URIGroups, _ = refreshablev2.Map(validParams, func(p refreshingclient.ValidatedClientParams) []refreshingclient.URIGroup {
+		return p.URIGroups
+	})
+	b.MaxAttempts = refreshable.NewIntPtr(mapValidParams(validParams, func(p refreshingclient.ValidatedClientParams) *int {
+		return p.MaxAttempts
+	}))
+	b.RetryParams, _ = refreshablev2.Map(validParams, func(p refreshingclient.ValidatedClientParams) refreshingclient.RetryParams {
+		return p.Retry
+	})
+	b.RequestCompressionThreshold = refreshable.NewInt(mapValidParams(validParams, func(p refreshingclient.ValidatedClientParams) int {
+		return p.RequestCompressionThreshold
+	}))
+	b.EndpointTimeouts, _ = refreshablev2.Map(validParams, func(p refreshingclient.ValidatedClientParams) refreshingclient.EndpointTimeouts {
+		return p.EndpointTimeouts
+	})
+	b.EndpointMaxRetries, _ = refreshablev2.Map(validParams, func(p refreshingclient.ValidatedClientParams) refreshingclient.EndpointMaxRetries {
+		return p.EndpointMaxRetries
+	})
 	return nil
 }
+
+// mapValidParams returns a v1 refreshable of the value mapFn selects from the validated params, for the fields of the
+// builders which are not typed refreshables.
+func mapValidParams[T any](params refreshablev2.Refreshable[refreshingclient.ValidatedClientParams], mapFn func(refreshingclient.ValidatedClientParams) T) refreshable.Refreshable {
+	mapped, _ := refreshablev2.Map(params, mapFn)
+	return refreshable.FromV2(mapped)
+}
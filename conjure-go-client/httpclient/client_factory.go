@@ -0,0 +1,143 @@
+// Copyright (c) 2026 Palantir Technologies. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpclient
+
+import (
+	"container/list"
+	"context"
+	"sync"
+
+	"github.com/palantir/pkg/refreshable"
+	werror "github.com/palantir/witchcraft-go-error"
+)
+
+// DefaultMaxTenantClients is the number of clients cached by a ClientFactory if maxClients is not positive.
+const DefaultMaxTenantClients = 1000
+
+// TenantServiceResolver returns the name of the service in ServicesConfig whose configuration is used to create
+// the client for tenantID.
+type TenantServiceResolver func(ctx context.Context, tenantID string) (serviceName string, err error)
+
+// ClientFactory creates and caches a Client for each tenant. The configuration of each tenant's client is the
+// ServicesConfig entry for the service returned by the TenantServiceResolver, and is refreshed when the
+// ServicesConfig changes. At most maxClients clients are cached; the least recently used client is evicted when the
+// limit is exceeded. Evicted clients remain usable, but no longer receive configuration updates.
+type ClientFactory struct {
+	services   RefreshableServicesConfig
+	resolve    TenantServiceResolver
+	maxClients int
+	params     []ClientParam
+
+	mu      sync.Mutex
+	clients map[string]*list.Element
+	lru     *list.List
+}
+
+type tenantClient struct {
+	tenantID    string
+	serviceName string
+	config      *refreshable.DefaultRefreshable
+	client      Client
+}
+
+// NewClientFactory returns a ClientFactory which creates clients using the configuration in services. The params
+// are applied to every client after the configuration.
+func NewClientFactory(services RefreshableServicesConfig, resolve TenantServiceResolver, maxClients int, params ...ClientParam) *ClientFactory {
+	if maxClients <= 0 {
+		maxClients = DefaultMaxTenantClients
+	}
+	f := &ClientFactory{
+		services:   services,
+		resolve:    resolve,
+		maxClients: maxClients,
+		params:     params,
+		clients:    make(map[string]*list.Element),
+		lru:        list.New(),
+	}
+	// Each client's configuration is held in a refreshable owned by the factory rather than mapped from services,
+	// so that evicted clients do not remain subscribed to services.
+	services.SubscribeToServicesConfig(func(config ServicesConfig) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		for _, elem := range f.clients {
+			tc := elem.Value.(*tenantClient)
+			_ = tc.config.Update(config.ClientConfig(tc.serviceName))
+		}
+	})
+	return f
+}
+
+// Client returns the client for tenantID, creating it if it is not cached.
+func (f *ClientFactory) Client(ctx context.Context, tenantID string) (Client, error) {
+	if client, ok := f.cachedClient(tenantID); ok {
+		return client, nil
+	}
+
+	serviceName, err := f.resolve(ctx, tenantID)
+	if err != nil {
+		return nil, werror.WrapWithContextParams(ctx, err, "failed to resolve service for tenant", werror.UnsafeParam("tenantId", tenantID))
+	}
+	config := refreshable.NewDefaultRefreshable(f.services.CurrentServicesConfig().ClientConfig(serviceName))
+	client, err := NewClientFromRefreshableConfig(ctx, NewRefreshingClientConfig(config), f.params...)
+	if err != nil {
+		return nil, werror.WrapWithContextParams(ctx, err, "failed to create client for tenant",
+			werror.UnsafeParam("tenantId", tenantID),
+			werror.SafeParam("serviceName", serviceName))
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if elem, ok := f.clients[tenantID]; ok {
+		// another caller created the client concurrently
+		f.lru.MoveToFront(elem)
+		return elem.Value.(*tenantClient).client, nil
+	}
+	// the configuration may have been updated before the client was added to the cache
+	_ = config.Update(f.services.CurrentServicesConfig().ClientConfig(serviceName))
+	f.clients[tenantID] = f.lru.PushFront(&tenantClient{
+		tenantID:    tenantID,
+		serviceName: serviceName,
+		config:      config,
+		client:      client,
+	})
+	for f.lru.Len() > f.maxClients {
+		oldest := f.lru.Back()
+		f.lru.Remove(oldest)
+		delete(f.clients, oldest.Value.(*tenantClient).tenantID)
+	}
+	return client, nil
+}
+
+// Evict removes the client for tenantID from the cache, if present. A subsequent call to Client will create a
+// new client, resolving the tenant's service again.
+func (f *ClientFactory) Evict(tenantID string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if elem, ok := f.clients[tenantID]; ok {
+		f.lru.Remove(elem)
+		delete(f.clients, tenantID)
+	}
+}
+
+func (f *ClientFactory) cachedClient(tenantID string) (Client, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	elem, ok := f.clients[tenantID]
+	if !ok {
+		return nil, false
+	}
+	f.lru.MoveToFront(elem)
+	return elem.Value.(*tenantClient).client, true
+}
@@ -0,0 +1,83 @@
+// Copyright (c) 2026 Palantir Technologies. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpclient_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/palantir/conjure-go-runtime/v2/conjure-go-client/httpclient"
+	"github.com/palantir/conjure-go-runtime/v2/conjure-go-contract/codecs"
+	"github.com/palantir/pkg/refreshable"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientFactory(t *testing.T) {
+	newServer := func(name string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			_, _ = rw.Write([]byte(name))
+		}))
+	}
+	serverA, serverB := newServer("a"), newServer("b")
+	defer serverA.Close()
+	defer serverB.Close()
+
+	newServicesConfig := func(uriA, uriB string) httpclient.ServicesConfig {
+		return httpclient.ServicesConfig{
+			Services: map[string]httpclient.ClientConfig{
+				"service-a": {URIs: []string{uriA}},
+				"service-b": {URIs: []string{uriB}},
+			},
+		}
+	}
+	services := refreshable.NewDefaultRefreshable(newServicesConfig(serverA.URL, serverB.URL))
+
+	resolved := map[string]int{}
+	factory := httpclient.NewClientFactory(httpclient.NewRefreshingServicesConfig(services),
+		func(ctx context.Context, tenantID string) (string, error) {
+			resolved[tenantID]++
+			return "service-" + tenantID, nil
+		}, 1)
+
+	doRequest := func(tenantID string) string {
+		client, err := factory.Client(context.Background(), tenantID)
+		require.NoError(t, err)
+		var body string
+		_, err = client.Get(context.Background(), httpclient.WithResponseBody(&body, codecs.Plain))
+		require.NoError(t, err)
+		return body
+	}
+
+	assert.Equal(t, "a", doRequest("a"))
+	assert.Equal(t, "a", doRequest("a"))
+	assert.Equal(t, 1, resolved["a"], "expected cached client")
+
+	// maxClients is 1, so creating the client for b evicts a
+	assert.Equal(t, "b", doRequest("b"))
+	assert.Equal(t, "a", doRequest("a"))
+	assert.Equal(t, 2, resolved["a"], "expected evicted client to be recreated")
+
+	// cached clients observe configuration updates
+	require.NoError(t, services.Update(newServicesConfig(serverB.URL, serverB.URL)))
+	assert.Equal(t, "b", doRequest("a"))
+	assert.Equal(t, 2, resolved["a"])
+
+	factory.Evict("a")
+	assert.Equal(t, "b", doRequest("a"))
+	assert.Equal(t, 3, resolved["a"])
+}
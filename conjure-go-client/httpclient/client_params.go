@@ -17,6 +17,8 @@ package httpclient
 import (
 	"context"
 	"crypto/tls"
+	"fmt"
+	"hash"
 	"net/http"
 	"net/url"
 	"time"
@@ -24,7 +26,9 @@ import (
 	"github.com/palantir/conjure-go-runtime/v2/conjure-go-client/httpclient/internal"
 	"github.com/palantir/conjure-go-runtime/v2/conjure-go-client/httpclient/internal/refreshingclient"
 	"github.com/palantir/pkg/bytesbuffers"
+	"github.com/palantir/pkg/metrics"
 	"github.com/palantir/pkg/refreshable"
+	refreshablev2 "github.com/palantir/pkg/refreshable/v2"
 	werror "github.com/palantir/witchcraft-go-error"
 )
 
@@ -148,6 +152,173 @@ func WithAuthTokenProvider(provideToken TokenProvider) ClientOrHTTPClientParam {
 	return WithMiddleware(&authTokenMiddleware{provideToken: provideToken})
 }
 
+// WithCookieAuthToken calls provideToken() and sends the token as the value of the cookieName cookie rather
+// than in the Authorization header, for conjure endpoints using cookie auth and services fronted by gateways which
+// require cookie-based sessions. If the token is empty, no cookie is set.
+func WithCookieAuthToken(cookieName string, provideToken TokenProvider) ClientOrHTTPClientParam {
+	return WithMiddleware(&cookieAuthTokenMiddleware{cookieName: cookieName, provideToken: provideToken})
+}
+
+// WithRefreshingAuthTokenProvider sets the Authorization header using provider.Token(). If the server responds with
+// 401 Unauthorized, provider.RefreshToken() is called and the request is retried once with the new token before the
+// error is returned. Requests whose body can not be replayed are not retried.
+func WithRefreshingAuthTokenProvider(provider RefreshingTokenProvider) ClientOrHTTPClientParam {
+	return WithMiddleware(&authTokenMiddleware{
+		provideToken: provider.Token,
+		refreshToken: provider.RefreshToken,
+	})
+}
+
+// WithRefreshableAuthToken sets the Authorization header to the current value of the refreshable bearerToken.
+// If the current value is empty, no Authorization header is set.
+func WithRefreshableAuthToken(bearerToken refreshable.String) ClientOrHTTPClientParam {
+	return WithAuthTokenProvider(func(context.Context) (string, error) {
+		return bearerToken.CurrentString(), nil
+	})
+}
+
+// WithOnBehalfOfProvider calls provider() and sets the X-On-Behalf-Of header to the returned principal
+// unless the request sets its own principal with WithRequestOnBehalfOf. If the provider returns an empty
+// principal, no header is set. Errors returned for requests carrying a principal include a hash of the
+// principal, keyed with a random per-process key, as the "onBehalfOfHash" safe param.
+func WithOnBehalfOfProvider(provider OnBehalfOfProvider) ClientOrHTTPClientParam {
+	return WithMiddleware(&onBehalfOfMiddleware{provider: provider})
+}
+
+// WithPropagatedHeaders sets the named headers on each request from the values stored on the request context using
+// ContextWithPropagatedHeaders, so that identifiers such as X-Correlation-Id can be forwarded across services in
+// the same way as trace IDs. Only the named headers are propagated, so they should not contain credentials or other
+// sensitive values. Headers set explicitly on the request take precedence.
+func WithPropagatedHeaders(names ...string) ClientOrHTTPClientParam {
+	canonical := make([]string, len(names))
+	for i, name := range names {
+		canonical[i] = http.CanonicalHeaderKey(name)
+	}
+	return WithMiddleware(&propagatedHeadersMiddleware{names: canonical})
+}
+
+// WithInformationalResponseHandler invokes handler for each 1xx informational response received before the final
+// response to a request. Handlers from multiple calls are each invoked.
+func WithInformationalResponseHandler(handler InformationalResponseHandler) ClientOrHTTPClientParam {
+	return WithMiddleware(informationalResponseMiddleware{handler: handler})
+}
+
+// WithEarlyHintsHandler invokes handler with the headers of each 103 Early Hints response, e.g. Link headers naming
+// resources the final response will reference, so that callers can begin preparatory work before the final response
+// is received.
+func WithEarlyHintsHandler(handler func(ctx context.Context, header http.Header)) ClientOrHTTPClientParam {
+	return WithInformationalResponseHandler(func(ctx context.Context, statusCode int, header http.Header) {
+		if statusCode == http.StatusEarlyHints {
+			handler(ctx, header)
+		}
+	})
+}
+
+// WithCookieJar stores the cookies set by servers in jar and sends them with subsequent requests, so that session
+// cookies survive across requests, retries and redirects. Cookies set explicitly on a request take precedence over
+// those in the jar. Use net/http/cookiejar for an in-memory jar. It takes precedence over the enable-cookies
+// configuration.
+func WithCookieJar(jar http.CookieJar) ClientOrHTTPClientParam {
+	return clientOrHTTPClientParamFunc(func(b *httpClientBuilder) error {
+		if jar == nil {
+			return werror.Error("cookie jar can not be nil")
+		}
+		b.CookieJar = jar
+		return nil
+	})
+}
+
+// WithResponseCache caches the responses to GET requests in cache, as a private HTTP cache would. Responses with a
+// status of 200 are stored if they have a Cache-Control max-age directive or an ETag or Last-Modified validator, and
+// their Cache-Control header does not contain no-store. While a response is fresh according to its max-age, it is
+// returned without sending the request; once it is stale, the request is sent with If-None-Match and
+// If-Modified-Since headers, and the cached response is returned if the server responds with 304 Not Modified.
+// Requests which set their own conditional or Range headers bypass the cache. Responses are cached separately for
+// each Authorization and Cookie header, and bodies larger than 1MiB are not cached. Responses served from the cache
+// mark the "client.cache.hit" meter and responses received from the server mark "client.cache.miss".
+func WithResponseCache(cache Cache) ClientOrHTTPClientParam {
+	return clientOrHTTPClientParamFunc(func(b *httpClientBuilder) error {
+		if cache == nil {
+			return werror.Error("response cache can not be nil")
+		}
+		b.ResponseCache = cache
+		return nil
+	})
+}
+
+// WithConcurrencyLimiter bounds the number of in-flight requests to each base URI with an adaptive limit. The limit
+// starts at 20, grows while successful responses are received with at least half of it in use, and shrinks by 10% on
+// each 429 or 503 response, down to a single request. Requests beyond the limit wait until a request to the same base
+// URI completes or their context is done. The current limit of each base URI is reported by the
+// "client.concurrency.limit" gauge, tagged with a hash of the base URI as by WithBaseURIMetricTag.
+func WithConcurrencyLimiter() ClientOrHTTPClientParam {
+	return clientOrHTTPClientParamFunc(func(b *httpClientBuilder) error {
+		b.ConcurrencyLimiter = true
+		return nil
+	})
+}
+
+// WithDeadlinePropagation sends the time remaining until the deadline of each request's context, if it has one, in the
+// X-Deadline-Millis header, so that servers can stop working on requests the caller has given up on. Servers using
+// httpserver.DeadlineHandler apply it as the deadline of the request's context. The header is recomputed for each
+// attempt.
+func WithDeadlinePropagation() ClientOrHTTPClientParam {
+	return WithMiddleware(deadlinePropagationMiddleware{})
+}
+
+// WithServerBackoffHints delays requests when the server asks the client to slow down on a successful response,
+// using the headers described by BackpressureHint: an X-Backoff-Millis header with the number of milliseconds to
+// wait, a Retry-After header, or an X-RateLimit-Remaining: 0 header with an X-RateLimit-Reset header giving the
+// number of seconds until the rate limit resets. Every request
+// sent by the client after such a response waits until the delay has passed or its context is done. Delays are
+// capped at maxDelay so that a misbehaving server can not stall the client indefinitely.
+func WithServerBackoffHints(maxDelay time.Duration) ClientOrHTTPClientParam {
+	return WithMiddleware(newServerBackoffMiddleware(maxDelay))
+}
+
+// WithRequestClassPolicy overrides the client's timeout, retries and server backoff for requests whose context has
+// been given class with ContextWithRequestClass, e.g. to fail interactive requests quickly while letting batch
+// requests retry for longer. Policies for the same class replace each other.
+func WithRequestClassPolicy(class RequestClass, policy RequestClassPolicy) ClientParam {
+	return clientParamFunc(func(b *clientBuilder) error {
+		policies := make(map[RequestClass]RequestClassPolicy, len(b.RequestClassPolicies)+1)
+		for k, v := range b.RequestClassPolicies {
+			policies[k] = v
+		}
+		policies[class] = policy
+		b.RequestClassPolicies = policies
+		return nil
+	})
+}
+
+// WithEndpointTimeouts sets the timeout of each attempt of requests whose context has been given an RPC method name in
+// timeouts with ContextWithRPCMethodName, overriding the client's timeout and the timeout of the request's
+// RequestClassPolicy. Timeouts set with WithRequestTimeout take precedence. Timeouts must be positive.
+func WithEndpointTimeouts(timeouts map[string]time.Duration) ClientParam {
+	return clientParamFunc(func(b *clientBuilder) error {
+		validTimeouts, err := newEndpointTimeouts(timeouts)
+		if err != nil {
+			return err
+		}
+		b.EndpointTimeouts = refreshablev2.New(validTimeouts)
+		return nil
+	})
+}
+
+// WithEndpointMaxRetries sets the maximum number of retries of requests whose context has been given an RPC method
+// name in retries with ContextWithRPCMethodName, overriding the client's maximum and the retries of the request's
+// RequestClassPolicy. Retries must not be negative.
+func WithEndpointMaxRetries(retries map[string]int) ClientParam {
+	return clientParamFunc(func(b *clientBuilder) error {
+		validRetries, err := newEndpointMaxRetries(retries)
+		if err != nil {
+			return err
+		}
+		b.EndpointMaxRetries = refreshablev2.New(validRetries)
+		return nil
+	})
+}
+
 // WithUserAgent sets the User-Agent header.
 func WithUserAgent(userAgent string) ClientOrHTTPClientParam {
 	return WithSetHeader("User-Agent", userAgent)
@@ -171,6 +342,71 @@ func WithMetrics(tagProviders ...TagsProvider) ClientOrHTTPClientParam {
 	})
 }
 
+// WithLatencyBudgets marks the "client.budget.exceeded" meter and tags the active span whenever a request attempt
+// takes longer than the budget configured for its RPC method name (see WithRPCMethodName). Budgets must be positive.
+// Requests to endpoints without a budget are not checked.
+func WithLatencyBudgets(budgets map[string]time.Duration) ClientOrHTTPClientParam {
+	return clientOrHTTPClientParamFunc(func(b *httpClientBuilder) error {
+		validBudgets, err := newLatencyBudgets(budgets)
+		if err != nil {
+			return err
+		}
+		b.LatencyBudgets = refreshablev2.New(validBudgets)
+		return nil
+	})
+}
+
+// WithResponseHistogram records the "client.response" metric as a histogram of durations in microseconds using an
+// exponentially decaying reservoir of reservoirSize samples, instead of a timer with the default reservoir. A smaller
+// reservoir reduces the memory used by each metric, at the cost of less accurate percentiles.
+func WithResponseHistogram(reservoirSize int) ClientOrHTTPClientParam {
+	return clientOrHTTPClientParamFunc(func(b *httpClientBuilder) error {
+		if reservoirSize <= 0 {
+			return werror.Error("response histogram reservoir size must be positive",
+				werror.SafeParam("reservoirSize", reservoirSize))
+		}
+		b.ResponseMetrics.HistogramReservoirSize = reservoirSize
+		return nil
+	})
+}
+
+// WithRPCMethodNameTagLimit bounds the number of distinct "method-name" tag values recorded on the "client.response"
+// metric. Once limit method names have been seen, requests with any other method name are recorded with the
+// "RPCMethodNameRolledUp" value. This protects the metrics registry from unbounded growth when method names are
+// derived from dynamic paths.
+func WithRPCMethodNameTagLimit(limit int) ClientOrHTTPClientParam {
+	return clientOrHTTPClientParamFunc(func(b *httpClientBuilder) error {
+		if limit <= 0 {
+			return werror.Error("method name tag limit must be positive", werror.SafeParam("limit", limit))
+		}
+		b.ResponseMetrics.RPCMethodNameTagLimit = limit
+		return nil
+	})
+}
+
+// WithMetricsTagValueLimit bounds the number of distinct values of each tag key returned by the TagsProviders passed
+// to WithMetrics. Once limit values of a key have been seen, any other value of that key is recorded as "other" and a
+// warning is logged. This protects the metrics registry from a TagsProvider which returns unbounded values.
+func WithMetricsTagValueLimit(limit int) ClientOrHTTPClientParam {
+	return clientOrHTTPClientParamFunc(func(b *httpClientBuilder) error {
+		if limit <= 0 {
+			return werror.Error("metrics tag value limit must be positive", werror.SafeParam("limit", limit))
+		}
+		b.ResponseMetrics.TagValueLimit = limit
+		return nil
+	})
+}
+
+// WithBaseURIMetricTag tags the "client.response" metric with a "base-uri" tag identifying the base URI each
+// request was sent to, allowing latency and errors to be broken down per node. The tag value is a short hash of the
+// base URI, keyed per process, rather than the URI itself, so that host names are not recorded.
+func WithBaseURIMetricTag() ClientParam {
+	return clientParamFunc(func(b *clientBuilder) error {
+		b.HTTP.MetricsTagProviders = append(b.HTTP.MetricsTagProviders, baseURITagsProvider{})
+		return nil
+	})
+}
+
 // WithBytesBufferPool stores a bytes buffer pool on the client for use in encoding request bodies.
 // This prevents allocating a new byte buffer for every request.
 func WithBytesBufferPool(pool bytesbuffers.Pool) ClientParam {
@@ -180,6 +416,270 @@ func WithBytesBufferPool(pool bytesbuffers.Pool) ClientParam {
 	})
 }
 
+// WithValidator validates request bodies before they are encoded and response bodies after they are decoded.
+// See Validator for details.
+func WithValidator(validator Validator) ClientParam {
+	return clientParamFunc(func(b *clientBuilder) error {
+		b.Validator = validator
+		return nil
+	})
+}
+
+// WithRequestCompressionThreshold compresses encoded request bodies which are at least threshold bytes, setting the
+// Content-Encoding header accordingly. Requests which already set a Content-Encoding, such as those using
+// WithCompressedRequest, and bodies provided as raw readers are not compressed. A threshold of 0 disables
+// compression.
+//
+// Bodies are compressed with gzip until a response carries an Accept-Encoding header listing the codings the server
+// accepts in requests (RFC 7694), after which the client's preferred accepted coding of gzip and deflate is used, or
+// none if neither is accepted. If the server rejects a compressed body with 415 Unsupported Media Type, the coding is
+// renegotiated from the response and the request is sent once more.
+func WithRequestCompressionThreshold(threshold int) ClientParam {
+	return clientParamFunc(func(b *clientBuilder) error {
+		if threshold < 0 {
+			return werror.Error("request compression threshold must not be negative", werror.SafeParam("threshold", threshold))
+		}
+		b.RequestCompressionThreshold = refreshable.NewInt(refreshable.NewDefaultRefreshable(threshold))
+		return nil
+	})
+}
+
+// WithResponseContentTypeCheck verifies that the Content-Type of each response decoded using WithResponseBody
+// matches the decoder's Accept header before decoding it. On mismatch, an error wrapping
+// ErrResponseContentTypeMismatch is returned instead of a decoding error, which makes responses such as HTML error
+// pages from proxies and load balancers easier to diagnose. Responses without a Content-Type are decoded as usual.
+func WithResponseContentTypeCheck() ClientParam {
+	return clientParamFunc(func(b *clientBuilder) error {
+		b.CheckResponseContentType = true
+		return nil
+	})
+}
+
+// WithResponseChecksumVerification verifies the body of each successful response against the hex or base64 encoded
+// digest in the response header headerName, computed using newHash (e.g. sha256.New). Responses without the header
+// are not verified. Decoded responses are read to completion and fail with an error wrapping
+// ErrResponseChecksumMismatch on mismatch, in which case the request is retried like any other failed attempt.
+// With WithRawResponseBody, the error is instead returned by the final Read of the body in place of io.EOF, so callers
+// must not act on the content until the body has been read to completion.
+func WithResponseChecksumVerification(headerName string, newHash func() hash.Hash) ClientParam {
+	return clientParamFunc(func(b *clientBuilder) error {
+		if headerName == "" || newHash == nil {
+			return werror.Error("response checksum header name and hash must be set")
+		}
+		b.ResponseChecksum = &responseChecksum{header: headerName, newHash: newHash}
+		return nil
+	})
+}
+
+// WithResponseBodyWrapper wraps the body of each successful response. Each wrapper added wraps the body returned
+// by the previous one. See ResponseBodyWrapper for details.
+func WithResponseBodyWrapper(wrapper ResponseBodyWrapper) ClientParam {
+	return clientParamFunc(func(b *clientBuilder) error {
+		b.BodyWrappers = append(b.BodyWrappers, wrapper)
+		return nil
+	})
+}
+
+// WithAttemptHooks registers functions invoked before and after each attempt to execute a request, including
+// retries. Either function may be nil. Hooks are lighter weight than a Middleware and have visibility into the
+// attempt number and selected URI. Hooks from multiple calls are invoked in the order they were added.
+func WithAttemptHooks(onStart func(ctx context.Context, attempt Attempt), onFinish func(ctx context.Context, attempt Attempt, resp *http.Response, err error)) ClientParam {
+	return clientParamFunc(func(b *clientBuilder) error {
+		b.AttemptHooks = append(b.AttemptHooks, attemptHooks{onStart: onStart, onFinish: onFinish})
+		return nil
+	})
+}
+
+// WithAttemptHeaders sets headers describing the current attempt on each attempt to execute a request:
+// attemptHeader is set to the one-based attempt number, and failoverHeader is set to "true" on every attempt after
+// the first. Either name may be empty to omit that header. Backends can use these headers to skip caches when a
+// request is retried or fails over. DefaultAttemptHeader and DefaultFailoverHeader are conventional names.
+func WithAttemptHeaders(attemptHeader, failoverHeader string) ClientParam {
+	return clientParamFunc(func(b *clientBuilder) error {
+		if attemptHeader == "" && failoverHeader == "" {
+			return nil
+		}
+		b.HTTP.Middlewares = append(b.HTTP.Middlewares, attemptHeadersMiddleware{
+			attemptHeader:  attemptHeader,
+			failoverHeader: failoverHeader,
+		})
+		return nil
+	})
+}
+
+// WithRetryObserver registers a RetryObserver which is notified of the backoff decisions made while retrying requests.
+// Observers from multiple calls are notified in the order they were added.
+func WithRetryObserver(observer RetryObserver) ClientParam {
+	return clientParamFunc(func(b *clientBuilder) error {
+		b.RetryObservers = append(b.RetryObservers, observer)
+		return nil
+	})
+}
+
+// WithRetryPredicate consults predicate after each attempt to decide whether the request is retried, e.g. to retry
+// 500 (Internal Server Error) responses of idempotent endpoints or to fail fast on errors the client would otherwise
+// retry. Predicates from multiple calls are consulted in the order they were added until one returns a decision other
+// than RetryDecisionDefault. Requests to mesh URIs are never retried, and the maximum number of retries and the retry
+// budget still apply.
+func WithRetryPredicate(predicate RetryPredicate) ClientParam {
+	return clientParamFunc(func(b *clientBuilder) error {
+		if predicate == nil {
+			return werror.Error("retry predicate can not be nil")
+		}
+		b.RetryPredicates = append(b.RetryPredicates, predicate)
+		return nil
+	})
+}
+
+// WithSafeMethodRetriesOnly prevents the client from retrying requests with non-idempotent methods, such as POST and
+// PATCH, after failures which may have happened once the server received the request, e.g. a connection reset or a
+// timeout, so that a partial failure does not apply a mutation twice. Such requests are still retried after errors
+// which occurred before the request was sent, such as connection refused and DNS errors, and after responses with
+// which the server rejected the request, such as 429 (Too Many Requests) and 503 (Service Unavailable). Requests with
+// idempotent methods (GET, HEAD, OPTIONS, TRACE, PUT and DELETE) are retried as usual. Errors without a response are
+// not passed to the predicates set by WithRetryPredicate for non-idempotent requests unless they are retried.
+func WithSafeMethodRetriesOnly() ClientParam {
+	return clientParamFunc(func(b *clientBuilder) error {
+		b.SafeMethodRetriesOnly = true
+		return nil
+	})
+}
+
+// WithFallbackClient sends idempotent requests (GET, HEAD, OPTIONS, TRACE, PUT and DELETE) to secondary, e.g. a read
+// replica or a client for a different region, when they fail against this client after all retries and trigger
+// returns true for the final response and error. If trigger is nil, FallbackOnUnavailable is used. Requests whose
+// context is done are never sent to the fallback. The request params are applied again for the secondary client, so
+// request bodies must be replayable.
+func WithFallbackClient(secondary Client, trigger FallbackTrigger) ClientParam {
+	return clientParamFunc(func(b *clientBuilder) error {
+		if secondary == nil {
+			return werror.Error("fallback client must not be nil")
+		}
+		if trigger == nil {
+			trigger = FallbackOnUnavailable
+		}
+		b.Fallback = &fallbackClient{client: secondary, trigger: trigger}
+		return nil
+	})
+}
+
+// WithRemovedURIGracePeriod cancels the requests to a URI which are still in flight gracePeriod after the URI is
+// removed from the client's configuration, unless it is added back. Responses are in flight until their body is
+// closed. The client's idle connections are also closed when a URI is removed so that stale keep-alive connections
+// are not reused. By default, in-flight requests to removed URIs are left to complete.
+func WithRemovedURIGracePeriod(gracePeriod time.Duration) ClientParam {
+	return clientParamFunc(func(b *clientBuilder) error {
+		if gracePeriod <= 0 {
+			return werror.Error("removed URI grace period must be positive", werror.SafeParam("gracePeriod", gracePeriod.String()))
+		}
+		b.RemovedURIGracePeriod = gracePeriod
+		return nil
+	})
+}
+
+// WithClockSkewDetector records the difference between the Date header of the client's responses and local time in
+// detector, and makes detector available to the middlewares and TokenProviders called for the client's requests, so
+// that they can use ServerNow to compute signing timestamps and check token expiry against the server's clock rather
+// than a drifting local one. A detector may be shared by several clients of the same service.
+func WithClockSkewDetector(detector *ClockSkewDetector) ClientParam {
+	return clientParamFunc(func(b *clientBuilder) error {
+		if detector == nil {
+			return werror.Error("clock skew detector can not be nil")
+		}
+		b.ContextTransformers = append(b.ContextTransformers, func(ctx context.Context) context.Context {
+			return context.WithValue(ctx, requestClockSkewDetector, detector)
+		})
+		b.HTTP.Middlewares = append(b.HTTP.Middlewares, detector)
+		return nil
+	})
+}
+
+// WithFallbackMetricsRegistry records the metrics of requests whose context has no metrics registry in registry rather
+// than in metrics.DefaultMetricsRegistry, so that clients used outside of a server which sets up a registry still
+// produce metrics somewhere discoverable. Whether or not a fallback is set, the client logs a warning the first time
+// it makes a request without a registry.
+func WithFallbackMetricsRegistry(registry metrics.Registry) ClientParam {
+	return clientParamFunc(func(b *clientBuilder) error {
+		if registry == nil {
+			return werror.Error("fallback metrics registry can not be nil")
+		}
+		b.FallbackMetricsRegistry = registry
+		return nil
+	})
+}
+
+// WithContextTransformer applies transform to the context of every request made by the client before the request is
+// built, e.g. to attach a metrics registry, logger or tenant information to all outbound calls uniformly. Transformers
+// are applied in the order they are added, and must return a non-nil context derived from the one they are given.
+func WithContextTransformer(transform func(ctx context.Context) context.Context) ClientParam {
+	return clientParamFunc(func(b *clientBuilder) error {
+		if transform == nil {
+			return werror.Error("context transformer can not be nil")
+		}
+		b.ContextTransformers = append(b.ContextTransformers, transform)
+		return nil
+	})
+}
+
+// WithHedgedRequests sends a request to another URI if it has not received a successful response within delay, returning
+// the first successful response and canceling the other sends. Up to maxHedges additional sends are made, each delay
+// after the previous one, which reduces tail latency at the cost of extra load on the service. Only requests with
+// idempotent methods (GET, HEAD, OPTIONS, TRACE, PUT and DELETE) whose body, if any, is encoded by a codec are hedged.
+// A hedged request counts as a single attempt towards the client's retry limit. Each additional send marks the
+// "client.request.hedge" meter.
+func WithHedgedRequests(delay time.Duration, maxHedges int) ClientParam {
+	return clientParamFunc(func(b *clientBuilder) error {
+		if delay <= 0 {
+			return werror.Error("hedging delay must be positive", werror.SafeParam("delay", delay.String()))
+		}
+		if maxHedges <= 0 {
+			return werror.Error("max hedges must be positive", werror.SafeParam("maxHedges", maxHedges))
+		}
+		b.Hedging = &hedgingParams{delay: delay, maxHedges: maxHedges}
+		return nil
+	})
+}
+
+// WithRetryAfter makes the client wait for the duration of the Retry-After header of 429 (Too Many Requests) responses
+// before retrying, instead of its exponential backoff. To smooth the retries of a fleet of callers which are throttled
+// at the same time, each delay is randomly shortened or lengthened by up to jitter, a fraction of the duration between
+// 0 and 1 (e.g. 0.2 for ±20%). If maxDelay is positive, longer durations are capped at maxDelay and jitter never
+// lengthens a delay past it. Throttle responses without a Retry-After header still back off.
+//
+// The Retry-After header of 503 (Service Unavailable) responses is also honored: the URI which sent the response is
+// not retried until the adjusted duration has passed, and other URIs are tried in the meantime.
+func WithRetryAfter(jitter float64, maxDelay time.Duration) ClientParam {
+	return clientParamFunc(func(b *clientBuilder) error {
+		if jitter < 0 || jitter > 1 {
+			return werror.Error("retry-after jitter must be between 0 and 1", werror.SafeParam("jitter", jitter))
+		}
+		if maxDelay < 0 {
+			return werror.Error("max retry-after delay must not be negative", werror.SafeParam("maxDelay", maxDelay.String()))
+		}
+		b.RetryAfter = &internal.RetryAfterParams{Jitter: jitter, MaxDelay: maxDelay}
+		return nil
+	})
+}
+
+// WithRetryBudget bounds the retries sent by the client, across all of its requests, to ratio times the number of
+// requests sent over a sliding window of ten seconds, plus minRetriesPerSecond, so that callers retrying every failed
+// request do not amplify the load on a struggling server. For example, a ratio of 0.2 allows at most one retry for
+// every five requests. Requests whose retry is rejected by the budget fail with the error of their last attempt and
+// mark the "client.retry.budget.exhausted" meter. A ratio and minRetriesPerSecond of 0 disable the budget.
+func WithRetryBudget(ratio float64, minRetriesPerSecond int) ClientParam {
+	return clientParamFunc(func(b *clientBuilder) error {
+		if err := validateRetryBudget(ratio, minRetriesPerSecond); err != nil {
+			return err
+		}
+		b.RetryParams = refreshingclient.ConfigureRetry(b.RetryParams, func(p refreshingclient.RetryParams) refreshingclient.RetryParams {
+			p.RetryBudgetRatio = ratio
+			p.MinRetriesPerSecond = minRetriesPerSecond
+			return p
+		})
+		return nil
+	})
+}
+
 // WithDisablePanicRecovery disables the enabled-by-default panic recovery middleware.
 // If the request was otherwise succeeding (err == nil), we return a new werror with
 // the recovered object as an unsafe param. If there's an error, we werror.Wrap it.
@@ -356,10 +856,49 @@ func WithTLSConfig(conf *tls.Config) ClientOrHTTPClientParam {
 		} else {
 			b.TLSConfig = conf.Clone()
 		}
+		b.RefreshableTLSConfig = nil
 		return nil
 	})
 }
 
+// WithRefreshableTLSConfig sets the SSL/TLS configuration for the HTTP client's Transport from a refreshable
+// containing either a *tls.Config or a SecurityConfig. Unlike WithTLSConfig, the transport is rebuilt whenever r is
+// updated, so applications which manage their own TLS material can rotate it without recreating the client.
+// If an updated value is invalid, the previous configuration continues to be used and the error is logged.
+// The provided *tls.Config values must not be modified after they are published. This supersedes WithTLSConfig
+// and the security configuration of WithConfig, and is not affected by WithTLSInsecureSkipVerify.
+func WithRefreshableTLSConfig(r refreshable.Refreshable) ClientOrHTTPClientParam {
+	return clientOrHTTPClientParamFunc(func(b *httpClientBuilder) error {
+		if r == nil {
+			b.RefreshableTLSConfig = nil
+		} else {
+			b.RefreshableTLSConfig = &refreshableTLSConfig{r: r}
+		}
+		return nil
+	})
+}
+
+// refreshableTLSConfig wraps the refreshable provided to WithRefreshableTLSConfig so that it can be compared by
+// identity regardless of its implementation.
+type refreshableTLSConfig struct {
+	r refreshable.Refreshable
+}
+
+func tlsConfigFromRefreshableValue(i interface{}) (*tls.Config, error) {
+	switch v := i.(type) {
+	case *tls.Config:
+		if v == nil {
+			return nil, werror.Error("refreshable tls config must not be nil")
+		}
+		return v.Clone(), nil
+	case SecurityConfig:
+		return refreshingclient.NewTLSConfig(context.TODO(), newTLSParams(v))
+	default:
+		return nil, werror.Error("refreshable tls config must contain a *tls.Config or SecurityConfig",
+			werror.SafeParam("type", fmt.Sprintf("%T", i)))
+	}
+}
+
 // WithTLSInsecureSkipVerify sets the InsecureSkipVerify field for the HTTP client's tls config.
 // This option should only be used in clients that have way to establish trust with servers.
 // If WithTLSConfig is used, the config's InsecureSkipVerify is set to true.
@@ -451,17 +990,38 @@ func WithKeepAlive(keepAlive time.Duration) ClientOrHTTPClientParam {
 }
 
 // WithBaseURLs sets the base URLs for every request. This is meant to be used in conjunction with WithPath.
+// Any URI groups set by configuration or WithURIGroups are cleared.
 func WithBaseURLs(urls []string) ClientParam {
 	return clientParamFunc(func(b *clientBuilder) error {
 		b.URIs = refreshable.NewStringSlice(refreshable.NewDefaultRefreshable(urls))
+		b.URIGroups = nil
 		return nil
 	})
 }
 
 // WithRefreshableBaseURLs sets the base URLs for every request. This is meant to be used in conjunction with WithPath.
+// Any URI groups set by configuration or WithURIGroups are cleared.
 func WithRefreshableBaseURLs(urls refreshable.StringSlice) ClientParam {
 	return clientParamFunc(func(b *clientBuilder) error {
 		b.URIs = urls
+		b.URIGroups = nil
+		return nil
+	})
+}
+
+// WithURIGroups sets the base URLs for every request to the URIs of groups, and sends each group the configured
+// percentage of requests. Requests are first attempted against the URIs of the selected group, falling back to the
+// URIs of the other groups on retries. If groups have different priorities, requests are sent to the groups with the
+// lowest priority which has a healthy URI, as described by ClientConfig.URIGroups. Metrics are tagged with the
+// "uri-group" of the URI used for each request.
+func WithURIGroups(groups ...URIGroupConfig) ClientParam {
+	return clientParamFunc(func(b *clientBuilder) error {
+		uris, validated, err := validateURIGroups(context.TODO(), b.HTTP.ServiceName.CurrentString(), groups)
+		if err != nil {
+			return err
+		}
+		b.URIs = refreshable.NewStringSlice(refreshable.NewDefaultRefreshable(uris))
+		b.URIGroups = refreshablev2.New(validated)
 		return nil
 	})
 }
@@ -531,6 +1091,28 @@ func WithDisableRestErrors() ClientParam {
 	})
 }
 
+// WithClientRegistry adds the client to the process-wide registry returned by ClientSnapshots. Clients derived from
+// it using DeriveClient are registered as well. A registered client is removed from the registry once it is garbage
+// collected.
+func WithClientRegistry() ClientParam {
+	return clientParamFunc(func(b *clientBuilder) error {
+		b.ClientRegistry = true
+		return nil
+	})
+}
+
+// WithHeaderNormalization validates and normalizes request headers after every middleware has run. Requests with
+// header names or values containing invalid characters fail with an error naming the header, rather than an error
+// from the transport. Hop-by-hop headers (Connection, Keep-Alive, Proxy-Connection, Transfer-Encoding, Upgrade and
+// any headers listed in Connection) are removed, as they are managed by the transport, and header names are
+// canonicalized.
+func WithHeaderNormalization() ClientOrHTTPClientParam {
+	return clientOrHTTPClientParamFunc(func(b *httpClientBuilder) error {
+		b.NormalizeHeaders = true
+		return nil
+	})
+}
+
 // WithDisableKeepAlives disables keep alives on the http transport
 func WithDisableKeepAlives() ClientOrHTTPClientParam {
 	return clientOrHTTPClientParamFunc(func(b *httpClientBuilder) error {
@@ -542,6 +1124,20 @@ func WithDisableKeepAlives() ClientOrHTTPClientParam {
 	})
 }
 
+// WithHTMLErrorBodies includes the bodies of HTML error responses, which are usually generated by load balancers and
+// ingresses, as the "responseBody" unsafe param of the errors returned by the default error decoder. By default only
+// the page's title is recorded, as the "responseSummary" safe param. It has no effect if the client's ErrorDecoder
+// has been replaced or disabled.
+func WithHTMLErrorBodies() ClientParam {
+	return clientParamFunc(func(b *clientBuilder) error {
+		if d, ok := b.ErrorDecoder.(restErrorDecoder); ok {
+			d.includeHTMLBodies = true
+			b.ErrorDecoder = d
+		}
+		return nil
+	})
+}
+
 func WithErrorDecoder(errorDecoder ErrorDecoder) ClientParam {
 	return clientParamFunc(func(b *clientBuilder) error {
 		b.ErrorDecoder = errorDecoder
@@ -549,6 +1145,17 @@ func WithErrorDecoder(errorDecoder ErrorDecoder) ClientParam {
 	})
 }
 
+// WithAdditionalErrorDecoder adds an ErrorDecoder which is consulted before the client's ErrorDecoder, allowing
+// decoders for different error formats, e.g. HTML error pages or vendor-specific bodies, to be layered on top of the
+// default Conjure error decoding. Additional decoders are consulted in the order they were added, and the first one
+// which handles a response decodes it. Additional decoders still apply if WithDisableRestErrors is used.
+func WithAdditionalErrorDecoder(errorDecoder ErrorDecoder) ClientParam {
+	return clientParamFunc(func(b *clientBuilder) error {
+		b.AdditionalErrorDecoders = append(b.AdditionalErrorDecoders, errorDecoder)
+		return nil
+	})
+}
+
 // WithBasicAuth sets the request's Authorization header to use HTTP Basic Authentication with the provided username and
 // password.
 func WithBasicAuth(user, password string) ClientOrHTTPClientParam {
@@ -575,6 +1182,9 @@ func WithBasicAuthProvider(provider BasicAuthProvider) ClientOrHTTPClientParam {
 // no basic authentication header values are set.
 func WithBasicAuthOptionalProvider(provider BasicAuthOptionalProvider) ClientOrHTTPClientParam {
 	return WithMiddleware(MiddlewareFunc(func(req *http.Request, next http.RoundTripper) (*http.Response, error) {
+		if hasRequestAuthOverride(req.Context()) {
+			return next.RoundTrip(req)
+		}
 		basicAuth, err := provider(req.Context())
 		if err != nil {
 			return nil, err
@@ -586,6 +1196,48 @@ func WithBasicAuthOptionalProvider(provider BasicAuthOptionalProvider) ClientOrH
 	}))
 }
 
+// AllNodesUnavailablePolicy determines how a client handles requests while every URI has failed recently.
+type AllNodesUnavailablePolicy int
+
+const (
+	// AllNodesUnavailableServeAll sends requests to all URIs as usual. This is the default.
+	AllNodesUnavailableServeAll AllNodesUnavailablePolicy = iota
+	// AllNodesUnavailableFailFast returns an error wrapping ErrAllNodesUnavailable without sending the request.
+	// This is preferable for latency-sensitive callers which have their own fallback.
+	AllNodesUnavailableFailFast
+)
+
+// WithAllNodesUnavailablePolicy sets the behavior when every URI has recently returned a server error or failed to
+// respond, as tracked by the default balanced URI scoring. Either way, the client.uri.all-unavailable meter is
+// marked for each such request. The policy has no effect with WithRandomURIScoring, which does not track failures.
+func WithAllNodesUnavailablePolicy(policy AllNodesUnavailablePolicy) ClientParam {
+	return clientParamFunc(func(b *clientBuilder) error {
+		b.AllNodesUnavailablePolicy = policy
+		return nil
+	})
+}
+
+// AuthFailoverPolicy determines whether a client sends the Authorization header on retries which fail over to a
+// different host than the one selected for the request's first attempt.
+type AuthFailoverPolicy int
+
+const (
+	// AuthFailoverResend sends the Authorization header on every attempt. This is the default.
+	AuthFailoverResend AuthFailoverPolicy = iota
+	// AuthFailoverOriginalHostOnly removes the Authorization header from attempts sent to any host other than the
+	// one selected for the first attempt. This is preferable for deployments using tokens bound to a single host.
+	AuthFailoverOriginalHostOnly
+)
+
+// WithAuthFailoverPolicy sets whether the Authorization header, including one set by WithAuthToken or
+// WithBasicAuth, is sent on retries which fail over to a different host.
+func WithAuthFailoverPolicy(policy AuthFailoverPolicy) ClientParam {
+	return clientParamFunc(func(b *clientBuilder) error {
+		b.AuthFailoverPolicy = policy
+		return nil
+	})
+}
+
 // WithBalancedURIScoring adds middleware that prioritizes sending requests to URIs with the fewest in-flight requests
 // and least recent errors.
 // Deprecated: This param is a no-op as balanced URI scoring is the default behavior.
@@ -600,6 +1252,44 @@ func WithBalancedURIScoring() ClientParam {
 	})
 }
 
+// WithStickyURIScoring pins the requests of each session, identified by the key set on the request context with
+// ContextWithSessionKey, to a single URI for as long as it is available, for backends which keep per-session state.
+// When the pinned URI fails, the session moves to another URI and stays there. Requests without a session key are
+// balanced between URIs as by default. Sessions are not pinned for clients using URI groups.
+func WithStickyURIScoring() ClientParam {
+	return clientParamFunc(func(b *clientBuilder) error {
+		b.URIScorerBuilder = func(uris []string) internal.URIScoringMiddleware {
+			return internal.NewStickyURIScoringMiddleware(uris, func() int64 {
+				return time.Now().UnixNano()
+			})
+		}
+		return nil
+	})
+}
+
+// WithURIPool replaces the client's URI scoring with the URIPool returned by newPool, which is called with the
+// client's URIs when the client is built and whenever its URIs are refreshed.
+func WithURIPool(newPool func(uris []string) URIPool) ClientParam {
+	return clientParamFunc(func(b *clientBuilder) error {
+		if newPool == nil {
+			return werror.Error("URI pool constructor can not be nil")
+		}
+		b.URIScorerBuilder = func(uris []string) internal.URIScoringMiddleware {
+			return uriPoolScorer{URIPool: newPool(uris)}
+		}
+		return nil
+	})
+}
+
+// WithURISelector uses selector to choose the URIs to try for each request from those ordered by the client's
+// URIPool or URI scoring. It is applied after URI groups and session affinity.
+func WithURISelector(selector URISelector) ClientParam {
+	return clientParamFunc(func(b *clientBuilder) error {
+		b.URISelector = selector
+		return nil
+	})
+}
+
 // WithRandomURIScoring adds middleware that randomizes the order URIs are prioritized in for each request.
 func WithRandomURIScoring() ClientParam {
 	return clientParamFunc(func(b *clientBuilder) error {
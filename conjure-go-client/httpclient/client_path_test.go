@@ -15,9 +15,13 @@
 package httpclient
 
 import (
+	"context"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestJoinURIandPath(t *testing.T) {
@@ -76,6 +80,41 @@ func TestJoinURIandPath(t *testing.T) {
 			"/api/+ti%2FQojjmKJxpxmY%2FA=",
 			"https://localhost/api/+ti%2FQojjmKJxpxmY%2FA=",
 		},
+		{
+			"https://localhost/api/v2/foo%2Fbar",
+			"/baz",
+			"https://localhost/api/v2/foo%2Fbar/baz",
+		},
+		{
+			"https://localhost/api/v2/foo%2Fbar/",
+			"/a b/c%2Fd",
+			"https://localhost/api/v2/foo%2Fbar/a%20b/c%2Fd",
+		},
+		{
+			"https://localhost/api%20v2",
+			"/100%",
+			"https://localhost/api%20v2/100%25",
+		},
+		{
+			"https://localhost/api",
+			"/search?q",
+			"https://localhost/api/search?q",
+		},
+		{
+			"https://localhost/api",
+			"/notes#1?draft",
+			"https://localhost/api/notes#1?draft",
+		},
+		{
+			"https://localhost/api/v2/foo%2Fbar",
+			"/a b?q=c%2Fd",
+			"https://localhost/api/v2/foo%2Fbar/a%20b?q=c%2Fd",
+		},
+		{
+			"https://localhost/foo%2Fbar",
+			"",
+			"https://localhost/foo%2Fbar",
+		},
 	} {
 		t.Run("", func(t *testing.T) {
 			actual := joinURIAndPath(test.baseURI, test.reqPath)
@@ -83,3 +122,20 @@ func TestJoinURIandPath(t *testing.T) {
 		})
 	}
 }
+
+func TestWithPathQuery(t *testing.T) {
+	var path, rawQuery string
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		path, rawQuery = req.URL.EscapedPath(), req.URL.RawQuery
+	}))
+	defer server.Close()
+
+	client, err := NewClient(WithBaseURLs([]string{server.URL + "/api%2Fv2"}))
+	require.NoError(t, err)
+
+	// a query in the request path is sent as the query of the request
+	_, err = client.Get(context.Background(), WithPath("/notes?draft=true"))
+	require.NoError(t, err)
+	assert.Equal(t, "/api%2Fv2/notes", path)
+	assert.Equal(t, "draft=true", rawQuery)
+}
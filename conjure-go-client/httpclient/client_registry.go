@@ -0,0 +1,156 @@
+// Copyright (c) 2026 Palantir Technologies. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpclient
+
+import (
+	"runtime"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/palantir/conjure-go-runtime/v2/conjure-go-client/httpclient/internal"
+	"github.com/palantir/pkg/refreshable"
+)
+
+// ClientSnapshot describes the current state of a Client registered with the process-wide client registry.
+type ClientSnapshot struct {
+	ServiceName string
+	// InFlight is the number of calls to Do which have not yet returned.
+	InFlight int64
+	// URIs is the state of each of the client's current URIs, sorted by URI.
+	URIs []URISnapshot
+}
+
+// URISnapshot describes the current state of a single URI of a registered Client.
+type URISnapshot struct {
+	URI string
+	// InFlight is the number of request attempts to the URI which have not yet completed.
+	InFlight int
+	// Unavailable is true if requests to the URI have failed recently. Unavailable URIs are tried after available ones.
+	Unavailable bool
+	// Requests is the number of request attempts to the URI which have completed since it was added to the client.
+	Requests int64
+	// Failures is the number of completed attempts which failed with a network error, a 5xx status or a QoS status.
+	Failures int64
+	// LastSuccess is when an attempt to the URI last completed without failing, or the zero time if none has.
+	LastSuccess time.Time
+}
+
+// ClientSnapshots returns the current state of every Client in the process which was built with WithClientRegistry.
+// Snapshots are sorted by service name. Clients are removed from the registry once they are garbage collected.
+//
+// Per-URI in-flight, availability and request statistics are only tracked by the default URI scorer; other scorers
+// report their URIs with zero values.
+func ClientSnapshots() []ClientSnapshot {
+	return defaultClientRegistry.snapshots()
+}
+
+// SnapshotClient returns the current state of client, which lets services build admission control or diagnostics
+// from the per-URI statistics the client already tracks. It returns false if client was not built by this package.
+// Only clients built with WithClientRegistry track the number of calls to Do in flight; others always report an
+// InFlight of 0.
+func SnapshotClient(client Client) (ClientSnapshot, bool) {
+	c, ok := client.(*clientImpl)
+	if !ok {
+		return ClientSnapshot{}, false
+	}
+	entry := c.registryEntry
+	if entry == nil {
+		entry = &clientRegistryEntry{
+			serviceName: c.serviceName,
+			uriScorer:   c.uriScorer,
+		}
+	}
+	return entry.snapshot(), true
+}
+
+var defaultClientRegistry = &clientRegistry{entries: make(map[*clientRegistryEntry]struct{})}
+
+type clientRegistry struct {
+	mu      sync.Mutex
+	entries map[*clientRegistryEntry]struct{}
+}
+
+// clientRegistryEntry holds the state of a single client needed to build its snapshot. It must not reference the
+// clientImpl so that the client can be garbage collected and unregistered by its finalizer.
+type clientRegistryEntry struct {
+	serviceName refreshable.String
+	uriScorer   internal.RefreshableURIScoringMiddleware
+	inFlight    atomic.Int64
+}
+
+// register adds c to the registry and removes it once c is garbage collected. The finalizer which removes it is only
+// attached to registered clients.
+func (r *clientRegistry) register(c *clientImpl) {
+	entry := &clientRegistryEntry{
+		serviceName: c.serviceName,
+		uriScorer:   c.uriScorer,
+	}
+	r.mu.Lock()
+	r.entries[entry] = struct{}{}
+	r.mu.Unlock()
+	c.registryEntry = entry
+	runtime.SetFinalizer(c, func(*clientImpl) {
+		r.mu.Lock()
+		delete(r.entries, entry)
+		r.mu.Unlock()
+	})
+}
+
+func (r *clientRegistry) snapshots() []ClientSnapshot {
+	r.mu.Lock()
+	entries := make([]*clientRegistryEntry, 0, len(r.entries))
+	for entry := range r.entries {
+		entries = append(entries, entry)
+	}
+	r.mu.Unlock()
+
+	snapshots := make([]ClientSnapshot, 0, len(entries))
+	for _, entry := range entries {
+		snapshots = append(snapshots, entry.snapshot())
+	}
+	sort.SliceStable(snapshots, func(i, j int) bool {
+		return snapshots[i].ServiceName < snapshots[j].ServiceName
+	})
+	return snapshots
+}
+
+func (e *clientRegistryEntry) snapshot() ClientSnapshot {
+	snapshot := ClientSnapshot{
+		ServiceName: e.serviceName.CurrentString(),
+		InFlight:    e.inFlight.Load(),
+	}
+	scorer := e.uriScorer.CurrentURIScoringMiddleware()
+	if reporter, ok := scorer.(internal.URIStateReporter); ok {
+		for _, state := range reporter.URIStates() {
+			snapshot.URIs = append(snapshot.URIs, URISnapshot{
+				URI:         state.URI,
+				InFlight:    state.InFlight,
+				Unavailable: state.Unavailable,
+				Requests:    state.Requests,
+				Failures:    state.Failures,
+				LastSuccess: state.LastSuccess,
+			})
+		}
+		return snapshot
+	}
+	uris := scorer.GetURIsInOrderOfIncreasingScore()
+	sort.Strings(uris)
+	for _, uri := range uris {
+		snapshot.URIs = append(snapshot.URIs, URISnapshot{URI: uri})
+	}
+	return snapshot
+}
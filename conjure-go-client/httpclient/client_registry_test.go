@@ -0,0 +1,122 @@
+// Copyright (c) 2026 Palantir Technologies. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpclient_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/palantir/conjure-go-runtime/v2/conjure-go-client/httpclient"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientSnapshots(t *testing.T) {
+	release := make(chan struct{})
+	received := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		received <- struct{}{}
+		<-release
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client, err := httpclient.NewClient(
+		httpclient.WithServiceName("registry-test-service"),
+		httpclient.WithBaseURLs([]string{server.URL}),
+		httpclient.WithClientRegistry())
+	require.NoError(t, err)
+	_, err = httpclient.NewClient(
+		httpclient.WithServiceName("registry-test-unregistered"),
+		httpclient.WithBaseURLs([]string{server.URL}))
+	require.NoError(t, err)
+
+	findSnapshot := func(serviceName string) (httpclient.ClientSnapshot, bool) {
+		for _, snapshot := range httpclient.ClientSnapshots() {
+			if snapshot.ServiceName == serviceName && len(snapshot.URIs) == 1 && snapshot.URIs[0].URI == server.URL {
+				return snapshot, true
+			}
+		}
+		return httpclient.ClientSnapshot{}, false
+	}
+
+	_, found := findSnapshot("registry-test-unregistered")
+	assert.False(t, found, "client built without WithClientRegistry should not be registered")
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, err := client.Get(context.Background())
+		assert.NoError(t, err)
+	}()
+	<-received
+
+	snapshot, found := findSnapshot("registry-test-service")
+	require.True(t, found)
+	assert.Equal(t, httpclient.ClientSnapshot{
+		ServiceName: "registry-test-service",
+		InFlight:    1,
+		URIs:        []httpclient.URISnapshot{{URI: server.URL, InFlight: 1}},
+	}, snapshot)
+
+	close(release)
+	<-done
+	snapshot, found = findSnapshot("registry-test-service")
+	require.True(t, found)
+	require.Len(t, snapshot.URIs, 1)
+	assert.WithinDuration(t, time.Now(), snapshot.URIs[0].LastSuccess, time.Minute)
+	snapshot.URIs[0].LastSuccess = time.Time{}
+	assert.Equal(t, httpclient.ClientSnapshot{
+		ServiceName: "registry-test-service",
+		URIs:        []httpclient.URISnapshot{{URI: server.URL, Requests: 1}},
+	}, snapshot)
+}
+
+func TestSnapshotClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client, err := httpclient.NewClient(
+		httpclient.WithServiceName("snapshot-test-service"),
+		httpclient.WithBaseURLs([]string{server.URL}),
+		httpclient.WithMaxRetries(0))
+	require.NoError(t, err)
+
+	_, err = client.Get(context.Background())
+	require.NoError(t, err)
+	_, err = client.Get(context.Background(), httpclient.WithPath("/fail"))
+	require.Error(t, err)
+
+	snapshot, ok := httpclient.SnapshotClient(client)
+	require.True(t, ok)
+	assert.Equal(t, "snapshot-test-service", snapshot.ServiceName)
+	require.Len(t, snapshot.URIs, 1)
+	assert.Equal(t, server.URL, snapshot.URIs[0].URI)
+	assert.Equal(t, int64(2), snapshot.URIs[0].Requests)
+	assert.Equal(t, int64(1), snapshot.URIs[0].Failures)
+	assert.False(t, snapshot.URIs[0].LastSuccess.IsZero())
+
+	_, ok = httpclient.SnapshotClient(nil)
+	assert.False(t, ok)
+}
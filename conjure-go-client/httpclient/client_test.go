@@ -16,6 +16,8 @@ package httpclient_test
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"errors"
 	"fmt"
 	"io"
@@ -23,12 +25,14 @@ import (
 	"net"
 	"net/http"
 	"net/http/httptest"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/palantir/conjure-go-runtime/v2/conjure-go-client/httpclient"
 	"github.com/palantir/conjure-go-runtime/v2/conjure-go-contract/codecs"
 	"github.com/palantir/pkg/bytesbuffers"
+	"github.com/palantir/pkg/refreshable"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -39,6 +43,42 @@ func TestNoBaseURIs(t *testing.T) {
 	require.Nil(t, client)
 }
 
+func TestDeriveClient(t *testing.T) {
+	var newConns int32
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		_, _ = rw.Write([]byte(req.Header.Get("Authorization")))
+	}))
+	server.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			atomic.AddInt32(&newConns, 1)
+		}
+	}
+	server.Start()
+	defer server.Close()
+
+	client, err := httpclient.NewClient(
+		httpclient.WithBaseURLs([]string{server.URL}),
+		httpclient.WithAuthToken("user"),
+	)
+	require.NoError(t, err)
+	admin, err := httpclient.DeriveClient(client, httpclient.WithAuthToken("admin"), httpclient.WithHTTPTimeout(time.Second))
+	require.NoError(t, err)
+
+	doRequest := func(c httpclient.Client) string {
+		var body string
+		_, err := c.Get(context.Background(), httpclient.WithResponseBody(&body, codecs.Plain))
+		require.NoError(t, err)
+		return body
+	}
+	assert.Equal(t, "Bearer user", doRequest(client))
+	assert.Equal(t, "Bearer admin", doRequest(admin))
+	assert.Equal(t, "Bearer user", doRequest(client))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&newConns), "expected derived client to reuse the parent's connection")
+
+	_, err = httpclient.DeriveClient(client, httpclient.WithDisableHTTP2())
+	require.EqualError(t, err, "client overrides can not modify transport configuration")
+}
+
 func TestCanReadBodyWithBufferPool(t *testing.T) {
 	unencodedBody := "body"
 	encodedBody, err := codecs.Plain.Marshal(unencodedBody)
@@ -417,3 +457,150 @@ func BenchmarkUnavailableURIs(b *testing.B) {
 		runBench(b, client)
 	})
 }
+
+func TestRefreshableTLSConfig(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	tlsConfig := refreshable.NewDefaultRefreshable(&tls.Config{RootCAs: x509.NewCertPool()})
+	client, err := httpclient.NewClient(
+		httpclient.WithBaseURLs([]string{server.URL}),
+		httpclient.WithMaxRetries(0),
+		httpclient.WithRefreshableTLSConfig(tlsConfig),
+	)
+	require.NoError(t, err)
+
+	_, err = client.Get(context.Background())
+	require.Error(t, err, "expected untrusted server certificate")
+
+	trusted := x509.NewCertPool()
+	trusted.AddCert(server.Certificate())
+	require.NoError(t, tlsConfig.Update(&tls.Config{RootCAs: trusted}))
+	_, err = client.Get(context.Background())
+	require.NoError(t, err)
+
+	// invalid updates are ignored
+	require.NoError(t, tlsConfig.Update((*tls.Config)(nil)))
+	_, err = client.Get(context.Background())
+	require.NoError(t, err)
+
+	_, err = httpclient.NewClient(
+		httpclient.WithBaseURLs([]string{server.URL}),
+		httpclient.WithRefreshableTLSConfig(refreshable.NewDefaultRefreshable("invalid")),
+	)
+	require.EqualError(t, err, "failed to build RefreshableTLSConfig: refreshable tls config must contain a *tls.Config or SecurityConfig")
+}
+
+func TestStickyURIScoring(t *testing.T) {
+	var requestsA, requestsB atomic.Int32
+	serverA := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) { requestsA.Add(1) }))
+	defer serverA.Close()
+	serverB := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) { requestsB.Add(1) }))
+	defer serverB.Close()
+
+	client, err := httpclient.NewClient(
+		httpclient.WithBaseURLs([]string{serverA.URL, serverB.URL}),
+		httpclient.WithStickyURIScoring())
+	require.NoError(t, err)
+
+	ctx := httpclient.ContextWithSessionKey(context.Background(), "session")
+	for i := 0; i < 20; i++ {
+		_, err := client.Get(ctx)
+		require.NoError(t, err)
+	}
+	assert.ElementsMatch(t, []int32{0, 20}, []int32{requestsA.Load(), requestsB.Load()}, "requests should all go to one server")
+}
+
+func TestURISelector(t *testing.T) {
+	var requestsA, requestsB atomic.Int32
+	serverA := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) { requestsA.Add(1) }))
+	defer serverA.Close()
+	serverB := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+		requestsB.Add(1)
+		rw.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer serverB.Close()
+
+	client, err := httpclient.NewClient(
+		httpclient.WithBaseURLs([]string{serverA.URL, serverB.URL}),
+		httpclient.WithMaxRetries(3),
+		httpclient.WithURISelector(httpclient.URISelectorFunc(func(_ context.Context, uris []httpclient.URISnapshot) []string {
+			assert.Len(t, uris, 2)
+			return []string{serverB.URL, "http://unknown"}
+		})))
+	require.NoError(t, err)
+
+	_, err = client.Get(context.Background())
+	require.Error(t, err)
+	assert.Equal(t, int32(0), requestsA.Load(), "only the selected URI should be tried")
+	assert.Equal(t, int32(4), requestsB.Load())
+}
+
+func TestURIPool(t *testing.T) {
+	var requestsA, requestsB atomic.Int32
+	serverA := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) { requestsA.Add(1) }))
+	defer serverA.Close()
+	serverB := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) { requestsB.Add(1) }))
+	defer serverB.Close()
+
+	pool := &lastURIPool{}
+	client, err := httpclient.NewClient(
+		httpclient.WithBaseURLs([]string{serverA.URL, serverB.URL}),
+		httpclient.WithURIPool(func(uris []string) httpclient.URIPool {
+			pool.uris = uris
+			return pool
+		}))
+	require.NoError(t, err)
+
+	for i := 0; i < 5; i++ {
+		_, err := client.Get(context.Background())
+		require.NoError(t, err)
+	}
+	assert.Equal(t, int32(0), requestsA.Load())
+	assert.Equal(t, int32(5), requestsB.Load())
+	assert.Equal(t, int32(5), pool.roundTrips.Load())
+}
+
+// lastURIPool prefers its URIs in reverse order.
+type lastURIPool struct {
+	uris       []string
+	roundTrips atomic.Int32
+}
+
+func (p *lastURIPool) URIsInOrder() []string {
+	uris := make([]string, len(p.uris))
+	for i, uri := range p.uris {
+		uris[len(uris)-1-i] = uri
+	}
+	return uris
+}
+
+func (p *lastURIPool) RoundTrip(req *http.Request, next http.RoundTripper) (*http.Response, error) {
+	p.roundTrips.Add(1)
+	return next.RoundTrip(req)
+}
+
+func TestContextTransformer(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	defer server.Close()
+
+	type tenantKey struct{}
+	var tenants []string
+	client, err := httpclient.NewClient(
+		httpclient.WithBaseURLs([]string{server.URL}),
+		httpclient.WithContextTransformer(func(ctx context.Context) context.Context {
+			return context.WithValue(ctx, tenantKey{}, "tenant")
+		}),
+		httpclient.WithMiddleware(httpclient.MiddlewareFunc(func(req *http.Request, next http.RoundTripper) (*http.Response, error) {
+			tenant, _ := req.Context().Value(tenantKey{}).(string)
+			tenants = append(tenants, tenant)
+			return next.RoundTrip(req)
+		})))
+	require.NoError(t, err)
+
+	_, err = client.Get(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"tenant"}, tenants)
+}
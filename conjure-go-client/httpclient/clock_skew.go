@@ -0,0 +1,98 @@
+// Copyright (c) 2026 Palantir Technologies. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpclient
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/palantir/conjure-go-runtime/v2/conjure-go-contract/httpheaders"
+)
+
+// clockSkewWeight is the weight given to each new observation in the moving average of the skew, which smooths out
+// the one second resolution of the Date header.
+const clockSkewWeight = 0.25
+
+// ClockSkewDetector estimates the offset of a server's clock from local time by comparing the Date header of
+// responses to the local time at which they were received. It is a Middleware, and is registered with a client
+// using WithClockSkewDetector.
+type ClockSkewDetector struct {
+	now func() time.Time
+
+	mu       sync.Mutex
+	skew     time.Duration
+	observed bool
+}
+
+// NewClockSkewDetector returns a ClockSkewDetector which has not observed any responses.
+func NewClockSkewDetector() *ClockSkewDetector {
+	return &ClockSkewDetector{now: time.Now}
+}
+
+// Skew returns the estimated offset of the server's clock from local time: positive if the server is ahead. It is 0
+// until a response with a valid Date header has been observed.
+func (d *ClockSkewDetector) Skew() time.Duration {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.skew
+}
+
+// Now returns the current time according to the server's clock, i.e. local time adjusted by Skew.
+func (d *ClockSkewDetector) Now() time.Time {
+	return d.now().Add(d.Skew())
+}
+
+func (d *ClockSkewDetector) RoundTrip(req *http.Request, next http.RoundTripper) (*http.Response, error) {
+	sent := d.now()
+	resp, err := next.RoundTrip(req)
+	if resp != nil {
+		d.observe(resp.Header.Get(httpheaders.Date), sent, d.now())
+	}
+	return resp, err
+}
+
+// observe updates the estimated skew using the Date header of a response to a request sent at sent and received at
+// received.
+func (d *ClockSkewDetector) observe(date string, sent, received time.Time) {
+	serverTime, err := http.ParseTime(date)
+	if err != nil {
+		return
+	}
+	// the Date header is truncated to the second, and the server generated it at some point during the round trip, so
+	// the midpoints of both intervals are compared.
+	serverTime = serverTime.Add(time.Second / 2)
+	localTime := sent.Add(received.Sub(sent) / 2)
+	observation := serverTime.Sub(localTime)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if !d.observed {
+		d.skew, d.observed = observation, true
+		return
+	}
+	d.skew += time.Duration(clockSkewWeight * float64(observation-d.skew))
+}
+
+// ServerNow returns the current time according to the clock of the server a request made with ctx is sent to, as
+// estimated by the ClockSkewDetector of the client making the request. If the client has no ClockSkewDetector, it
+// returns local time. Signing middlewares and TokenProviders can use it in place of time.Now.
+func ServerNow(ctx context.Context) time.Time {
+	if detector, ok := ctx.Value(requestClockSkewDetector).(*ClockSkewDetector); ok {
+		return detector.Now()
+	}
+	return time.Now()
+}
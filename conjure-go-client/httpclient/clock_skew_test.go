@@ -0,0 +1,62 @@
+// Copyright (c) 2026 Palantir Technologies. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpclient_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/palantir/conjure-go-runtime/v2/conjure-go-client/httpclient"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClockSkewDetector(t *testing.T) {
+	const serverOffset = time.Hour
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("Date", time.Now().Add(serverOffset).UTC().Format(http.TimeFormat))
+		rw.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	detector := httpclient.NewClockSkewDetector()
+	assert.Zero(t, detector.Skew())
+
+	var tokenTimes []time.Time
+	client, err := httpclient.NewClient(
+		httpclient.WithBaseURLs([]string{server.URL}),
+		httpclient.WithClockSkewDetector(detector),
+		httpclient.WithAuthTokenProvider(func(ctx context.Context) (string, error) {
+			tokenTimes = append(tokenTimes, httpclient.ServerNow(ctx))
+			return "token", nil
+		}))
+	require.NoError(t, err)
+
+	for i := 0; i < 2; i++ {
+		_, err = client.Get(context.Background())
+		require.NoError(t, err)
+	}
+	assert.InDelta(t, serverOffset, detector.Skew(), float64(2*time.Second))
+	assert.WithinDuration(t, time.Now().Add(serverOffset), detector.Now(), 2*time.Second)
+	require.Len(t, tokenTimes, 2)
+	// the first token is requested before any response has been observed.
+	assert.WithinDuration(t, time.Now(), tokenTimes[0], 2*time.Second)
+	assert.WithinDuration(t, time.Now().Add(serverOffset), tokenTimes[1], 2*time.Second)
+
+	assert.WithinDuration(t, time.Now(), httpclient.ServerNow(context.Background()), time.Second)
+}
@@ -19,10 +19,12 @@ import (
 	"context"
 	"fmt"
 	"io/ioutil"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"runtime"
 	"runtime/pprof"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -75,7 +77,7 @@ func TestCloseOnError(t *testing.T) {
 	_, err = client.Get(
 		ctx,
 		httpclient.WithPath("/"),
-		httpclient.WithHeader("Connection", "close "),
+		httpclient.WithConnectionClose(),
 	)
 	require.Error(t, err)
 
@@ -106,7 +108,7 @@ func TestCloseOnEmptyResponse(t *testing.T) {
 	_, err = client.Get(
 		ctx,
 		httpclient.WithPath("/"),
-		httpclient.WithHeader("Connection", "close "),
+		httpclient.WithConnectionClose(),
 	)
 	require.Error(t, err)
 
@@ -149,3 +151,34 @@ func TestStreamingResponse(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, firstLine+"\n"+secondLine+"\n", string(b))
 }
+
+func TestWithConnectionClose(t *testing.T) {
+	var newConns int32
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.WriteHeader(200)
+	}))
+	ts.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			atomic.AddInt32(&newConns, 1)
+		}
+	}
+	ts.Start()
+	defer ts.Close()
+
+	client, err := httpclient.NewClient(httpclient.WithBaseURLs([]string{ts.URL}))
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		_, err = client.Get(ctx, httpclient.WithConnectionClose())
+		require.NoError(t, err)
+	}
+	assert.Equal(t, int32(3), atomic.LoadInt32(&newConns))
+
+	// connections are reused by default
+	for i := 0; i < 3; i++ {
+		_, err = client.Get(ctx)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, int32(4), atomic.LoadInt32(&newConns))
+}
@@ -0,0 +1,189 @@
+// Copyright (c) 2026 Palantir Technologies. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpclient
+
+import (
+	"context"
+	"io"
+	"math"
+	"net/http"
+	"sync"
+
+	"github.com/palantir/pkg/metrics"
+	"github.com/palantir/pkg/refreshable"
+)
+
+const (
+	concurrencyLimitInitial = 20
+	concurrencyLimitMin     = 1
+	concurrencyLimitMax     = 1000
+	// concurrencyLimitBackoffRatio is the factor the limit is multiplied by when a request is rejected.
+	concurrencyLimitBackoffRatio = 0.9
+)
+
+// concurrencyLimiterMiddleware bounds the number of in-flight requests to each base URI with an
+// additive-increase/multiplicative-decrease limit: the limit grows by one per limit's worth of successful responses
+// received while at least half of it is in use, and shrinks by concurrencyLimitBackoffRatio on each 429 or 503
+// response. A request holds its slot until its response body is closed. Requests beyond the limit wait, in order,
+// until a slot is released or their context is done.
+type concurrencyLimiterMiddleware struct {
+	serviceName refreshable.String
+	disabled    refreshable.Bool
+
+	mu       sync.Mutex
+	limiters map[string]*aimdLimiter
+}
+
+func newConcurrencyLimiterMiddleware(serviceName refreshable.String, disabled refreshable.Bool) *concurrencyLimiterMiddleware {
+	return &concurrencyLimiterMiddleware{
+		serviceName: serviceName,
+		disabled:    disabled,
+		limiters:    make(map[string]*aimdLimiter),
+	}
+}
+
+func (m *concurrencyLimiterMiddleware) RoundTrip(req *http.Request, next http.RoundTripper) (*http.Response, error) {
+	key := getBaseURI(req.Context())
+	if key == "" {
+		key = req.URL.Host
+	}
+	limiter := m.limiter(key)
+	if err := limiter.acquire(req.Context()); err != nil {
+		return nil, err
+	}
+	resp, err := next.RoundTrip(req)
+	release := func() {
+		limit := limiter.release(limitOutcome(resp, err))
+		m.updateLimitGauge(req.Context(), key, limit)
+	}
+	if err != nil || resp == nil || resp.Body == nil {
+		release()
+		return resp, err
+	}
+	resp.Body = &limiterReleasingBody{ReadCloser: resp.Body, release: release}
+	return resp, nil
+}
+
+type limiterReleasingBody struct {
+	io.ReadCloser
+	once    sync.Once
+	release func()
+}
+
+func (b *limiterReleasingBody) Close() error {
+	defer b.once.Do(b.release)
+	return b.ReadCloser.Close()
+}
+
+func (m *concurrencyLimiterMiddleware) limiter(key string) *aimdLimiter {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	limiter, ok := m.limiters[key]
+	if !ok {
+		limiter = &aimdLimiter{limit: concurrencyLimitInitial}
+		m.limiters[key] = limiter
+	}
+	return limiter
+}
+
+func (m *concurrencyLimiterMiddleware) updateLimitGauge(ctx context.Context, key string, limit int) {
+	if m.disabled != nil && m.disabled.CurrentBool() {
+		return
+	}
+	serviceNameTag := metrics.NewTagWithFallbackValue(MetricTagServiceName, m.serviceName.CurrentString(), "unknown")
+	baseURITag := metrics.MustNewTag(metricTagBaseURI, baseURIHash(key))
+	metrics.FromContext(ctx).Gauge(MetricConcurrencyLimit, serviceNameTag, baseURITag).Update(int64(limit))
+}
+
+type limiterOutcome int
+
+const (
+	// limiterIgnore leaves the limit unchanged, e.g. for network errors which say nothing about the server's load.
+	limiterIgnore limiterOutcome = iota
+	limiterSuccess
+	limiterDropped
+)
+
+func limitOutcome(resp *http.Response, err error) limiterOutcome {
+	switch {
+	case err != nil || resp == nil:
+		return limiterIgnore
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable:
+		return limiterDropped
+	default:
+		return limiterSuccess
+	}
+}
+
+// aimdLimiter is the limit and queue of a single base URI.
+type aimdLimiter struct {
+	mu       sync.Mutex
+	limit    float64
+	inFlight int
+	// waiters are closed, in order, when a slot is handed to them.
+	waiters []chan struct{}
+}
+
+// acquire blocks until a slot is available or ctx is done.
+func (l *aimdLimiter) acquire(ctx context.Context) error {
+	l.mu.Lock()
+	if len(l.waiters) == 0 && l.inFlight < int(l.limit) {
+		l.inFlight++
+		l.mu.Unlock()
+		return nil
+	}
+	ready := make(chan struct{})
+	l.waiters = append(l.waiters, ready)
+	l.mu.Unlock()
+
+	select {
+	case <-ready:
+		return nil
+	case <-ctx.Done():
+		l.mu.Lock()
+		for i, waiter := range l.waiters {
+			if waiter == ready {
+				l.waiters = append(l.waiters[:i], l.waiters[i+1:]...)
+				l.mu.Unlock()
+				return ctx.Err()
+			}
+		}
+		l.mu.Unlock()
+		// a slot was handed over concurrently, so give it back.
+		l.release(limiterIgnore)
+		return ctx.Err()
+	}
+}
+
+// release frees a slot after a request completed with outcome, hands free slots to waiters and returns the new limit.
+func (l *aimdLimiter) release(outcome limiterOutcome) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	switch outcome {
+	case limiterSuccess:
+		if l.inFlight*2 >= int(l.limit) {
+			l.limit = math.Min(concurrencyLimitMax, l.limit+1/l.limit)
+		}
+	case limiterDropped:
+		l.limit = math.Max(concurrencyLimitMin, l.limit*concurrencyLimitBackoffRatio)
+	}
+	l.inFlight--
+	for len(l.waiters) > 0 && l.inFlight < int(l.limit) {
+		l.inFlight++
+		close(l.waiters[0])
+		l.waiters = l.waiters[1:]
+	}
+	return int(l.limit)
+}
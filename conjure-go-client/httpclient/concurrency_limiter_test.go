@@ -0,0 +1,99 @@
+// Copyright (c) 2026 Palantir Technologies. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpclient
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/palantir/pkg/metrics"
+	"github.com/palantir/pkg/refreshable"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAIMDLimiter(t *testing.T) {
+	l := &aimdLimiter{limit: 2}
+	ctx := context.Background()
+	require.NoError(t, l.acquire(ctx))
+	require.NoError(t, l.acquire(ctx))
+
+	// a third request waits for a slot.
+	timeoutCtx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	require.Equal(t, context.DeadlineExceeded, l.acquire(timeoutCtx))
+
+	acquired := make(chan error)
+	go func() { acquired <- l.acquire(ctx) }()
+	assert.Equal(t, 1, l.release(limiterDropped), "limit should shrink on a dropped request")
+	select {
+	case <-acquired:
+		t.Fatal("request should wait until in-flight requests are below the limit")
+	case <-time.After(20 * time.Millisecond):
+	}
+	assert.Equal(t, 1, l.release(limiterIgnore))
+	require.NoError(t, <-acquired)
+
+	assert.Equal(t, 2, l.release(limiterSuccess), "limit should grow on success")
+	assert.Equal(t, 0, l.inFlight)
+}
+
+func TestConcurrencyLimiter(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+		rw.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	rootRegistry := metrics.NewRootMetricsRegistry()
+	ctx := metrics.WithRegistry(context.Background(), rootRegistry)
+	client, err := NewClient(
+		WithServiceName("test-service"),
+		WithBaseURLs([]string{server.URL}),
+		WithMaxRetries(0),
+		WithConcurrencyLimiter())
+	require.NoError(t, err)
+
+	for i := 0; i < 3; i++ {
+		_, err = client.Get(ctx)
+		require.Error(t, err)
+	}
+	gauge := rootRegistry.Gauge(MetricConcurrencyLimit,
+		metrics.MustNewTag(MetricTagServiceName, "test-service"),
+		metrics.MustNewTag(metricTagBaseURI, baseURIHash(server.URL)))
+	assert.EqualValues(t, 14, gauge.Value())
+}
+
+func TestConcurrencyLimiterReleasesOnBodyClose(t *testing.T) {
+	m := newConcurrencyLimiterMiddleware(refreshable.NewString(refreshable.NewDefaultRefreshable("test-service")), nil)
+	next := roundTripperFunc(func(*http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader("body"))}, nil
+	})
+	req, err := http.NewRequest(http.MethodGet, "http://localhost", nil)
+	require.NoError(t, err)
+
+	resp, err := m.RoundTrip(req, next)
+	require.NoError(t, err)
+	limiter := m.limiter("localhost")
+	assert.Equal(t, 1, limiter.inFlight, "slot should be held until the response body is closed")
+
+	require.NoError(t, resp.Body.Close())
+	require.NoError(t, resp.Body.Close())
+	assert.Equal(t, 0, limiter.inFlight)
+}
@@ -18,13 +18,20 @@ import (
 	"bytes"
 	"context"
 	"io/ioutil"
+	"net"
+	"net/http"
 	"net/url"
+	"reflect"
 	"slices"
+	"strings"
 	"time"
 
 	"github.com/palantir/conjure-go-runtime/v2/conjure-go-client/httpclient/internal/refreshingclient"
 	"github.com/palantir/pkg/metrics"
+	"github.com/palantir/pkg/refreshable"
+	refreshablev2 "github.com/palantir/pkg/refreshable/v2"
 	werror "github.com/palantir/witchcraft-go-error"
+	"github.com/palantir/witchcraft-go-logging/wlog/svclog/svc1log"
 )
 
 // ServicesConfig is the top-level configuration struct for all HTTP clients. It supports
@@ -39,12 +46,26 @@ type ServicesConfig struct {
 	Services map[string]ClientConfig `json:"services,omitempty" yaml:"services,omitempty"`
 }
 
+// NewRefreshingServicesConfigFromV2 returns a RefreshableServicesConfig backed by a generics-based refreshable from
+// github.com/palantir/pkg/refreshable/v2, for use with APIs such as NewClientFactory.
+func NewRefreshingServicesConfigFromV2(config refreshablev2.Refreshable[ServicesConfig]) RefreshableServicesConfig {
+	return NewRefreshingServicesConfig(refreshable.FromV2(config))
+}
+
 // ClientConfig represents the configuration for a single REST client.
 type ClientConfig struct {
 	ServiceName string `json:"-" yaml:"-"`
 	// URIs is a list of fully specified base URIs for the service. These can optionally include a path
 	// which will be prepended to the request path specified when invoking the client.
 	URIs []string `json:"uris,omitempty" yaml:"uris,omitempty"`
+	// URIGroups partitions the service's URIs into named groups which each receive a percentage of requests, e.g.
+	// 95% to a "stable" group and 5% to a "canary" group. The percentages must add up to 100. Groups may also be
+	// given priorities for active/passive deployments, e.g. a "primary" group with priority 0 and a "secondary" group
+	// with priority 1: requests are only sent to the secondary group once every primary URI is unavailable, and return
+	// to the primary group once it has been healthy for a minute. The percentages of the groups with each priority
+	// must add up to 100; a priority with a single group may omit its percentage. URIGroups may not be set together
+	// with URIs.
+	URIGroups []URIGroupConfig `json:"uri-groups,omitempty" yaml:"uri-groups,omitempty"`
 	// APIToken is a string which, if provided, will be used as a Bearer token in the Authorization header.
 	// This takes precedence over APITokenFile.
 	APIToken *string `json:"api-token,omitempty" yaml:"api-token,omitempty"`
@@ -69,6 +90,14 @@ type ClientConfig struct {
 	InitialBackoff *time.Duration `json:"initial-backoff,omitempty" yaml:"initial-backoff,omitempty"`
 	// MaxBackoff controls the maximum duration the client will sleep before retrying a request.
 	MaxBackoff *time.Duration `json:"max-backoff,omitempty" yaml:"max-backoff,omitempty"`
+	// RetryBudgetRatio, if set and positive, bounds the retries sent by the client to this fraction of its requests
+	// over a sliding window of ten seconds, e.g. 0.2 for at most one retry for every five requests. Retries rejected
+	// by the budget mark the client.retry.budget.exhausted meter. If unset, retries are not budgeted.
+	RetryBudgetRatio *float64 `json:"retry-budget-ratio,omitempty" yaml:"retry-budget-ratio,omitempty"`
+	// MinRetriesPerSecond is the number of retries per second allowed by the retry budget regardless of
+	// RetryBudgetRatio, so that clients which send few requests can still retry. It only applies if
+	// RetryBudgetRatio is set, and defaults to 10.
+	MinRetriesPerSecond *int `json:"min-retries-per-second,omitempty" yaml:"min-retries-per-second,omitempty"`
 
 	// ConnectTimeout is the maximum time for the net.Dialer to connect to the remote host.
 	ConnectTimeout *time.Duration `json:"connect-timeout,omitempty" yaml:"connect-timeout,omitempty"`
@@ -107,11 +136,46 @@ type ClientConfig struct {
 	// If unset, the client defaults to 100.
 	MaxIdleConnsPerHost *int `json:"max-idle-conns-per-host,omitempty" yaml:"max-idle-conns-per-host,omitempty"`
 
+	// RequestCompressionThreshold, if positive, is the encoded size in bytes at or above which request bodies are
+	// compressed with gzip or deflate, as negotiated with the server. See WithRequestCompressionThreshold.
+	// Requests which already set a Content-Encoding are not compressed.
+	// The threshold may be written with a unit suffix, e.g. "64KB". See ByteSize for details.
+	// If unset, request bodies are not compressed automatically.
+	RequestCompressionThreshold *ByteSize `json:"request-compression-threshold,omitempty" yaml:"request-compression-threshold,omitempty"`
+
+	// EnableCookies, if true, stores the cookies set by servers in an in-memory cookie jar and sends them with
+	// subsequent requests, so that session cookies survive across requests and retries. If unset, cookies are not
+	// stored. A refresh can disable and re-enable cookies, but only if they were enabled when the client was built.
+	EnableCookies *bool `json:"enable-cookies,omitempty" yaml:"enable-cookies,omitempty"`
+
+	// LatencyBudgets maps RPC method names to the maximum duration a request to that endpoint is expected to take.
+	// Request attempts which exceed their budget mark the client.budget.exceeded meter.
+	LatencyBudgets map[string]time.Duration `json:"latency-budgets,omitempty" yaml:"latency-budgets,omitempty"`
+
+	// EndpointTimeouts maps RPC method names to the timeout of each attempt of a request to that endpoint, overriding
+	// ReadTimeout, WriteTimeout and the timeout of the request's RequestClassPolicy. Timeouts set on a request with
+	// WithRequestTimeout take precedence.
+	EndpointTimeouts map[string]time.Duration `json:"endpoint-timeouts,omitempty" yaml:"endpoint-timeouts,omitempty"`
+
+	// EndpointMaxNumRetries maps RPC method names to the number of times a request to that endpoint is retried,
+	// overriding MaxNumRetries and the retries of the request's RequestClassPolicy.
+	EndpointMaxNumRetries map[string]int `json:"endpoint-max-num-retries,omitempty" yaml:"endpoint-max-num-retries,omitempty"`
+
+	// Headers are set on every request, e.g. to add routing or identification headers such as X-Client-Id without
+	// code changes. Header names are case-insensitive. Service-specific headers take precedence over default headers
+	// with the same name, and headers set by middlewares or request params take precedence over both.
+	Headers map[string]string `json:"headers,omitempty" yaml:"headers,omitempty"`
+
 	// Metrics allows disabling metric emission or adding additional static tags to the client metrics.
 	Metrics MetricsConfig `json:"metrics,omitempty" yaml:"metrics,omitempty"`
 	// Security configures the TLS configuration for the client. It accepts file paths which should be
 	// absolute paths or relative to the process's current working directory.
 	Security SecurityConfig `json:"security,omitempty" yaml:"security,omitempty"`
+	// URISecurity overrides Security for connections to specific URIs, e.g. while a service migrates to endpoints
+	// which use a different CA or server name. Keys are base URIs; overrides apply to every URI with the same scheme,
+	// host and port. Unset fields of an override use the value from Security. Overrides do not apply to connections
+	// tunneled through an HTTP proxy.
+	URISecurity map[string]SecurityConfig `json:"uri-security,omitempty" yaml:"uri-security,omitempty"`
 }
 
 // BasicAuth represents the configuration for HTTP Basic Authorization
@@ -122,6 +186,19 @@ type BasicAuth struct {
 	Password string `json:"password,omitempty" yaml:"password,omitempty"`
 }
 
+// URIGroupConfig represents a named group of URIs which receives a percentage of a client's requests.
+type URIGroupConfig struct {
+	// Name identifies the group. It is used as the value of the "uri-group" metric tag.
+	Name string `json:"name,omitempty" yaml:"name,omitempty"`
+	// URIs is the list of base URIs in the group.
+	URIs []string `json:"uris,omitempty" yaml:"uris,omitempty"`
+	// Percentage is the percentage of the requests sent to the group's priority which are sent to the group first.
+	Percentage int `json:"percentage,omitempty" yaml:"percentage,omitempty"`
+	// Priority orders the group relative to the client's other groups. Groups with a lower priority receive requests
+	// while any of their URIs is healthy; groups with a higher priority are used on failover. Defaults to 0.
+	Priority int `json:"priority,omitempty" yaml:"priority,omitempty"`
+}
+
 type MetricsConfig struct {
 	// Enabled can be used to disable metrics with an explicit 'false'. Metrics are enabled if this is unset.
 	Enabled *bool `json:"enabled,omitempty" yaml:"enabled,omitempty"`
@@ -134,9 +211,24 @@ type SecurityConfig struct {
 	CertFile string   `json:"cert-file,omitempty" yaml:"cert-file,omitempty"`
 	KeyFile  string   `json:"key-file,omitempty" yaml:"key-file,omitempty"`
 
+	// CAPEM, CertPEM and KeyPEM configure TLS from PEM-encoded content rather than files, e.g. for certificates
+	// received from a secret store. The CA certificates in CAPEM are trusted in addition to those in CAFiles. If both
+	// CertPEM and KeyPEM are set, they are used as the client certificate instead of CertFile and KeyFile.
+	CAPEM   string `json:"ca-pem,omitempty" yaml:"ca-pem,omitempty"`
+	CertPEM string `json:"cert-pem,omitempty" yaml:"cert-pem,omitempty"`
+	KeyPEM  string `json:"key-pem,omitempty" yaml:"key-pem,omitempty"`
+
 	// InsecureSkipVerify sets the InsecureSkipVerify field for the HTTP client's tls config.
 	// This option should only be used in clients that have other ways to establish trust with servers.
 	InsecureSkipVerify *bool `json:"insecure-skip-verify,omitempty" yaml:"insecure-skip-verify,omitempty"`
+
+	// ServerName, if set, is used to verify the server's certificate and as the SNI value instead of the URI's host.
+	ServerName string `json:"server-name,omitempty" yaml:"server-name,omitempty"`
+
+	// TLSRefreshInterval, if positive, is the interval at which the contents of the CA, certificate and key files are
+	// checked for changes. When they change, the TLS configuration is rebuilt so that certificates rotated on disk are
+	// used without a configuration change. If unset, the files are only read when the configuration changes.
+	TLSRefreshInterval *time.Duration `json:"tls-refresh-interval,omitempty" yaml:"tls-refresh-interval,omitempty"`
 }
 
 // MustClientConfig returns an error if the service name is not configured.
@@ -162,8 +254,9 @@ func (c ServicesConfig) ClientConfig(serviceName string) ClientConfig {
 // MergeClientConfig merges two instances of ClientConfig, preferring values from conf over defaults.
 // The ServiceName field is not affected, and is expected to be set in the config before building a Client.
 func MergeClientConfig(conf, defaults ClientConfig) ClientConfig {
-	if len(conf.URIs) == 0 {
+	if len(conf.URIs) == 0 && len(conf.URIGroups) == 0 {
 		conf.URIs = defaults.URIs
+		conf.URIGroups = defaults.URIGroups
 	}
 	if conf.APIToken == nil {
 		conf.APIToken = defaults.APIToken
@@ -177,6 +270,12 @@ func MergeClientConfig(conf, defaults ClientConfig) ClientConfig {
 	if conf.MaxNumRetries == nil {
 		conf.MaxNumRetries = defaults.MaxNumRetries
 	}
+	if conf.RequestCompressionThreshold == nil {
+		conf.RequestCompressionThreshold = defaults.RequestCompressionThreshold
+	}
+	if conf.EnableCookies == nil {
+		conf.EnableCookies = defaults.EnableCookies
+	}
 	if conf.ConnectTimeout == nil {
 		conf.ConnectTimeout = defaults.ConnectTimeout
 	}
@@ -222,6 +321,12 @@ func MergeClientConfig(conf, defaults ClientConfig) ClientConfig {
 	if conf.MaxBackoff == nil {
 		conf.MaxBackoff = defaults.MaxBackoff
 	}
+	if conf.RetryBudgetRatio == nil {
+		conf.RetryBudgetRatio = defaults.RetryBudgetRatio
+	}
+	if conf.MinRetriesPerSecond == nil {
+		conf.MinRetriesPerSecond = defaults.MinRetriesPerSecond
+	}
 	if conf.DisableHTTP2 == nil {
 		conf.DisableHTTP2 = defaults.DisableHTTP2
 	}
@@ -242,6 +347,46 @@ func MergeClientConfig(conf, defaults ClientConfig) ClientConfig {
 			}
 		}
 	}
+	if len(defaults.LatencyBudgets) != 0 {
+		if conf.LatencyBudgets == nil {
+			conf.LatencyBudgets = make(map[string]time.Duration, len(defaults.LatencyBudgets))
+		}
+		for k, v := range defaults.LatencyBudgets {
+			if _, ok := conf.LatencyBudgets[k]; !ok {
+				conf.LatencyBudgets[k] = v
+			}
+		}
+	}
+	if len(defaults.EndpointTimeouts) != 0 {
+		if conf.EndpointTimeouts == nil {
+			conf.EndpointTimeouts = make(map[string]time.Duration, len(defaults.EndpointTimeouts))
+		}
+		for k, v := range defaults.EndpointTimeouts {
+			if _, ok := conf.EndpointTimeouts[k]; !ok {
+				conf.EndpointTimeouts[k] = v
+			}
+		}
+	}
+	if len(defaults.EndpointMaxNumRetries) != 0 {
+		if conf.EndpointMaxNumRetries == nil {
+			conf.EndpointMaxNumRetries = make(map[string]int, len(defaults.EndpointMaxNumRetries))
+		}
+		for k, v := range defaults.EndpointMaxNumRetries {
+			if _, ok := conf.EndpointMaxNumRetries[k]; !ok {
+				conf.EndpointMaxNumRetries[k] = v
+			}
+		}
+	}
+	if len(defaults.Headers) != 0 {
+		merged := make(map[string]string, len(conf.Headers)+len(defaults.Headers))
+		for k, v := range defaults.Headers {
+			merged[http.CanonicalHeaderKey(k)] = v
+		}
+		for k, v := range conf.Headers {
+			merged[http.CanonicalHeaderKey(k)] = v
+		}
+		conf.Headers = merged
+	}
 	if conf.Security.CAFiles == nil {
 		conf.Security.CAFiles = defaults.Security.CAFiles
 	}
@@ -251,9 +396,21 @@ func MergeClientConfig(conf, defaults ClientConfig) ClientConfig {
 	if conf.Security.KeyFile == "" {
 		conf.Security.KeyFile = defaults.Security.KeyFile
 	}
+	if conf.Security.CAPEM == "" {
+		conf.Security.CAPEM = defaults.Security.CAPEM
+	}
+	if conf.Security.CertPEM == "" {
+		conf.Security.CertPEM = defaults.Security.CertPEM
+	}
+	if conf.Security.KeyPEM == "" {
+		conf.Security.KeyPEM = defaults.Security.KeyPEM
+	}
 	if conf.Security.InsecureSkipVerify == nil {
 		conf.Security.InsecureSkipVerify = defaults.Security.InsecureSkipVerify
 	}
+	if conf.Security.TLSRefreshInterval == nil {
+		conf.Security.TLSRefreshInterval = defaults.Security.TLSRefreshInterval
+	}
 	return conf
 }
 
@@ -264,6 +421,10 @@ func configToParams(c ClientConfig) ([]ClientParam, error) {
 		params = append(params, WithBaseURLs(c.URIs))
 	}
 
+	if len(c.URIGroups) > 0 {
+		params = append(params, WithURIGroups(c.URIGroups...))
+	}
+
 	if c.ServiceName != "" {
 		params = append(params, WithServiceName(c.ServiceName))
 	}
@@ -293,6 +454,45 @@ func configToParams(c ClientConfig) ([]ClientParam, error) {
 		params = append(params, WithMaxRetries(*c.MaxNumRetries))
 	}
 
+	// Request compression
+
+	if c.RequestCompressionThreshold != nil {
+		params = append(params, WithRequestCompressionThreshold(int(*c.RequestCompressionThreshold)))
+	}
+	if derefPtr(c.EnableCookies, false) {
+		params = append(params, clientOrHTTPClientParamFunc(func(b *httpClientBuilder) error {
+			b.EnableCookies = refreshable.NewBool(refreshable.NewDefaultRefreshable(true))
+			return nil
+		}))
+	}
+
+	// Latency budgets
+
+	if len(c.LatencyBudgets) > 0 {
+		params = append(params, WithLatencyBudgets(c.LatencyBudgets))
+	}
+
+	// Endpoint timeouts
+
+	if len(c.EndpointTimeouts) > 0 {
+		params = append(params, WithEndpointTimeouts(c.EndpointTimeouts))
+	}
+	if len(c.EndpointMaxNumRetries) > 0 {
+		params = append(params, WithEndpointMaxRetries(c.EndpointMaxNumRetries))
+	}
+
+	// Static headers
+
+	if len(c.Headers) > 0 {
+		headers, err := newStaticHeaders(c.Headers)
+		if err != nil {
+			return nil, werror.Wrap(err, "invalid headers configuration")
+		}
+		params = append(params, WithMiddleware(&staticHeadersMiddleware{
+			headers: refreshablev2.New(headers),
+		}))
+	}
+
 	// Backoff
 
 	if c.MaxBackoff != nil {
@@ -303,6 +503,12 @@ func configToParams(c ClientConfig) ([]ClientParam, error) {
 		params = append(params, WithInitialBackoff(*c.InitialBackoff))
 	}
 
+	// Retry budget
+
+	if c.RetryBudgetRatio != nil {
+		params = append(params, WithRetryBudget(*c.RetryBudgetRatio, derefPtr(c.MinRetriesPerSecond, defaultMinRetriesPerSecond)))
+	}
+
 	// Metrics (default enabled)
 
 	if c.Metrics.Enabled == nil || (c.Metrics.Enabled != nil && *c.Metrics.Enabled) {
@@ -365,16 +571,32 @@ func configToParams(c ClientConfig) ([]ClientParam, error) {
 	}
 
 	// Security (TLS) Config
-	if tlsConfig, err := refreshingclient.NewTLSConfig(context.TODO(), refreshingclient.TLSParams{
-		CAFiles:            c.Security.CAFiles,
-		CertFile:           c.Security.CertFile,
-		KeyFile:            c.Security.KeyFile,
-		InsecureSkipVerify: derefPtr(c.Security.InsecureSkipVerify, false),
-	}); err != nil {
+	tlsParams := newTLSParams(c.Security)
+	if tlsConfig, err := refreshingclient.NewTLSConfig(context.TODO(), tlsParams); err != nil {
 		return nil, err
+	} else if tlsParams.RefreshInterval > 0 {
+		// the files are read by the transport's refreshable TLS config so that it can watch them.
+		params = append(params, clientOrHTTPClientParamFunc(func(b *httpClientBuilder) error {
+			b.TransportParams = refreshingclient.ConfigureTransport(b.TransportParams, func(p refreshingclient.TransportParams) refreshingclient.TransportParams {
+				p.TLS = tlsParams
+				return p
+			})
+			return nil
+		}))
 	} else if tlsConfig != nil {
 		params = append(params, WithTLSConfig(tlsConfig))
 	}
+	if hostTLS, err := newHostTLSParams(context.TODO(), c); err != nil {
+		return nil, err
+	} else if hostTLS != nil {
+		params = append(params, clientOrHTTPClientParamFunc(func(b *httpClientBuilder) error {
+			b.TransportParams = refreshingclient.ConfigureTransport(b.TransportParams, func(p refreshingclient.TransportParams) refreshingclient.TransportParams {
+				p.HostTLS = hostTLS
+				return p
+			})
+			return nil
+		}))
+	}
 
 	return params, nil
 }
@@ -402,13 +624,13 @@ func newValidatedClientParamsFromConfig(ctx context.Context, config ClientConfig
 		HTTP2ReadIdleTimeout:  derefPtr(config.HTTP2ReadIdleTimeout, defaultHTTP2ReadIdleTimeout),
 		ProxyFromEnvironment:  derefPtr(config.ProxyFromEnvironment, true),
 		TLSHandshakeTimeout:   derefPtr(config.TLSHandshakeTimeout, defaultTLSHandshakeTimeout),
-		TLS: refreshingclient.TLSParams{
-			CAFiles:            config.Security.CAFiles,
-			CertFile:           config.Security.CertFile,
-			KeyFile:            config.Security.KeyFile,
-			InsecureSkipVerify: derefPtr(config.Security.InsecureSkipVerify, false),
-		},
+		TLS:                   newTLSParams(config.Security),
 	}
+	hostTLS, err := newHostTLSParams(ctx, config)
+	if err != nil {
+		return refreshingclient.ValidatedClientParams{}, err
+	}
+	transport.HostTLS = hostTLS
 
 	if config.ProxyURL != nil {
 		proxyURL, err := url.ParseRequestURI(*config.ProxyURL)
@@ -455,12 +677,50 @@ func newValidatedClientParamsFromConfig(ctx context.Context, config ClientConfig
 		InitialBackoff: derefPtr(config.InitialBackoff, defaultInitialBackoff),
 		MaxBackoff:     derefPtr(config.MaxBackoff, defaultMaxBackoff),
 	}
+	if config.RetryBudgetRatio != nil {
+		retryParams.RetryBudgetRatio = *config.RetryBudgetRatio
+		retryParams.MinRetriesPerSecond = derefPtr(config.MinRetriesPerSecond, defaultMinRetriesPerSecond)
+		if err := validateRetryBudget(retryParams.RetryBudgetRatio, retryParams.MinRetriesPerSecond); err != nil {
+			return refreshingclient.ValidatedClientParams{}, werror.WrapWithContextParams(ctx, err, "invalid retry budget",
+				werror.SafeParam("serviceName", config.ServiceName))
+		}
+	}
 	var maxAttempts *int
 	if config.MaxNumRetries != nil {
 		attempts := *config.MaxNumRetries + 1
 		maxAttempts = &attempts
 	}
 
+	compressionThreshold := int(derefPtr(config.RequestCompressionThreshold, 0))
+	if compressionThreshold < 0 {
+		return refreshingclient.ValidatedClientParams{}, werror.ErrorWithContextParams(ctx, "request-compression-threshold must not be negative",
+			werror.SafeParam("requestCompressionThreshold", compressionThreshold))
+	}
+
+	endpointTimeouts, err := newEndpointTimeouts(config.EndpointTimeouts)
+	if err != nil {
+		return refreshingclient.ValidatedClientParams{}, werror.WrapWithContextParams(ctx, err, "invalid endpoint-timeouts",
+			werror.SafeParam("serviceName", config.ServiceName))
+	}
+
+	endpointMaxRetries, err := newEndpointMaxRetries(config.EndpointMaxNumRetries)
+	if err != nil {
+		return refreshingclient.ValidatedClientParams{}, werror.WrapWithContextParams(ctx, err, "invalid endpoint-max-num-retries",
+			werror.SafeParam("serviceName", config.ServiceName))
+	}
+
+	latencyBudgets, err := newLatencyBudgets(config.LatencyBudgets)
+	if err != nil {
+		return refreshingclient.ValidatedClientParams{}, werror.WrapWithContextParams(ctx, err, "invalid latency-budgets",
+			werror.SafeParam("serviceName", config.ServiceName))
+	}
+
+	staticHeaders, err := newStaticHeaders(config.Headers)
+	if err != nil {
+		return refreshingclient.ValidatedClientParams{}, werror.WrapWithContextParams(ctx, err, "invalid headers",
+			werror.SafeParam("serviceName", config.ServiceName))
+	}
+
 	timeout := defaultHTTPTimeout
 	if config.ReadTimeout != nil || config.WriteTimeout != nil {
 		rt := derefPtr(config.ReadTimeout, 0)
@@ -473,31 +733,323 @@ func newValidatedClientParamsFromConfig(ctx context.Context, config ClientConfig
 		}
 	}
 
-	uris := make([]string, 0, len(config.URIs))
-	for _, uriStr := range config.URIs {
+	if len(config.URIs) > 0 && len(config.URIGroups) > 0 {
+		return refreshingclient.ValidatedClientParams{}, werror.ErrorWithContextParams(ctx, "uris and uri-groups must not both be set",
+			werror.SafeParam("serviceName", config.ServiceName))
+	}
+	var uris []string
+	var uriGroups []refreshingclient.URIGroup
+	if len(config.URIGroups) > 0 {
+		uris, uriGroups, err = validateURIGroups(ctx, config.ServiceName, config.URIGroups)
+	} else {
+		uris, err = normalizeURIs(ctx, config.ServiceName, config.URIs)
+	}
+	if err != nil {
+		return refreshingclient.ValidatedClientParams{}, err
+	}
+
+	return refreshingclient.ValidatedClientParams{
+		APIToken:                    apiToken,
+		BasicAuth:                   basicAuth,
+		Dialer:                      dialer,
+		DisableMetrics:              disableMetrics,
+		EnableCookies:               derefPtr(config.EnableCookies, false),
+		EndpointMaxRetries:          endpointMaxRetries,
+		EndpointTimeouts:            endpointTimeouts,
+		LatencyBudgets:              latencyBudgets,
+		MaxAttempts:                 maxAttempts,
+		MetricsTags:                 metricsTags,
+		RequestCompressionThreshold: compressionThreshold,
+		Retry:                       retryParams,
+		ServiceName:                 config.ServiceName,
+		StaticHeaders:               staticHeaders,
+		Timeout:                     timeout,
+		Transport:                   transport,
+		URIGroups:                   uriGroups,
+		URIs:                        uris,
+	}, nil
+}
+
+// validateRetryBudget returns an error if ratio or minRetriesPerSecond is negative.
+func validateRetryBudget(ratio float64, minRetriesPerSecond int) error {
+	if ratio < 0 {
+		return werror.Error("retry-budget-ratio must not be negative", werror.SafeParam("retryBudgetRatio", ratio))
+	}
+	if minRetriesPerSecond < 0 {
+		return werror.Error("min-retries-per-second must not be negative", werror.SafeParam("minRetriesPerSecond", minRetriesPerSecond))
+	}
+	return nil
+}
+
+// newEndpointTimeouts copies timeouts, returning an error if any RPC method name is empty or any timeout is not
+// positive.
+func newEndpointTimeouts(timeouts map[string]time.Duration) (refreshingclient.EndpointTimeouts, error) {
+	validTimeouts := make(refreshingclient.EndpointTimeouts, len(timeouts))
+	for methodName, timeout := range timeouts {
+		if methodName == "" {
+			return nil, werror.Error("endpoint timeout method names must not be empty")
+		}
+		if timeout <= 0 {
+			return nil, werror.Error("endpoint timeouts must be positive",
+				werror.SafeParam("rpcMethodName", methodName),
+				werror.SafeParam("endpointTimeout", timeout.String()))
+		}
+		validTimeouts[methodName] = timeout
+	}
+	return validTimeouts, nil
+}
+
+// newEndpointMaxRetries copies retries, returning an error if any RPC method name is empty or any number of retries
+// is negative.
+func newEndpointMaxRetries(retries map[string]int) (refreshingclient.EndpointMaxRetries, error) {
+	validRetries := make(refreshingclient.EndpointMaxRetries, len(retries))
+	for methodName, maxRetries := range retries {
+		if methodName == "" {
+			return nil, werror.Error("endpoint max retries method names must not be empty")
+		}
+		if maxRetries < 0 {
+			return nil, werror.Error("endpoint max retries must not be negative",
+				werror.SafeParam("rpcMethodName", methodName),
+				werror.SafeParam("endpointMaxRetries", maxRetries))
+		}
+		validRetries[methodName] = maxRetries
+	}
+	return validRetries, nil
+}
+
+// newLatencyBudgets copies budgets, returning an error if any RPC method name is empty or any budget is not positive.
+func newLatencyBudgets(budgets map[string]time.Duration) (refreshingclient.LatencyBudgets, error) {
+	validBudgets := make(refreshingclient.LatencyBudgets, len(budgets))
+	for methodName, budget := range budgets {
+		if methodName == "" {
+			return nil, werror.Error("latency budget method names must not be empty")
+		}
+		if budget <= 0 {
+			return nil, werror.Error("latency budgets must be positive",
+				werror.SafeParam("rpcMethodName", methodName),
+				werror.SafeParam("latencyBudget", budget.String()))
+		}
+		validBudgets[methodName] = budget
+	}
+	return validBudgets, nil
+}
+
+// normalizeURIs parses and normalizes uriStrs, dropping empty and duplicate URIs. The result is sorted.
+func normalizeURIs(ctx context.Context, serviceName string, uriStrs []string) ([]string, error) {
+	uris := make([]string, 0, len(uriStrs))
+	seenURIs := make(map[string]string, len(uriStrs))
+	for _, uriStr := range uriStrs {
 		if uriStr == "" {
 			continue
 		}
-		if _, err := url.ParseRequestURI(uriStr); err != nil {
-			return refreshingclient.ValidatedClientParams{}, werror.WrapWithContextParams(ctx, err, "invalid url")
+		uri, err := url.ParseRequestURI(uriStr)
+		if err != nil {
+			return nil, werror.WrapWithContextParams(ctx, err, "invalid url")
+		}
+		normalized := normalizeURI(uri)
+		if original, ok := seenURIs[normalized]; ok {
+			// Duplicate URIs would receive a disproportionate share of requests and retries.
+			svc1log.FromContext(ctx).Warn("Ignoring duplicate client URI",
+				svc1log.SafeParam("serviceName", serviceName),
+				svc1log.UnsafeParam("uri", uriStr),
+				svc1log.UnsafeParam("duplicateOf", original))
+			continue
 		}
-		uris = append(uris, uriStr)
+		seenURIs[normalized] = uriStr
+		uris = append(uris, normalized)
 	}
 	slices.Sort(uris)
+	return uris, nil
+}
 
-	return refreshingclient.ValidatedClientParams{
-		APIToken:       apiToken,
-		BasicAuth:      basicAuth,
-		Dialer:         dialer,
-		DisableMetrics: disableMetrics,
-		MaxAttempts:    maxAttempts,
-		MetricsTags:    metricsTags,
-		Retry:          retryParams,
-		ServiceName:    config.ServiceName,
-		Timeout:        timeout,
-		Transport:      transport,
-		URIs:           uris,
-	}, nil
+// validateURIGroups normalizes the URIs of each group and verifies that group names are unique, that no URI belongs
+// to more than one group, and that the percentages of the groups with each priority add up to 100. The percentage of
+// a group which is alone at its priority defaults to 100. It returns the sorted URIs of all groups along with the
+// validated groups.
+func validateURIGroups(ctx context.Context, serviceName string, groups []URIGroupConfig) ([]string, []refreshingclient.URIGroup, error) {
+	var allURIs []string
+	validated := make([]refreshingclient.URIGroup, 0, len(groups))
+	uriGroupNames := make(map[string]string)
+	seenNames := make(map[string]struct{}, len(groups))
+	totals := make(map[int]int)
+	counts := make(map[int]int)
+	for _, group := range groups {
+		counts[group.Priority]++
+	}
+	for _, group := range groups {
+		if group.Name == "" {
+			return nil, nil, werror.ErrorWithContextParams(ctx, "uri-groups names must not be empty",
+				werror.SafeParam("serviceName", serviceName))
+		}
+		if _, ok := seenNames[group.Name]; ok {
+			return nil, nil, werror.ErrorWithContextParams(ctx, "uri-groups names must be unique",
+				werror.SafeParam("serviceName", serviceName),
+				werror.SafeParam("uriGroup", group.Name))
+		}
+		seenNames[group.Name] = struct{}{}
+		if group.Percentage < 0 || group.Percentage > 100 {
+			return nil, nil, werror.ErrorWithContextParams(ctx, "uri-groups percentage must be between 0 and 100",
+				werror.SafeParam("serviceName", serviceName),
+				werror.SafeParam("uriGroup", group.Name),
+				werror.SafeParam("percentage", group.Percentage))
+		}
+		if group.Priority < 0 {
+			return nil, nil, werror.ErrorWithContextParams(ctx, "uri-groups priority must not be negative",
+				werror.SafeParam("serviceName", serviceName),
+				werror.SafeParam("uriGroup", group.Name),
+				werror.SafeParam("priority", group.Priority))
+		}
+		percentage := group.Percentage
+		if percentage == 0 && counts[group.Priority] == 1 {
+			percentage = 100
+		}
+		totals[group.Priority] += percentage
+		uris, err := normalizeURIs(ctx, serviceName, group.URIs)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, uri := range uris {
+			if other, ok := uriGroupNames[uri]; ok {
+				return nil, nil, werror.ErrorWithContextParams(ctx, "uri-groups must not share URIs",
+					werror.SafeParam("serviceName", serviceName),
+					werror.SafeParam("uriGroup", group.Name),
+					werror.SafeParam("otherURIGroup", other),
+					werror.UnsafeParam("uri", uri))
+			}
+			uriGroupNames[uri] = group.Name
+		}
+		allURIs = append(allURIs, uris...)
+		validated = append(validated, refreshingclient.URIGroup{
+			Name:       group.Name,
+			URIs:       uris,
+			Percentage: percentage,
+			Priority:   group.Priority,
+		})
+	}
+	for _, priority := range uriGroupPriorities(validated) {
+		if total := totals[priority]; total != 100 {
+			return nil, nil, werror.ErrorWithContextParams(ctx, "uri-groups percentages must add up to 100",
+				werror.SafeParam("serviceName", serviceName),
+				werror.SafeParam("priority", priority),
+				werror.SafeParam("totalPercentage", total))
+		}
+	}
+	slices.Sort(allURIs)
+	return allURIs, validated, nil
+}
+
+// normalizeURI returns the canonical form of uri so that equivalent URIs compare equal: the scheme and host are
+// lowercased, the default port for the scheme is removed, and trailing slashes are removed from the path.
+func normalizeURI(uri *url.URL) string {
+	normalized := *uri
+	normalized.Scheme = strings.ToLower(uri.Scheme)
+	host, port := strings.ToLower(uri.Hostname()), uri.Port()
+	if (normalized.Scheme == "http" && port == "80") || (normalized.Scheme == "https" && port == "443") {
+		port = ""
+	}
+	if strings.Contains(host, ":") {
+		// IPv6 literal
+		host = "[" + host + "]"
+	}
+	if port != "" {
+		host += ":" + port
+	}
+	normalized.Host = host
+	normalized.Path = strings.TrimRight(uri.Path, "/")
+	normalized.RawPath = ""
+	if uri.RawPath != "" {
+		normalized.RawPath = strings.TrimRight(uri.RawPath, "/")
+	}
+	return normalized.String()
+}
+
+// newTLSParams returns the TLS params configured by security.
+func newTLSParams(security SecurityConfig) refreshingclient.TLSParams {
+	return refreshingclient.TLSParams{
+		CAFiles:            security.CAFiles,
+		CertFile:           security.CertFile,
+		KeyFile:            security.KeyFile,
+		CAPEM:              security.CAPEM,
+		CertPEM:            security.CertPEM,
+		KeyPEM:             security.KeyPEM,
+		InsecureSkipVerify: derefPtr(security.InsecureSkipVerify, false),
+		ServerName:         security.ServerName,
+		RefreshInterval:    derefPtr(security.TLSRefreshInterval, 0),
+	}
+}
+
+// newHostTLSParams returns the TLS params of the URISecurity overrides of config, keyed by the address of the URIs
+// they apply to, or nil if there are none. It returns an error if an override is invalid or two overrides apply to
+// the same address.
+func newHostTLSParams(ctx context.Context, config ClientConfig) (refreshingclient.HostTLSParams, error) {
+	if len(config.URISecurity) == 0 {
+		return nil, nil
+	}
+	hostTLS := make(refreshingclient.HostTLSParams, len(config.URISecurity))
+	for uri, security := range config.URISecurity {
+		addr, err := uriAddress(uri)
+		if err != nil {
+			return nil, werror.WrapWithContextParams(ctx, err, "invalid uri-security uri", werror.UnsafeParam("uri", uri))
+		}
+		params := newTLSParams(config.Security)
+		params.ServerName = security.ServerName
+		if security.CAFiles != nil {
+			params.CAFiles = security.CAFiles
+		}
+		if security.CertFile != "" {
+			params.CertFile = security.CertFile
+		}
+		if security.KeyFile != "" {
+			params.KeyFile = security.KeyFile
+		}
+		if security.CAPEM != "" {
+			params.CAPEM = security.CAPEM
+		}
+		if security.CertPEM != "" {
+			params.CertPEM = security.CertPEM
+		}
+		if security.KeyPEM != "" {
+			params.KeyPEM = security.KeyPEM
+		}
+		if security.InsecureSkipVerify != nil {
+			params.InsecureSkipVerify = *security.InsecureSkipVerify
+		}
+		if security.TLSRefreshInterval != nil {
+			params.RefreshInterval = *security.TLSRefreshInterval
+		}
+		if _, err := refreshingclient.NewTLSConfig(ctx, params); err != nil {
+			return nil, werror.WrapWithContextParams(ctx, err, "invalid uri-security configuration", werror.UnsafeParam("uri", uri))
+		}
+		if existing, ok := hostTLS[addr]; ok && !reflect.DeepEqual(existing, params) {
+			return nil, werror.ErrorWithContextParams(ctx, "conflicting uri-security configurations for the same host and port", werror.UnsafeParam("address", addr))
+		}
+		hostTLS[addr] = params
+	}
+	return hostTLS, nil
+}
+
+// uriAddress returns the lowercase "host:port" address the transport dials for uri, using the default port of the
+// URI's scheme if it has none.
+func uriAddress(uri string) (string, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return "", err
+	}
+	if u.Host == "" {
+		return "", werror.Error("uri must be absolute")
+	}
+	port := u.Port()
+	if port == "" {
+		switch u.Scheme {
+		case "http":
+			port = "80"
+		case "https":
+			port = "443"
+		default:
+			return "", werror.Error("uri must have a port or an http(s) scheme", werror.SafeParam("scheme", u.Scheme))
+		}
+	}
+	return net.JoinHostPort(strings.ToLower(u.Hostname()), port), nil
 }
 
 func derefPtr[T any](ptr *T, defaultVal T) T {
@@ -0,0 +1,86 @@
+// Copyright (c) 2026 Palantir Technologies. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpclient
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/palantir/conjure-go-runtime/v2/conjure-go-client/httpclient/internal/refreshingclient"
+	"github.com/palantir/pkg/metrics"
+	refreshablev2 "github.com/palantir/pkg/refreshable/v2"
+	"github.com/palantir/witchcraft-go-logging/wlog/svclog/svc1log"
+)
+
+// configRefreshTracker measures the time between observing an update to a client's configuration and having fully
+// applied it, i.e. having rebuilt the transport, dialer and every other value derived from the validated params.
+// Refreshables notify their subscribers synchronously and in order, so a subscriber added to the configuration after
+// the validating refreshable runs once all derived values have been updated.
+type configRefreshTracker struct {
+	params refreshablev2.Refreshable[refreshingclient.ValidatedClientParams]
+
+	mu sync.Mutex
+	// observed is when the update currently being applied was first seen.
+	observed time.Time
+	// generation counts the distinct validated params applied, starting at 1 for the initial configuration.
+	generation int64
+	// reported is the generation most recently reported.
+	reported int64
+}
+
+// observe records that a configuration update was seen. It is called before the update is validated.
+func (t *configRefreshTracker) observe() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.observed = time.Now()
+}
+
+// changed records that the validated params changed. Invalid and unchanged configurations do not start a new
+// generation.
+func (t *configRefreshTracker) changed() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.generation++
+}
+
+// applied reports the time taken to apply a new generation of the configuration, if one was started since the last
+// report, and updates the MetricConfigGeneration gauge.
+func (t *configRefreshTracker) applied(ctx context.Context) {
+	t.mu.Lock()
+	generation, reported, latency := t.generation, t.reported, time.Since(t.observed)
+	t.reported = generation
+	t.mu.Unlock()
+	if generation == reported {
+		return
+	}
+
+	p := t.params.Current()
+	if reported != 0 {
+		svc1log.FromContext(ctx).Info("Applied refreshed client configuration",
+			svc1log.SafeParam("serviceName", p.ServiceName),
+			svc1log.SafeParam("configGeneration", generation),
+			svc1log.SafeParam("applyLatency", latency.String()))
+	}
+	if p.DisableMetrics {
+		return
+	}
+	serviceNameTag := metrics.NewTagWithFallbackValue(MetricTagServiceName, p.ServiceName, "unknown")
+	registry := metrics.FromContext(ctx)
+	if reported != 0 {
+		registry.Timer(MetricConfigRefresh, serviceNameTag).Update(latency / time.Microsecond)
+	}
+	registry.Gauge(MetricConfigGeneration, serviceNameTag).Update(generation)
+}
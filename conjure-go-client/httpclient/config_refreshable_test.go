@@ -19,10 +19,13 @@ import (
 	"crypto/tls"
 	"net"
 	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
+	"github.com/palantir/conjure-go-runtime/v2/conjure-go-contract/codecs"
 	"github.com/palantir/pkg/refreshable"
+	refreshablev2 "github.com/palantir/pkg/refreshable/v2"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"gopkg.in/yaml.v2"
@@ -38,8 +41,8 @@ func TestRefreshableClientConfig(t *testing.T) {
 		if client.maxAttempts != nil {
 			assert.Nil(t, client.maxAttempts.CurrentIntPtr())
 		}
-		assert.Equal(t, defaultInitialBackoff, client.backoffOptions.InitialBackoff().CurrentDuration())
-		assert.Equal(t, defaultMaxBackoff, client.backoffOptions.MaxBackoff().CurrentDuration())
+		assert.Equal(t, defaultInitialBackoff, client.backoffOptions.Current().InitialBackoff)
+		assert.Equal(t, defaultMaxBackoff, client.backoffOptions.Current().MaxBackoff)
 
 		initialTransport, initialMiddlewares := unwrapTransport(httpClient.Transport)
 		assert.Equal(t, defaultMaxIdleConns, initialTransport.MaxIdleConns)
@@ -50,7 +53,7 @@ func TestRefreshableClientConfig(t *testing.T) {
 		assert.Equal(t, false, initialTransport.DisableKeepAlives)
 		assert.NotNil(t, initialTransport.Proxy)
 
-		if assert.Len(t, initialMiddlewares, 3) {
+		if assert.Len(t, initialMiddlewares, 4) {
 			assert.IsType(t, recoveryMiddleware{}, initialMiddlewares[0])
 			if assert.IsType(t, traceMiddleware{}, initialMiddlewares[1]) {
 				traceM := initialMiddlewares[1].(traceMiddleware)
@@ -62,6 +65,10 @@ func TestRefreshableClientConfig(t *testing.T) {
 				assert.False(t, metricsM.Disabled.CurrentBool())
 				assert.Equal(t, serviceName, metricsM.ServiceName.CurrentString())
 			}
+			if assert.IsType(t, &latencyBudgetMiddleware{}, initialMiddlewares[3]) {
+				budgetM := initialMiddlewares[3].(*latencyBudgetMiddleware)
+				assert.Empty(t, budgetM.budgets.Current())
+			}
 		}
 
 		if tlsConfig := initialTransport.TLSClientConfig; assert.NotNil(t, tlsConfig) {
@@ -283,3 +290,28 @@ func TestRefreshableClientConfig(t *testing.T) {
 func newDurationPtr(dur time.Duration) *time.Duration {
 	return &dur
 }
+
+func TestRefreshableV2ClientConfig(t *testing.T) {
+	newServer := func(name string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			_, _ = rw.Write([]byte(name))
+		}))
+	}
+	server1, server2 := newServer("server1"), newServer("server2")
+	defer server1.Close()
+	defer server2.Close()
+
+	config := refreshablev2.New(ClientConfig{ServiceName: "my-service", URIs: []string{server1.URL}})
+	client, err := NewClientFromRefreshableV2Config(context.Background(), config)
+	require.NoError(t, err)
+
+	get := func() string {
+		var body string
+		_, err := client.Get(context.Background(), WithResponseBody(&body, codecs.Plain))
+		require.NoError(t, err)
+		return body
+	}
+	assert.Equal(t, "server1", get())
+	config.Update(ClientConfig{ServiceName: "my-service", URIs: []string{server2.URL}})
+	assert.Equal(t, "server2", get())
+}
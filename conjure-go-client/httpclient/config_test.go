@@ -15,9 +15,18 @@
 package httpclient
 
 import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/palantir/conjure-go-runtime/v2/conjure-go-client/httpclient/internal"
+	"github.com/palantir/conjure-go-runtime/v2/conjure-go-client/httpclient/internal/refreshingclient"
+	"github.com/palantir/pkg/metrics"
+	"github.com/palantir/pkg/refreshable"
+	refreshablev2 "github.com/palantir/pkg/refreshable/v2"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"gopkg.in/yaml.v2"
@@ -72,6 +81,552 @@ func TestWithConfigParam(t *testing.T) {
 	assert.Equal(t, 3*time.Second, client.(*clientImpl).client.CurrentHTTPClient().Timeout)
 }
 
+func TestConfigNormalizesURIs(t *testing.T) {
+	params, err := newValidatedClientParamsFromConfig(context.Background(), ClientConfig{
+		ServiceName: "my-service",
+		URIs: []string{
+			"https://Host-A.example.com:443/api/",
+			"https://host-a.example.com/api",
+			"HTTP://host-b.example.com:80",
+			"http://host-b.example.com/",
+			"https://host-c.example.com:8443//",
+			"https://[::1]:443/api",
+			"",
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"http://host-b.example.com",
+		"https://[::1]/api",
+		"https://host-a.example.com/api",
+		"https://host-c.example.com:8443",
+	}, params.URIs)
+}
+
+func TestConfigURIGroups(t *testing.T) {
+	params, err := newValidatedClientParamsFromConfig(context.Background(), ClientConfig{
+		ServiceName: "my-service",
+		URIGroups: []URIGroupConfig{
+			{Name: "stable", URIs: []string{"https://host-b.example.com/", "https://host-a.example.com"}, Percentage: 95},
+			{Name: "canary", URIs: []string{"https://HOST-C.example.com:443"}, Percentage: 5},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"https://host-a.example.com",
+		"https://host-b.example.com",
+		"https://host-c.example.com",
+	}, params.URIs)
+	assert.Equal(t, []refreshingclient.URIGroup{
+		{Name: "stable", URIs: []string{"https://host-a.example.com", "https://host-b.example.com"}, Percentage: 95},
+		{Name: "canary", URIs: []string{"https://host-c.example.com"}, Percentage: 5},
+	}, params.URIGroups)
+
+	for _, tc := range []struct {
+		name   string
+		config ClientConfig
+		err    string
+	}{
+		{
+			name: "uris and groups",
+			config: ClientConfig{
+				URIs:      []string{"https://host-a"},
+				URIGroups: []URIGroupConfig{{Name: "stable", URIs: []string{"https://host-b"}, Percentage: 100}},
+			},
+			err: "uris and uri-groups must not both be set",
+		},
+		{
+			name:   "empty name",
+			config: ClientConfig{URIGroups: []URIGroupConfig{{URIs: []string{"https://host-a"}, Percentage: 100}}},
+			err:    "uri-groups names must not be empty",
+		},
+		{
+			name: "duplicate name",
+			config: ClientConfig{URIGroups: []URIGroupConfig{
+				{Name: "stable", URIs: []string{"https://host-a"}, Percentage: 50},
+				{Name: "stable", URIs: []string{"https://host-b"}, Percentage: 50},
+			}},
+			err: "uri-groups names must be unique",
+		},
+		{
+			name: "shared uri",
+			config: ClientConfig{URIGroups: []URIGroupConfig{
+				{Name: "stable", URIs: []string{"https://host-a"}, Percentage: 50},
+				{Name: "canary", URIs: []string{"https://host-a/"}, Percentage: 50},
+			}},
+			err: "uri-groups must not share URIs",
+		},
+		{
+			name: "percentages",
+			config: ClientConfig{URIGroups: []URIGroupConfig{
+				{Name: "stable", URIs: []string{"https://host-a"}, Percentage: 90},
+				{Name: "canary", URIs: []string{"https://host-b"}, Percentage: 5},
+			}},
+			err: "uri-groups percentages must add up to 100",
+		},
+		{
+			name: "priority percentages",
+			config: ClientConfig{URIGroups: []URIGroupConfig{
+				{Name: "primary-a", URIs: []string{"https://host-a"}, Percentage: 50},
+				{Name: "primary-b", URIs: []string{"https://host-b"}, Percentage: 50},
+				{Name: "secondary-a", URIs: []string{"https://host-c"}, Priority: 1, Percentage: 50},
+				{Name: "secondary-b", URIs: []string{"https://host-d"}, Priority: 1},
+			}},
+			err: "uri-groups percentages must add up to 100",
+		},
+		{
+			name: "negative priority",
+			config: ClientConfig{URIGroups: []URIGroupConfig{
+				{Name: "primary", URIs: []string{"https://host-a"}, Priority: -1},
+			}},
+			err: "uri-groups priority must not be negative",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := newValidatedClientParamsFromConfig(context.Background(), tc.config)
+			require.EqualError(t, err, tc.err)
+		})
+	}
+}
+
+func TestConfigURIGroupPriorities(t *testing.T) {
+	params, err := newValidatedClientParamsFromConfig(context.Background(), ClientConfig{
+		ServiceName: "my-service",
+		URIGroups: []URIGroupConfig{
+			{Name: "primary", URIs: []string{"https://host-a"}},
+			{Name: "secondary", URIs: []string{"https://host-b"}, Priority: 1},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []refreshingclient.URIGroup{
+		{Name: "primary", URIs: []string{"https://host-a"}, Percentage: 100},
+		{Name: "secondary", URIs: []string{"https://host-b"}, Percentage: 100, Priority: 1},
+	}, params.URIGroups)
+}
+
+// fakeURIStateScorer reports the URIs in unavailable as unavailable.
+type fakeURIStateScorer struct {
+	internal.URIScoringMiddleware
+	unavailable map[string]bool
+}
+
+func (s *fakeURIStateScorer) URIStates() []internal.URIState {
+	var states []internal.URIState
+	for uri, unavailable := range s.unavailable {
+		states = append(states, internal.URIState{URI: uri, Unavailable: unavailable})
+	}
+	return states
+}
+
+func TestURIGroupSelectorFailover(t *testing.T) {
+	groups := []refreshingclient.URIGroup{
+		{Name: "primary", URIs: []string{"https://a", "https://b"}, Percentage: 100},
+		{Name: "secondary", URIs: []string{"https://c"}, Percentage: 100, Priority: 1},
+	}
+	selector := newURIGroupSelector(refreshablev2.New(groups))
+	now := time.Unix(0, 0)
+	selector.now = func() time.Time { return now }
+	scorer := &fakeURIStateScorer{unavailable: map[string]bool{}}
+	uris := []string{"https://c", "https://b", "https://a"}
+	primaryFirst := []string{"https://b", "https://a", "https://c"}
+	secondaryFirst := []string{"https://c", "https://b", "https://a"}
+
+	assert.Equal(t, primaryFirst, selector.orderURIs(uris, scorer))
+
+	// one unavailable primary URI does not cause a failover.
+	scorer.unavailable["https://a"] = true
+	assert.Equal(t, primaryFirst, selector.orderURIs(uris, scorer))
+
+	scorer.unavailable["https://b"] = true
+	assert.Equal(t, secondaryFirst, selector.orderURIs(uris, scorer))
+
+	// the primary group must stay healthy for the failback delay before requests return to it.
+	scorer.unavailable["https://a"] = false
+	assert.Equal(t, secondaryFirst, selector.orderURIs(uris, scorer))
+	now = now.Add(uriGroupFailbackDelay / 2)
+	assert.Equal(t, secondaryFirst, selector.orderURIs(uris, scorer))
+	scorer.unavailable["https://a"] = true
+	assert.Equal(t, secondaryFirst, selector.orderURIs(uris, scorer))
+	scorer.unavailable["https://a"] = false
+	now = now.Add(uriGroupFailbackDelay / 2)
+	assert.Equal(t, secondaryFirst, selector.orderURIs(uris, scorer), "failback delay should restart once the primary group fails again")
+	now = now.Add(uriGroupFailbackDelay)
+	assert.Equal(t, primaryFirst, selector.orderURIs(uris, scorer))
+
+	// if every group is unavailable, requests stay on the active group.
+	scorer.unavailable = map[string]bool{"https://a": true, "https://b": true, "https://c": true}
+	assert.Equal(t, primaryFirst, selector.orderURIs(uris, scorer))
+}
+
+func TestOrderURIsByGroup(t *testing.T) {
+	groups := []refreshingclient.URIGroup{
+		{Name: "stable", URIs: []string{"https://a", "https://b"}, Percentage: 95},
+		{Name: "canary", URIs: []string{"https://c"}, Percentage: 5},
+		{Name: "disabled", URIs: []string{"https://d"}, Percentage: 0},
+	}
+	for roll, expected := range map[int]string{0: "stable", 94: "stable", 95: "canary", 99: "canary"} {
+		group, ok := selectURIGroup(groups, roll)
+		require.True(t, ok)
+		assert.Equal(t, expected, group.Name, "roll %d", roll)
+	}
+
+	uris := []string{"https://c", "https://d", "https://b", "https://a"}
+	for i := 0; i < 100; i++ {
+		ordered := orderURIsByGroup(uris, groups)
+		require.Len(t, ordered, len(uris))
+		if ordered[0] == "https://c" {
+			assert.Equal(t, uris, ordered)
+		} else {
+			assert.Equal(t, []string{"https://b", "https://a", "https://c", "https://d"}, ordered)
+		}
+	}
+}
+
+func TestURIGroupsMetricTag(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {}))
+	defer server.Close()
+	ctx := metrics.WithRegistry(context.Background(), metrics.NewRootMetricsRegistry())
+	client, err := NewClientFromRefreshableConfig(ctx, NewRefreshingClientConfig(refreshable.NewDefaultRefreshable(ClientConfig{
+		ServiceName: "my-service",
+		URIGroups: []URIGroupConfig{
+			{Name: "canary", URIs: []string{server.URL}, Percentage: 100},
+			{Name: "stable", URIs: []string{"http://127.0.0.1:1"}, Percentage: 0},
+		},
+	})))
+	require.NoError(t, err)
+
+	_, err = client.Get(ctx)
+	require.NoError(t, err)
+
+	var found bool
+	metrics.FromContext(ctx).Each(func(name string, tags metrics.Tags, _ metrics.MetricVal) {
+		if name == "client.response" {
+			assert.Contains(t, tags, metrics.MustNewTag(metricTagURIGroup, "canary"))
+			found = true
+		}
+	})
+	assert.True(t, found)
+}
+
+func TestConfigWarnings(t *testing.T) {
+	ctx := metrics.WithRegistry(context.Background(), metrics.NewRootMetricsRegistry())
+	config := refreshable.NewDefaultRefreshable(ClientConfig{
+		ServiceName:    "my-service",
+		URIs:           []string{"https://host-a"},
+		ReadTimeout:    &[]time.Duration{time.Second}[0],
+		ConnectTimeout: &[]time.Duration{2 * time.Second}[0],
+		MaxNumRetries:  &[]int{5}[0],
+		InitialBackoff: &[]time.Duration{250 * time.Millisecond}[0],
+		MaxBackoff:     &[]time.Duration{2 * time.Second}[0],
+	})
+	_, err := NewClientFromRefreshableConfig(ctx, NewRefreshingClientConfig(config))
+	require.NoError(t, err)
+
+	gauge := func() int64 {
+		return metrics.FromContext(ctx).Gauge(MetricConfigWarnings, metrics.MustNewTag(MetricTagServiceName, "my-service")).Value()
+	}
+	assert.Equal(t, int64(2), gauge())
+
+	params, err := newValidatedClientParamsFromConfig(ctx, config.Current().(ClientConfig))
+	require.NoError(t, err)
+	warnings := checkClientParams(params)
+	require.Len(t, warnings, 2)
+	assert.Equal(t, "Client retry backoff exceeds the request timeout", warnings[0].message)
+	assert.Equal(t, "Client connect timeout exceeds the request timeout", warnings[1].message)
+	assert.Equal(t, 5750*time.Millisecond, totalBackoff(params.Retry, *params.MaxAttempts))
+
+	require.NoError(t, config.Update(ClientConfig{
+		ServiceName: "my-service",
+		URIs:        []string{"https://host-a"},
+		ReadTimeout: &[]time.Duration{time.Minute}[0],
+	}))
+	assert.Equal(t, int64(0), gauge())
+}
+
+func TestRetryScheduleFromConfig(t *testing.T) {
+	schedule, err := RetryScheduleFromConfig(ClientConfig{
+		ServiceName:    "my-service",
+		URIs:           []string{"https://host-a"},
+		MaxNumRetries:  &[]int{5}[0],
+		InitialBackoff: &[]time.Duration{250 * time.Millisecond}[0],
+		MaxBackoff:     &[]time.Duration{2 * time.Second}[0],
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []time.Duration{
+		250 * time.Millisecond,
+		500 * time.Millisecond,
+		time.Second,
+		2 * time.Second,
+		2 * time.Second,
+	}, schedule)
+
+	// the default maximum attempts is twice the number of URIs.
+	schedule, err = RetryScheduleFromConfig(ClientConfig{
+		ServiceName:    "my-service",
+		URIs:           []string{"https://host-a", "https://host-b"},
+		InitialBackoff: &[]time.Duration{time.Second}[0],
+		MaxBackoff:     &[]time.Duration{100 * time.Millisecond}[0],
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []time.Duration{time.Second, time.Second, time.Second}, schedule, "initial backoff takes precedence over max backoff")
+
+	_, err = RetryScheduleFromConfig(ClientConfig{ServiceName: "my-service", URIs: []string{"://invalid"}})
+	assert.Error(t, err)
+}
+
+func TestConfigRefreshMetrics(t *testing.T) {
+	ctx := metrics.WithRegistry(context.Background(), metrics.NewRootMetricsRegistry())
+	config := refreshable.NewDefaultRefreshable(ClientConfig{
+		ServiceName: "my-service",
+		URIs:        []string{"https://host-a"},
+	})
+	_, err := NewClientFromRefreshableConfig(ctx, NewRefreshingClientConfig(config))
+	require.NoError(t, err)
+
+	serviceNameTag := metrics.MustNewTag(MetricTagServiceName, "my-service")
+	generation := func() int64 {
+		return metrics.FromContext(ctx).Gauge(MetricConfigGeneration, serviceNameTag).Value()
+	}
+	refreshes := func() int64 {
+		return metrics.FromContext(ctx).Timer(MetricConfigRefresh, serviceNameTag).Count()
+	}
+	assert.Equal(t, int64(1), generation())
+	assert.Equal(t, int64(0), refreshes())
+
+	require.NoError(t, config.Update(ClientConfig{
+		ServiceName: "my-service",
+		URIs:        []string{"https://host-b"},
+	}))
+	assert.Equal(t, int64(2), generation())
+	assert.Equal(t, int64(1), refreshes())
+
+	// an invalid configuration is not applied, so does not start a new generation.
+	require.NoError(t, config.Update(ClientConfig{
+		ServiceName: "my-service",
+		URIs:        []string{"https://host-b"},
+		Headers:     map[string]string{"Bad Header": "value"},
+	}))
+	assert.Equal(t, int64(2), generation())
+	assert.Equal(t, int64(1), refreshes())
+}
+
+func TestConfigLatencyBudgets(t *testing.T) {
+	ctx := context.Background()
+	params, err := newValidatedClientParamsFromConfig(ctx, ClientConfig{
+		ServiceName:    "my-service",
+		LatencyBudgets: map[string]time.Duration{"getThing": 100 * time.Millisecond},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, refreshingclient.LatencyBudgets{"getThing": 100 * time.Millisecond}, params.LatencyBudgets)
+
+	_, err = newValidatedClientParamsFromConfig(ctx, ClientConfig{
+		ServiceName:    "my-service",
+		LatencyBudgets: map[string]time.Duration{"getThing": -time.Second},
+	})
+	assert.EqualError(t, err, "invalid latency-budgets: latency budgets must be positive")
+
+	merged := MergeClientConfig(
+		ClientConfig{LatencyBudgets: map[string]time.Duration{"getThing": time.Second}},
+		ClientConfig{LatencyBudgets: map[string]time.Duration{"getThing": time.Minute, "putThing": time.Minute}})
+	assert.Equal(t, map[string]time.Duration{"getThing": time.Second, "putThing": time.Minute}, merged.LatencyBudgets)
+}
+
+func TestConfigEndpointTimeouts(t *testing.T) {
+	ctx := context.Background()
+	params, err := newValidatedClientParamsFromConfig(ctx, ClientConfig{
+		ServiceName:      "my-service",
+		EndpointTimeouts: map[string]time.Duration{"getThing": time.Minute},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, refreshingclient.EndpointTimeouts{"getThing": time.Minute}, params.EndpointTimeouts)
+
+	_, err = newValidatedClientParamsFromConfig(ctx, ClientConfig{
+		ServiceName:      "my-service",
+		EndpointTimeouts: map[string]time.Duration{"getThing": 0},
+	})
+	assert.EqualError(t, err, "invalid endpoint-timeouts: endpoint timeouts must be positive")
+
+	merged := MergeClientConfig(
+		ClientConfig{EndpointTimeouts: map[string]time.Duration{"getThing": time.Second}},
+		ClientConfig{EndpointTimeouts: map[string]time.Duration{"getThing": time.Minute, "putThing": time.Minute}})
+	assert.Equal(t, map[string]time.Duration{"getThing": time.Second, "putThing": time.Minute}, merged.EndpointTimeouts)
+
+	// requests block until they are released or canceled by the client.
+	release := make(chan struct{})
+	received := make(chan struct{}, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		received <- struct{}{}
+		select {
+		case <-release:
+		case <-req.Context().Done():
+		}
+	}))
+	defer server.Close()
+	client, err := NewClientFromRefreshableConfig(ctx, NewRefreshingClientConfig(refreshable.NewDefaultRefreshable(ClientConfig{
+		ServiceName:      "my-service",
+		URIs:             []string{server.URL},
+		MaxNumRetries:    &[]int{0}[0],
+		EndpointTimeouts: map[string]time.Duration{"slowThing": 50 * time.Millisecond},
+	})))
+	require.NoError(t, err)
+
+	_, err = client.Get(ContextWithRPCMethodName(ctx, "slowThing"))
+	require.Error(t, err)
+	<-received
+	_, err = client.Get(ctx, WithRPCMethodName("slowThing"))
+	require.Error(t, err)
+	<-received
+
+	// a request timeout overrides the endpoint timeout, so the request is still in flight after it has passed.
+	done := make(chan error)
+	go func() {
+		_, err := client.Get(ContextWithRPCMethodName(ctx, "slowThing"), WithRequestTimeout(time.Minute))
+		done <- err
+	}()
+	<-received
+	select {
+	case err := <-done:
+		t.Fatalf("request should not time out after the endpoint timeout: %v", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+	close(release)
+	require.NoError(t, <-done)
+
+	_, err = client.Get(ContextWithRPCMethodName(ctx, "otherThing"))
+	require.NoError(t, err)
+}
+
+func TestConfigEndpointMaxNumRetries(t *testing.T) {
+	ctx := context.Background()
+	params, err := newValidatedClientParamsFromConfig(ctx, ClientConfig{
+		ServiceName:           "my-service",
+		EndpointMaxNumRetries: map[string]int{"getThing": 0},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, refreshingclient.EndpointMaxRetries{"getThing": 0}, params.EndpointMaxRetries)
+
+	_, err = newValidatedClientParamsFromConfig(ctx, ClientConfig{
+		ServiceName:           "my-service",
+		EndpointMaxNumRetries: map[string]int{"getThing": -1},
+	})
+	assert.EqualError(t, err, "invalid endpoint-max-num-retries: endpoint max retries must not be negative")
+
+	merged := MergeClientConfig(
+		ClientConfig{EndpointMaxNumRetries: map[string]int{"getThing": 1}},
+		ClientConfig{EndpointMaxNumRetries: map[string]int{"getThing": 5, "putThing": 0}})
+	assert.Equal(t, map[string]int{"getThing": 1, "putThing": 0}, merged.EndpointMaxNumRetries)
+
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		requests.Add(1)
+		rw.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+	client, err := NewClientFromRefreshableConfig(ctx, NewRefreshingClientConfig(refreshable.NewDefaultRefreshable(ClientConfig{
+		ServiceName:           "my-service",
+		URIs:                  []string{server.URL},
+		MaxNumRetries:         &[]int{3}[0],
+		InitialBackoff:        &[]time.Duration{time.Millisecond}[0],
+		MaxBackoff:            &[]time.Duration{time.Millisecond}[0],
+		EndpointMaxNumRetries: map[string]int{"putThing": 0},
+	})))
+	require.NoError(t, err)
+
+	_, err = client.Put(ctx, WithRPCMethodName("putThing"))
+	require.Error(t, err)
+	assert.Equal(t, int32(1), requests.Load())
+	requests.Store(0)
+	_, err = client.Get(ContextWithRPCMethodName(ctx, "getThing"))
+	require.Error(t, err)
+	assert.Equal(t, int32(4), requests.Load())
+}
+
+func TestConfigHeaders(t *testing.T) {
+	var received http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		received = req.Header.Clone()
+		rw.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	services := ServicesConfig{
+		Default: ClientConfig{Headers: map[string]string{"X-Client-Id": "default-app", "X-Region": "us-east"}},
+		Services: map[string]ClientConfig{
+			"my-service": {URIs: []string{server.URL}, Headers: map[string]string{"x-client-id": "my-app"}},
+		},
+	}
+	conf := services.ClientConfig("my-service")
+	assert.Equal(t, map[string]string{"X-Client-Id": "my-app", "X-Region": "us-east"}, conf.Headers)
+
+	refreshableConf := refreshable.NewDefaultRefreshable(conf)
+	client, err := NewClientFromRefreshableConfig(context.Background(), NewRefreshingClientConfig(refreshableConf))
+	require.NoError(t, err)
+
+	_, err = client.Get(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "my-app", received.Get("X-Client-Id"))
+	assert.Equal(t, "us-east", received.Get("X-Region"))
+
+	_, err = client.Get(context.Background(), WithHeader("X-Region", "eu-west"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"eu-west"}, received.Values("X-Region"), "request headers take precedence")
+
+	conf.Headers = map[string]string{"X-Client-Id": "refreshed-app"}
+	require.NoError(t, refreshableConf.Update(conf))
+	_, err = client.Get(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "refreshed-app", received.Get("X-Client-Id"))
+	assert.Empty(t, received.Get("X-Region"))
+
+	staticClient, err := NewClient(WithConfig(conf))
+	require.NoError(t, err)
+	_, err = staticClient.Get(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "refreshed-app", received.Get("X-Client-Id"))
+
+	_, err = newValidatedClientParamsFromConfig(context.Background(), ClientConfig{
+		ServiceName: "my-service",
+		Headers:     map[string]string{"X-Bad": "line\nbreak"},
+	})
+	assert.EqualError(t, err, "invalid headers: invalid header value")
+}
+
+func TestEffectiveConfig(t *testing.T) {
+	conf := ServicesConfig{
+		Default: ClientConfig{
+			ReadTimeout:    &[]time.Duration{2 * time.Second}[0],
+			InitialBackoff: &[]time.Duration{5 * time.Millisecond}[0],
+		},
+		Services: map[string]ClientConfig{
+			"my-service": {
+				WriteTimeout: &[]time.Duration{3 * time.Second}[0],
+				URIs:         []string{"https://host-a", "https://host-b"},
+				Metrics:      MetricsConfig{Enabled: &[]bool{false}[0]},
+			},
+		},
+	}
+	client, err := NewClientFromRefreshableConfig(context.Background(), NewRefreshingClientConfig(refreshable.NewDefaultRefreshable(conf.ClientConfig("my-service"))))
+	require.NoError(t, err)
+
+	snapshot, ok := EffectiveConfig(client)
+	require.True(t, ok)
+	assert.Equal(t, "my-service", snapshot.ServiceName)
+	assert.Equal(t, []string{"https://host-a", "https://host-b"}, snapshot.URIs)
+	assert.Equal(t, 4, snapshot.MaxAttempts)
+	assert.Equal(t, 5*time.Millisecond, snapshot.InitialBackoff)
+	assert.Equal(t, defaultMaxBackoff, snapshot.MaxBackoff)
+	assert.Equal(t, 3*time.Second, snapshot.Timeout)
+	assert.Equal(t, defaultDialTimeout, snapshot.ConnectTimeout)
+	assert.Equal(t, defaultMaxIdleConns, snapshot.MaxIdleConns)
+	assert.False(t, snapshot.MetricsEnabled)
+
+	overridden, err := DeriveClient(client, WithMaxRetries(1))
+	require.NoError(t, err)
+	snapshot, ok = EffectiveConfig(overridden)
+	require.True(t, ok)
+	assert.Equal(t, 2, snapshot.MaxAttempts)
+}
+
 func TestWithConfigForHTTPClientParam(t *testing.T) {
 	conf := ServicesConfig{
 		Services: map[string]ClientConfig{
@@ -0,0 +1,90 @@
+// Copyright (c) 2026 Palantir Technologies. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpclient
+
+import (
+	"context"
+	"time"
+
+	"github.com/palantir/conjure-go-runtime/v2/conjure-go-client/httpclient/internal/refreshingclient"
+	"github.com/palantir/pkg/metrics"
+	"github.com/palantir/witchcraft-go-logging/wlog/svclog/svc1log"
+)
+
+// configWarning describes a combination of configuration values which is valid but unlikely to behave as intended.
+type configWarning struct {
+	message string
+	params  []svc1log.Param
+}
+
+// checkClientParams returns the warnings which apply to p:
+//   - the backoff between the configured maximum number of attempts adds up to more than the request timeout, so a
+//     caller using a deadline of the same order as the timeout will never see the later retries.
+//   - the connect timeout is larger than the request timeout, so it can never take effect.
+func checkClientParams(p refreshingclient.ValidatedClientParams) []configWarning {
+	if p.Timeout <= 0 {
+		return nil
+	}
+	var warnings []configWarning
+	maxAttempts := 2 * len(p.URIs)
+	if p.MaxAttempts != nil {
+		maxAttempts = *p.MaxAttempts
+	}
+	if backoff := totalBackoff(p.Retry, maxAttempts); backoff > p.Timeout {
+		warnings = append(warnings, configWarning{
+			message: "Client retry backoff exceeds the request timeout",
+			params: []svc1log.Param{
+				svc1log.SafeParam("maxAttempts", maxAttempts),
+				svc1log.SafeParam("totalBackoff", backoff.String()),
+				svc1log.SafeParam("timeout", p.Timeout.String()),
+			},
+		})
+	}
+	if p.Dialer.DialTimeout > p.Timeout {
+		warnings = append(warnings, configWarning{
+			message: "Client connect timeout exceeds the request timeout",
+			params: []svc1log.Param{
+				svc1log.SafeParam("connectTimeout", p.Dialer.DialTimeout.String()),
+				svc1log.SafeParam("timeout", p.Timeout.String()),
+			},
+		})
+	}
+	return warnings
+}
+
+// totalBackoff returns the nominal backoff, excluding jitter, between maxAttempts attempts using the exponential
+// backoff of github.com/palantir/pkg/retry. maxAttempts of 0 means no limit, for which no bound is computed.
+func totalBackoff(p refreshingclient.RetryParams, maxAttempts int) time.Duration {
+	var total time.Duration
+	for _, backoff := range retrySchedule(p, maxAttempts) {
+		total += backoff
+	}
+	return total
+}
+
+// reportConfigWarnings logs each warning for p and sets the MetricConfigWarnings gauge to the number of warnings, so
+// that it returns to 0 once the configuration is fixed.
+func reportConfigWarnings(ctx context.Context, p refreshingclient.ValidatedClientParams) {
+	warnings := checkClientParams(p)
+	for _, warning := range warnings {
+		svc1log.FromContext(ctx).Warn(warning.message,
+			append([]svc1log.Param{svc1log.SafeParam("serviceName", p.ServiceName)}, warning.params...)...)
+	}
+	if p.DisableMetrics {
+		return
+	}
+	serviceNameTag := metrics.NewTagWithFallbackValue(MetricTagServiceName, p.ServiceName, "unknown")
+	metrics.FromContext(ctx).Gauge(MetricConfigWarnings, serviceNameTag).Update(int64(len(warnings)))
+}
@@ -23,6 +23,42 @@ type ctxKey string
 const (
 	// context-key for the RPC method name associated with the HTTP request call
 	rpcMethodName ctxKey = "rpcMethodName"
+	// context-key marking that the request sets its own authentication and client-level auth must not be applied
+	requestAuthOverride ctxKey = "requestAuthOverride"
+	// context-key for the principal set by WithRequestOnBehalfOf
+	requestOnBehalfOf ctxKey = "requestOnBehalfOf"
+	// context-key for the base URI selected for the current attempt
+	requestBaseURI ctxKey = "requestBaseURI"
+	// context-key for the headers set by ContextWithPropagatedHeaders
+	propagatedHeaders ctxKey = "propagatedHeaders"
+	// context-key for the Attempt currently being executed
+	requestAttempt ctxKey = "requestAttempt"
+	// context-key marking that 3xx responses are returned to the caller rather than decoded as errors
+	requestAcceptRedirects ctxKey = "requestAcceptRedirects"
+	// context-key for the URI selected for the first attempt of a request
+	requestInitialURI ctxKey = "requestInitialURI"
+	// context-key for the RequestAnnotations shared by the middlewares handling a request
+	requestAnnotations ctxKey = "requestAnnotations"
+	// context-key for the RequestClass set by ContextWithRequestClass
+	requestClass ctxKey = "requestClass"
+	// context-key for the client's RequestClassPolicy for the class of the current request
+	requestClassPolicy ctxKey = "requestClassPolicy"
+	// context-key for the session key set by ContextWithSessionKey
+	requestSessionKey ctxKey = "requestSessionKey"
+	// context-key for the hedgeGate of the current send of a hedged attempt
+	requestHedgeGate ctxKey = "requestHedgeGate"
+	// context-key for the MeshMode set by ContextWithMeshMode
+	requestMeshMode ctxKey = "requestMeshMode"
+	// context-key for the callMetricsRecord of the current call to Do
+	requestCallRecord ctxKey = "requestCallRecord"
+	// context-key for the callState of the current call to Do
+	requestCallState ctxKey = "requestCallState"
+	// context-key set by WithoutMetrics
+	requestWithoutMetrics ctxKey = "requestWithoutMetrics"
+	// context-key set by WithoutTracing
+	requestWithoutTracing ctxKey = "requestWithoutTracing"
+	// context-key for the ClockSkewDetector set by WithClockSkewDetector
+	requestClockSkewDetector ctxKey = "requestClockSkewDetector"
 )
 
 // ContextWithRPCMethodName returns a copy of ctx with the rpcMethodName key set.
@@ -31,6 +67,17 @@ func ContextWithRPCMethodName(ctx context.Context, name string) context.Context
 	return context.WithValue(ctx, rpcMethodName, name)
 }
 
+// ContextWithSessionKey returns a copy of ctx with the session key of the requests made using it set to key.
+// Clients built with WithStickyURIScoring send every request with the same session key to the same URI.
+func ContextWithSessionKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, requestSessionKey, key)
+}
+
+func getSessionKey(ctx context.Context) string {
+	key, _ := ctx.Value(requestSessionKey).(string)
+	return key
+}
+
 func getRPCMethodName(ctx context.Context) string {
 	e := ctx.Value(rpcMethodName)
 	if e == nil {
@@ -38,3 +85,76 @@ func getRPCMethodName(ctx context.Context) string {
 	}
 	return e.(string)
 }
+
+func contextWithRequestAuthOverride(ctx context.Context) context.Context {
+	return context.WithValue(ctx, requestAuthOverride, true)
+}
+
+// hasRequestAuthOverride returns true if the request's authentication was set (or suppressed) by a request param,
+// in which case client-level auth middlewares should not modify the Authorization header.
+func hasRequestAuthOverride(ctx context.Context) bool {
+	override, _ := ctx.Value(requestAuthOverride).(bool)
+	return override
+}
+
+func getRequestOnBehalfOf(ctx context.Context) string {
+	principal, _ := ctx.Value(requestOnBehalfOf).(string)
+	return principal
+}
+
+func contextWithBaseURI(ctx context.Context, baseURI string) context.Context {
+	return context.WithValue(ctx, requestBaseURI, baseURI)
+}
+
+func getBaseURI(ctx context.Context) string {
+	baseURI, _ := ctx.Value(requestBaseURI).(string)
+	return baseURI
+}
+
+func contextWithAttempt(ctx context.Context, attempt Attempt) context.Context {
+	return context.WithValue(ctx, requestAttempt, attempt)
+}
+
+func getAttempt(ctx context.Context) (Attempt, bool) {
+	attempt, ok := ctx.Value(requestAttempt).(Attempt)
+	return attempt, ok
+}
+
+func contextWithInitialURI(ctx context.Context, uri string) context.Context {
+	return context.WithValue(ctx, requestInitialURI, uri)
+}
+
+func getInitialURI(ctx context.Context) (string, bool) {
+	uri, ok := ctx.Value(requestInitialURI).(string)
+	return uri, ok
+}
+
+func contextWithAcceptRedirects(ctx context.Context) context.Context {
+	return context.WithValue(ctx, requestAcceptRedirects, true)
+}
+
+// acceptsRedirects returns true if the request was made using WithAcceptRedirects.
+func acceptsRedirects(ctx context.Context) bool {
+	accept, _ := ctx.Value(requestAcceptRedirects).(bool)
+	return accept
+}
+
+func contextWithoutMetrics(ctx context.Context) context.Context {
+	return context.WithValue(ctx, requestWithoutMetrics, true)
+}
+
+// withoutMetrics returns true if the request was made using WithoutMetrics.
+func withoutMetrics(ctx context.Context) bool {
+	without, _ := ctx.Value(requestWithoutMetrics).(bool)
+	return without
+}
+
+func contextWithoutTracing(ctx context.Context) context.Context {
+	return context.WithValue(ctx, requestWithoutTracing, true)
+}
+
+// withoutTracing returns true if the request was made using WithoutTracing.
+func withoutTracing(ctx context.Context) bool {
+	without, _ := ctx.Value(requestWithoutTracing).(bool)
+	return without
+}
@@ -0,0 +1,90 @@
+// Copyright (c) 2026 Palantir Technologies. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpclient
+
+import (
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+
+	"github.com/palantir/pkg/refreshable"
+)
+
+// cookieJar returns the jar used by the client built by b, or nil if cookies are not stored.
+func (b *httpClientBuilder) cookieJar() http.CookieJar {
+	if b.CookieJar != nil {
+		return b.CookieJar
+	}
+	if b.EnableCookies == nil {
+		return nil
+	}
+	return &toggledCookieJar{enabled: b.EnableCookies, jar: newCookieJar()}
+}
+
+func newCookieJar() http.CookieJar {
+	// cookiejar.New only returns an error for invalid options.
+	jar, _ := cookiejar.New(nil)
+	return jar
+}
+
+// cookieJarMiddleware sends the cookies stored in jar with each request, unless the request sets a cookie with the
+// same name itself, and stores the cookies set by each response,
+// whatever its status. The jar of the http.Client is not used because responses converted to errors by the client's
+// ErrorDecoder never reach it.
+type cookieJarMiddleware struct {
+	jar http.CookieJar
+}
+
+func (m cookieJarMiddleware) RoundTrip(req *http.Request, next http.RoundTripper) (*http.Response, error) {
+	if cookies := m.jar.Cookies(req.URL); len(cookies) > 0 {
+		explicit := make(map[string]struct{})
+		for _, cookie := range req.Cookies() {
+			explicit[cookie.Name] = struct{}{}
+		}
+		for _, cookie := range cookies {
+			if _, ok := explicit[cookie.Name]; !ok {
+				req.AddCookie(cookie)
+			}
+		}
+	}
+	resp, err := next.RoundTrip(req)
+	if resp != nil {
+		if cookies := resp.Cookies(); len(cookies) > 0 {
+			m.jar.SetCookies(req.URL, cookies)
+		}
+	}
+	return resp, err
+}
+
+// toggledCookieJar stores and returns cookies only while enabled is true, so that the enable-cookies configuration
+// can be refreshed without rebuilding the client. Cookies stored while enabled are kept if it is disabled and
+// enabled again.
+type toggledCookieJar struct {
+	enabled refreshable.Bool
+	jar     http.CookieJar
+}
+
+func (j *toggledCookieJar) SetCookies(u *url.URL, cookies []*http.Cookie) {
+	if j.enabled.CurrentBool() {
+		j.jar.SetCookies(u, cookies)
+	}
+}
+
+func (j *toggledCookieJar) Cookies(u *url.URL) []*http.Cookie {
+	if !j.enabled.CurrentBool() {
+		return nil
+	}
+	return j.jar.Cookies(u)
+}
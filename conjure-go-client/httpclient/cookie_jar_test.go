@@ -0,0 +1,91 @@
+// Copyright (c) 2026 Palantir Technologies. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpclient_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/palantir/conjure-go-runtime/v2/conjure-go-client/httpclient"
+	"github.com/palantir/pkg/refreshable"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newSessionServer returns a server which sets a session cookie on the first request, failing it with 503, and
+// records the session cookie sent with later requests.
+func newSessionServer(t *testing.T) (*httptest.Server, *[]string) {
+	var sessions []string
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if requests.Add(1) == 1 {
+			http.SetCookie(rw, &http.Cookie{Name: "session", Value: "abc"})
+			rw.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		cookie, err := req.Cookie("session")
+		if err != nil {
+			sessions = append(sessions, "")
+		} else {
+			sessions = append(sessions, cookie.Value)
+		}
+		rw.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+	return server, &sessions
+}
+
+func TestWithCookieJar(t *testing.T) {
+	server, sessions := newSessionServer(t)
+	jar, err := cookiejar.New(nil)
+	require.NoError(t, err)
+	client, err := httpclient.NewClient(
+		httpclient.WithBaseURLs([]string{server.URL}),
+		httpclient.WithCookieJar(jar))
+	require.NoError(t, err)
+
+	// the retry after the 503 sends the cookie set by the failed attempt.
+	_, err = client.Get(context.Background())
+	require.NoError(t, err)
+	_, err = client.Get(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"abc", "abc"}, *sessions)
+}
+
+func TestEnableCookiesConfig(t *testing.T) {
+	server, sessions := newSessionServer(t)
+	enabled := true
+	config := refreshable.NewDefaultRefreshable(httpclient.ClientConfig{
+		ServiceName:   "my-service",
+		URIs:          []string{server.URL},
+		EnableCookies: &enabled,
+	})
+	client, err := httpclient.NewClientFromRefreshableConfig(context.Background(), httpclient.NewRefreshingClientConfig(config))
+	require.NoError(t, err)
+
+	_, err = client.Get(context.Background())
+	require.NoError(t, err)
+	require.NoError(t, config.Update(httpclient.ClientConfig{
+		ServiceName: "my-service",
+		URIs:        []string{server.URL},
+	}))
+	_, err = client.Get(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"abc", ""}, *sessions)
+}
@@ -0,0 +1,33 @@
+// Copyright (c) 2026 Palantir Technologies. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpclient
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/palantir/conjure-go-runtime/v2/conjure-go-contract/httpheaders"
+)
+
+// deadlinePropagationMiddleware sets the X-Deadline-Millis header of each attempt to the time remaining until the
+// deadline of the request's context, if it has one. See WithDeadlinePropagation.
+type deadlinePropagationMiddleware struct{}
+
+func (deadlinePropagationMiddleware) RoundTrip(req *http.Request, next http.RoundTripper) (*http.Response, error) {
+	if deadline, ok := req.Context().Deadline(); ok {
+		req.Header.Set(httpheaders.DeadlineMillis, httpheaders.FormatDeadlineMillis(time.Until(deadline)))
+	}
+	return next.RoundTrip(req)
+}
@@ -0,0 +1,119 @@
+// Copyright (c) 2026 Palantir Technologies. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpclient
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+
+	werror "github.com/palantir/witchcraft-go-error"
+	"github.com/palantir/witchcraft-go-logging/wlog/svclog/svc1log"
+)
+
+// maxDownloadAttemptsWithoutProgress bounds the number of consecutive resumptions which fail before reading any
+// bytes. Failures to obtain a response at all are already retried by the client.
+const maxDownloadAttemptsWithoutProgress = 3
+
+// DownloadWithResume executes a GET request for path and writes the response body to w. If reading the body fails
+// partway through, the download is resumed from the last byte written using a Range request, which may be served
+// by a different URI than the original request. The ETag of each response must match that of the first response;
+// if the resource changed between attempts an error is returned. Additional params are applied to every request.
+//
+// The number of bytes written to w is returned. If the server does not support Range requests, each resumption
+// restarts the download from the beginning.
+func DownloadWithResume(ctx context.Context, client Client, path string, w io.WriterAt, params ...RequestParam) (int64, error) {
+	var (
+		offset          int64
+		etag            string
+		attemptsNoBytes int
+	)
+	for {
+		reqParams := []RequestParam{
+			WithRequestMethod(http.MethodGet),
+			WithPath(path),
+			WithRawResponseBody(),
+		}
+		if offset > 0 {
+			reqParams = append(reqParams, WithHeader("Range", fmt.Sprintf("bytes=%d-", offset)))
+			if etag != "" {
+				reqParams = append(reqParams, WithHeader("If-Range", etag))
+			}
+		}
+		resp, err := client.Do(ctx, append(reqParams, params...)...)
+		if err != nil {
+			return offset, err
+		}
+
+		start, err := downloadStartOffset(resp, offset, etag)
+		if err != nil {
+			_ = resp.Body.Close()
+			return offset, werror.WrapWithContextParams(ctx, err, "failed to resume download", werror.SafeParam("offset", offset))
+		}
+		if etag == "" {
+			etag = resp.Header.Get("ETag")
+		}
+
+		n, copyErr := io.Copy(io.NewOffsetWriter(w, start), resp.Body)
+		_ = resp.Body.Close()
+		offset = start + n
+		if copyErr == nil {
+			return offset, nil
+		}
+		if ctx.Err() != nil {
+			return offset, werror.WrapWithContextParams(ctx, ctx.Err(), "download canceled", werror.SafeParam("offset", offset))
+		}
+		if n > 0 {
+			attemptsNoBytes = 0
+		} else if attemptsNoBytes++; attemptsNoBytes >= maxDownloadAttemptsWithoutProgress {
+			return offset, werror.WrapWithContextParams(ctx, copyErr, "download made no progress", werror.SafeParam("offset", offset))
+		}
+		svc1log.FromContext(ctx).Debug("Resuming interrupted download",
+			svc1log.SafeParam("offset", offset),
+			svc1log.Stacktrace(copyErr))
+	}
+}
+
+// downloadStartOffset returns the offset at which the response body begins, verifying that the response is
+// consistent with the data already downloaded.
+func downloadStartOffset(resp *http.Response, offset int64, etag string) (int64, error) {
+	if offset == 0 {
+		return 0, nil
+	}
+	if respETag := resp.Header.Get("ETag"); etag != "" && respETag != etag {
+		return 0, werror.Error("resource changed between download attempts",
+			werror.UnsafeParam("expectedETag", etag),
+			werror.UnsafeParam("actualETag", respETag))
+	}
+	if resp.StatusCode != http.StatusPartialContent {
+		// the server ignored the Range header and is sending the entire body
+		return 0, nil
+	}
+	var start, end, total int64
+	contentRange := resp.Header.Get("Content-Range")
+	if _, err := fmt.Sscanf(contentRange, "bytes %d-%d/%d", &start, &end, &total); err != nil {
+		// total may be "*" if unknown
+		if _, err := fmt.Sscanf(contentRange, "bytes %d-%d/*", &start, &end); err != nil {
+			return 0, werror.Error("invalid Content-Range header", werror.UnsafeParam("contentRange", contentRange))
+		}
+	}
+	if start != offset {
+		return 0, werror.Error("unexpected Content-Range start",
+			werror.SafeParam("expectedStart", offset),
+			werror.SafeParam("actualStart", start))
+	}
+	return start, nil
+}
@@ -0,0 +1,118 @@
+// Copyright (c) 2026 Palantir Technologies. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpclient_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/palantir/conjure-go-runtime/v2/conjure-go-client/httpclient"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDownloadWithResume(t *testing.T) {
+	content := strings.Repeat("0123456789", 100)
+	etag := `"v1"`
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		requests++
+		rw.Header().Set("ETag", etag)
+		if requests == 1 {
+			// write half of the body, then abort the connection
+			rw.Header().Set("Content-Length", strconv.Itoa(len(content)))
+			_, _ = rw.Write([]byte(content[:len(content)/2]))
+			rw.(http.Flusher).Flush()
+			panic(http.ErrAbortHandler)
+		}
+		assert.Equal(t, "bytes=500-", req.Header.Get("Range"))
+		http.ServeContent(rw, req, "", time.Time{}, strings.NewReader(content))
+	}))
+	defer server.Close()
+
+	client, err := httpclient.NewClient(httpclient.WithBaseURLs([]string{server.URL}))
+	require.NoError(t, err)
+
+	t.Run("resumes", func(t *testing.T) {
+		f, err := os.Create(filepath.Join(t.TempDir(), "download"))
+		require.NoError(t, err)
+		defer func() { _ = f.Close() }()
+
+		n, err := httpclient.DownloadWithResume(context.Background(), client, "/file", f)
+		require.NoError(t, err)
+		assert.Equal(t, int64(len(content)), n)
+		assert.Equal(t, 2, requests)
+		written, err := os.ReadFile(f.Name())
+		require.NoError(t, err)
+		assert.Equal(t, content, string(written))
+	})
+	t.Run("resource changed", func(t *testing.T) {
+		requests = 0
+		etag = `"v1"`
+		client, err := httpclient.NewClient(
+			httpclient.WithBaseURLs([]string{server.URL}),
+			httpclient.WithMiddleware(httpclient.MiddlewareFunc(func(req *http.Request, next http.RoundTripper) (*http.Response, error) {
+				if requests == 1 {
+					etag = `"v2"`
+				}
+				return next.RoundTrip(req)
+			})))
+		require.NoError(t, err)
+
+		f, err := os.Create(filepath.Join(t.TempDir(), "download"))
+		require.NoError(t, err)
+		defer func() { _ = f.Close() }()
+
+		_, err = httpclient.DownloadWithResume(context.Background(), client, "/file", f)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "resource changed between download attempts")
+	})
+}
+
+func TestDownloadWithResumeNoInterruption(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		assert.Empty(t, req.Header.Get("Range"))
+		_, _ = rw.Write([]byte("content"))
+	}))
+	defer server.Close()
+
+	client, err := httpclient.NewClient(httpclient.WithBaseURLs([]string{server.URL}))
+	require.NoError(t, err)
+
+	var buf writerAtBuffer
+	n, err := httpclient.DownloadWithResume(context.Background(), client, "/file", &buf)
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), n)
+	assert.Equal(t, "content", buf.String())
+}
+
+type writerAtBuffer struct {
+	bytes.Buffer
+}
+
+func (w *writerAtBuffer) WriteAt(p []byte, off int64) (int, error) {
+	if off != int64(w.Len()) {
+		return 0, os.ErrInvalid
+	}
+	return w.Write(p)
+}
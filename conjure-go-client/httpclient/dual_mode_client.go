@@ -0,0 +1,109 @@
+// Copyright (c) 2026 Palantir Technologies. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpclient
+
+import (
+	"context"
+	"net/http"
+)
+
+// MeshMode selects whether a client built with NewDualModeClient sends a request through a service mesh or directly
+// to the service's URIs.
+type MeshMode string
+
+const (
+	// MeshModeDirect sends requests directly to the service's URIs, with the client's retries and load balancing.
+	MeshModeDirect MeshMode = "direct"
+	// MeshModeMesh sends requests to a service mesh proxy, which is responsible for retries and load balancing.
+	MeshModeMesh MeshMode = "mesh"
+)
+
+// ContextWithMeshMode returns a copy of ctx with the MeshMode of the requests made using it set to mode, overriding
+// the default mode of clients built with NewDualModeClient.
+func ContextWithMeshMode(ctx context.Context, mode MeshMode) context.Context {
+	return context.WithValue(ctx, requestMeshMode, mode)
+}
+
+// MeshModeFromContext returns the MeshMode set on ctx by ContextWithMeshMode, or the empty string if none was set.
+func MeshModeFromContext(ctx context.Context) MeshMode {
+	mode, _ := ctx.Value(requestMeshMode).(MeshMode)
+	return mode
+}
+
+// NewDualModeClient returns a Client which sends each request using either mesh or direct, so that a service can be
+// migrated onto a service mesh without changing its call sites. mesh is typically built with a single "mesh-" prefixed
+// URI for the mesh proxy, which disables retries, and direct with the service's URIs. The client used for a request
+// is chosen by the MeshMode set on its context with ContextWithMeshMode, if any, and otherwise by defaultMode, which is
+// called for every request so that it can follow refreshable configuration. A nil defaultMode, or one returning
+// neither MeshModeMesh nor MeshModeDirect, selects MeshModeDirect.
+//
+// EffectiveConfig and Prewarm apply to the client selected by defaultMode. DeriveClient applies params to both clients.
+func NewDualModeClient(mesh, direct Client, defaultMode func() MeshMode) Client {
+	return &dualModeClient{mesh: mesh, direct: direct, defaultMode: defaultMode}
+}
+
+type dualModeClient struct {
+	mesh        Client
+	direct      Client
+	defaultMode func() MeshMode
+}
+
+// client returns the client for requests made with ctx.
+func (c *dualModeClient) client(ctx context.Context) Client {
+	mode := MeshModeFromContext(ctx)
+	if mode == "" && c.defaultMode != nil {
+		mode = c.defaultMode()
+	}
+	if mode == MeshModeMesh {
+		return c.mesh
+	}
+	return c.direct
+}
+
+func (c *dualModeClient) Do(ctx context.Context, params ...RequestParam) (*http.Response, error) {
+	return c.client(ctx).Do(ctx, params...)
+}
+
+func (c *dualModeClient) Get(ctx context.Context, params ...RequestParam) (*http.Response, error) {
+	return c.client(ctx).Get(ctx, params...)
+}
+
+func (c *dualModeClient) Head(ctx context.Context, params ...RequestParam) (*http.Response, error) {
+	return c.client(ctx).Head(ctx, params...)
+}
+
+func (c *dualModeClient) Post(ctx context.Context, params ...RequestParam) (*http.Response, error) {
+	return c.client(ctx).Post(ctx, params...)
+}
+
+func (c *dualModeClient) Put(ctx context.Context, params ...RequestParam) (*http.Response, error) {
+	return c.client(ctx).Put(ctx, params...)
+}
+
+func (c *dualModeClient) Delete(ctx context.Context, params ...RequestParam) (*http.Response, error) {
+	return c.client(ctx).Delete(ctx, params...)
+}
+
+func (c *dualModeClient) withOverrides(params ...ClientParam) (Client, error) {
+	mesh, err := DeriveClient(c.mesh, params...)
+	if err != nil {
+		return nil, err
+	}
+	direct, err := DeriveClient(c.direct, params...)
+	if err != nil {
+		return nil, err
+	}
+	return NewDualModeClient(mesh, direct, c.defaultMode), nil
+}
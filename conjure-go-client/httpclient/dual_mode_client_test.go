@@ -0,0 +1,61 @@
+// Copyright (c) 2026 Palantir Technologies. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpclient_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/palantir/conjure-go-runtime/v2/conjure-go-client/httpclient"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDualModeClient(t *testing.T) {
+	var meshRequests, directRequests atomic.Int32
+	meshServer := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) { meshRequests.Add(1) }))
+	defer meshServer.Close()
+	directServer := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) { directRequests.Add(1) }))
+	defer directServer.Close()
+
+	mesh, err := httpclient.NewClient(httpclient.WithBaseURLs([]string{"mesh-" + meshServer.URL}))
+	require.NoError(t, err)
+	direct, err := httpclient.NewClient(httpclient.WithBaseURLs([]string{directServer.URL}))
+	require.NoError(t, err)
+
+	var mode atomic.Value
+	mode.Store(httpclient.MeshModeDirect)
+	client := httpclient.NewDualModeClient(mesh, direct, func() httpclient.MeshMode {
+		return mode.Load().(httpclient.MeshMode)
+	})
+
+	ctx := context.Background()
+	_, err = client.Get(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), directRequests.Load())
+
+	mode.Store(httpclient.MeshModeMesh)
+	_, err = client.Get(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), meshRequests.Load())
+
+	_, err = client.Get(httpclient.ContextWithMeshMode(ctx, httpclient.MeshModeDirect))
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), directRequests.Load())
+	assert.Equal(t, int32(1), meshRequests.Load())
+}
@@ -0,0 +1,97 @@
+// Copyright (c) 2026 Palantir Technologies. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpclient
+
+import (
+	"context"
+	"time"
+)
+
+// ClientConfigSnapshot contains the configuration currently applied by a Client, after defaults, configuration and
+// params have been resolved. It is intended for debugging differences between intended and actual behavior and
+// should not be used to configure other clients.
+type ClientConfigSnapshot struct {
+	ServiceName string   `json:"service-name" yaml:"service-name"`
+	URIs        []string `json:"uris" yaml:"uris"`
+
+	// MaxAttempts is the maximum number of attempts for each request, including the first. 0 means no limit.
+	MaxAttempts    int           `json:"max-attempts" yaml:"max-attempts"`
+	InitialBackoff time.Duration `json:"initial-backoff" yaml:"initial-backoff"`
+	MaxBackoff     time.Duration `json:"max-backoff" yaml:"max-backoff"`
+
+	// Timeout is the overall http.Client timeout: the max of the configured read and write timeouts.
+	Timeout               time.Duration `json:"timeout" yaml:"timeout"`
+	ConnectTimeout        time.Duration `json:"connect-timeout" yaml:"connect-timeout"`
+	KeepAlive             time.Duration `json:"keep-alive" yaml:"keep-alive"`
+	IdleConnTimeout       time.Duration `json:"idle-conn-timeout" yaml:"idle-conn-timeout"`
+	TLSHandshakeTimeout   time.Duration `json:"tls-handshake-timeout" yaml:"tls-handshake-timeout"`
+	ExpectContinueTimeout time.Duration `json:"expect-continue-timeout" yaml:"expect-continue-timeout"`
+	ResponseHeaderTimeout time.Duration `json:"response-header-timeout" yaml:"response-header-timeout"`
+	HTTP2ReadIdleTimeout  time.Duration `json:"http2-read-idle-timeout" yaml:"http2-read-idle-timeout"`
+	HTTP2PingTimeout      time.Duration `json:"http2-ping-timeout" yaml:"http2-ping-timeout"`
+
+	MaxIdleConns         int  `json:"max-idle-conns" yaml:"max-idle-conns"`
+	MaxIdleConnsPerHost  int  `json:"max-idle-conns-per-host" yaml:"max-idle-conns-per-host"`
+	DisableHTTP2         bool `json:"disable-http2" yaml:"disable-http2"`
+	ProxyFromEnvironment bool `json:"proxy-from-environment" yaml:"proxy-from-environment"`
+
+	MetricsEnabled              bool `json:"metrics-enabled" yaml:"metrics-enabled"`
+	RequestCompressionThreshold int  `json:"request-compression-threshold" yaml:"request-compression-threshold"`
+}
+
+// EffectiveConfig returns a snapshot of the configuration currently applied by client, after defaults, configuration
+// and params have been resolved. It returns false if client was not built by this package.
+func EffectiveConfig(client Client) (ClientConfigSnapshot, bool) {
+	switch c := client.(type) {
+	case *clientImpl:
+		return c.effectiveConfig(), true
+	case *dualModeClient:
+		return EffectiveConfig(c.client(context.Background()))
+	default:
+		return ClientConfigSnapshot{}, false
+	}
+}
+
+func (c *clientImpl) effectiveConfig() ClientConfigSnapshot {
+	var uris []string
+	if c.builder.URIs != nil {
+		uris = append(uris, c.builder.URIs.CurrentStringSlice()...)
+	}
+	retryParams := c.backoffOptions.Current()
+	dialerParams := c.builder.HTTP.DialerParams.Current()
+	transportParams := c.builder.HTTP.TransportParams.Current()
+	return ClientConfigSnapshot{
+		ServiceName:                 c.serviceName.CurrentString(),
+		URIs:                        uris,
+		MaxAttempts:                 c.currentMaxAttempts(len(uris)),
+		InitialBackoff:              retryParams.InitialBackoff,
+		MaxBackoff:                  retryParams.MaxBackoff,
+		Timeout:                     c.client.CurrentHTTPClient().Timeout,
+		ConnectTimeout:              dialerParams.DialTimeout,
+		KeepAlive:                   dialerParams.KeepAlive,
+		IdleConnTimeout:             transportParams.IdleConnTimeout,
+		TLSHandshakeTimeout:         transportParams.TLSHandshakeTimeout,
+		ExpectContinueTimeout:       transportParams.ExpectContinueTimeout,
+		ResponseHeaderTimeout:       transportParams.ResponseHeaderTimeout,
+		HTTP2ReadIdleTimeout:        transportParams.HTTP2ReadIdleTimeout,
+		HTTP2PingTimeout:            transportParams.HTTP2PingTimeout,
+		MaxIdleConns:                transportParams.MaxIdleConns,
+		MaxIdleConnsPerHost:         transportParams.MaxIdleConnsPerHost,
+		DisableHTTP2:                transportParams.DisableHTTP2,
+		ProxyFromEnvironment:        transportParams.ProxyFromEnvironment,
+		MetricsEnabled:              !c.builder.HTTP.DisableMetrics.CurrentBool(),
+		RequestCompressionThreshold: c.compressionThreshold.CurrentInt(),
+	}
+}
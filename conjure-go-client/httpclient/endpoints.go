@@ -0,0 +1,255 @@
+// Copyright (c) 2026 Palantir Technologies. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/palantir/pkg/metrics"
+	werror "github.com/palantir/witchcraft-go-error"
+	"github.com/palantir/witchcraft-go-logging/wlog/svclog/svc1log"
+)
+
+// Endpoint describes an endpoint of a Conjure service, as generated by conjure-go.
+type Endpoint struct {
+	// Name is the RPC method name of the endpoint, e.g. "getThing".
+	Name string
+	// Method is the HTTP method of the endpoint, e.g. "GET".
+	Method string
+	// PathTemplate is the path of the endpoint, with each path parameter written as a whole segment in braces,
+	// e.g. "/things/{thingId}".
+	PathTemplate string
+	// Tags are added to the metrics of requests to the endpoint.
+	Tags metrics.Tags
+}
+
+// ServiceDefinition describes the endpoints of a Conjure service, as generated by conjure-go.
+type ServiceDefinition struct {
+	// Name is the name of the Conjure service, e.g. "ThingService".
+	Name      string
+	Endpoints []Endpoint
+}
+
+// WithServiceDefinition registers the endpoints of the service the client calls, so that requests made with
+// WithEndpoint, or whose RPC method name is set with WithRPCMethodName or ContextWithRPCMethodName, are instrumented
+// consistently:
+//   - the metrics of requests to an endpoint are tagged with its Tags.
+//   - the RPC method names of the endpoints are always recorded as the "method-name" tag, regardless of the limit set
+//     by WithRPCMethodNameTagLimit.
+//   - a warning is logged the first time a request is made with an RPC method name which is not one of the
+//     endpoints, which usually means that a hand-written method name has drifted from the service's definition.
+//
+// An error is returned if an endpoint has no name, an invalid method or path template, or if two endpoints have the
+// same name.
+func WithServiceDefinition(definition ServiceDefinition) ClientParam {
+	return clientParamFunc(func(b *clientBuilder) error {
+		endpoints := make(map[string]Endpoint, len(definition.Endpoints))
+		for _, endpoint := range definition.Endpoints {
+			if err := validateEndpoint(endpoint); err != nil {
+				return werror.Wrap(err, "invalid endpoint", werror.SafeParam("serviceDefinition", definition.Name))
+			}
+			if _, err := parsePathTemplate(endpoint.PathTemplate); err != nil {
+				return werror.Wrap(err, "invalid endpoint path template",
+					werror.SafeParam("serviceDefinition", definition.Name),
+					werror.SafeParam("rpcMethodName", endpoint.Name),
+					werror.SafeParam("pathTemplate", endpoint.PathTemplate))
+			}
+			if _, ok := endpoints[endpoint.Name]; ok {
+				return werror.Error("endpoint names must be unique",
+					werror.SafeParam("serviceDefinition", definition.Name),
+					werror.SafeParam("rpcMethodName", endpoint.Name))
+			}
+			endpoints[endpoint.Name] = endpoint
+		}
+		registered := &registeredEndpoints{serviceDefinition: definition.Name, endpoints: endpoints}
+		b.Endpoints = registered
+		b.HTTP.MetricsTagProviders = append(b.HTTP.MetricsTagProviders, registered)
+		b.HTTP.ResponseMetrics.RPCMethodNames = registered.names()
+		return nil
+	})
+}
+
+// WithEndpoint sets the method and path of the request from endpoint, substituting pathParams for the parameters of
+// its path template, and sets the RPC method name of the request to the endpoint's name. Path parameter values are
+// escaped, so they can not change the structure of the path. An error is returned if a parameter of the template is
+// missing from pathParams or empty, or if pathParams has a parameter which is not in the template.
+func WithEndpoint(endpoint Endpoint, pathParams map[string]string) RequestParam {
+	return requestParamFunc(func(b *requestBuilder) error {
+		if err := validateEndpoint(endpoint); err != nil {
+			return err
+		}
+		path, err := expandPathTemplate(endpoint.PathTemplate, pathParams)
+		if err != nil {
+			return werror.Wrap(err, "failed to expand endpoint path template",
+				werror.SafeParam("rpcMethodName", endpoint.Name),
+				werror.SafeParam("pathTemplate", endpoint.PathTemplate))
+		}
+		b.method = endpoint.Method
+		b.path = path
+		return WithRPCMethodName(endpoint.Name).apply(b)
+	})
+}
+
+// DoEndpoint sends a request to the endpoint named endpointName of the service definition registered with
+// WithServiceDefinition on the client which sends the request: client itself, or the client it delegates to. The
+// method, path and RPC method name of the request are set from the endpoint as by WithEndpoint, so the timeout and
+// retries configured for the endpoint with endpoint-timeouts and endpoint-max-num-retries, or WithEndpointTimeouts
+// and WithEndpointMaxRetries, apply without repeating its name in WithRPCMethodName. params are applied afterwards
+// and may add to or override the request. An error is returned without sending the request if the client has no
+// endpoint named endpointName.
+func DoEndpoint(ctx context.Context, client Client, endpointName string, pathParams map[string]string, params ...RequestParam) (*http.Response, error) {
+	return client.Do(ctx, append([]RequestParam{withRegisteredEndpoint(endpointName, pathParams)}, params...)...)
+}
+
+// errEndpointNotRegistered is returned by requests made with DoEndpoint to an endpoint which is not registered.
+var errEndpointNotRegistered = errors.New("endpoint is not registered with the client's service definition")
+
+// withRegisteredEndpoint applies WithEndpoint for the endpoint named name of the endpoints registered on the client
+// building the request.
+func withRegisteredEndpoint(name string, pathParams map[string]string) RequestParam {
+	return requestParamFunc(func(b *requestBuilder) error {
+		var endpoint Endpoint
+		var ok bool
+		if b.endpoints != nil {
+			endpoint, ok = b.endpoints.endpoints[name]
+		}
+		if !ok {
+			return werror.Wrap(errEndpointNotRegistered, "", werror.SafeParam("rpcMethodName", name))
+		}
+		return WithEndpoint(endpoint, pathParams).apply(b)
+	})
+}
+
+func validateEndpoint(endpoint Endpoint) error {
+	if endpoint.Name == "" {
+		return werror.Error("endpoint name must not be empty")
+	}
+	switch endpoint.Method {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete, http.MethodOptions:
+		return nil
+	default:
+		return werror.Error("endpoint method is not supported",
+			werror.SafeParam("rpcMethodName", endpoint.Name),
+			werror.SafeParam("method", endpoint.Method))
+	}
+}
+
+// pathTemplateSegment is a segment of a parsed path template: either a literal or the name of a parameter.
+type pathTemplateSegment struct {
+	literal string
+	param   string
+}
+
+// parsePathTemplate splits template into segments, returning an error if it does not start with a slash, if a segment
+// contains a brace without being a whole parameter, or if a parameter appears twice.
+func parsePathTemplate(template string) ([]pathTemplateSegment, error) {
+	if !strings.HasPrefix(template, "/") {
+		return nil, werror.Error("path template must start with a slash")
+	}
+	parts := strings.Split(template[1:], "/")
+	segments := make([]pathTemplateSegment, len(parts))
+	params := make(map[string]struct{})
+	for i, part := range parts {
+		if !strings.HasPrefix(part, "{") || !strings.HasSuffix(part, "}") {
+			if strings.ContainsAny(part, "{}") {
+				return nil, werror.Error("path template parameters must be whole segments", werror.SafeParam("segment", part))
+			}
+			segments[i] = pathTemplateSegment{literal: part}
+			continue
+		}
+		param := part[1 : len(part)-1]
+		if param == "" || strings.ContainsAny(param, "{}") {
+			return nil, werror.Error("path template parameter is invalid", werror.SafeParam("segment", part))
+		}
+		if _, ok := params[param]; ok {
+			return nil, werror.Error("path template parameters must be unique", werror.SafeParam("param", param))
+		}
+		params[param] = struct{}{}
+		segments[i] = pathTemplateSegment{param: param}
+	}
+	return segments, nil
+}
+
+// expandPathTemplate returns template with each parameter replaced by its escaped value in params.
+func expandPathTemplate(template string, params map[string]string) (string, error) {
+	segments, err := parsePathTemplate(template)
+	if err != nil {
+		return "", err
+	}
+	var path strings.Builder
+	used := make(map[string]struct{}, len(params))
+	for _, segment := range segments {
+		path.WriteByte('/')
+		if segment.param == "" {
+			path.WriteString(segment.literal)
+			continue
+		}
+		value, ok := params[segment.param]
+		if !ok || value == "" {
+			return "", werror.Error("path parameter must be set", werror.SafeParam("param", segment.param))
+		}
+		path.WriteString(url.PathEscape(value))
+		used[segment.param] = struct{}{}
+	}
+	for param := range params {
+		if _, ok := used[param]; !ok {
+			return "", werror.Error("path parameter is not in the path template", werror.SafeParam("param", param))
+		}
+	}
+	return path.String(), nil
+}
+
+// registeredEndpoints are the endpoints registered with WithServiceDefinition.
+type registeredEndpoints struct {
+	serviceDefinition string
+	endpoints         map[string]Endpoint
+	// unknown contains the unregistered RPC method names for which a warning has been logged.
+	unknown sync.Map
+}
+
+func (r *registeredEndpoints) names() []string {
+	names := make([]string, 0, len(r.endpoints))
+	for name := range r.endpoints {
+		names = append(names, name)
+	}
+	return names
+}
+
+// Tags returns the tags of the endpoint of the request, if it is registered.
+func (r *registeredEndpoints) Tags(req *http.Request, _ *http.Response, _ error) metrics.Tags {
+	return r.endpoints[getRPCMethodName(req.Context())].Tags
+}
+
+// check logs a warning the first time a request is made with an RPC method name which is not registered.
+func (r *registeredEndpoints) check(ctx context.Context) {
+	name := getRPCMethodName(ctx)
+	if name == "" {
+		return
+	}
+	if _, ok := r.endpoints[name]; ok {
+		return
+	}
+	if _, warned := r.unknown.LoadOrStore(name, struct{}{}); warned {
+		return
+	}
+	svc1log.FromContext(ctx).Warn("Request made with an RPC method name which is not an endpoint of the client's service definition",
+		svc1log.SafeParam("serviceDefinition", r.serviceDefinition),
+		svc1log.SafeParam("rpcMethodName", name))
+}
@@ -0,0 +1,204 @@
+// Copyright (c) 2026 Palantir Technologies. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpclient_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/palantir/conjure-go-runtime/v2/conjure-go-client/httpclient"
+	"github.com/palantir/pkg/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	getThing = httpclient.Endpoint{
+		Name:         "getThing",
+		Method:       http.MethodGet,
+		PathTemplate: "/things/{thingId}/versions/{version}",
+		Tags:         metrics.Tags{metrics.MustNewTag("endpoint-kind", "read")},
+	}
+	putThing = httpclient.Endpoint{
+		Name:         "putThing",
+		Method:       http.MethodPut,
+		PathTemplate: "/things/{thingId}",
+	}
+)
+
+func TestWithEndpoint(t *testing.T) {
+	var method, path string
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		method, path = req.Method, req.URL.EscapedPath()
+	}))
+	defer server.Close()
+
+	registry := metrics.NewRootMetricsRegistry()
+	ctx := metrics.WithRegistry(context.Background(), registry)
+	client, err := httpclient.NewClient(
+		httpclient.WithServiceName("my-service"),
+		httpclient.WithBaseURLs([]string{server.URL}),
+		httpclient.WithRPCMethodNameTagLimit(1),
+		httpclient.WithServiceDefinition(httpclient.ServiceDefinition{
+			Name:      "ThingService",
+			Endpoints: []httpclient.Endpoint{getThing, putThing},
+		}))
+	require.NoError(t, err)
+
+	_, err = client.Do(ctx, httpclient.WithEndpoint(getThing, map[string]string{"thingId": "a/../b", "version": "1"}))
+	require.NoError(t, err)
+	assert.Equal(t, http.MethodGet, method)
+	assert.Equal(t, "/things/a%2F..%2Fb/versions/1", path)
+
+	_, err = client.Do(ctx, httpclient.WithEndpoint(putThing, map[string]string{"thingId": "a"}))
+	require.NoError(t, err)
+	assert.Equal(t, http.MethodPut, method)
+
+	_, err = client.Do(ctx, httpclient.WithEndpoint(getThing, map[string]string{"thingId": "a"}))
+	assert.EqualError(t, err, "failed to expand endpoint path template: path parameter must be set")
+	_, err = client.Do(ctx, httpclient.WithEndpoint(putThing, map[string]string{"thingId": "a", "version": "1"}))
+	assert.EqualError(t, err, "failed to expand endpoint path template: path parameter is not in the path template")
+
+	t.Run("metrics", func(t *testing.T) {
+		kinds := map[string]string{}
+		registry.Each(func(name string, tags metrics.Tags, _ metrics.MetricVal) {
+			if name == "client.response" {
+				kinds[tags.ToMap()["method-name"]] = tags.ToMap()["endpoint-kind"]
+			}
+		})
+		// both registered method names are recorded despite the limit of 1.
+		assert.Equal(t, map[string]string{"getthing": "read", "putthing": ""}, kinds)
+	})
+}
+
+func TestDoEndpoint(t *testing.T) {
+	var method, path string
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		requests.Add(1)
+		method, path = req.Method, req.URL.EscapedPath()
+		switch {
+		case req.Method == http.MethodPut:
+			// block until the endpoint's timeout cancels the request.
+			<-req.Context().Done()
+		case req.URL.Path == "/things/unavailable/versions/1":
+			rw.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	client, err := httpclient.NewClient(
+		httpclient.WithBaseURLs([]string{server.URL}),
+		httpclient.WithMaxRetries(0),
+		httpclient.WithInitialBackoff(time.Millisecond),
+		httpclient.WithMaxBackoff(time.Millisecond),
+		httpclient.WithEndpointTimeouts(map[string]time.Duration{putThing.Name: 50 * time.Millisecond}),
+		httpclient.WithEndpointMaxRetries(map[string]int{getThing.Name: 2}),
+		httpclient.WithServiceDefinition(httpclient.ServiceDefinition{
+			Name:      "ThingService",
+			Endpoints: []httpclient.Endpoint{getThing, putThing},
+		}))
+	require.NoError(t, err)
+
+	_, err = httpclient.DoEndpoint(ctx, client, getThing.Name, map[string]string{"thingId": "a", "version": "1"},
+		httpclient.WithQueryValues(map[string][]string{"q": {"v"}}))
+	require.NoError(t, err)
+	assert.Equal(t, http.MethodGet, method)
+	assert.Equal(t, "/things/a/versions/1", path)
+
+	t.Run("endpoint timeout", func(t *testing.T) {
+		_, err := httpclient.DoEndpoint(ctx, client, putThing.Name, map[string]string{"thingId": "a"})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "Client.Timeout exceeded")
+	})
+	t.Run("endpoint retries", func(t *testing.T) {
+		requests.Store(0)
+		_, err := httpclient.DoEndpoint(ctx, client, getThing.Name, map[string]string{"thingId": "unavailable", "version": "1"})
+		require.Error(t, err)
+		assert.Equal(t, int32(3), requests.Load())
+	})
+	t.Run("delegating client", func(t *testing.T) {
+		// the endpoint is resolved by the client which sends the request, so clients which delegate to it work too.
+		_, err := httpclient.DoEndpoint(ctx, delegatingClient{client}, getThing.Name, map[string]string{"thingId": "b", "version": "2"})
+		require.NoError(t, err)
+		assert.Equal(t, "/things/b/versions/2", path)
+	})
+	t.Run("unknown endpoint", func(t *testing.T) {
+		requests.Store(0)
+		_, err := httpclient.DoEndpoint(ctx, client, "deleteThing", nil)
+		assert.EqualError(t, err, "endpoint is not registered with the client's service definition")
+		assert.Equal(t, int32(0), requests.Load())
+	})
+}
+
+// delegatingClient is a Client which forwards its requests to another client.
+type delegatingClient struct {
+	httpclient.Client
+}
+
+func (c delegatingClient) Do(ctx context.Context, params ...httpclient.RequestParam) (*http.Response, error) {
+	return c.Client.Do(ctx, params...)
+}
+
+func TestWithServiceDefinition_Invalid(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		endpoint httpclient.Endpoint
+		err      string
+	}{
+		{
+			name:     "missing name",
+			endpoint: httpclient.Endpoint{Method: http.MethodGet, PathTemplate: "/"},
+			err:      "invalid endpoint: endpoint name must not be empty",
+		},
+		{
+			name:     "invalid method",
+			endpoint: httpclient.Endpoint{Name: "getThing", Method: "FETCH", PathTemplate: "/"},
+			err:      "invalid endpoint: endpoint method is not supported",
+		},
+		{
+			name:     "relative path",
+			endpoint: httpclient.Endpoint{Name: "getThing", Method: http.MethodGet, PathTemplate: "things"},
+			err:      "invalid endpoint path template: path template must start with a slash",
+		},
+		{
+			name:     "partial segment parameter",
+			endpoint: httpclient.Endpoint{Name: "getThing", Method: http.MethodGet, PathTemplate: "/things/v{version}"},
+			err:      "invalid endpoint path template: path template parameters must be whole segments",
+		},
+		{
+			name:     "duplicate parameter",
+			endpoint: httpclient.Endpoint{Name: "getThing", Method: http.MethodGet, PathTemplate: "/{id}/{id}"},
+			err:      "invalid endpoint path template: path template parameters must be unique",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := httpclient.NewClient(
+				httpclient.WithBaseURLs([]string{"https://localhost"}),
+				httpclient.WithServiceDefinition(httpclient.ServiceDefinition{Endpoints: []httpclient.Endpoint{tc.endpoint}}))
+			assert.EqualError(t, err, tc.err)
+		})
+	}
+
+	_, err := httpclient.NewClient(
+		httpclient.WithBaseURLs([]string{"https://localhost"}),
+		httpclient.WithServiceDefinition(httpclient.ServiceDefinition{Endpoints: []httpclient.Endpoint{putThing, putThing}}))
+	assert.EqualError(t, err, "endpoint names must be unique")
+}
@@ -16,6 +16,7 @@ package httpclient
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -23,7 +24,10 @@ import (
 	"time"
 
 	"github.com/palantir/conjure-go-runtime/v2/conjure-go-client/httpclient/internal"
+	"github.com/palantir/conjure-go-runtime/v2/conjure-go-client/httpclient/qostest"
 	"github.com/palantir/pkg/httpserver"
+	"github.com/palantir/pkg/metrics"
+	"github.com/palantir/pkg/refreshable"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -86,6 +90,39 @@ func TestFailover429(t *testing.T) {
 	assert.Equal(t, 3, n)
 }
 
+func TestFailover429RetryAfter(t *testing.T) {
+	n := 0
+	s := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		n++
+		if n == 1 {
+			rw.Header().Set("Retry-After", "60")
+			rw.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		rw.WriteHeader(http.StatusOK)
+	}))
+	defer s.Close()
+
+	observer := &recordingRetryObserver{}
+	cli, err := NewClient(
+		WithBaseURLs([]string{s.URL}),
+		WithInitialBackoff(time.Millisecond),
+		WithMaxBackoff(time.Millisecond),
+		WithRetryAfter(0.5, 100*time.Millisecond),
+		WithRetryObserver(observer),
+	)
+	require.NoError(t, err)
+
+	start := time.Now()
+	_, err = cli.Do(context.Background(), WithRequestMethod("GET"))
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	require.Len(t, observer.nominal, 1)
+	assert.GreaterOrEqual(t, observer.nominal[0], 50*time.Millisecond)
+	assert.LessOrEqual(t, observer.nominal[0], 100*time.Millisecond)
+	assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+}
+
 func TestFailover200(t *testing.T) {
 	n := 0
 	handler := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
@@ -104,6 +141,146 @@ func TestFailover200(t *testing.T) {
 	assert.Equal(t, 1, n)
 }
 
+func TestFailoverConnectionReset(t *testing.T) {
+	s1 := qostest.NewServer(qostest.ConnectionReset())
+	defer s1.Close()
+	s2 := qostest.NewServer(qostest.ConnectionReset())
+	defer s2.Close()
+
+	backoff := time.Millisecond
+	cli, err := NewClient(WithBaseURLs(qostest.URLs(s1, s2)), WithInitialBackoff(backoff), WithMaxBackoff(backoff))
+	require.NoError(t, err)
+
+	_, err = cli.Do(context.Background(), WithRequestMethod("GET"))
+	require.NoError(t, err)
+	assert.Equal(t, 3, s1.RequestCount()+s2.RequestCount())
+}
+
+func TestFailoverRetryBudget(t *testing.T) {
+	s := qostest.NewServer()
+	s.SetDefault(qostest.Unavailable())
+	defer s.Close()
+
+	ratio := 0.5
+	cli, err := NewClientFromRefreshableConfig(context.Background(), NewRefreshingClientConfig(refreshable.NewDefaultRefreshable(ClientConfig{
+		ServiceName:         "my-service",
+		URIs:                []string{s.URL},
+		MaxNumRetries:       &[]int{5}[0],
+		InitialBackoff:      &[]time.Duration{time.Millisecond}[0],
+		MaxBackoff:          &[]time.Duration{time.Millisecond}[0],
+		RetryBudgetRatio:    &ratio,
+		MinRetriesPerSecond: &[]int{0}[0],
+	})))
+	require.NoError(t, err)
+
+	registry := metrics.NewRootMetricsRegistry()
+	ctx := metrics.WithRegistry(context.Background(), registry)
+	for i := 0; i < 4; i++ {
+		_, err = cli.Get(ctx)
+		require.Error(t, err)
+	}
+	// each request deposits half a retry, so 2 of the 4 requests are retried once before the budget is exhausted.
+	assert.Equal(t, 6, s.RequestCount())
+	exhausted := registry.Meter(MetricRetryBudgetExhausted, metrics.MustNewTag(MetricTagServiceName, "my-service")).Count()
+	assert.Equal(t, int64(4), exhausted)
+}
+
+func TestFailoverRetryPredicate(t *testing.T) {
+	retry500 := WithRetryPredicate(func(_ *http.Response, err error) RetryDecision {
+		if statusCode, ok := StatusCodeFromError(err); ok && statusCode == http.StatusInternalServerError {
+			return RetryDecisionRetry
+		}
+		return RetryDecisionDefault
+	})
+	noRetry503 := WithRetryPredicate(func(_ *http.Response, err error) RetryDecision {
+		if statusCode, ok := StatusCodeFromError(err); ok && statusCode == http.StatusServiceUnavailable {
+			return RetryDecisionNoRetry
+		}
+		return RetryDecisionDefault
+	})
+	backoff := time.Millisecond
+
+	t.Run("retries 500", func(t *testing.T) {
+		s := qostest.NewServer(qostest.Status(http.StatusInternalServerError), qostest.OK())
+		defer s.Close()
+		cli, err := NewClient(WithBaseURLs(qostest.URLs(s)), WithInitialBackoff(backoff), WithMaxBackoff(backoff), retry500)
+		require.NoError(t, err)
+
+		_, err = cli.Do(context.Background(), WithRequestMethod("GET"))
+		require.NoError(t, err)
+		assert.Equal(t, 2, s.RequestCount())
+	})
+	t.Run("does not retry 503", func(t *testing.T) {
+		s := qostest.NewServer(qostest.Unavailable(), qostest.OK())
+		defer s.Close()
+		cli, err := NewClient(WithBaseURLs(qostest.URLs(s)), WithInitialBackoff(backoff), WithMaxBackoff(backoff), retry500, noRetry503)
+		require.NoError(t, err)
+
+		_, err = cli.Do(context.Background(), WithRequestMethod("GET"))
+		require.Error(t, err)
+		assert.Equal(t, 1, s.RequestCount())
+	})
+	t.Run("max retries still apply", func(t *testing.T) {
+		s := qostest.NewServer()
+		s.SetDefault(qostest.Status(http.StatusInternalServerError))
+		defer s.Close()
+		cli, err := NewClient(WithBaseURLs(qostest.URLs(s)), WithInitialBackoff(backoff), WithMaxBackoff(backoff), WithMaxRetries(2), retry500)
+		require.NoError(t, err)
+
+		_, err = cli.Do(context.Background(), WithRequestMethod("GET"))
+		require.Error(t, err)
+		assert.Equal(t, 3, s.RequestCount())
+	})
+}
+
+func TestFailoverSafeMethodRetriesOnly(t *testing.T) {
+	backoff := time.Millisecond
+	newClient := func(t *testing.T, uris []string) Client {
+		cli, err := NewClient(WithBaseURLs(uris), WithInitialBackoff(backoff), WithMaxBackoff(backoff), WithSafeMethodRetriesOnly())
+		require.NoError(t, err)
+		return cli
+	}
+
+	t.Run("POST is not retried after a connection reset", func(t *testing.T) {
+		s := qostest.NewServer(qostest.ConnectionReset())
+		defer s.Close()
+
+		_, err := newClient(t, qostest.URLs(s)).Do(context.Background(), WithRequestMethod(http.MethodPost))
+		require.Error(t, err)
+		assert.Equal(t, 1, s.RequestCount())
+	})
+	t.Run("GET is retried after a connection reset", func(t *testing.T) {
+		s := qostest.NewServer(qostest.ConnectionReset())
+		defer s.Close()
+
+		_, err := newClient(t, qostest.URLs(s)).Do(context.Background(), WithRequestMethod(http.MethodGet))
+		require.NoError(t, err)
+		assert.Equal(t, 2, s.RequestCount())
+	})
+	t.Run("POST is retried after connection refused", func(t *testing.T) {
+		refused := httptest.NewServer(http.NotFoundHandler())
+		refused.Close()
+		s := qostest.NewServer()
+		defer s.Close()
+
+		cli := newClient(t, []string{refused.URL, s.URL})
+		// the URIs are not tried in a fixed order, so send enough requests for some to try the refused URI first.
+		for i := 0; i < 10; i++ {
+			_, err := cli.Do(context.Background(), WithRequestMethod(http.MethodPost))
+			require.NoError(t, err)
+		}
+		assert.Equal(t, 10, s.RequestCount())
+	})
+	t.Run("POST is retried after 503", func(t *testing.T) {
+		s := qostest.NewServer(qostest.Unavailable())
+		defer s.Close()
+
+		_, err := newClient(t, qostest.URLs(s)).Do(context.Background(), WithRequestMethod(http.MethodPost))
+		require.NoError(t, err)
+		assert.Equal(t, 2, s.RequestCount())
+	})
+}
+
 func TestFailoverEverythingDown(t *testing.T) {
 	n := 0
 	handler := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
@@ -122,6 +299,176 @@ func TestFailoverEverythingDown(t *testing.T) {
 	assert.Equal(t, 6, n)
 }
 
+func TestFailoverAttemptsFromError(t *testing.T) {
+	unavailable := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer unavailable.Close()
+	down := httptest.NewServer(http.NotFoundHandler())
+	down.Close()
+
+	backoff := 5 * time.Millisecond
+	cli, err := NewClient(WithBaseURLs([]string{unavailable.URL, down.URL}), WithMaxRetries(3), WithInitialBackoff(backoff), WithMaxBackoff(backoff))
+	require.NoError(t, err)
+
+	_, err = cli.Do(context.Background(), WithRequestMethod("GET"))
+	require.Error(t, err)
+
+	attempts, ok := AttemptsFromError(err)
+	require.True(t, ok)
+	require.Len(t, attempts, 4)
+	assert.Zero(t, attempts[0].Backoff)
+	for _, attempt := range attempts {
+		switch attempt.URIIndex {
+		case 0:
+			assert.Equal(t, http.StatusServiceUnavailable, attempt.StatusCode)
+			assert.Equal(t, AttemptErrorClassStatus, attempt.ErrorClass)
+		case 1:
+			assert.Zero(t, attempt.StatusCode)
+			assert.Equal(t, AttemptErrorClassNetwork, attempt.ErrorClass)
+		default:
+			t.Errorf("unexpected URI index %d", attempt.URIIndex)
+		}
+	}
+}
+
+func TestAttemptHooks(t *testing.T) {
+	n := 0
+	s := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		n++
+		if n < 3 {
+			rw.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		rw.WriteHeader(http.StatusOK)
+	}))
+	defer s.Close()
+
+	var started, finished []Attempt
+	backoff := time.Millisecond
+	cli, err := NewClient(
+		WithBaseURLs([]string{s.URL}),
+		WithMaxRetries(3),
+		WithInitialBackoff(backoff),
+		WithMaxBackoff(backoff),
+		WithAttemptHooks(
+			func(ctx context.Context, attempt Attempt) {
+				started = append(started, attempt)
+			},
+			func(ctx context.Context, attempt Attempt, resp *http.Response, err error) {
+				if attempt.Number < 2 {
+					assert.Error(t, err)
+				} else {
+					assert.NoError(t, err)
+					assert.Equal(t, http.StatusOK, resp.StatusCode)
+				}
+				finished = append(finished, attempt)
+			}),
+	)
+	require.NoError(t, err)
+
+	_, err = cli.Do(context.Background(), WithRequestMethod("GET"))
+	require.NoError(t, err)
+
+	require.Len(t, started, 3)
+	require.Len(t, finished, 3)
+	for i := range started {
+		assert.Equal(t, i, started[i].Number)
+		assert.Equal(t, s.URL, started[i].URI)
+		assert.Equal(t, 0, started[i].URIIndex)
+		assert.Zero(t, started[i].StatusCode)
+		assert.Equal(t, started[i].Number, finished[i].Number)
+	}
+	assert.Equal(t, http.StatusServiceUnavailable, finished[0].StatusCode)
+	assert.Equal(t, AttemptErrorClassStatus, finished[1].ErrorClass)
+	assert.Equal(t, http.StatusOK, finished[2].StatusCode)
+	assert.Empty(t, finished[2].ErrorClass)
+}
+
+func TestAttemptHeaders(t *testing.T) {
+	var attemptHeaders, failoverHeaders []string
+	s := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		attemptHeaders = append(attemptHeaders, req.Header.Get(DefaultAttemptHeader))
+		failoverHeaders = append(failoverHeaders, req.Header.Get(DefaultFailoverHeader))
+		if len(attemptHeaders) < 3 {
+			rw.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		rw.WriteHeader(http.StatusOK)
+	}))
+	defer s.Close()
+
+	backoff := time.Millisecond
+	cli, err := NewClient(
+		WithBaseURLs([]string{s.URL}),
+		WithMaxRetries(3),
+		WithInitialBackoff(backoff),
+		WithMaxBackoff(backoff),
+		WithAttemptHeaders(DefaultAttemptHeader, DefaultFailoverHeader),
+	)
+	require.NoError(t, err)
+
+	_, err = cli.Do(context.Background(), WithRequestMethod("GET"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1", "2", "3"}, attemptHeaders)
+	assert.Equal(t, []string{"", "true", "true"}, failoverHeaders)
+}
+
+type recordingRetryObserver struct {
+	nominal []time.Duration
+	resets  int
+}
+
+func (o *recordingRetryObserver) OnBackoff(_ context.Context, retry int, nominal, delay time.Duration) {
+	o.nominal = append(o.nominal, nominal)
+}
+
+func (o *recordingRetryObserver) OnReset(context.Context) {
+	o.resets++
+}
+
+func TestRetryObserver(t *testing.T) {
+	n := 0
+	s := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		n++
+		if n < 4 {
+			rw.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		rw.WriteHeader(http.StatusOK)
+	}))
+	defer s.Close()
+
+	observer := &recordingRetryObserver{}
+	cli, err := NewClient(
+		WithBaseURLs([]string{s.URL}),
+		WithMaxRetries(3),
+		WithInitialBackoff(time.Millisecond),
+		WithMaxBackoff(2*time.Millisecond),
+		WithRetryObserver(observer),
+	)
+	require.NoError(t, err)
+
+	_, err = cli.Do(context.Background(), WithRequestMethod("GET"))
+	require.NoError(t, err)
+	assert.Equal(t, []time.Duration{time.Millisecond, 2 * time.Millisecond, 2 * time.Millisecond}, observer.nominal)
+	assert.Zero(t, observer.resets)
+}
+
+func TestAttemptsFromErrorSingleAttempt(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusBadRequest)
+	}))
+	defer s.Close()
+	cli, err := NewClient(WithBaseURLs([]string{s.URL}))
+	require.NoError(t, err)
+
+	_, err = cli.Do(context.Background(), WithRequestMethod("GET"))
+	require.Error(t, err)
+	_, ok := AttemptsFromError(err)
+	assert.False(t, ok)
+}
+
 func TestBackoffSingleURL(t *testing.T) {
 	n := 0
 	handler := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
@@ -279,3 +626,95 @@ func TestFailover_NoHost(t *testing.T) {
 	_, err = cli.Get(context.Background())
 	assert.NoError(t, err)
 }
+
+func TestAllNodesUnavailablePolicy(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		requests++
+		rw.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	for _, tc := range []struct {
+		name             string
+		policy           AllNodesUnavailablePolicy
+		expectedRequests int
+	}{
+		{name: "serve all", policy: AllNodesUnavailableServeAll, expectedRequests: 2},
+		{name: "fail fast", policy: AllNodesUnavailableFailFast, expectedRequests: 1},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			requests = 0
+			client, err := NewClient(
+				WithBaseURLs([]string{server.URL}),
+				WithMaxRetries(0),
+				WithAllNodesUnavailablePolicy(tc.policy),
+			)
+			require.NoError(t, err)
+
+			_, err = client.Get(context.Background())
+			require.Error(t, err)
+			assert.NotErrorIs(t, err, ErrAllNodesUnavailable)
+
+			_, err = client.Get(context.Background())
+			require.Error(t, err)
+			assert.Equal(t, tc.policy == AllNodesUnavailableFailFast, errors.Is(err, ErrAllNodesUnavailable))
+			assert.Equal(t, tc.expectedRequests, requests)
+		})
+	}
+}
+
+func TestAuthFailoverPolicy(t *testing.T) {
+	for _, tc := range []struct {
+		name         string
+		policy       AuthFailoverPolicy
+		expectedAuth []string
+	}{
+		{
+			name:         "resend",
+			policy:       AuthFailoverResend,
+			expectedAuth: []string{"Bearer token", "Bearer token", "Bearer token"},
+		},
+		{
+			name:         "original host only",
+			policy:       AuthFailoverOriginalHostOnly,
+			expectedAuth: []string{"Bearer token", "", "Bearer token"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var authHeaders []string
+			handler := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				authHeaders = append(authHeaders, req.Header.Get("Authorization"))
+				if len(authHeaders) < 3 {
+					rw.WriteHeader(http.StatusServiceUnavailable)
+					return
+				}
+				rw.WriteHeader(http.StatusOK)
+			})
+			s1 := httptest.NewServer(handler)
+			defer s1.Close()
+			s2 := httptest.NewServer(handler)
+			defer s2.Close()
+
+			var attemptURIs []string
+			cli, err := NewClient(
+				WithBaseURLs([]string{s1.URL, s2.URL}),
+				WithAuthToken("token"),
+				WithAuthFailoverPolicy(tc.policy),
+				WithInitialBackoff(time.Millisecond),
+				WithMaxBackoff(time.Millisecond),
+				WithAttemptHooks(func(_ context.Context, attempt Attempt) {
+					attemptURIs = append(attemptURIs, attempt.URI)
+				}, nil),
+			)
+			require.NoError(t, err)
+
+			_, err = cli.Do(context.Background(), WithRequestMethod("GET"))
+			require.NoError(t, err)
+			require.Len(t, attemptURIs, 3)
+			require.NotEqual(t, attemptURIs[0], attemptURIs[1], "second attempt should fail over")
+			require.Equal(t, attemptURIs[0], attemptURIs[2], "third attempt should return to the first host")
+			assert.Equal(t, tc.expectedAuth, authHeaders)
+		})
+	}
+}
@@ -0,0 +1,102 @@
+// Copyright (c) 2026 Palantir Technologies. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+
+	werror "github.com/palantir/witchcraft-go-error"
+	"github.com/palantir/witchcraft-go-logging/wlog/svclog/svc1log"
+)
+
+// FallbackTrigger decides whether a request which failed against the primary service is sent to the fallback client.
+// resp and err are the final result of the primary request after all retries.
+type FallbackTrigger func(resp *http.Response, err error) bool
+
+// FallbackOnUnavailable is the default FallbackTrigger. It falls back when the primary request failed without a
+// response, e.g. because no URI could be reached or every node was marked unavailable, or with a 429 or 5xx status.
+func FallbackOnUnavailable(_ *http.Response, err error) bool {
+	if err == nil {
+		return false
+	}
+	statusCode, ok := StatusCodeFromError(err)
+	if !ok {
+		return true
+	}
+	return statusCode == http.StatusTooManyRequests || statusCode >= http.StatusInternalServerError
+}
+
+// fallbackClient sends idempotent requests to a secondary Client when the primary fails. See WithFallbackClient.
+type fallbackClient struct {
+	client  Client
+	trigger FallbackTrigger
+}
+
+// shouldFallback returns true if a request made with params, which failed with resp and err, should be sent to the
+// fallback client. Requests are never sent to the fallback once ctx is done.
+func (f *fallbackClient) shouldFallback(ctx context.Context, params []RequestParam, resp *http.Response, err error) bool {
+	if err == nil || ctx.Err() != nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if !isIdempotentMethod(requestMethod(params)) {
+		return false
+	}
+	return f.trigger(resp, err)
+}
+
+// do sends the request to the fallback client. If it fails, the returned error records the primary error as a param.
+func (f *fallbackClient) do(ctx context.Context, serviceName string, primaryErr error, params []RequestParam) (*http.Response, error) {
+	svc1log.FromContext(ctx).Info("Sending request to fallback client",
+		svc1log.SafeParam("serviceName", serviceName),
+		svc1log.Stacktrace(primaryErr))
+	resp, err := f.client.Do(ctx, params...)
+	if err != nil {
+		return nil, werror.WrapWithContextParams(ctx, err, "fallback client request failed",
+			werror.SafeParam("serviceName", serviceName),
+			werror.UnsafeParam("primaryError", primaryErr.Error()))
+	}
+	return resp, nil
+}
+
+// requestMethod returns the HTTP method set by params, or the empty string if it can not be determined.
+func requestMethod(params []RequestParam) string {
+	b := &requestBuilder{
+		headers:        make(http.Header),
+		query:          make(url.Values),
+		bodyMiddleware: &bodyMiddleware{},
+	}
+	for _, p := range params {
+		if p == nil {
+			continue
+		}
+		if err := p.apply(b); err != nil {
+			return ""
+		}
+	}
+	return b.method
+}
+
+// isIdempotentMethod returns true for the methods defined as idempotent by RFC 9110.
+func isIdempotentMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
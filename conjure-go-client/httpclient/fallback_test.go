@@ -0,0 +1,96 @@
+// Copyright (c) 2026 Palantir Technologies. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpclient_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/palantir/conjure-go-runtime/v2/conjure-go-client/httpclient"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFallbackClient(t *testing.T) {
+	var primaryCalls, secondaryCalls int32
+	primaryStatus := http.StatusServiceUnavailable
+	primary := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&primaryCalls, 1)
+		rw.WriteHeader(primaryStatus)
+	}))
+	defer primary.Close()
+	secondary := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&secondaryCalls, 1)
+		rw.Header().Set("X-Served-By", "secondary")
+		rw.WriteHeader(http.StatusOK)
+	}))
+	defer secondary.Close()
+
+	secondaryClient, err := httpclient.NewClient(httpclient.WithBaseURLs([]string{secondary.URL}))
+	require.NoError(t, err)
+	newPrimaryClient := func(trigger httpclient.FallbackTrigger) httpclient.Client {
+		client, err := httpclient.NewClient(
+			httpclient.WithBaseURLs([]string{primary.URL}),
+			httpclient.WithMaxRetries(1),
+			httpclient.WithInitialBackoff(time.Millisecond),
+			httpclient.WithMaxBackoff(time.Millisecond),
+			httpclient.WithFallbackClient(secondaryClient, trigger))
+		require.NoError(t, err)
+		return client
+	}
+	reset := func() {
+		atomic.StoreInt32(&primaryCalls, 0)
+		atomic.StoreInt32(&secondaryCalls, 0)
+	}
+
+	t.Run("idempotent request falls back after retries", func(t *testing.T) {
+		reset()
+		resp, err := newPrimaryClient(nil).Get(context.Background(), httpclient.WithPath("/thing"))
+		require.NoError(t, err)
+		assert.Equal(t, "secondary", resp.Header.Get("X-Served-By"))
+		assert.Equal(t, int32(2), atomic.LoadInt32(&primaryCalls))
+		assert.Equal(t, int32(1), atomic.LoadInt32(&secondaryCalls))
+	})
+	t.Run("non-idempotent request does not fall back", func(t *testing.T) {
+		reset()
+		_, err := newPrimaryClient(nil).Post(context.Background(), httpclient.WithPath("/thing"))
+		require.Error(t, err)
+		assert.Equal(t, int32(0), atomic.LoadInt32(&secondaryCalls))
+	})
+	t.Run("trigger rejects fallback", func(t *testing.T) {
+		reset()
+		_, err := newPrimaryClient(func(*http.Response, error) bool { return false }).Get(context.Background())
+		require.Error(t, err)
+		code, ok := httpclient.StatusCodeFromError(err)
+		assert.True(t, ok)
+		assert.Equal(t, http.StatusServiceUnavailable, code)
+		assert.Equal(t, int32(0), atomic.LoadInt32(&secondaryCalls))
+	})
+	t.Run("client errors do not fall back by default", func(t *testing.T) {
+		reset()
+		primaryStatus = http.StatusNotFound
+		defer func() { primaryStatus = http.StatusServiceUnavailable }()
+		_, err := newPrimaryClient(nil).Get(context.Background())
+		require.Error(t, err)
+		assert.Equal(t, int32(0), atomic.LoadInt32(&secondaryCalls))
+	})
+
+	_, err = httpclient.NewClient(httpclient.WithBaseURLs([]string{primary.URL}), httpclient.WithFallbackClient(nil, nil))
+	assert.EqualError(t, err, "fallback client must not be nil")
+}
@@ -0,0 +1,96 @@
+// Copyright (c) 2026 Palantir Technologies. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpclient
+
+import (
+	"net/http"
+	"net/textproto"
+	"strings"
+
+	werror "github.com/palantir/witchcraft-go-error"
+	"golang.org/x/net/http/httpguts"
+)
+
+// hopByHopHeaders are connection-specific headers which are managed by the transport and must not be set by callers.
+var hopByHopHeaders = []string{
+	"Connection",
+	"Keep-Alive",
+	"Proxy-Connection",
+	"Transfer-Encoding",
+	"Upgrade",
+}
+
+// headerNormalizationMiddleware rejects requests with invalid header names or values, removes hop-by-hop headers and
+// canonicalizes header names. See WithHeaderNormalization.
+type headerNormalizationMiddleware struct{}
+
+func (headerNormalizationMiddleware) RoundTrip(req *http.Request, next http.RoundTripper) (*http.Response, error) {
+	hopByHop := hopByHopHeaderNames(req.Header)
+	changed := false
+	for name, values := range req.Header {
+		if !httpguts.ValidHeaderFieldName(name) {
+			return nil, werror.ErrorWithContextParams(req.Context(), "invalid request header name",
+				werror.UnsafeParam("headerName", name))
+		}
+		for _, value := range values {
+			if !httpguts.ValidHeaderFieldValue(value) {
+				return nil, werror.ErrorWithContextParams(req.Context(), "invalid request header value",
+					werror.UnsafeParam("headerName", name))
+			}
+		}
+		canonicalName := textproto.CanonicalMIMEHeaderKey(name)
+		if _, ok := hopByHop[canonicalName]; ok || name != canonicalName {
+			changed = true
+		}
+	}
+	if !changed {
+		return next.RoundTrip(req)
+	}
+
+	// Copy the request rather than modifying the caller's headers.
+	normalized := make(http.Header, len(req.Header))
+	for name, values := range req.Header {
+		canonicalName := textproto.CanonicalMIMEHeaderKey(name)
+		if _, ok := hopByHop[canonicalName]; ok {
+			continue
+		}
+		normalized[canonicalName] = append(normalized[canonicalName], values...)
+	}
+	reqCopy := *req
+	reqCopy.Header = normalized
+	return next.RoundTrip(&reqCopy)
+}
+
+// hopByHopHeaderNames returns the canonical names of the hop-by-hop headers and of the headers listed in the
+// Connection header of h, regardless of its casing.
+func hopByHopHeaderNames(h http.Header) map[string]struct{} {
+	names := make(map[string]struct{}, len(hopByHopHeaders))
+	for _, name := range hopByHopHeaders {
+		names[name] = struct{}{}
+	}
+	for name, values := range h {
+		if textproto.CanonicalMIMEHeaderKey(name) != "Connection" {
+			continue
+		}
+		for _, value := range values {
+			for _, token := range strings.Split(value, ",") {
+				if token = strings.TrimSpace(token); token != "" {
+					names[textproto.CanonicalMIMEHeaderKey(token)] = struct{}{}
+				}
+			}
+		}
+	}
+	return names
+}
@@ -0,0 +1,117 @@
+// Copyright (c) 2026 Palantir Technologies. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHeaderNormalizationMiddleware(t *testing.T) {
+	var received http.Header
+	next := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		received = req.Header
+		return &http.Response{StatusCode: http.StatusOK}, nil
+	})
+
+	t.Run("normalizes headers", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, "http://localhost", nil)
+		require.NoError(t, err)
+		req.Header = http.Header{
+			"x-custom":          {"a"},
+			"X-Custom":          {"b"},
+			"connection":        {"close, X-Remove"},
+			"X-Remove":          {"c"},
+			"Transfer-Encoding": {"chunked"},
+			"keep-alive":        {"timeout=5"},
+			"Accept":            {"application/json"},
+		}
+		_, err = headerNormalizationMiddleware{}.RoundTrip(req, next)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"a", "b"}, received["X-Custom"])
+		delete(received, "X-Custom")
+		assert.Equal(t, http.Header{"Accept": {"application/json"}}, received)
+		assert.Len(t, req.Header, 7, "caller's headers should not be modified")
+	})
+
+	t.Run("passes through normalized headers", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, "http://localhost", nil)
+		require.NoError(t, err)
+		req.Header.Set("Accept", "application/json")
+		_, err = headerNormalizationMiddleware{}.RoundTrip(req, next)
+		require.NoError(t, err)
+		assert.Equal(t, req.Header, received)
+	})
+
+	for _, tc := range []struct {
+		name   string
+		header http.Header
+		errMsg string
+	}{
+		{
+			name:   "invalid name",
+			header: http.Header{"X Custom": {"a"}},
+			errMsg: "invalid request header name",
+		},
+		{
+			name:   "invalid value",
+			header: http.Header{"X-Custom": {"a\r\nX-Injected: b"}},
+			errMsg: "invalid request header value",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, "http://localhost", nil)
+			require.NoError(t, err)
+			req.Header = tc.header
+			_, err = headerNormalizationMiddleware{}.RoundTrip(req, next)
+			assert.EqualError(t, err, tc.errMsg)
+		})
+	}
+}
+
+func TestWithHeaderNormalization(t *testing.T) {
+	var received http.Header
+	s := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		received = req.Header
+		rw.WriteHeader(http.StatusOK)
+	}))
+	defer s.Close()
+
+	cli, err := NewClient(
+		WithBaseURLs([]string{s.URL}),
+		WithHeaderNormalization(),
+		WithMaxRetries(0),
+	)
+	require.NoError(t, err)
+
+	_, err = cli.Get(context.Background(), WithHeader("Keep-Alive", "timeout=5"), WithHeader("X-Custom", "a"))
+	require.NoError(t, err)
+	assert.Equal(t, "a", received.Get("X-Custom"))
+	assert.Empty(t, received.Values("Keep-Alive"))
+
+	_, err = cli.Get(context.Background(), WithHeader("X-Custom", "a\nb"))
+	assert.EqualError(t, err, "httpclient request failed: invalid request header value")
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
@@ -0,0 +1,261 @@
+// Copyright (c) 2026 Palantir Technologies. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/palantir/pkg/metrics"
+)
+
+// errHedgeLost is returned by the attempts of a hedged request which received a response after another attempt.
+var errHedgeLost = errors.New("hedged request attempt received a response after another attempt")
+
+// hedgingParams is set by WithHedgedRequests.
+type hedgingParams struct {
+	delay     time.Duration
+	maxHedges int
+}
+
+const (
+	hedgeableUnknown int32 = iota
+	hedgeableYes
+	hedgeableNo
+)
+
+// hedgedAttempt coordinates the concurrent sends of a single hedged attempt. Every send is canceled once one of them
+// receives a successful response, which then becomes the result of the attempt.
+type hedgedAttempt struct {
+	// hedgeable is set by the first send once its request params have been applied.
+	hedgeable atomic.Int32
+	// rawOutput is true if the caller reads the response body after Do returns.
+	rawOutput atomic.Bool
+
+	mu      sync.Mutex
+	winner  int // -1 until a send receives a successful response.
+	cancels []context.CancelFunc
+}
+
+func newHedgedAttempt() *hedgedAttempt {
+	return &hedgedAttempt{winner: -1}
+}
+
+// start returns the index and context of a new send.
+func (h *hedgedAttempt) start(ctx context.Context) (int, context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.cancels = append(h.cancels, cancel)
+	return len(h.cancels) - 1, ctx
+}
+
+// inspect records whether the request built by b can be hedged: its method must be idempotent and its body, if any,
+// must be encoded from a value by a codec, so that every send encodes its own copy.
+func (h *hedgedAttempt) inspect(b *requestBuilder) {
+	hedgeable := isIdempotentMethod(b.method)
+	switch b.bodyMiddleware.requestInput.(type) {
+	case nil:
+	case io.Reader, *readerRequestBody, *chanRequestBody, *multipartRequestBody:
+		hedgeable = false
+	default:
+		hedgeable = hedgeable && b.bodyMiddleware.requestEncoder != nil
+	}
+	h.rawOutput.Store(b.bodyMiddleware.rawOutput)
+	if hedgeable {
+		h.hedgeable.CompareAndSwap(hedgeableUnknown, hedgeableYes)
+	} else {
+		h.hedgeable.CompareAndSwap(hedgeableUnknown, hedgeableNo)
+	}
+}
+
+// claim makes index the winner if no other send has won, and cancels the other sends.
+func (h *hedgedAttempt) claim(index int) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.winner >= 0 {
+		return h.winner == index
+	}
+	h.winner = index
+	for i, cancel := range h.cancels {
+		if i != index {
+			cancel()
+		}
+	}
+	return true
+}
+
+func (h *hedgedAttempt) isWinner(index int) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.winner == index
+}
+
+func (h *hedgedAttempt) claimed() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.winner >= 0
+}
+
+// finish cancels every send once the attempt has completed with resp. If the caller reads the winner's response
+// body, its send is canceled when the body is closed instead.
+func (h *hedgedAttempt) finish(resp *http.Response) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for i, cancel := range h.cancels {
+		if i == h.winner && resp != nil && resp.Body != nil && h.rawOutput.Load() {
+			resp.Body = &cancelOnCloseBody{ReadCloser: resp.Body, cancel: cancel}
+			continue
+		}
+		cancel()
+	}
+}
+
+// hedgeGate is the middleware of a single send of a hedged attempt. The first send to receive a successful response
+// wins; the responses of the other sends are discarded so that only the winner's body is read.
+type hedgeGate struct {
+	attempt *hedgedAttempt
+	index   int
+}
+
+func (g hedgeGate) RoundTrip(req *http.Request, next http.RoundTripper) (*http.Response, error) {
+	resp, err := next.RoundTrip(req)
+	if err != nil || resp == nil {
+		return resp, err
+	}
+	if !g.attempt.claim(g.index) {
+		_ = resp.Body.Close()
+		return nil, errHedgeLost
+	}
+	return resp, nil
+}
+
+func contextWithHedgeGate(ctx context.Context, gate hedgeGate) context.Context {
+	return context.WithValue(ctx, requestHedgeGate, gate)
+}
+
+func getHedgeGate(ctx context.Context) (hedgeGate, bool) {
+	gate, ok := ctx.Value(requestHedgeGate).(hedgeGate)
+	return gate, ok
+}
+
+type cancelOnCloseBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnCloseBody) Close() error {
+	defer b.cancel()
+	return b.ReadCloser.Close()
+}
+
+// doHedged sends the request to uri and, each time the hedging delay passes without a send having received a
+// successful response, to the next URI in uris which has not been tried, up to the configured number of hedges. The
+// first successful response is returned and the other sends are canceled. If every send fails, the last error is
+// returned.
+func (c *clientImpl) doHedged(ctx context.Context, uri string, uris []string, params ...RequestParam) (*http.Response, error) {
+	type result struct {
+		index int
+		resp  *http.Response
+		err   error
+	}
+	h := newHedgedAttempt()
+	results := make(chan result, c.hedging.maxHedges+1)
+	send := func(uri string) {
+		index, sendCtx := h.start(ctx)
+		sendCtx = contextWithHedgeGate(sendCtx, hedgeGate{attempt: h, index: index})
+		go func() {
+			resp, err := c.doOnce(sendCtx, uri, false, params...)
+			results <- result{index: index, resp: resp, err: err}
+		}()
+	}
+
+	send(uri)
+	tried := map[string]struct{}{uri: {}}
+	pending, hedges := 1, 0
+	timer := time.NewTimer(c.hedging.delay)
+	defer timer.Stop()
+	var lastErr error
+	for {
+		select {
+		case r := <-results:
+			pending--
+			if r.err == nil || h.isWinner(r.index) {
+				h.finish(r.resp)
+				return r.resp, r.err
+			}
+			if lastErr == nil || r.err != errHedgeLost {
+				lastErr = r.err
+			}
+			if pending == 0 {
+				h.finish(nil)
+				return nil, lastErr
+			}
+		case <-timer.C:
+			switch h.hedgeable.Load() {
+			case hedgeableUnknown:
+				timer.Reset(c.hedging.delay)
+				continue
+			case hedgeableNo:
+				continue
+			}
+			if h.claimed() || hedges >= c.hedging.maxHedges {
+				continue
+			}
+			next := nextUntriedURI(uris, uri, tried)
+			if next == "" {
+				continue
+			}
+			tried[next] = struct{}{}
+			send(next)
+			pending++
+			hedges++
+			markRequestHedge(ctx, c)
+			timer.Reset(c.hedging.delay)
+		}
+	}
+}
+
+// nextUntriedURI returns the first URI in uris after uri which is not in tried, or the empty string if there is none.
+func nextUntriedURI(uris []string, uri string, tried map[string]struct{}) string {
+	start := 0
+	for i, u := range uris {
+		if u == uri {
+			start = i + 1
+			break
+		}
+	}
+	for i := 0; i < len(uris); i++ {
+		candidate := uris[(start+i)%len(uris)]
+		if _, ok := tried[candidate]; !ok {
+			return candidate
+		}
+	}
+	return ""
+}
+
+// markRequestHedge records a hedge sent by c.
+func markRequestHedge(ctx context.Context, c *clientImpl) {
+	if disabled := c.builder.HTTP.DisableMetrics; disabled != nil && disabled.CurrentBool() {
+		return
+	}
+	serviceNameTag := metrics.NewTagWithFallbackValue(MetricTagServiceName, c.serviceName.CurrentString(), "unknown")
+	metrics.FromContext(ctx).Meter(MetricRequestHedge, serviceNameTag).Mark(1)
+}
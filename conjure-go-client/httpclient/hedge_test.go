@@ -0,0 +1,91 @@
+// Copyright (c) 2026 Palantir Technologies. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpclient_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/palantir/conjure-go-runtime/v2/conjure-go-client/httpclient"
+	"github.com/palantir/conjure-go-runtime/v2/conjure-go-contract/codecs"
+	"github.com/palantir/pkg/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHedgedRequests(t *testing.T) {
+	slowCanceled := make(chan struct{}, 10)
+	testDone := make(chan struct{})
+	var slowRequests, fastRequests atomic.Int32
+	slow := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		slowRequests.Add(1)
+		select {
+		case <-req.Context().Done():
+			slowCanceled <- struct{}{}
+		case <-testDone:
+		}
+		_, _ = rw.Write([]byte(`"slow"`))
+	}))
+	defer slow.Close()
+	defer close(testDone)
+	fast := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+		fastRequests.Add(1)
+		_, _ = rw.Write([]byte(`"fast"`))
+	}))
+	defer fast.Close()
+
+	rootRegistry := metrics.NewRootMetricsRegistry()
+	ctx := metrics.WithRegistry(context.Background(), rootRegistry)
+	client, err := httpclient.NewClient(
+		httpclient.WithServiceName("test-service"),
+		httpclient.WithBaseURLs([]string{slow.URL, fast.URL}),
+		httpclient.WithHedgedRequests(20*time.Millisecond, 1),
+		httpclient.WithURISelector(httpclient.URISelectorFunc(func(context.Context, []httpclient.URISnapshot) []string {
+			return []string{slow.URL, fast.URL}
+		})))
+	require.NoError(t, err)
+
+	t.Run("idempotent request is hedged", func(t *testing.T) {
+		var out string
+		start := time.Now()
+		_, err := client.Get(ctx, httpclient.WithJSONResponse(&out))
+		require.NoError(t, err)
+		assert.Equal(t, "fast", out)
+		assert.Less(t, time.Since(start), time.Second)
+		select {
+		case <-slowCanceled:
+		case <-time.After(time.Second):
+			t.Fatal("slow request was not canceled")
+		}
+		assert.Equal(t, int32(1), slowRequests.Load())
+		assert.Equal(t, int32(1), fastRequests.Load())
+		assert.EqualValues(t, 1, rootRegistry.Meter("client.request.hedge", metrics.MustNewTag("service-name", "test-service")).Count())
+	})
+
+	t.Run("non-idempotent request is not hedged", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(ctx, 200*time.Millisecond)
+		defer cancel()
+		_, err := client.Post(ctx, httpclient.WithRequestBody("body", codecs.JSON))
+		require.Error(t, err)
+		assert.Equal(t, int32(1), fastRequests.Load())
+	})
+
+	_, err = httpclient.NewClient(httpclient.WithHedgedRequests(0, 1))
+	require.EqualError(t, err, "hedging delay must be positive")
+}
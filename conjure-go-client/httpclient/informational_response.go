@@ -0,0 +1,44 @@
+// Copyright (c) 2026 Palantir Technologies. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptrace"
+	"net/textproto"
+)
+
+// InformationalResponseHandler is invoked with the status code and headers of each 1xx informational response
+// received before the final response to a request, e.g. 103 Early Hints. ctx is the context of the request.
+// Handlers are invoked synchronously while the response is being read, so they should not block.
+type InformationalResponseHandler func(ctx context.Context, statusCode int, header http.Header)
+
+// informationalResponseMiddleware installs an httptrace.ClientTrace which invokes handler for each 1xx response.
+// Any trace already present in the request context continues to be invoked.
+type informationalResponseMiddleware struct {
+	handler InformationalResponseHandler
+}
+
+func (m informationalResponseMiddleware) RoundTrip(req *http.Request, next http.RoundTripper) (*http.Response, error) {
+	ctx := req.Context()
+	trace := &httptrace.ClientTrace{
+		Got1xxResponse: func(code int, header textproto.MIMEHeader) error {
+			m.handler(ctx, code, http.Header(header))
+			return nil
+		},
+	}
+	return next.RoundTrip(req.WithContext(httptrace.WithClientTrace(ctx, trace)))
+}
@@ -0,0 +1,59 @@
+// Copyright (c) 2026 Palantir Technologies. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpclient_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/palantir/conjure-go-runtime/v2/conjure-go-client/httpclient"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEarlyHintsHandler(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("Link", "</style.css>; rel=preload; as=style")
+		rw.WriteHeader(http.StatusEarlyHints)
+		rw.Header().Del("Link")
+		rw.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	type ctxKey struct{}
+	var hints []http.Header
+	var statusCodes []int
+	client, err := httpclient.NewClient(
+		httpclient.WithBaseURLs([]string{server.URL}),
+		httpclient.WithEarlyHintsHandler(func(ctx context.Context, header http.Header) {
+			assert.Equal(t, "value", ctx.Value(ctxKey{}))
+			hints = append(hints, header)
+		}),
+		httpclient.WithInformationalResponseHandler(func(ctx context.Context, statusCode int, header http.Header) {
+			statusCodes = append(statusCodes, statusCode)
+		}),
+	)
+	require.NoError(t, err)
+
+	resp, err := client.Get(context.WithValue(context.Background(), ctxKey{}, "value"))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Empty(t, resp.Header.Get("Link"))
+	require.Len(t, hints, 1)
+	assert.Equal(t, "</style.css>; rel=preload; as=style", hints[0].Get("Link"))
+	assert.Equal(t, []int{http.StatusEarlyHints}, statusCodes)
+}
@@ -0,0 +1,134 @@
+// Copyright (c) 2026 Palantir Technologies. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/palantir/pkg/retry"
+)
+
+// The multiplier and randomization factor are the defaults of retry.Start, which the client has always used.
+// retry.Start can not be wrapped instead because its retrier does not expose the delay it is about to wait, which
+// BackoffObserver reports, and can not be made to wait for a caller-chosen delay, which NextIn requires.
+const (
+	backoffMultiplier          = 2
+	backoffRandomizationFactor = 0.15
+)
+
+// BackoffObserver is notified of the decisions made by a retrier returned by NewBackoffRetrier. Either function may
+// be nil.
+type BackoffObserver struct {
+	// OnBackoff is called before waiting with the one-based number of the retry, the backoff before jitter is
+	// applied, and the delay which will be waited.
+	OnBackoff func(retry int, nominal, delay time.Duration)
+	// OnReset is called when the retrier is reset, after which the next retry does not wait.
+	OnReset func()
+}
+
+// NewBackoffRetrier returns a retry.Retrier with the same exponential backoff as retry.Start using the default
+// multiplier and randomization factor, which additionally reports each backoff and reset to observer.
+// A maxBackoff of 0 means no limit. As with retry.Start, the first call to Next returns immediately.
+func NewBackoffRetrier(ctx context.Context, initialBackoff, maxBackoff time.Duration, observer BackoffObserver) retry.Retrier {
+	// If initial backoff is larger than max backoff and the max backoff is set, initial takes precedence.
+	if maxBackoff != 0 && initialBackoff > maxBackoff {
+		maxBackoff = initialBackoff
+	}
+	return &backoffRetrier{
+		ctx:            ctx,
+		initialBackoff: initialBackoff,
+		maxBackoff:     maxBackoff,
+		observer:       observer,
+		isReset:        ctx.Err() == nil,
+	}
+}
+
+// DelayRetrier is implemented by retriers which can wait for a delay chosen by the caller instead of their backoff.
+type DelayRetrier interface {
+	retry.Retrier
+	// NextIn waits for delay before the next attempt and returns true, or returns false if the retrier is done.
+	NextIn(delay time.Duration) bool
+}
+
+type backoffRetrier struct {
+	ctx            context.Context
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+	observer       BackoffObserver
+
+	currentAttempt int
+	isReset        bool
+}
+
+func (r *backoffRetrier) Reset() {
+	if r.ctx.Err() != nil {
+		return
+	}
+	r.currentAttempt = 0
+	r.isReset = true
+	if r.observer.OnReset != nil {
+		r.observer.OnReset()
+	}
+}
+
+func (r *backoffRetrier) Next() bool {
+	if r.isReset {
+		r.isReset = false
+		return true
+	}
+	nominal, delay := r.retryIn()
+	return r.wait(nominal, delay)
+}
+
+// NextIn waits for delay instead of the exponential backoff. The wait counts as a retry, so later backoffs are as
+// long as if it had been a backoff.
+func (r *backoffRetrier) NextIn(delay time.Duration) bool {
+	r.isReset = false
+	return r.wait(delay, delay)
+}
+
+func (r *backoffRetrier) wait(nominal, delay time.Duration) bool {
+	if r.observer.OnBackoff != nil {
+		r.observer.OnBackoff(r.currentAttempt+1, nominal, delay)
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		r.currentAttempt++
+		return true
+	case <-r.ctx.Done():
+		return false
+	}
+}
+
+func (r *backoffRetrier) CurrentAttempt() int {
+	return r.currentAttempt
+}
+
+// retryIn returns the backoff before the next attempt, and the delay after applying jitter of up to
+// backoffRandomizationFactor in either direction.
+func (r *backoffRetrier) retryIn() (nominal, delay time.Duration) {
+	backoff := float64(r.initialBackoff) * math.Pow(backoffMultiplier, float64(r.currentAttempt))
+	if r.maxBackoff != 0 && backoff > float64(r.maxBackoff) {
+		backoff = float64(r.maxBackoff)
+	}
+	delta := backoffRandomizationFactor * backoff
+	jittered := math.Trunc(backoff - delta + rand.Float64()*(2*delta) + 0.5)
+	return time.Duration(backoff), time.Duration(jittered)
+}
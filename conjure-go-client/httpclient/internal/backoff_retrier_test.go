@@ -0,0 +1,72 @@
+// Copyright (c) 2026 Palantir Technologies. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBackoffRetrier(t *testing.T) {
+	type backoff struct {
+		retry   int
+		nominal time.Duration
+		delay   time.Duration
+	}
+	var backoffs []backoff
+	var resets int
+	r := NewBackoffRetrier(context.Background(), time.Millisecond, 3*time.Millisecond, BackoffObserver{
+		OnBackoff: func(retry int, nominal, delay time.Duration) {
+			backoffs = append(backoffs, backoff{retry: retry, nominal: nominal, delay: delay})
+		},
+		OnReset: func() {
+			resets++
+		},
+	})
+	for i := 0; i < 4; i++ {
+		assert.True(t, r.Next())
+	}
+	assert.Equal(t, 3, r.CurrentAttempt())
+	r.Reset()
+	assert.Equal(t, 1, resets)
+	assert.True(t, r.Next())
+	assert.True(t, r.Next())
+
+	expected := []backoff{
+		{retry: 1, nominal: time.Millisecond},
+		{retry: 2, nominal: 2 * time.Millisecond},
+		{retry: 3, nominal: 3 * time.Millisecond},
+		{retry: 1, nominal: time.Millisecond},
+	}
+	if assert.Len(t, backoffs, len(expected)) {
+		for i := range expected {
+			assert.Equal(t, expected[i].retry, backoffs[i].retry)
+			assert.Equal(t, expected[i].nominal, backoffs[i].nominal)
+			assert.InDelta(t, float64(expected[i].nominal), float64(backoffs[i].delay), 0.15*float64(expected[i].nominal)+1)
+		}
+	}
+}
+
+func TestBackoffRetrierContextDone(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	r := NewBackoffRetrier(ctx, time.Hour, 0, BackoffObserver{})
+	assert.False(t, r.Next())
+	r.Reset()
+	assert.False(t, r.Next())
+}
@@ -22,11 +22,19 @@ import (
 	"sort"
 	"sync/atomic"
 	"time"
+
+	"github.com/palantir/conjure-go-runtime/v2/conjure-go-contract/httpheaders"
 )
 
 const (
 	failureWeight = 10.0
 	failureMemory = 30 * time.Second
+	// unavailableThreshold is the decayed failure score at or above which a URI is considered unavailable,
+	// i.e. it has had a server error or network failure within roughly the last failureMemory.
+	unavailableThreshold = failureWeight / 2
+	// retryAfterScore is added to the score of a URI which responded 503 with a Retry-After header until the
+	// duration has passed, so that it is only tried once other URIs have been.
+	retryAfterScore = 1000
 )
 
 type URIScoringMiddleware interface {
@@ -34,38 +42,72 @@ type URIScoringMiddleware interface {
 	RoundTrip(req *http.Request, next http.RoundTripper) (*http.Response, error)
 }
 
+// URIAvailabilityTracker is implemented by URIScoringMiddleware which track whether URIs are currently failing.
+type URIAvailabilityTracker interface {
+	// AllURIsUnavailable returns true if every URI has failed recently.
+	AllURIsUnavailable() bool
+}
+
+// URIStateReporter is implemented by URIScoringMiddleware which can report the current state of each URI.
+type URIStateReporter interface {
+	// URIStates returns the current state of each URI, sorted by URI.
+	URIStates() []URIState
+}
+
+// URIState is a snapshot of the state tracked by a URIScoringMiddleware for a single URI.
+type URIState struct {
+	URI         string
+	InFlight    int
+	Unavailable bool
+	// Requests is the number of request attempts to the URI which have completed.
+	Requests int64
+	// Failures is the number of completed attempts which failed with a network error, a server error or a QoS status.
+	Failures int64
+	// LastSuccess is when an attempt to the URI last completed without failing, or the zero time if none has.
+	LastSuccess time.Time
+}
+
 type balancedScorer struct {
-	uriInfos map[string]uriInfo
+	uriInfos  map[string]*uriInfo
+	nanoClock func() int64
 }
 
 type uriInfo struct {
 	inflight       int32
 	recentFailures CourseExponentialDecayReservoir
+	requests       int64
+	failures       int64
+	// lastSuccess is the nanoClock time of the last successful attempt, or 0 if none has succeeded.
+	lastSuccess int64
+	// unavailableUntil is the nanoClock time before which the URI asked not to be retried with a 503 response.
+	unavailableUntil int64
 }
 
 // NewBalancedURIScoringMiddleware returns URI scoring middleware that tracks in-flight requests and recent failures
 // for each URI configured on an HTTP client. URIs are scored based on fewest in-flight requests and recent errors,
 // where client errors are weighted the same as 1/10 of an in-flight request, server errors are weighted as 10
-// in-flight requests, and errors are decayed using exponential decay with a half-life of 30 seconds.
+// in-flight requests, and errors are decayed using exponential decay with a half-life of 30 seconds. URIs which
+// respond 503 with a Retry-After header are scored after every other URI until the duration has passed.
 //
 // This implementation is based on Dialogue's BalancedScoreTracker:
 // https://github.com/palantir/dialogue/blob/develop/dialogue-core/src/main/java/com/palantir/dialogue/core/BalancedScoreTracker.java
 func NewBalancedURIScoringMiddleware(uris []string, nanoClock func() int64) URIScoringMiddleware {
-	uriInfos := make(map[string]uriInfo, len(uris))
+	uriInfos := make(map[string]*uriInfo, len(uris))
 	for _, uri := range uris {
-		uriInfos[uri] = uriInfo{
+		uriInfos[uri] = &uriInfo{
 			recentFailures: NewCourseExponentialDecayReservoir(nanoClock, failureMemory),
 		}
 	}
-	return &balancedScorer{uriInfos}
+	return &balancedScorer{uriInfos: uriInfos, nanoClock: nanoClock}
 }
 
 func (u *balancedScorer) GetURIsInOrderOfIncreasingScore() []string {
 	uris := make([]string, 0, len(u.uriInfos))
 	scores := make(map[string]int32, len(u.uriInfos))
+	now := u.nanoClock()
 	for uri, info := range u.uriInfos {
 		uris = append(uris, uri)
-		scores[uri] = info.computeScore()
+		scores[uri] = info.computeScore(now)
 	}
 	// Pre-shuffle to avoid overloading first URI when no request are in-flight
 	rand.Shuffle(len(uris), func(i, j int) {
@@ -77,6 +119,41 @@ func (u *balancedScorer) GetURIsInOrderOfIncreasingScore() []string {
 	return uris
 }
 
+func (u *balancedScorer) AllURIsUnavailable() bool {
+	if len(u.uriInfos) == 0 {
+		return false
+	}
+	now := u.nanoClock()
+	for _, info := range u.uriInfos {
+		if !info.unavailable(now) {
+			return false
+		}
+	}
+	return true
+}
+
+func (u *balancedScorer) URIStates() []URIState {
+	states := make([]URIState, 0, len(u.uriInfos))
+	now := u.nanoClock()
+	for uri, info := range u.uriInfos {
+		state := URIState{
+			URI:         uri,
+			InFlight:    int(atomic.LoadInt32(&info.inflight)),
+			Unavailable: info.unavailable(now),
+			Requests:    atomic.LoadInt64(&info.requests),
+			Failures:    atomic.LoadInt64(&info.failures),
+		}
+		if lastSuccess := atomic.LoadInt64(&info.lastSuccess); lastSuccess != 0 {
+			state.LastSuccess = time.Unix(0, lastSuccess)
+		}
+		states = append(states, state)
+	}
+	sort.Slice(states, func(i, j int) bool {
+		return states[i].URI < states[j].URI
+	})
+	return states
+}
+
 func (u *balancedScorer) RoundTrip(req *http.Request, next http.RoundTripper) (*http.Response, error) {
 	baseURI := getBaseURI(req.URL)
 	info, foundInfo := u.uriInfos[baseURI]
@@ -88,6 +165,7 @@ func (u *balancedScorer) RoundTrip(req *http.Request, next http.RoundTripper) (*
 	if resp == nil || err != nil {
 		if foundInfo {
 			info.recentFailures.Update(failureWeight)
+			info.recordAttempt(false, 0)
 		}
 		return nil, err
 	}
@@ -95,15 +173,43 @@ func (u *balancedScorer) RoundTrip(req *http.Request, next http.RoundTripper) (*
 		statusCode := resp.StatusCode
 		if isGlobalQosStatus(statusCode) || isServerErrorRange(statusCode) {
 			info.recentFailures.Update(failureWeight)
-		} else if isClientError(statusCode) {
-			info.recentFailures.Update(failureWeight / 100)
+			info.recordAttempt(false, 0)
+			if statusCode == StatusCodeUnavailable {
+				now := u.nanoClock()
+				if retryAfter, ok := httpheaders.ParseRetryAfter(resp.Header.Get(httpheaders.RetryAfter), time.Unix(0, now)); ok && retryAfter > 0 {
+					atomic.StoreInt64(&info.unavailableUntil, now+int64(retryAfter))
+				}
+			}
+		} else {
+			if isClientError(statusCode) {
+				info.recentFailures.Update(failureWeight / 100)
+			}
+			info.recordAttempt(true, u.nanoClock())
 		}
 	}
 	return resp, nil
 }
 
-func (i *uriInfo) computeScore() int32 {
-	return atomic.LoadInt32(&i.inflight) + int32(math.Round(i.recentFailures.Get()))
+func (i *uriInfo) recordAttempt(success bool, now int64) {
+	atomic.AddInt64(&i.requests, 1)
+	if success {
+		atomic.StoreInt64(&i.lastSuccess, now)
+	} else {
+		atomic.AddInt64(&i.failures, 1)
+	}
+}
+
+func (i *uriInfo) computeScore(now int64) int32 {
+	score := atomic.LoadInt32(&i.inflight) + int32(math.Round(i.recentFailures.Get()))
+	if now < atomic.LoadInt64(&i.unavailableUntil) {
+		score += retryAfterScore
+	}
+	return score
+}
+
+// unavailable returns true if the URI has failed recently or asked not to be retried until after now.
+func (i *uriInfo) unavailable(now int64) bool {
+	return i.recentFailures.Get() >= unavailableThreshold || now < atomic.LoadInt64(&i.unavailableUntil)
 }
 
 func getBaseURI(u *url.URL) string {
@@ -17,7 +17,9 @@ package internal
 import (
 	"net/http"
 	"net/http/httptest"
+	"sort"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -56,3 +58,114 @@ func TestBalancedScoring(t *testing.T) {
 	scoredUris := scorer.GetURIsInOrderOfIncreasingScore()
 	assert.Equal(t, []string{server200.URL, server429.URL, server503.URL}, scoredUris)
 }
+
+func TestBalancedScorerAllURIsUnavailable(t *testing.T) {
+	server200 := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusOK)
+	}))
+	defer server200.Close()
+	server503 := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server503.Close()
+	var now int64
+	scorer := NewBalancedURIScoringMiddleware([]string{server200.URL, server503.URL}, func() int64 { return now })
+	tracker := scorer.(URIAvailabilityTracker)
+	roundTrip := func(server *httptest.Server) {
+		req, err := http.NewRequest("GET", server.URL, nil)
+		assert.NoError(t, err)
+		_, err = scorer.RoundTrip(req, server.Client().Transport)
+		assert.NoError(t, err)
+	}
+
+	assert.False(t, tracker.AllURIsUnavailable())
+	roundTrip(server503)
+	roundTrip(server200)
+	assert.False(t, tracker.AllURIsUnavailable(), "successful responses are not failures")
+
+	server200.Config.Handler = server503.Config.Handler
+	roundTrip(server200)
+	assert.True(t, tracker.AllURIsUnavailable())
+
+	now += 2 * failureMemory.Nanoseconds()
+	assert.False(t, tracker.AllURIsUnavailable(), "failures should decay")
+}
+
+func TestBalancedScorerURIStates(t *testing.T) {
+	release := make(chan struct{})
+	received := make(chan struct{})
+	serverBlocking := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		received <- struct{}{}
+		<-release
+		rw.WriteHeader(http.StatusOK)
+	}))
+	defer serverBlocking.Close()
+	server503 := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server503.Close()
+	now := int64(1)
+	scorer := NewBalancedURIScoringMiddleware([]string{serverBlocking.URL, server503.URL}, func() int64 { return now })
+	reporter := scorer.(URIStateReporter)
+	roundTrip := func(server *httptest.Server) {
+		req, err := http.NewRequest("GET", server.URL, nil)
+		assert.NoError(t, err)
+		_, err = scorer.RoundTrip(req, server.Client().Transport)
+		assert.NoError(t, err)
+	}
+
+	roundTrip(server503)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		roundTrip(serverBlocking)
+	}()
+	<-received
+
+	expected := []URIState{
+		{URI: serverBlocking.URL, InFlight: 1},
+		{URI: server503.URL, Unavailable: true, Requests: 1, Failures: 1},
+	}
+	sort.Slice(expected, func(i, j int) bool { return expected[i].URI < expected[j].URI })
+	assert.Equal(t, expected, reporter.URIStates())
+
+	close(release)
+	<-done
+	for _, state := range reporter.URIStates() {
+		assert.Zero(t, state.InFlight, "request to %s should no longer be in flight", state.URI)
+		if state.URI == serverBlocking.URL {
+			assert.Equal(t, int64(1), state.Requests)
+			assert.Zero(t, state.Failures)
+			assert.Equal(t, time.Unix(0, now), state.LastSuccess)
+		}
+	}
+}
+
+func TestBalancedScorerUnavailableRetryAfter(t *testing.T) {
+	server200 := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusOK)
+	}))
+	defer server200.Close()
+	server503 := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("Retry-After", "60")
+		rw.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server503.Close()
+	now := time.Now().UnixNano()
+	scorer := NewBalancedURIScoringMiddleware([]string{server200.URL, server503.URL}, func() int64 { return now })
+	req, err := http.NewRequest("GET", server503.URL, nil)
+	assert.NoError(t, err)
+	_, err = scorer.RoundTrip(req, server503.Client().Transport)
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{server200.URL, server503.URL}, scorer.GetURIsInOrderOfIncreasingScore())
+	for _, state := range scorer.(URIStateReporter).URIStates() {
+		assert.Equal(t, state.URI == server503.URL, state.Unavailable, state.URI)
+	}
+
+	// once the Retry-After duration has passed, only the decayed failure counts against the URI
+	now += int64(10 * time.Minute)
+	for _, state := range scorer.(URIStateReporter).URIStates() {
+		assert.False(t, state.Unavailable, state.URI)
+	}
+}
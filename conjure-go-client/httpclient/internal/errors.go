@@ -15,6 +15,9 @@
 package internal
 
 import (
+	"time"
+
+	"github.com/palantir/conjure-go-runtime/v2/conjure-go-contract/httpheaders"
 	werror "github.com/palantir/witchcraft-go-error"
 )
 
@@ -46,3 +49,17 @@ func LocationFromError(err error) (location string, ok bool) {
 	location, ok = locationI.(string)
 	return location, ok
 }
+
+// RetryAfterFromError retrieves the delay requested by the 'retryAfter' parameter from the provided werror.
+// If the error is not a werror or does not have a valid retryAfter param, ok is false.
+//
+// The default client error decoder sets the retryAfter parameter on its returned errors
+// if the status code is 429 or 503 and a Retry-After header is set in the response.
+func RetryAfterFromError(err error) (retryAfter time.Duration, ok bool) {
+	retryAfterI, _ := werror.ParamFromError(err, "retryAfter")
+	value, ok := retryAfterI.(string)
+	if !ok {
+		return 0, false
+	}
+	return httpheaders.ParseRetryAfter(value, time.Now())
+}
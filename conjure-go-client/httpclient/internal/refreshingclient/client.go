@@ -21,6 +21,8 @@ import (
 	"github.com/palantir/pkg/refreshable"
 )
 
+// RefreshableHTTPClient is exported as httpclient.RefreshableHTTPClient, so it embeds the v1 refreshable interface
+// while the rest of this package uses typed v2 refreshables.
 type RefreshableHTTPClient interface {
 	refreshable.Refreshable
 	CurrentHTTPClient() *http.Client
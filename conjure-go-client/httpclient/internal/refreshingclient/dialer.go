@@ -20,7 +20,7 @@ import (
 	"net/url"
 	"time"
 
-	"github.com/palantir/pkg/refreshable"
+	"github.com/palantir/pkg/refreshable/v2"
 	"github.com/palantir/witchcraft-go-logging/wlog/svclog/svc1log"
 	"golang.org/x/net/proxy"
 )
@@ -28,7 +28,7 @@ import (
 type DialerParams struct {
 	DialTimeout   time.Duration
 	KeepAlive     time.Duration
-	SocksProxyURL *url.URL `refreshables:",exclude"`
+	SocksProxyURL *url.URL
 }
 
 // ContextDialer is the interface implemented by net.Dialer, proxy.Dialer, and others
@@ -36,40 +36,38 @@ type ContextDialer interface {
 	DialContext(ctx context.Context, network, address string) (net.Conn, error)
 }
 
-func NewRefreshableDialer(ctx context.Context, p RefreshableDialerParams) ContextDialer {
-	return &RefreshableDialer{
-		Refreshable: p.MapDialerParams(func(p DialerParams) interface{} {
-			svc1log.FromContext(ctx).Debug("Reconstructing HTTP Dialer")
-			dialer := &net.Dialer{
-				Timeout:   p.DialTimeout,
-				KeepAlive: p.KeepAlive,
-			}
-			if p.SocksProxyURL == nil {
-				return dialer
-			}
-			proxyDialer, err := proxy.FromURL(p.SocksProxyURL, dialer)
-			if err != nil {
-				// should never happen; checked in the validating refreshable
-				svc1log.FromContext(ctx).Error("Failed to construct socks5 dialer. Please report this as a bug in conjure-go-runtime.", svc1log.Stacktrace(err))
-				return dialer
-			}
-			return proxyDialer
-		}),
-	}
+func NewRefreshableDialer(ctx context.Context, p refreshable.Refreshable[DialerParams]) ContextDialer {
+	dialer, _ := refreshable.Map(p, func(p DialerParams) ContextDialer {
+		svc1log.FromContext(ctx).Debug("Reconstructing HTTP Dialer")
+		dialer := &net.Dialer{
+			Timeout:   p.DialTimeout,
+			KeepAlive: p.KeepAlive,
+		}
+		if p.SocksProxyURL == nil {
+			return dialer
+		}
+		proxyDialer, err := proxy.FromURL(p.SocksProxyURL, dialer)
+		if err != nil {
+			// should never happen; checked in the validating refreshable
+			svc1log.FromContext(ctx).Error("Failed to construct socks5 dialer. Please report this as a bug in conjure-go-runtime.", svc1log.Stacktrace(err))
+			return dialer
+		}
+		return proxyDialer.(ContextDialer)
+	})
+	return &RefreshableDialer{Refreshable: dialer}
 }
 
 // ConfigureDialer accepts a mapping function which will be applied to the params value as it is evaluated.
 // This can be used to layer/overwrite configuration before building the RefreshableDialer.
-func ConfigureDialer(r RefreshableDialerParams, mapFn func(p DialerParams) DialerParams) RefreshableDialerParams {
-	return NewRefreshingDialerParams(r.MapDialerParams(func(params DialerParams) interface{} {
-		return mapFn(params)
-	}))
+func ConfigureDialer(r refreshable.Refreshable[DialerParams], mapFn func(p DialerParams) DialerParams) refreshable.Refreshable[DialerParams] {
+	configured, _ := refreshable.Map(r, mapFn)
+	return configured
 }
 
 type RefreshableDialer struct {
-	refreshable.Refreshable // contains ContextDialer
+	refreshable.Refreshable[ContextDialer]
 }
 
 func (r *RefreshableDialer) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
-	return r.Current().(ContextDialer).DialContext(ctx, network, address)
+	return r.Current().DialContext(ctx, network, address)
 }
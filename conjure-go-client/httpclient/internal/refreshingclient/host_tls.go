@@ -0,0 +1,138 @@
+// Copyright (c) 2026 Palantir Technologies. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package refreshingclient
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"strings"
+	"time"
+
+	"github.com/palantir/pkg/refreshable/v2"
+	"github.com/palantir/witchcraft-go-logging/wlog/svclog/svc1log"
+)
+
+// HostTLSParams maps destination addresses, written as lowercase "host:port", to the TLSParams used for connections
+// to them instead of the transport's TLS configuration.
+type HostTLSParams map[string]TLSParams
+
+// hostTLSConfigs holds a refreshable TLS config for each destination of a HostTLSParams, built like the transport's
+// TLS config by NewRefreshableTLSConfig, so that the files of the overrides are also checked for changes at their
+// RefreshInterval.
+type hostTLSConfigs struct {
+	params    HostTLSParams
+	providers map[string]TLSProvider
+	// stop stops checking the files of the overrides.
+	stop context.CancelFunc
+}
+
+// newHostTLSConfigs returns the TLS configs of hostTLS. onFilesChanged is called when the files of an override change
+// until stop is called. Overrides which can not be built are logged and the transport's configuration is used.
+func newHostTLSConfigs(ctx context.Context, hostTLS HostTLSParams, onFilesChanged func()) *hostTLSConfigs {
+	ctx, stop := context.WithCancel(ctx)
+	configs := &hostTLSConfigs{params: hostTLS, providers: make(map[string]TLSProvider, len(hostTLS)), stop: stop}
+	for addr, params := range hostTLS {
+		provider, err := NewRefreshableTLSConfig(ctx, refreshable.New(params))
+		if err != nil {
+			svc1log.FromContext(ctx).Error("Invalid TLS config for host. Using the client's TLS config.",
+				svc1log.SafeParam("address", addr), svc1log.Stacktrace(err))
+			continue
+		}
+		if subscribable, ok := provider.(SubscribableTLSProvider); ok {
+			subscribeToUpdates(subscribable.SubscribeToTLSConfig, func(*tls.Config) {
+				if ctx.Err() == nil {
+					onFilesChanged()
+				}
+			})
+		}
+		configs.providers[addr] = provider
+	}
+	return configs
+}
+
+// tlsConfigs returns the current TLS config of each destination.
+func (c *hostTLSConfigs) tlsConfigs(ctx context.Context) map[string]*tls.Config {
+	configs := make(map[string]*tls.Config, len(c.providers))
+	for addr, provider := range c.providers {
+		configs[addr] = provider.GetTLSConfig(ctx)
+	}
+	return configs
+}
+
+// newHostTLSDialer returns a function suitable for http.Transport.DialTLSContext which dials addr with dialer and
+// performs the TLS handshake using the configuration in configs for addr, if any, or the transport's TLSClientConfig
+// otherwise.
+//
+// The transport does not use DialTLSContext for requests tunneled through an HTTP proxy, so those use the transport's
+// configuration regardless of the overrides.
+func newHostTLSDialer(transport *http.Transport, configs map[string]*tls.Config, dialer ContextDialer) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		conn, err := dialer.DialContext(ctx, network, addr)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig, ok := configs[strings.ToLower(addr)]
+		if ok {
+			tlsConfig = tlsConfig.Clone()
+			if transport.TLSClientConfig != nil {
+				// NextProtos is set on the transport's config when HTTP/2 is configured.
+				tlsConfig.NextProtos = transport.TLSClientConfig.NextProtos
+			}
+		} else if transport.TLSClientConfig != nil {
+			tlsConfig = transport.TLSClientConfig.Clone()
+		} else {
+			tlsConfig = &tls.Config{}
+		}
+		if tlsConfig.ServerName == "" {
+			host, _, err := net.SplitHostPort(addr)
+			if err != nil {
+				host = addr
+			}
+			tlsConfig.ServerName = host
+		}
+		tlsConn, err := tlsHandshake(ctx, conn, tlsConfig, transport.TLSHandshakeTimeout)
+		if err != nil {
+			_ = conn.Close()
+			return nil, err
+		}
+		return tlsConn, nil
+	}
+}
+
+// tlsHandshake performs the client handshake on conn, reporting it to the request's httptrace.ClientTrace like the
+// transport does for the connections it upgrades itself.
+func tlsHandshake(ctx context.Context, conn net.Conn, tlsConfig *tls.Config, timeout time.Duration) (*tls.Conn, error) {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	trace := httptrace.ContextClientTrace(ctx)
+	if trace != nil && trace.TLSHandshakeStart != nil {
+		trace.TLSHandshakeStart()
+	}
+	tlsConn := tls.Client(conn, tlsConfig)
+	err := tlsConn.HandshakeContext(ctx)
+	if trace != nil && trace.TLSHandshakeDone != nil {
+		trace.TLSHandshakeDone(tlsConn.ConnectionState(), err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return tlsConn, nil
+}
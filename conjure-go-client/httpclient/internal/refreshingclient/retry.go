@@ -15,28 +15,24 @@
 package refreshingclient
 
 import (
-	"context"
 	"time"
 
-	"github.com/palantir/pkg/retry"
+	"github.com/palantir/pkg/refreshable/v2"
 )
 
 type RetryParams struct {
 	InitialBackoff time.Duration
 	MaxBackoff     time.Duration
+	// RetryBudgetRatio, if positive, is the number of retries allowed for each request sent by the client over a
+	// sliding window.
+	RetryBudgetRatio float64
+	// MinRetriesPerSecond is the number of retries allowed per second regardless of RetryBudgetRatio.
+	MinRetriesPerSecond int
 }
 
 // ConfigureRetry accepts a mapping function which will be applied to the params value as it is evaluated.
-// This can be used to layer/overwrite configuration before building the RefreshableRetryParams.
-func ConfigureRetry(r RefreshableRetryParams, mapFn func(p RetryParams) RetryParams) RefreshableRetryParams {
-	return NewRefreshingRetryParams(r.MapRetryParams(func(params RetryParams) interface{} {
-		return mapFn(params)
-	}))
-}
-
-func (r RetryParams) Start(ctx context.Context) retry.Retrier {
-	return retry.Start(ctx,
-		retry.WithInitialBackoff(r.InitialBackoff),
-		retry.WithMaxBackoff(r.MaxBackoff),
-	)
+// This can be used to layer/overwrite configuration before the params are used by the client.
+func ConfigureRetry(r refreshable.Refreshable[RetryParams], mapFn func(p RetryParams) RetryParams) refreshable.Refreshable[RetryParams] {
+	configured, _ := refreshable.Map(r, mapFn)
+	return configured
 }
@@ -0,0 +1,130 @@
+// Copyright (c) 2026 Palantir Technologies. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package refreshingclient
+
+import (
+	"context"
+	"crypto/sha256"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/palantir/pkg/refreshable/v2"
+	"github.com/palantir/witchcraft-go-logging/wlog/svclog/svc1log"
+)
+
+// tlsFiles is the value of a tlsFilesWatcher: the TLSParams and a generation which is incremented each time the
+// contents of their files change.
+type tlsFiles struct {
+	params     TLSParams
+	generation int
+}
+
+// tlsFilesWatcher updates files when either the TLSParams or the contents of their files change. The files are
+// polled by a goroutine which is started the first time the RefreshInterval is positive and runs until ctx is done.
+type tlsFilesWatcher struct {
+	ctx    context.Context
+	params refreshable.Refreshable[TLSParams]
+	files  refreshable.Updatable[tlsFiles]
+
+	mu sync.Mutex
+	// fingerprint is the hash of the contents of the files when they were last read.
+	fingerprint [sha256.Size]byte
+	start       sync.Once
+}
+
+func newTLSFilesWatcher(ctx context.Context, params refreshable.Refreshable[TLSParams]) *tlsFilesWatcher {
+	w := &tlsFilesWatcher{
+		ctx:    ctx,
+		params: params,
+		files:  refreshable.New(tlsFiles{params: params.Current()}),
+	}
+	// called with the current params, then on every update.
+	params.Subscribe(func(p TLSParams) {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		// the files may have changed along with the params, so they are read again.
+		w.fingerprint, _ = fingerprintTLSFiles(p)
+		w.files.Update(tlsFiles{params: p, generation: w.files.Current().generation})
+		w.startIfEnabled(p)
+	})
+	return w
+}
+
+func (w *tlsFilesWatcher) startIfEnabled(p TLSParams) {
+	if p.RefreshInterval <= 0 {
+		return
+	}
+	w.start.Do(func() {
+		go w.poll()
+	})
+}
+
+func (w *tlsFilesWatcher) poll() {
+	for {
+		interval := w.params.Current().RefreshInterval
+		if interval <= 0 {
+			// polling was disabled; check again later in case it is enabled again.
+			interval = time.Minute
+		}
+		timer := time.NewTimer(interval)
+		select {
+		case <-w.ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		if w.params.Current().RefreshInterval > 0 {
+			w.check()
+		}
+	}
+}
+
+// check reads the files and updates files if their contents changed since they were last read.
+func (w *tlsFilesWatcher) check() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	current := w.files.Current()
+	fingerprint, err := fingerprintTLSFiles(current.params)
+	if err != nil {
+		svc1log.FromContext(w.ctx).Warn("Failed to read TLS files. Using previous value.", svc1log.Stacktrace(err))
+		return
+	}
+	if fingerprint == w.fingerprint {
+		return
+	}
+	w.fingerprint = fingerprint
+	svc1log.FromContext(w.ctx).Info("TLS files changed. Reloading TLS config.")
+	w.files.Update(tlsFiles{params: current.params, generation: current.generation + 1})
+}
+
+// fingerprintTLSFiles returns a hash of the contents of the CA, certificate and key files of p.
+func fingerprintTLSFiles(p TLSParams) ([sha256.Size]byte, error) {
+	hash := sha256.New()
+	for _, file := range append(append([]string{}, p.CAFiles...), p.CertFile, p.KeyFile) {
+		if file == "" {
+			continue
+		}
+		contents, err := os.ReadFile(file)
+		if err != nil {
+			return [sha256.Size]byte{}, err
+		}
+		contentsHash := sha256.Sum256(contents)
+		_, _ = hash.Write(contentsHash[:])
+	}
+	var fingerprint [sha256.Size]byte
+	copy(fingerprint[:], hash.Sum(nil))
+	return fingerprint, nil
+}
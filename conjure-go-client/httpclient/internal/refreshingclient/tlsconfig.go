@@ -17,8 +17,10 @@ package refreshingclient
 import (
 	"context"
 	"crypto/tls"
+	"crypto/x509"
+	"time"
 
-	"github.com/palantir/pkg/refreshable"
+	"github.com/palantir/pkg/refreshable/v2"
 	"github.com/palantir/pkg/tlsconfig"
 	werror "github.com/palantir/witchcraft-go-error"
 	"github.com/palantir/witchcraft-go-logging/wlog/svclog/svc1log"
@@ -27,10 +29,20 @@ import (
 // TLSParams contains the parameters needed to build a *tls.Config.
 // Its fields must all be compatible with reflect.DeepEqual.
 type TLSParams struct {
-	CAFiles            []string
-	CertFile           string
-	KeyFile            string
+	CAFiles  []string
+	CertFile string
+	KeyFile  string
+	// CAPEM, CertPEM and KeyPEM are PEM-encoded CA certificates, client certificate and client key. The CA
+	// certificates are trusted in addition to those in CAFiles, and the client certificate is used instead of CertFile
+	// and KeyFile if both CertPEM and KeyPEM are set.
+	CAPEM              string
+	CertPEM            string
+	KeyPEM             string
 	InsecureSkipVerify bool
+	// ServerName, if set, is used to verify the server's certificate and as the SNI value instead of the host.
+	ServerName string
+	// RefreshInterval, if positive, is the interval at which the contents of the files are checked for changes.
+	RefreshInterval time.Duration
 }
 
 type TLSProvider interface {
@@ -49,43 +61,89 @@ func (p *StaticTLSConfigProvider) GetTLSConfig(context.Context) *tls.Config {
 }
 
 type RefreshableTLSConfig struct {
-	r *refreshable.ValidatingRefreshable // contains *tls.Config
+	r refreshable.Validated[*tls.Config]
 }
 
-// NewRefreshableTLSConfig evaluates the provided TLSParams and returns a RefreshableTLSConfig that will update the
+// NewRefreshableTLSConfig evaluates the provided TLSParams and returns a TLSProvider that will update the
 // underlying *tls.Config when the TLSParams change.
 // IF the initial TLSParams are invalid, NewRefreshableTLSConfig will return an error.
-// If the updated TLSParams are invalid, the RefreshableTLSConfig will continue to use the previous value and log the error.
+// If the updated TLSParams are invalid, the provider will continue to use the previous value and log the error.
 //
-// N.B. This subscription only fires when the paths are updated, not when the contents of the files are updated.
-// We could consider adding a file refreshable to watch the key and cert files.
-func NewRefreshableTLSConfig(ctx context.Context, params RefreshableTLSParams) (TLSProvider, error) {
-	r, err := refreshable.NewMapValidatingRefreshable(params, func(i interface{}) (interface{}, error) {
-		return NewTLSConfig(ctx, i.(TLSParams))
+// While the RefreshInterval of the TLSParams is positive, the contents of the CA, certificate and key files are also
+// checked at that interval until ctx is done, and the *tls.Config is rebuilt when they change, so that certificates
+// rotated on disk are used without a configuration change. The returned provider is a SubscribableTLSProvider, so
+// transports using it are rebuilt when the files change.
+func NewRefreshableTLSConfig(ctx context.Context, params refreshable.Refreshable[TLSParams]) (TLSProvider, error) {
+	watcher := newTLSFilesWatcher(ctx, params)
+	r, _, err := refreshable.MapWithError(watcher.files, func(files tlsFiles) (*tls.Config, error) {
+		return NewTLSConfig(ctx, files.params)
 	})
 	if err != nil {
 		return nil, werror.WrapWithContextParams(ctx, err, "failed to build RefreshableTLSConfig")
 	}
-	return RefreshableTLSConfig{r: r}, nil
+	return SubscribableTLSConfig{RefreshableTLSConfig: RefreshableTLSConfig{r: r}}, nil
 }
 
 // GetTLSConfig returns the most recent valid *tls.Config.
 // If the last refreshable update resulted in an error, that error is logged and
 // the previous value is returned.
 func (r RefreshableTLSConfig) GetTLSConfig(ctx context.Context) *tls.Config {
-	if err := r.r.LastValidateErr(); err != nil {
+	if _, err := r.r.Validation(); err != nil {
 		svc1log.FromContext(ctx).Warn("Invalid TLS config. Using previous value.", svc1log.Stacktrace(err))
 	}
-	return r.r.Current().(*tls.Config)
+	return r.r.Current()
+}
+
+// SubscribableTLSProvider is a TLSProvider whose *tls.Config may change independently of the TransportParams.
+// Transports using it are rebuilt when it is updated.
+type SubscribableTLSProvider interface {
+	TLSProvider
+	// SubscribeToTLSConfig calls consumer with the current config and then whenever the config is updated.
+	SubscribeToTLSConfig(consumer func(*tls.Config)) refreshable.UnsubscribeFunc
+}
+
+// SubscribableTLSConfig is a SubscribableTLSProvider backed by a refreshable provided by the application.
+type SubscribableTLSConfig struct {
+	RefreshableTLSConfig
+}
+
+// NewSubscribableTLSConfig returns a SubscribableTLSConfig which applies mapFn to each value of r.
+// If the initial value is invalid, NewSubscribableTLSConfig will return an error.
+// If an updated value is invalid, the SubscribableTLSConfig will continue to use the previous value and log the error.
+func NewSubscribableTLSConfig[T any](ctx context.Context, r refreshable.Refreshable[T], mapFn func(T) (*tls.Config, error)) (SubscribableTLSConfig, error) {
+	validating, _, err := refreshable.MapWithError(r, mapFn)
+	if err != nil {
+		return SubscribableTLSConfig{}, werror.WrapWithContextParams(ctx, err, "failed to build RefreshableTLSConfig")
+	}
+	return SubscribableTLSConfig{RefreshableTLSConfig: RefreshableTLSConfig{r: validating}}, nil
+}
+
+func (s SubscribableTLSConfig) SubscribeToTLSConfig(consumer func(*tls.Config)) refreshable.UnsubscribeFunc {
+	return s.r.Subscribe(consumer)
 }
 
 // NewTLSConfig returns a *tls.Config built from the provided TLSParams.
 func NewTLSConfig(ctx context.Context, p TLSParams) (*tls.Config, error) {
 	var tlsParams []tlsconfig.ClientParam
-	if len(p.CAFiles) != 0 {
+	if p.CAPEM != "" {
+		certPool, err := tlsconfig.CertPoolFromCAFiles(p.CAFiles...)()
+		if err != nil {
+			return nil, werror.WrapWithContextParams(ctx, err, "failed to build tlsConfig")
+		}
+		if !certPool.AppendCertsFromPEM([]byte(p.CAPEM)) {
+			return nil, werror.ErrorWithContextParams(ctx, "failed to build tlsConfig: no certificates detected in CA PEM")
+		}
+		tlsParams = append(tlsParams, tlsconfig.ClientRootCAs(func() (*x509.CertPool, error) { return certPool, nil }))
+	} else if len(p.CAFiles) != 0 {
 		tlsParams = append(tlsParams, tlsconfig.ClientRootCAFiles(p.CAFiles...))
 	}
-	if p.CertFile != "" && p.KeyFile != "" {
+	if p.CertPEM != "" && p.KeyPEM != "" {
+		cert, err := tls.X509KeyPair([]byte(p.CertPEM), []byte(p.KeyPEM))
+		if err != nil {
+			return nil, werror.WrapWithContextParams(ctx, err, "failed to build tlsConfig: invalid certificate or key PEM")
+		}
+		tlsParams = append(tlsParams, tlsconfig.ClientKeyPair(func() (tls.Certificate, error) { return cert, nil }))
+	} else if p.CertFile != "" && p.KeyFile != "" {
 		tlsParams = append(tlsParams, tlsconfig.ClientKeyPairFiles(p.CertFile, p.KeyFile))
 	}
 	if p.InsecureSkipVerify {
@@ -95,5 +153,6 @@ func NewTLSConfig(ctx context.Context, p TLSParams) (*tls.Config, error) {
 	if err != nil {
 		return nil, werror.WrapWithContextParams(ctx, err, "failed to build tlsConfig")
 	}
+	tlsConfig.ServerName = p.ServerName
 	return tlsConfig, nil
 }
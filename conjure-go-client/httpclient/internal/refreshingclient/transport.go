@@ -16,11 +16,15 @@ package refreshingclient
 
 import (
 	"context"
+	"crypto/tls"
 	"net/http"
 	"net/url"
+	"reflect"
+	"sync"
+	"sync/atomic"
 	"time"
 
-	"github.com/palantir/pkg/refreshable"
+	"github.com/palantir/pkg/refreshable/v2"
 	"github.com/palantir/witchcraft-go-logging/wlog/svclog/svc1log"
 	"golang.org/x/net/http2"
 )
@@ -34,41 +38,150 @@ type TransportParams struct {
 	ExpectContinueTimeout time.Duration
 	ResponseHeaderTimeout time.Duration
 	TLSHandshakeTimeout   time.Duration
-	HTTPProxyURL          *url.URL `refreshables:",exclude"`
+	HTTPProxyURL          *url.URL
 	ProxyFromEnvironment  bool
 	HTTP2ReadIdleTimeout  time.Duration
 	HTTP2PingTimeout      time.Duration
 
 	TLS TLSParams
+	// HostTLS overrides TLS for connections to specific destinations.
+	HostTLS HostTLSParams
 }
 
-func NewRefreshableTransport(ctx context.Context, p RefreshableTransportParams, tlsProvider TLSProvider, dialer ContextDialer) http.RoundTripper {
-	return &RefreshableTransport{
-		Refreshable: p.MapTransportParams(func(p TransportParams) interface{} {
-			return newTransport(ctx, p, tlsProvider, dialer)
-		}),
+func NewRefreshableTransport(ctx context.Context, p refreshable.Refreshable[TransportParams], tlsProvider TLSProvider, dialer ContextDialer) http.RoundTripper {
+	b := &transportBuilder{ctx: ctx, params: p, tlsProvider: tlsProvider, dialer: dialer}
+	b.current = newTransportRefreshable(b.build(p.Current()))
+	// rebuild the transport when either the params or the tls config are updated
+	subscribeToUpdates(p.Subscribe, func(params TransportParams) {
+		b.rebuild(params)
+	})
+	if subscribable, ok := tlsProvider.(SubscribableTLSProvider); ok {
+		subscribeToUpdates(subscribable.SubscribeToTLSConfig, func(*tls.Config) {
+			b.rebuild(p.Current())
+		})
+	}
+	return &RefreshableTransport{Refreshable: b.current}
+}
+
+// transportBuilder builds the transports of a RefreshableTransport.
+type transportBuilder struct {
+	ctx         context.Context
+	params      refreshable.Refreshable[TransportParams]
+	tlsProvider TLSProvider
+	dialer      ContextDialer
+	current     *transportRefreshable
+
+	// mu serializes rebuilds so that the most recently built transport is current, and protects hostTLS.
+	mu sync.Mutex
+	// hostTLS holds the TLS configs of the HostTLS of the most recent params. The transport is also rebuilt when their
+	// files change.
+	hostTLS *hostTLSConfigs
+}
+
+func (b *transportBuilder) rebuild(params TransportParams) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.current.update(b.build(params))
+}
+
+// build must be called with mu held, unless the transport has not been returned yet.
+func (b *transportBuilder) build(params TransportParams) *http.Transport {
+	if b.hostTLS == nil || !reflect.DeepEqual(b.hostTLS.params, params.HostTLS) {
+		if b.hostTLS != nil {
+			b.hostTLS.stop()
+		}
+		b.hostTLS = newHostTLSConfigs(b.ctx, params.HostTLS, func() {
+			b.rebuild(b.params.Current())
+		})
+	}
+	return newTransport(b.ctx, params, b.tlsProvider, b.hostTLS.tlsConfigs(b.ctx), b.dialer)
+}
+
+// subscribeToUpdates subscribes consumer using subscribe, which calls its consumer with the current value and then
+// with every update, but only calls consumer with the updates.
+func subscribeToUpdates[T any](subscribe func(func(T)) refreshable.UnsubscribeFunc, consumer func(T)) refreshable.UnsubscribeFunc {
+	// subscribers are called with the lock of the refreshable held, so subscribed is not accessed concurrently.
+	subscribed := false
+	return subscribe(func(v T) {
+		if !subscribed {
+			subscribed = true
+			return
+		}
+		consumer(v)
+	})
+}
+
+// transportRefreshable holds the most recently built *http.Transport. Unlike the refreshables returned by
+// refreshable.New and refreshable.Map, it does not compare the new value to the current one on update: every
+// transport it is given is freshly built, and comparing a transport which is serving requests would read its
+// connection state concurrently with its use.
+type transportRefreshable struct {
+	current atomic.Pointer[http.Transport]
+
+	mu          sync.Mutex // protects subscribers
+	subscribers []*func(*http.Transport)
+}
+
+func newTransportRefreshable(transport *http.Transport) *transportRefreshable {
+	r := &transportRefreshable{}
+	r.current.Store(transport)
+	return r
+}
+
+func (r *transportRefreshable) Current() *http.Transport {
+	return r.current.Load()
+}
+
+func (r *transportRefreshable) Subscribe(consumer func(*http.Transport)) refreshable.UnsubscribeFunc {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	consumerPtr := &consumer
+	r.subscribers = append(r.subscribers, consumerPtr)
+	consumer(r.Current())
+	return func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		for i, sub := range r.subscribers {
+			if sub == consumerPtr {
+				r.subscribers = append(r.subscribers[:i], r.subscribers[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+func (r *transportRefreshable) update(transport *http.Transport) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.current.Store(transport)
+	for _, sub := range r.subscribers {
+		(*sub)(transport)
 	}
 }
 
 // ConfigureTransport accepts a mapping function which will be applied to the params value as it is evaluated.
-// This can be used to layer/overwrite configuration before building the RefreshableTransportParams.
-func ConfigureTransport(r RefreshableTransportParams, mapFn func(p TransportParams) TransportParams) RefreshableTransportParams {
-	return NewRefreshingTransportParams(r.MapTransportParams(func(params TransportParams) interface{} {
-		return mapFn(params)
-	}))
+// This can be used to layer/overwrite configuration before building the RefreshableTransport.
+func ConfigureTransport(r refreshable.Refreshable[TransportParams], mapFn func(p TransportParams) TransportParams) refreshable.Refreshable[TransportParams] {
+	configured, _ := refreshable.Map(r, mapFn)
+	return configured
 }
 
 // RefreshableTransport implements http.RoundTripper backed by a refreshable *http.Transport.
 // The transport and internal dialer are each rebuilt when any of their respective parameters are updated.
 type RefreshableTransport struct {
-	refreshable.Refreshable // contains *http.Transport
+	refreshable.Refreshable[*http.Transport]
+}
+
+// CloseIdleConnections closes the idle connections of the current transport.
+func (r *RefreshableTransport) CloseIdleConnections() {
+	r.Current().CloseIdleConnections()
 }
 
 func (r *RefreshableTransport) RoundTrip(req *http.Request) (*http.Response, error) {
-	return r.Current().(*http.Transport).RoundTrip(req)
+	return r.Current().RoundTrip(req)
 }
 
-func newTransport(ctx context.Context, p TransportParams, tlsProvider TLSProvider, dialer ContextDialer) *http.Transport {
+func newTransport(ctx context.Context, p TransportParams, tlsProvider TLSProvider, hostTLSConfigs map[string]*tls.Config, dialer ContextDialer) *http.Transport {
 	svc1log.FromContext(ctx).Debug("Reconstructing HTTP Transport")
 
 	var transportProxy func(*http.Request) (*url.URL, error)
@@ -92,6 +205,10 @@ func newTransport(ctx context.Context, p TransportParams, tlsProvider TLSProvide
 		ResponseHeaderTimeout: p.ResponseHeaderTimeout,
 	}
 
+	if len(hostTLSConfigs) > 0 {
+		transport.DialTLSContext = newHostTLSDialer(transport, hostTLSConfigs, dialer)
+	}
+
 	if !p.DisableHTTP2 {
 		// Attempt to configure net/http HTTP/1 Transport to use HTTP/2.
 		http2Transport, err := http2.ConfigureTransports(transport)
@@ -25,17 +25,44 @@ import (
 // so unnecessary updates are not pushed to subscribers.
 // Values are generally known to be "valid" to minimize downstream error handling.
 type ValidatedClientParams struct {
-	APIToken       *string
-	BasicAuth      *BasicAuth
-	Dialer         DialerParams
-	DisableMetrics bool
-	MaxAttempts    *int
-	MetricsTags    metrics.Tags
-	Retry          RetryParams
-	ServiceName    string
-	Timeout        time.Duration
-	Transport      TransportParams
-	URIs           []string
+	APIToken                    *string
+	BasicAuth                   *BasicAuth
+	Dialer                      DialerParams
+	DisableMetrics              bool
+	EnableCookies               bool
+	EndpointMaxRetries          EndpointMaxRetries
+	EndpointTimeouts            EndpointTimeouts
+	LatencyBudgets              LatencyBudgets
+	MaxAttempts                 *int
+	MetricsTags                 metrics.Tags
+	RequestCompressionThreshold int
+	Retry                       RetryParams
+	ServiceName                 string
+	StaticHeaders               StaticHeaders
+	Timeout                     time.Duration
+	Transport                   TransportParams
+	URIGroups                   []URIGroup
+	URIs                        []string
+}
+
+// EndpointMaxRetries maps RPC method names to the maximum number of retries of a request to that endpoint.
+type EndpointMaxRetries map[string]int
+
+// EndpointTimeouts maps RPC method names to the timeout of each attempt of a request to that endpoint.
+type EndpointTimeouts map[string]time.Duration
+
+// LatencyBudgets maps RPC method names to the maximum duration a request to that endpoint is expected to take.
+type LatencyBudgets map[string]time.Duration
+
+// StaticHeaders maps canonical header names to the values set on every request.
+type StaticHeaders map[string]string
+
+// URIGroup is a named subset of a client's URIs which receives a percentage of the requests sent to its priority level.
+type URIGroup struct {
+	Name       string
+	URIs       []string
+	Percentage int
+	Priority   int
 }
 
 // BasicAuth represents the configuration for HTTP Basic Authorization
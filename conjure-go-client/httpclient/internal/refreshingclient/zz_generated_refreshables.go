@@ -1,570 +0,0 @@
-// Generated by godel-refreshable-plugin: do not edit.
-
-package refreshingclient
-
-import (
-	metrics "github.com/palantir/pkg/metrics"
-	refreshable "github.com/palantir/pkg/refreshable"
-)
-
-type RefreshableValidatedClientParams interface {
-	refreshable.Refreshable
-	CurrentValidatedClientParams() ValidatedClientParams
-	MapValidatedClientParams(func(ValidatedClientParams) interface{}) refreshable.Refreshable
-	SubscribeToValidatedClientParams(func(ValidatedClientParams)) (unsubscribe func())
-
-	APIToken() refreshable.StringPtr
-	BasicAuth() RefreshableBasicAuthPtr
-	Dialer() RefreshableDialerParams
-	DisableMetrics() refreshable.Bool
-	MaxAttempts() refreshable.IntPtr
-	MetricsTags() RefreshableTags
-	Retry() RefreshableRetryParams
-	ServiceName() refreshable.String
-	Timeout() refreshable.Duration
-	Transport() RefreshableTransportParams
-	URIs() refreshable.StringSlice
-}
-
-type RefreshingValidatedClientParams struct {
-	refreshable.Refreshable
-}
-
-func NewRefreshingValidatedClientParams(in refreshable.Refreshable) RefreshingValidatedClientParams {
-	return RefreshingValidatedClientParams{Refreshable: in}
-}
-
-func (r RefreshingValidatedClientParams) CurrentValidatedClientParams() ValidatedClientParams {
-	return r.Current().(ValidatedClientParams)
-}
-
-func (r RefreshingValidatedClientParams) MapValidatedClientParams(mapFn func(ValidatedClientParams) interface{}) refreshable.Refreshable {
-	return r.Map(func(i interface{}) interface{} {
-		return mapFn(i.(ValidatedClientParams))
-	})
-}
-
-func (r RefreshingValidatedClientParams) SubscribeToValidatedClientParams(consumer func(ValidatedClientParams)) (unsubscribe func()) {
-	return r.Subscribe(func(i interface{}) {
-		consumer(i.(ValidatedClientParams))
-	})
-}
-
-func (r RefreshingValidatedClientParams) APIToken() refreshable.StringPtr {
-	return refreshable.NewStringPtr(r.MapValidatedClientParams(func(i ValidatedClientParams) interface{} {
-		return i.APIToken
-	}))
-}
-
-func (r RefreshingValidatedClientParams) BasicAuth() RefreshableBasicAuthPtr {
-	return NewRefreshingBasicAuthPtr(r.MapValidatedClientParams(func(i ValidatedClientParams) interface{} {
-		return i.BasicAuth
-	}))
-}
-
-func (r RefreshingValidatedClientParams) Dialer() RefreshableDialerParams {
-	return NewRefreshingDialerParams(r.MapValidatedClientParams(func(i ValidatedClientParams) interface{} {
-		return i.Dialer
-	}))
-}
-
-func (r RefreshingValidatedClientParams) DisableMetrics() refreshable.Bool {
-	return refreshable.NewBool(r.MapValidatedClientParams(func(i ValidatedClientParams) interface{} {
-		return i.DisableMetrics
-	}))
-}
-
-func (r RefreshingValidatedClientParams) MaxAttempts() refreshable.IntPtr {
-	return refreshable.NewIntPtr(r.MapValidatedClientParams(func(i ValidatedClientParams) interface{} {
-		return i.MaxAttempts
-	}))
-}
-
-func (r RefreshingValidatedClientParams) MetricsTags() RefreshableTags {
-	return NewRefreshingTags(r.MapValidatedClientParams(func(i ValidatedClientParams) interface{} {
-		return i.MetricsTags
-	}))
-}
-
-func (r RefreshingValidatedClientParams) Retry() RefreshableRetryParams {
-	return NewRefreshingRetryParams(r.MapValidatedClientParams(func(i ValidatedClientParams) interface{} {
-		return i.Retry
-	}))
-}
-
-func (r RefreshingValidatedClientParams) ServiceName() refreshable.String {
-	return refreshable.NewString(r.MapValidatedClientParams(func(i ValidatedClientParams) interface{} {
-		return i.ServiceName
-	}))
-}
-
-func (r RefreshingValidatedClientParams) Timeout() refreshable.Duration {
-	return refreshable.NewDuration(r.MapValidatedClientParams(func(i ValidatedClientParams) interface{} {
-		return i.Timeout
-	}))
-}
-
-func (r RefreshingValidatedClientParams) Transport() RefreshableTransportParams {
-	return NewRefreshingTransportParams(r.MapValidatedClientParams(func(i ValidatedClientParams) interface{} {
-		return i.Transport
-	}))
-}
-
-func (r RefreshingValidatedClientParams) URIs() refreshable.StringSlice {
-	return refreshable.NewStringSlice(r.MapValidatedClientParams(func(i ValidatedClientParams) interface{} {
-		return i.URIs
-	}))
-}
-
-type RefreshableBasicAuthPtr interface {
-	refreshable.Refreshable
-	CurrentBasicAuthPtr() *BasicAuth
-	MapBasicAuthPtr(func(*BasicAuth) interface{}) refreshable.Refreshable
-	SubscribeToBasicAuthPtr(func(*BasicAuth)) (unsubscribe func())
-
-	User() refreshable.String
-	Password() refreshable.String
-}
-
-type RefreshingBasicAuthPtr struct {
-	refreshable.Refreshable
-}
-
-func NewRefreshingBasicAuthPtr(in refreshable.Refreshable) RefreshingBasicAuthPtr {
-	return RefreshingBasicAuthPtr{Refreshable: in}
-}
-
-func (r RefreshingBasicAuthPtr) CurrentBasicAuthPtr() *BasicAuth {
-	return r.Current().(*BasicAuth)
-}
-
-func (r RefreshingBasicAuthPtr) MapBasicAuthPtr(mapFn func(*BasicAuth) interface{}) refreshable.Refreshable {
-	return r.Map(func(i interface{}) interface{} {
-		return mapFn(i.(*BasicAuth))
-	})
-}
-
-func (r RefreshingBasicAuthPtr) SubscribeToBasicAuthPtr(consumer func(*BasicAuth)) (unsubscribe func()) {
-	return r.Subscribe(func(i interface{}) {
-		consumer(i.(*BasicAuth))
-	})
-}
-
-func (r RefreshingBasicAuthPtr) User() refreshable.String {
-	return refreshable.NewString(r.MapBasicAuthPtr(func(i *BasicAuth) interface{} {
-		return i.User
-	}))
-}
-
-func (r RefreshingBasicAuthPtr) Password() refreshable.String {
-	return refreshable.NewString(r.MapBasicAuthPtr(func(i *BasicAuth) interface{} {
-		return i.Password
-	}))
-}
-
-type RefreshableBasicAuth interface {
-	refreshable.Refreshable
-	CurrentBasicAuth() BasicAuth
-	MapBasicAuth(func(BasicAuth) interface{}) refreshable.Refreshable
-	SubscribeToBasicAuth(func(BasicAuth)) (unsubscribe func())
-
-	User() refreshable.String
-	Password() refreshable.String
-}
-
-type RefreshingBasicAuth struct {
-	refreshable.Refreshable
-}
-
-func NewRefreshingBasicAuth(in refreshable.Refreshable) RefreshingBasicAuth {
-	return RefreshingBasicAuth{Refreshable: in}
-}
-
-func (r RefreshingBasicAuth) CurrentBasicAuth() BasicAuth {
-	return r.Current().(BasicAuth)
-}
-
-func (r RefreshingBasicAuth) MapBasicAuth(mapFn func(BasicAuth) interface{}) refreshable.Refreshable {
-	return r.Map(func(i interface{}) interface{} {
-		return mapFn(i.(BasicAuth))
-	})
-}
-
-func (r RefreshingBasicAuth) SubscribeToBasicAuth(consumer func(BasicAuth)) (unsubscribe func()) {
-	return r.Subscribe(func(i interface{}) {
-		consumer(i.(BasicAuth))
-	})
-}
-
-func (r RefreshingBasicAuth) User() refreshable.String {
-	return refreshable.NewString(r.MapBasicAuth(func(i BasicAuth) interface{} {
-		return i.User
-	}))
-}
-
-func (r RefreshingBasicAuth) Password() refreshable.String {
-	return refreshable.NewString(r.MapBasicAuth(func(i BasicAuth) interface{} {
-		return i.Password
-	}))
-}
-
-type RefreshableDialerParams interface {
-	refreshable.Refreshable
-	CurrentDialerParams() DialerParams
-	MapDialerParams(func(DialerParams) interface{}) refreshable.Refreshable
-	SubscribeToDialerParams(func(DialerParams)) (unsubscribe func())
-
-	DialTimeout() refreshable.Duration
-	KeepAlive() refreshable.Duration
-}
-
-type RefreshingDialerParams struct {
-	refreshable.Refreshable
-}
-
-func NewRefreshingDialerParams(in refreshable.Refreshable) RefreshingDialerParams {
-	return RefreshingDialerParams{Refreshable: in}
-}
-
-func (r RefreshingDialerParams) CurrentDialerParams() DialerParams {
-	return r.Current().(DialerParams)
-}
-
-func (r RefreshingDialerParams) MapDialerParams(mapFn func(DialerParams) interface{}) refreshable.Refreshable {
-	return r.Map(func(i interface{}) interface{} {
-		return mapFn(i.(DialerParams))
-	})
-}
-
-func (r RefreshingDialerParams) SubscribeToDialerParams(consumer func(DialerParams)) (unsubscribe func()) {
-	return r.Subscribe(func(i interface{}) {
-		consumer(i.(DialerParams))
-	})
-}
-
-func (r RefreshingDialerParams) DialTimeout() refreshable.Duration {
-	return refreshable.NewDuration(r.MapDialerParams(func(i DialerParams) interface{} {
-		return i.DialTimeout
-	}))
-}
-
-func (r RefreshingDialerParams) KeepAlive() refreshable.Duration {
-	return refreshable.NewDuration(r.MapDialerParams(func(i DialerParams) interface{} {
-		return i.KeepAlive
-	}))
-}
-
-type RefreshableTags interface {
-	refreshable.Refreshable
-	CurrentTags() metrics.Tags
-	MapTags(func(metrics.Tags) interface{}) refreshable.Refreshable
-	SubscribeToTags(func(metrics.Tags)) (unsubscribe func())
-}
-
-type RefreshingTags struct {
-	refreshable.Refreshable
-}
-
-func NewRefreshingTags(in refreshable.Refreshable) RefreshingTags {
-	return RefreshingTags{Refreshable: in}
-}
-
-func (r RefreshingTags) CurrentTags() metrics.Tags {
-	return r.Current().(metrics.Tags)
-}
-
-func (r RefreshingTags) MapTags(mapFn func(metrics.Tags) interface{}) refreshable.Refreshable {
-	return r.Map(func(i interface{}) interface{} {
-		return mapFn(i.(metrics.Tags))
-	})
-}
-
-func (r RefreshingTags) SubscribeToTags(consumer func(metrics.Tags)) (unsubscribe func()) {
-	return r.Subscribe(func(i interface{}) {
-		consumer(i.(metrics.Tags))
-	})
-}
-
-type RefreshableTagSlice interface {
-	refreshable.Refreshable
-	CurrentTagSlice() []metrics.Tag
-	MapTagSlice(func([]metrics.Tag) interface{}) refreshable.Refreshable
-	SubscribeToTagSlice(func([]metrics.Tag)) (unsubscribe func())
-}
-
-type RefreshingTagSlice struct {
-	refreshable.Refreshable
-}
-
-func NewRefreshingTagSlice(in refreshable.Refreshable) RefreshingTagSlice {
-	return RefreshingTagSlice{Refreshable: in}
-}
-
-func (r RefreshingTagSlice) CurrentTagSlice() []metrics.Tag {
-	return r.Current().([]metrics.Tag)
-}
-
-func (r RefreshingTagSlice) MapTagSlice(mapFn func([]metrics.Tag) interface{}) refreshable.Refreshable {
-	return r.Map(func(i interface{}) interface{} {
-		return mapFn(i.([]metrics.Tag))
-	})
-}
-
-func (r RefreshingTagSlice) SubscribeToTagSlice(consumer func([]metrics.Tag)) (unsubscribe func()) {
-	return r.Subscribe(func(i interface{}) {
-		consumer(i.([]metrics.Tag))
-	})
-}
-
-type RefreshableTag interface {
-	refreshable.Refreshable
-	CurrentTag() metrics.Tag
-	MapTag(func(metrics.Tag) interface{}) refreshable.Refreshable
-	SubscribeToTag(func(metrics.Tag)) (unsubscribe func())
-}
-
-type RefreshingTag struct {
-	refreshable.Refreshable
-}
-
-func NewRefreshingTag(in refreshable.Refreshable) RefreshingTag {
-	return RefreshingTag{Refreshable: in}
-}
-
-func (r RefreshingTag) CurrentTag() metrics.Tag {
-	return r.Current().(metrics.Tag)
-}
-
-func (r RefreshingTag) MapTag(mapFn func(metrics.Tag) interface{}) refreshable.Refreshable {
-	return r.Map(func(i interface{}) interface{} {
-		return mapFn(i.(metrics.Tag))
-	})
-}
-
-func (r RefreshingTag) SubscribeToTag(consumer func(metrics.Tag)) (unsubscribe func()) {
-	return r.Subscribe(func(i interface{}) {
-		consumer(i.(metrics.Tag))
-	})
-}
-
-type RefreshableRetryParams interface {
-	refreshable.Refreshable
-	CurrentRetryParams() RetryParams
-	MapRetryParams(func(RetryParams) interface{}) refreshable.Refreshable
-	SubscribeToRetryParams(func(RetryParams)) (unsubscribe func())
-
-	InitialBackoff() refreshable.Duration
-	MaxBackoff() refreshable.Duration
-}
-
-type RefreshingRetryParams struct {
-	refreshable.Refreshable
-}
-
-func NewRefreshingRetryParams(in refreshable.Refreshable) RefreshingRetryParams {
-	return RefreshingRetryParams{Refreshable: in}
-}
-
-func (r RefreshingRetryParams) CurrentRetryParams() RetryParams {
-	return r.Current().(RetryParams)
-}
-
-func (r RefreshingRetryParams) MapRetryParams(mapFn func(RetryParams) interface{}) refreshable.Refreshable {
-	return r.Map(func(i interface{}) interface{} {
-		return mapFn(i.(RetryParams))
-	})
-}
-
-func (r RefreshingRetryParams) SubscribeToRetryParams(consumer func(RetryParams)) (unsubscribe func()) {
-	return r.Subscribe(func(i interface{}) {
-		consumer(i.(RetryParams))
-	})
-}
-
-func (r RefreshingRetryParams) InitialBackoff() refreshable.Duration {
-	return refreshable.NewDuration(r.MapRetryParams(func(i RetryParams) interface{} {
-		return i.InitialBackoff
-	}))
-}
-
-func (r RefreshingRetryParams) MaxBackoff() refreshable.Duration {
-	return refreshable.NewDuration(r.MapRetryParams(func(i RetryParams) interface{} {
-		return i.MaxBackoff
-	}))
-}
-
-type RefreshableTransportParams interface {
-	refreshable.Refreshable
-	CurrentTransportParams() TransportParams
-	MapTransportParams(func(TransportParams) interface{}) refreshable.Refreshable
-	SubscribeToTransportParams(func(TransportParams)) (unsubscribe func())
-
-	MaxIdleConns() refreshable.Int
-	MaxIdleConnsPerHost() refreshable.Int
-	DisableHTTP2() refreshable.Bool
-	DisableKeepAlives() refreshable.Bool
-	IdleConnTimeout() refreshable.Duration
-	ExpectContinueTimeout() refreshable.Duration
-	ResponseHeaderTimeout() refreshable.Duration
-	TLSHandshakeTimeout() refreshable.Duration
-	ProxyFromEnvironment() refreshable.Bool
-	HTTP2ReadIdleTimeout() refreshable.Duration
-	HTTP2PingTimeout() refreshable.Duration
-	TLS() RefreshableTLSParams
-}
-
-type RefreshingTransportParams struct {
-	refreshable.Refreshable
-}
-
-func NewRefreshingTransportParams(in refreshable.Refreshable) RefreshingTransportParams {
-	return RefreshingTransportParams{Refreshable: in}
-}
-
-func (r RefreshingTransportParams) CurrentTransportParams() TransportParams {
-	return r.Current().(TransportParams)
-}
-
-func (r RefreshingTransportParams) MapTransportParams(mapFn func(TransportParams) interface{}) refreshable.Refreshable {
-	return r.Map(func(i interface{}) interface{} {
-		return mapFn(i.(TransportParams))
-	})
-}
-
-func (r RefreshingTransportParams) SubscribeToTransportParams(consumer func(TransportParams)) (unsubscribe func()) {
-	return r.Subscribe(func(i interface{}) {
-		consumer(i.(TransportParams))
-	})
-}
-
-func (r RefreshingTransportParams) MaxIdleConns() refreshable.Int {
-	return refreshable.NewInt(r.MapTransportParams(func(i TransportParams) interface{} {
-		return i.MaxIdleConns
-	}))
-}
-
-func (r RefreshingTransportParams) MaxIdleConnsPerHost() refreshable.Int {
-	return refreshable.NewInt(r.MapTransportParams(func(i TransportParams) interface{} {
-		return i.MaxIdleConnsPerHost
-	}))
-}
-
-func (r RefreshingTransportParams) DisableHTTP2() refreshable.Bool {
-	return refreshable.NewBool(r.MapTransportParams(func(i TransportParams) interface{} {
-		return i.DisableHTTP2
-	}))
-}
-
-func (r RefreshingTransportParams) DisableKeepAlives() refreshable.Bool {
-	return refreshable.NewBool(r.MapTransportParams(func(i TransportParams) interface{} {
-		return i.DisableKeepAlives
-	}))
-}
-
-func (r RefreshingTransportParams) IdleConnTimeout() refreshable.Duration {
-	return refreshable.NewDuration(r.MapTransportParams(func(i TransportParams) interface{} {
-		return i.IdleConnTimeout
-	}))
-}
-
-func (r RefreshingTransportParams) ExpectContinueTimeout() refreshable.Duration {
-	return refreshable.NewDuration(r.MapTransportParams(func(i TransportParams) interface{} {
-		return i.ExpectContinueTimeout
-	}))
-}
-
-func (r RefreshingTransportParams) ResponseHeaderTimeout() refreshable.Duration {
-	return refreshable.NewDuration(r.MapTransportParams(func(i TransportParams) interface{} {
-		return i.ResponseHeaderTimeout
-	}))
-}
-
-func (r RefreshingTransportParams) TLSHandshakeTimeout() refreshable.Duration {
-	return refreshable.NewDuration(r.MapTransportParams(func(i TransportParams) interface{} {
-		return i.TLSHandshakeTimeout
-	}))
-}
-
-func (r RefreshingTransportParams) ProxyFromEnvironment() refreshable.Bool {
-	return refreshable.NewBool(r.MapTransportParams(func(i TransportParams) interface{} {
-		return i.ProxyFromEnvironment
-	}))
-}
-
-func (r RefreshingTransportParams) HTTP2ReadIdleTimeout() refreshable.Duration {
-	return refreshable.NewDuration(r.MapTransportParams(func(i TransportParams) interface{} {
-		return i.HTTP2ReadIdleTimeout
-	}))
-}
-
-func (r RefreshingTransportParams) HTTP2PingTimeout() refreshable.Duration {
-	return refreshable.NewDuration(r.MapTransportParams(func(i TransportParams) interface{} {
-		return i.HTTP2PingTimeout
-	}))
-}
-
-func (r RefreshingTransportParams) TLS() RefreshableTLSParams {
-	return NewRefreshingTLSParams(r.MapTransportParams(func(i TransportParams) interface{} {
-		return i.TLS
-	}))
-}
-
-type RefreshableTLSParams interface {
-	refreshable.Refreshable
-	CurrentTLSParams() TLSParams
-	MapTLSParams(func(TLSParams) interface{}) refreshable.Refreshable
-	SubscribeToTLSParams(func(TLSParams)) (unsubscribe func())
-
-	CAFiles() refreshable.StringSlice
-	CertFile() refreshable.String
-	KeyFile() refreshable.String
-	InsecureSkipVerify() refreshable.Bool
-}
-
-type RefreshingTLSParams struct {
-	refreshable.Refreshable
-}
-
-func NewRefreshingTLSParams(in refreshable.Refreshable) RefreshingTLSParams {
-	return RefreshingTLSParams{Refreshable: in}
-}
-
-func (r RefreshingTLSParams) CurrentTLSParams() TLSParams {
-	return r.Current().(TLSParams)
-}
-
-func (r RefreshingTLSParams) MapTLSParams(mapFn func(TLSParams) interface{}) refreshable.Refreshable {
-	return r.Map(func(i interface{}) interface{} {
-		return mapFn(i.(TLSParams))
-	})
-}
-
-func (r RefreshingTLSParams) SubscribeToTLSParams(consumer func(TLSParams)) (unsubscribe func()) {
-	return r.Subscribe(func(i interface{}) {
-		consumer(i.(TLSParams))
-	})
-}
-
-func (r RefreshingTLSParams) CAFiles() refreshable.StringSlice {
-	return refreshable.NewStringSlice(r.MapTLSParams(func(i TLSParams) interface{} {
-		return i.CAFiles
-	}))
-}
-
-func (r RefreshingTLSParams) CertFile() refreshable.String {
-	return refreshable.NewString(r.MapTLSParams(func(i TLSParams) interface{} {
-		return i.CertFile
-	}))
-}
-
-func (r RefreshingTLSParams) KeyFile() refreshable.String {
-	return refreshable.NewString(r.MapTLSParams(func(i TLSParams) interface{} {
-		return i.KeyFile
-	}))
-}
-
-func (r RefreshingTLSParams) InsecureSkipVerify() refreshable.Bool {
-	return refreshable.NewBool(r.MapTLSParams(func(i TLSParams) interface{} {
-		return i.InsecureSkipVerify
-	}))
-}
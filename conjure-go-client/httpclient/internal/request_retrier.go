@@ -18,6 +18,7 @@ import (
 	"net/http"
 	"net/url"
 	"strings"
+	"time"
 
 	"github.com/palantir/pkg/retry"
 )
@@ -37,8 +38,18 @@ type RequestRetrier struct {
 	offset        int
 	relocatedURIs map[string]struct{}
 	failedURIs    map[string]struct{}
-	maxAttempts   int
-	attemptCount  int
+	// unavailableUntil is the time before which each URI which responded 503 with a honored Retry-After should not
+	// be retried.
+	unavailableUntil map[string]time.Time
+	maxAttempts      int
+	attemptCount     int
+	retryAfter       *RetryAfterParams
+	budget           *RetryBudget
+	budgetParams     RetryBudgetParams
+	// budgetExhausted is set when a retry was not sent because the budget was exhausted.
+	budgetExhausted bool
+	// predicate, if set, overrides whether a response is retried. See UseRetryPredicate.
+	predicate func(resp *http.Response, respErr error) (retry bool, decided bool)
 }
 
 // NewRequestRetrier creates a new request retrier.
@@ -46,17 +57,44 @@ type RequestRetrier struct {
 func NewRequestRetrier(uris []string, retrier retry.Retrier, maxAttempts int) *RequestRetrier {
 	offset := 0
 	return &RequestRetrier{
-		currentURI:    uris[offset],
-		retrier:       retrier,
-		uris:          uris,
-		offset:        offset,
-		relocatedURIs: map[string]struct{}{},
-		failedURIs:    map[string]struct{}{},
-		maxAttempts:   maxAttempts,
-		attemptCount:  0,
+		currentURI:       uris[offset],
+		retrier:          retrier,
+		uris:             uris,
+		offset:           offset,
+		relocatedURIs:    map[string]struct{}{},
+		failedURIs:       map[string]struct{}{},
+		unavailableUntil: map[string]time.Time{},
+		maxAttempts:      maxAttempts,
+		attemptCount:     0,
 	}
 }
 
+// HonorRetryAfter makes the retrier wait for the Retry-After duration of throttle responses, adjusted by p, instead
+// of backing off. Throttle responses without a Retry-After duration still back off. URIs which respond unavailable
+// with a Retry-After duration are not retried until the adjusted duration has passed; other URIs are tried meanwhile.
+func (r *RequestRetrier) HonorRetryAfter(p RetryAfterParams) {
+	r.retryAfter = &p
+}
+
+// UseRetryBudget records the request in budget and only retries it if budget allows it according to p.
+func (r *RequestRetrier) UseRetryBudget(budget *RetryBudget, p RetryBudgetParams) {
+	r.budget = budget
+	r.budgetParams = p
+}
+
+// UseRetryPredicate consults predicate before the default handling of each response. If predicate returns decided,
+// the response is retried on the next URI, backing off as for an unavailable response, if and only if retry is true.
+// Otherwise, the response is handled as if no predicate was set. Mesh URIs, the maximum number of attempts and the
+// retry budget still apply.
+func (r *RequestRetrier) UseRetryPredicate(predicate func(resp *http.Response, respErr error) (retry bool, decided bool)) {
+	r.predicate = predicate
+}
+
+// RetryBudgetExhausted returns true if the request was not retried because its RetryBudget was exhausted.
+func (r *RequestRetrier) RetryBudgetExhausted() bool {
+	return r.budgetExhausted
+}
+
 func (r *RequestRetrier) attemptsRemaining() bool {
 	// maxAttempts of 0 indicates no limit
 	if r.maxAttempts == 0 {
@@ -76,6 +114,9 @@ func (r *RequestRetrier) GetNextURI(resp *http.Response, respErr error) (uri str
 		// but ignore the returned value to ensure that the client can instrument the request even
 		// if the context is done.
 		r.retrier.Next()
+		if r.budget != nil {
+			r.budget.deposit()
+		}
 		return r.removeMeshSchemeIfPresent(r.currentURI), false
 	}
 	if !r.attemptsRemaining() {
@@ -91,6 +132,10 @@ func (r *RequestRetrier) GetNextURI(resp *http.Response, respErr error) (uri str
 		// The previous response was not retryable
 		return "", false
 	}
+	if r.budget != nil && !r.budget.withdraw(r.budgetParams) {
+		r.budgetExhausted = true
+		return "", false
+	}
 	// Updates currentURI
 	if !retryFn() {
 		return "", false
@@ -99,14 +144,35 @@ func (r *RequestRetrier) GetNextURI(resp *http.Response, respErr error) (uri str
 }
 
 func (r *RequestRetrier) getRetryFn(resp *http.Response, respErr error) func() bool {
+	if r.predicate != nil {
+		if retry, decided := r.predicate(resp, respErr); decided {
+			if retry {
+				return r.nextURIOrBackoff
+			}
+			return nil
+		}
+	}
 	errCode, _ := StatusCodeFromError(respErr)
-	if retryOther, _ := isThrottleResponse(resp, errCode); retryOther {
+	if throttle, retryAfter := isThrottleResponse(resp, respErr, errCode); throttle {
 		// 429: throttle
-		// Immediately backoff and select the next URI.
-		// TODO(whickman): use the retry-after header once #81 is resolved
+		// Select the next URI and wait for the Retry-After duration if it is honored, or backoff otherwise.
+		if r.retryAfter != nil && retryAfter > 0 {
+			delay := r.retryAfter.Delay(retryAfter)
+			return func() bool {
+				return r.nextURIAndWait(delay)
+			}
+		}
 		return r.nextURIAndBackoff
-	} else if isUnavailableResponse(resp, errCode) {
+	} else if unavailable, retryAfter := isUnavailableResponse(resp, respErr, errCode); unavailable {
 		// 503: go to next node
+		// If the Retry-After duration is honored, do not return to this node until it has passed.
+		if r.retryAfter != nil && retryAfter > 0 {
+			delay := r.retryAfter.Delay(retryAfter)
+			return func() bool {
+				r.unavailableUntil[r.currentURI] = time.Now().Add(delay)
+				return r.nextURIOrBackoff()
+			}
+		}
 		return r.nextURIOrBackoff
 	} else if shouldTryOther, otherURI := isRetryOtherResponse(resp, respErr, errCode); shouldTryOther {
 		// 307 or 308: go to next node, or particular node if provided.
@@ -138,6 +204,12 @@ func (r *RequestRetrier) setURIAndResetBackoff(otherURI *url.URL) {
 func (r *RequestRetrier) nextURIOrBackoff() bool {
 	_, performBackoff := r.failedURIs[r.currentURI]
 	r.markFailedAndMoveToNextURI()
+	// If the next URI asked not to be retried yet, wait until it can be
+	if wait := time.Until(r.unavailableUntil[r.currentURI]); wait > 0 {
+		if delayRetrier, ok := r.retrier.(DelayRetrier); ok {
+			return delayRetrier.NextIn(wait)
+		}
+	}
 	// If the URI has failed before, perform a backoff
 	if performBackoff || len(r.uris) == 1 {
 		return r.retrier.Next()
@@ -151,6 +223,16 @@ func (r *RequestRetrier) nextURIAndBackoff() bool {
 	return r.retrier.Next()
 }
 
+// Marks the current URI as failed, gets the next URI, and waits for delay if the retrier supports it, or performs a
+// backoff as determined by the retrier otherwise.
+func (r *RequestRetrier) nextURIAndWait(delay time.Duration) bool {
+	r.markFailedAndMoveToNextURI()
+	if delayRetrier, ok := r.retrier.(DelayRetrier); ok {
+		return delayRetrier.NextIn(delay)
+	}
+	return r.retrier.Next()
+}
+
 func (r *RequestRetrier) markFailedAndMoveToNextURI() {
 	r.failedURIs[r.currentURI] = struct{}{}
 	nextURIOffset := (r.offset + 1) % len(r.uris)
@@ -370,3 +370,63 @@ func (m *mockRetrier) Next() bool {
 func (m *mockRetrier) CurrentAttempt() int {
 	return 0
 }
+
+type delayRecordingRetrier struct {
+	mockRetrier
+	delays []time.Duration
+}
+
+func (m *delayRecordingRetrier) NextIn(delay time.Duration) bool {
+	m.delays = append(m.delays, delay)
+	return true
+}
+
+func TestRequestRetrier_UnavailableRetryAfter(t *testing.T) {
+	retrier := &delayRecordingRetrier{}
+	r := NewRequestRetrier([]string{"https://a.example.com", "https://b.example.com"}, retrier, 0)
+	r.HonorRetryAfter(RetryAfterParams{})
+	uri, _ := r.GetNextURI(nil, nil)
+	require.Equal(t, "https://a.example.com", uri)
+
+	// a asks not to be retried for a minute, so b is tried immediately
+	uri, _ = r.GetNextURI(&http.Response{
+		StatusCode: http.StatusServiceUnavailable,
+		Header:     http.Header{"Retry-After": []string{"60"}},
+	}, nil)
+	require.Equal(t, "https://b.example.com", uri)
+	assert.Empty(t, retrier.delays)
+
+	// b is also unavailable, so the retrier waits for a to be available again
+	uri, _ = r.GetNextURI(&http.Response{StatusCode: http.StatusServiceUnavailable, Header: http.Header{}}, nil)
+	require.Equal(t, "https://a.example.com", uri)
+	require.Len(t, retrier.delays, 1)
+	assert.InDelta(t, time.Minute, retrier.delays[0], float64(time.Second))
+}
+
+func TestRequestRetrier_RetryPredicate(t *testing.T) {
+	r := NewRequestRetrier([]string{"https://a.example.com", "https://b.example.com"}, retry.Start(context.Background()), 0)
+	r.UseRetryPredicate(func(resp *http.Response, respErr error) (bool, bool) {
+		if statusCode, ok := StatusCodeFromError(respErr); ok && statusCode == http.StatusInternalServerError {
+			return true, true
+		}
+		if resp != nil && resp.StatusCode == http.StatusServiceUnavailable {
+			return false, true
+		}
+		return false, false
+	})
+	uri, _ := r.GetNextURI(nil, nil)
+	require.Equal(t, "https://a.example.com", uri)
+
+	// 500 is not retried by default, but the predicate retries it on the next URI
+	respErr := werror.ErrorWithContextParams(context.Background(), "500", werror.SafeParam("statusCode", 500))
+	uri, _ = r.GetNextURI(nil, respErr)
+	require.Equal(t, "https://b.example.com", uri)
+
+	// 429 is not decided by the predicate, so it is still retried
+	uri, _ = r.GetNextURI(&http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{}}, nil)
+	require.Equal(t, "https://a.example.com", uri)
+
+	// 503 is retried by default, but the predicate prevents it
+	uri, _ = r.GetNextURI(&http.Response{StatusCode: http.StatusServiceUnavailable, Header: http.Header{}}, nil)
+	require.Empty(t, uri)
+}
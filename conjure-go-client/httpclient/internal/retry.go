@@ -15,10 +15,12 @@
 package internal
 
 import (
+	"math/rand"
 	"net/http"
 	"net/url"
-	"strconv"
 	"time"
+
+	"github.com/palantir/conjure-go-runtime/v2/conjure-go-contract/httpheaders"
 )
 
 /* https://github.com/palantir/http-remoting#quality-of-service-retry-failover-throttling
@@ -35,13 +37,13 @@ The QosExceptions have a stable mapping to HTTP status codes and response header
 * throttle: 429 Too Many Requests, plus optional Retry-After header
 * retryOther: 308 Permanent Redirect, plus Location header indicating the target host
 * retryTemporaryRedirect: 307 Temporary Redirect, plus Location header indicating the target host
-* unavailable: 503 Unavailable
+* unavailable: 503 Unavailable, plus optional Retry-After header
 
 http-remoting clients (both Retrofit2 and JaxRs) handle the above error codes and take the appropriate action:
 
 * throttle: reschedule the request with a delay: either the indicated Retry-After period, or a configured exponential backoff
 * retryOther: retry the request against the indicated service node; all request parameters and headers are maintained
-* unavailable: retry the request on a different host after a configurable exponential delay
+* unavailable: retry the request on a different host after a configurable exponential delay; if the response has a Retry-After header, the host is not retried until the indicated period has passed
 
 Connection errors (e.g., connection refused or DNS errors) yield a retry against a different node of the service.
 Retries pick a target host by cycling through the list of URLs configured for a Service (see ClientConfiguration#uris).
@@ -83,48 +85,59 @@ func isRetryOtherResponse(resp *http.Response, err error, errCode int) (bool, *u
 }
 
 func parseLocationURL(locationStr string) *url.URL {
-	if locationStr == "" {
-		return nil
-	}
-	locationURL, err := url.Parse(locationStr)
-	if err != nil {
-		// Unable to parse location as something we recognize
-		return nil
-	}
+	// Returns nil if location is empty or can not be parsed as something we recognize
+	locationURL, _ := httpheaders.ParseLocation(locationStr, nil)
 	return locationURL
 }
 
 // isThrottleResponse returns true if the response a throttle response type. It
 // also returns a duration after which the failed URI can be retried
-func isThrottleResponse(resp *http.Response, errCode int) (bool, time.Duration) {
+func isThrottleResponse(resp *http.Response, respErr error, errCode int) (bool, time.Duration) {
 	if errCode == StatusCodeThrottle {
-		return true, 0
+		retryAfter, _ := RetryAfterFromError(respErr)
+		return true, retryAfter
 	}
 	if resp == nil || resp.StatusCode != StatusCodeThrottle {
 		return false, 0
 	}
-	retryAfterStr := resp.Header.Get("Retry-After")
-	if retryAfterStr == "" {
-		return true, 0
-	}
-	// Retry-After can be either a Date or a number of seconds; look for both.
-	if retryAfterSec, err := strconv.Atoi(retryAfterStr); err == nil {
-		return true, time.Duration(retryAfterSec) * time.Second
-	}
-	retryAfterDate, err := http.ParseTime(retryAfterStr)
-	if err != nil {
-		// Unable to parse non-zero header as something we recognize...
-		return true, 0
-	}
-	return true, time.Until(retryAfterDate)
+	// Retry-After can be either a Date or a number of seconds; unparseable values are ignored.
+	retryAfter, _ := httpheaders.ParseRetryAfter(resp.Header.Get(httpheaders.RetryAfter), time.Now())
+	return true, retryAfter
 }
 
-func isUnavailableResponse(resp *http.Response, errCode int) bool {
+// isUnavailableResponse returns true if the response is an unavailable response type. It also returns the duration
+// after which the failed URI can be retried, if the response has a Retry-After header.
+func isUnavailableResponse(resp *http.Response, respErr error, errCode int) (bool, time.Duration) {
 	if errCode == StatusCodeUnavailable {
-		return true
+		retryAfter, _ := RetryAfterFromError(respErr)
+		return true, retryAfter
 	}
 	if resp == nil || resp.StatusCode != StatusCodeUnavailable {
-		return false
+		return false, 0
+	}
+	retryAfter, _ := httpheaders.ParseRetryAfter(resp.Header.Get(httpheaders.RetryAfter), time.Now())
+	return true, retryAfter
+}
+
+// RetryAfterParams configures how the Retry-After durations of throttle responses are honored.
+type RetryAfterParams struct {
+	// Jitter is the fraction of the duration by which each delay is randomly shortened or lengthened, so that the
+	// replicas of a caller which are throttled together do not all retry at the same time.
+	Jitter float64
+	// MaxDelay, if positive, is the longest delay honored. Longer durations are shortened to MaxDelay before jitter
+	// is applied, and jitter never lengthens a delay past MaxDelay.
+	MaxDelay time.Duration
+}
+
+// Delay returns the time to wait before retrying a request which was throttled with a Retry-After of retryAfter.
+func (p RetryAfterParams) Delay(retryAfter time.Duration) time.Duration {
+	if p.MaxDelay > 0 && retryAfter > p.MaxDelay {
+		retryAfter = p.MaxDelay
+	}
+	low := float64(retryAfter) * (1 - p.Jitter)
+	high := float64(retryAfter) * (1 + p.Jitter)
+	if p.MaxDelay > 0 && high > float64(p.MaxDelay) {
+		high = float64(p.MaxDelay)
 	}
-	return true
+	return time.Duration(low + rand.Float64()*(high-low))
 }
@@ -0,0 +1,91 @@
+// Copyright (c) 2026 Palantir Technologies. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"sync"
+	"time"
+)
+
+// retryBudgetWindow is the number of one-second buckets over which a RetryBudget counts requests and retries.
+const retryBudgetWindow = 10
+
+// RetryBudgetParams configures the retries allowed by a RetryBudget.
+type RetryBudgetParams struct {
+	// Ratio is the number of retries allowed for each request sent over the window, e.g. 0.2 for at most 20% of
+	// requests to be retries.
+	Ratio float64
+	// MinRetriesPerSecond is the number of retries allowed regardless of Ratio, so that clients which send few
+	// requests can still retry.
+	MinRetriesPerSecond int
+}
+
+// RetryBudget bounds the retries of a client to a fraction of its requests over a sliding window of ten seconds, so
+// that a failing server does not receive many times its usual load from callers retrying every request. It is safe
+// for concurrent use and is shared by every request sent by a client.
+type RetryBudget struct {
+	now func() time.Time
+
+	mu      sync.Mutex
+	buckets [retryBudgetWindow]retryBudgetBucket
+}
+
+type retryBudgetBucket struct {
+	// second is the Unix time, in seconds, counted by the bucket.
+	second   int64
+	requests int
+	retries  int
+}
+
+func NewRetryBudget() *RetryBudget {
+	return &RetryBudget{now: time.Now}
+}
+
+// deposit records a request, allowing Ratio retries.
+func (b *RetryBudget) deposit() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.bucket().requests++
+}
+
+// withdraw records a retry and returns true if it is allowed by p, or returns false without recording it otherwise.
+func (b *RetryBudget) withdraw(p RetryBudgetParams) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	current := b.bucket()
+	var requests, retries int
+	for _, bucket := range b.buckets {
+		if bucket.second > current.second-retryBudgetWindow {
+			requests += bucket.requests
+			retries += bucket.retries
+		}
+	}
+	allowed := p.Ratio*float64(requests) + float64(p.MinRetriesPerSecond*retryBudgetWindow)
+	if float64(retries+1) > allowed {
+		return false
+	}
+	current.retries++
+	return true
+}
+
+// bucket returns the bucket of the current second, resetting it if it last counted an earlier second.
+func (b *RetryBudget) bucket() *retryBudgetBucket {
+	second := b.now().Unix()
+	bucket := &b.buckets[second%retryBudgetWindow]
+	if bucket.second != second {
+		*bucket = retryBudgetBucket{second: second}
+	}
+	return bucket
+}
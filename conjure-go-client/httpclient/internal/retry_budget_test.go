@@ -0,0 +1,70 @@
+// Copyright (c) 2026 Palantir Technologies. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/palantir/pkg/retry"
+	werror "github.com/palantir/witchcraft-go-error"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetryBudget(t *testing.T) {
+	now := time.Unix(1000, 0)
+	budget := NewRetryBudget()
+	budget.now = func() time.Time { return now }
+	params := RetryBudgetParams{Ratio: 0.2}
+
+	for i := 0; i < 10; i++ {
+		budget.deposit()
+	}
+	assert.True(t, budget.withdraw(params))
+	assert.True(t, budget.withdraw(params))
+	assert.False(t, budget.withdraw(params))
+
+	// requests and retries expire once they leave the window
+	now = now.Add(retryBudgetWindow * time.Second)
+	assert.False(t, budget.withdraw(params))
+	budget.deposit()
+	budget.deposit()
+	budget.deposit()
+	budget.deposit()
+	budget.deposit()
+	assert.True(t, budget.withdraw(params))
+	assert.False(t, budget.withdraw(params))
+
+	// the minimum applies without requests
+	assert.True(t, budget.withdraw(RetryBudgetParams{MinRetriesPerSecond: 1}))
+}
+
+func TestRequestRetrier_RetryBudget(t *testing.T) {
+	budget := NewRetryBudget()
+	respErr := werror.ErrorWithContextParams(context.Background(), "error", werror.SafeParam("statusCode", 503))
+
+	r := NewRequestRetrier([]string{"https://a.example.com", "https://b.example.com"}, retry.Start(context.Background()), 0)
+	r.UseRetryBudget(budget, RetryBudgetParams{Ratio: 1})
+	uri, _ := r.GetNextURI(nil, nil)
+	require.Equal(t, "https://a.example.com", uri)
+	uri, _ = r.GetNextURI(nil, respErr)
+	require.Equal(t, "https://b.example.com", uri)
+	assert.False(t, r.RetryBudgetExhausted())
+	uri, _ = r.GetNextURI(nil, respErr)
+	require.Empty(t, uri)
+	assert.True(t, r.RetryBudgetExhausted())
+}
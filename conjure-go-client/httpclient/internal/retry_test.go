@@ -33,6 +33,8 @@ func TestRetryResponseParsers(t *testing.T) {
 		IsThrottle       bool
 		ThrottleDuration time.Duration
 		IsUnavailable    bool
+		// UnavailableDuration is the Retry-After duration of an unavailable response.
+		UnavailableDuration time.Duration
 	}{
 		{
 			Name: "200 OK",
@@ -109,6 +111,21 @@ func TestRetryResponseParsers(t *testing.T) {
 			RespErr:       werror.Error("error", werror.SafeParam("statusCode", 503)),
 			IsUnavailable: true,
 		},
+		{
+			Name: "503 unavailable with Retry-After seconds",
+			Response: &http.Response{
+				Header:     http.Header{"Retry-After": []string{"30"}},
+				StatusCode: 503,
+			},
+			IsUnavailable:       true,
+			UnavailableDuration: 30 * time.Second,
+		},
+		{
+			Name:                "503 unavailable with Retry-After in error",
+			RespErr:             werror.Error("error", werror.SafeParam("statusCode", 503), werror.UnsafeParam("retryAfter", "30")),
+			IsUnavailable:       true,
+			UnavailableDuration: 30 * time.Second,
+		},
 		{
 			Name: "429 throttle with Retry-After seconds",
 			Response: &http.Response{
@@ -118,6 +135,13 @@ func TestRetryResponseParsers(t *testing.T) {
 			IsThrottle:       true,
 			ThrottleDuration: time.Minute,
 		},
+		{
+			Name:             "429 throttle with Retry-After in error",
+			Response:         nil,
+			RespErr:          werror.Error("error", werror.SafeParam("statusCode", 429), werror.UnsafeParam("retryAfter", "60")),
+			IsThrottle:       true,
+			ThrottleDuration: time.Minute,
+		},
 		{
 			Name: "429 throttle with Retry-After Date",
 			Response: &http.Response{
@@ -137,13 +161,61 @@ func TestRetryResponseParsers(t *testing.T) {
 				}
 			}
 
-			isThrottle, throttleDur := isThrottleResponse(test.Response, errCode)
+			isThrottle, throttleDur := isThrottleResponse(test.Response, test.RespErr, errCode)
 			if assert.Equal(t, test.IsThrottle, isThrottle) {
 				assert.WithinDuration(t, time.Now().Add(test.ThrottleDuration), time.Now().Add(throttleDur), time.Second)
 			}
 
-			isUnavailable := isUnavailableResponse(test.Response, errCode)
-			assert.Equal(t, test.IsUnavailable, isUnavailable)
+			isUnavailable, unavailableDur := isUnavailableResponse(test.Response, test.RespErr, errCode)
+			if assert.Equal(t, test.IsUnavailable, isUnavailable) {
+				assert.WithinDuration(t, time.Now().Add(test.UnavailableDuration), time.Now().Add(unavailableDur), time.Second)
+			}
+		})
+	}
+}
+
+func TestRetryAfterParams_Delay(t *testing.T) {
+	for _, test := range []struct {
+		Name       string
+		Params     RetryAfterParams
+		RetryAfter time.Duration
+		Min, Max   time.Duration
+	}{
+		{
+			Name:       "no jitter",
+			Params:     RetryAfterParams{},
+			RetryAfter: time.Minute,
+			Min:        time.Minute,
+			Max:        time.Minute,
+		},
+		{
+			Name:       "jitter",
+			Params:     RetryAfterParams{Jitter: 0.2},
+			RetryAfter: 10 * time.Second,
+			Min:        8 * time.Second,
+			Max:        12 * time.Second,
+		},
+		{
+			Name:       "clamped",
+			Params:     RetryAfterParams{MaxDelay: 30 * time.Second},
+			RetryAfter: time.Minute,
+			Min:        30 * time.Second,
+			Max:        30 * time.Second,
+		},
+		{
+			Name:       "jitter does not exceed clamp",
+			Params:     RetryAfterParams{Jitter: 0.5, MaxDelay: 30 * time.Second},
+			RetryAfter: 25 * time.Second,
+			Min:        12500 * time.Millisecond,
+			Max:        30 * time.Second,
+		},
+	} {
+		t.Run(test.Name, func(t *testing.T) {
+			for i := 0; i < 100; i++ {
+				delay := test.Params.Delay(test.RetryAfter)
+				assert.GreaterOrEqual(t, delay, test.Min)
+				assert.LessOrEqual(t, delay, test.Max)
+			}
 		})
 	}
 }
@@ -0,0 +1,130 @@
+// Copyright (c) 2026 Palantir Technologies. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"hash/fnv"
+	"net/http"
+	"sort"
+	"sync"
+)
+
+// maxStickySessionOverrides bounds the number of sessions a sticky scorer remembers having moved away from their
+// preferred URI. Once exceeded, every session returns to its preferred URI, or the next available one.
+const maxStickySessionOverrides = 10000
+
+// SessionURIScorer is implemented by URIScoringMiddleware which order URIs differently for each session.
+type SessionURIScorer interface {
+	// GetURIsForSession returns the URIs to try for a request of the session identified by key, in order.
+	GetURIsForSession(key string) []string
+}
+
+type stickyScorer struct {
+	uris     []string
+	balanced *balancedScorer
+
+	mu sync.Mutex
+	// overrides maps the keys of sessions which have moved away from their preferred URI to the URI they moved to.
+	overrides map[string]string
+}
+
+// NewStickyURIScoringMiddleware returns URI scoring middleware which pins the requests of each session to a single
+// URI. A session's preferred URI is chosen by rendezvous hashing, so sessions are spread evenly over the URIs and
+// keep their URI when other URIs are added or removed. When the pinned URI becomes unavailable, the session moves to
+// the next available URI for its key and stays there until that URI also fails, even once the original URI
+// recovers. Requests without a session are ordered as by NewBalancedURIScoringMiddleware, which is also used to
+// track in-flight requests and failures.
+func NewStickyURIScoringMiddleware(uris []string, nanoClock func() int64) URIScoringMiddleware {
+	return &stickyScorer{
+		uris:      uris,
+		balanced:  NewBalancedURIScoringMiddleware(uris, nanoClock).(*balancedScorer),
+		overrides: make(map[string]string),
+	}
+}
+
+func (s *stickyScorer) GetURIsInOrderOfIncreasingScore() []string {
+	return s.balanced.GetURIsInOrderOfIncreasingScore()
+}
+
+func (s *stickyScorer) GetURIsForSession(key string) []string {
+	ranked := rendezvousOrder(key, s.uris)
+	if len(ranked) == 0 {
+		return ranked
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	target, ok := s.overrides[key]
+	if !ok || !s.isAvailable(target) {
+		target = ranked[0]
+		for _, uri := range ranked {
+			if s.isAvailable(uri) {
+				target = uri
+				break
+			}
+		}
+		if target == ranked[0] {
+			delete(s.overrides, key)
+		} else {
+			if len(s.overrides) >= maxStickySessionOverrides {
+				s.overrides = make(map[string]string)
+			}
+			s.overrides[key] = target
+		}
+	}
+	ordered := make([]string, 0, len(ranked))
+	ordered = append(ordered, target)
+	for _, uri := range ranked {
+		if uri != target {
+			ordered = append(ordered, uri)
+		}
+	}
+	return ordered
+}
+
+// isAvailable returns true if uri is one of the scorer's URIs and has not failed recently.
+func (s *stickyScorer) isAvailable(uri string) bool {
+	info, ok := s.balanced.uriInfos[uri]
+	return ok && info.recentFailures.Get() < unavailableThreshold
+}
+
+func (s *stickyScorer) AllURIsUnavailable() bool {
+	return s.balanced.AllURIsUnavailable()
+}
+
+func (s *stickyScorer) URIStates() []URIState {
+	return s.balanced.URIStates()
+}
+
+func (s *stickyScorer) RoundTrip(req *http.Request, next http.RoundTripper) (*http.Response, error) {
+	return s.balanced.RoundTrip(req, next)
+}
+
+// rendezvousOrder returns uris ordered by decreasing rendezvous hash weight for key.
+func rendezvousOrder(key string, uris []string) []string {
+	weights := make(map[string]uint64, len(uris))
+	ordered := make([]string, len(uris))
+	copy(ordered, uris)
+	for _, uri := range uris {
+		h := fnv.New64a()
+		_, _ = h.Write([]byte(key))
+		_, _ = h.Write([]byte{0})
+		_, _ = h.Write([]byte(uri))
+		weights[uri] = h.Sum64()
+	}
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return weights[ordered[i]] > weights[ordered[j]]
+	})
+	return ordered
+}
@@ -0,0 +1,72 @@
+// Copyright (c) 2026 Palantir Technologies. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStickyScorerPinsSessions(t *testing.T) {
+	uris := []string{"https://a", "https://b", "https://c"}
+	scorer := NewStickyURIScoringMiddleware(uris, func() int64 { return 0 }).(SessionURIScorer)
+
+	pinned := make(map[string]int)
+	for i := 0; i < 300; i++ {
+		key := fmt.Sprintf("session-%d", i)
+		ordered := scorer.GetURIsForSession(key)
+		require.ElementsMatch(t, uris, ordered)
+		assert.Equal(t, ordered, scorer.GetURIsForSession(key), "session %s should keep its URI", key)
+		pinned[ordered[0]]++
+	}
+	for _, uri := range uris {
+		assert.Greater(t, pinned[uri], 50, "sessions should be spread over %s", uri)
+	}
+}
+
+func TestStickyScorerFailover(t *testing.T) {
+	server200 := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {}))
+	defer server200.Close()
+	server503 := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server503.Close()
+	now := int64(0)
+	scorer := NewStickyURIScoringMiddleware([]string{server200.URL, server503.URL}, func() int64 { return now })
+	sessions := scorer.(SessionURIScorer)
+
+	// find a session which prefers the failing server.
+	var key string
+	for i := 0; key == ""; i++ {
+		if candidate := fmt.Sprintf("session-%d", i); sessions.GetURIsForSession(candidate)[0] == server503.URL {
+			key = candidate
+		}
+	}
+
+	req, err := http.NewRequest(http.MethodGet, server503.URL, nil)
+	require.NoError(t, err)
+	_, err = scorer.RoundTrip(req, server503.Client().Transport)
+	require.NoError(t, err)
+	assert.Equal(t, []string{server200.URL, server503.URL}, sessions.GetURIsForSession(key))
+
+	// the session stays on its new URI once the original recovers.
+	now += 2 * failureMemory.Nanoseconds()
+	assert.Equal(t, []string{server200.URL, server503.URL}, sessions.GetURIsForSession(key))
+}
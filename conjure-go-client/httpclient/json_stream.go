@@ -0,0 +1,72 @@
+// Copyright (c) 2026 Palantir Technologies. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+
+	"github.com/palantir/conjure-go-runtime/v2/conjure-go-contract/httpheaders"
+	"github.com/palantir/pkg/safejson"
+	werror "github.com/palantir/witchcraft-go-error"
+)
+
+const contentTypeNDJSON = "application/x-ndjson"
+
+// WithJSONStreamResponse consumes a newline-delimited JSON (application/x-ndjson) response incrementally: fn is called
+// with a json.Decoder reading from the response body, which is configured with UseNumber like the JSON codec, and
+// typically calls Decode until it returns io.EOF. The body is streamed as fn reads it rather than buffered, and is
+// drained and closed once fn returns. An error returned by fn is returned by Do.
+//
+// Once fn has been called the request is not retried, as it may already have processed part of the response.
+func WithJSONStreamResponse(fn func(decoder *json.Decoder) error) RequestParam {
+	return requestParamFunc(func(b *requestBuilder) error {
+		if fn == nil {
+			return werror.Error("JSON stream response handler can not be nil")
+		}
+		b.bodyMiddleware.rawOutput = false
+		b.bodyMiddleware.decompressRawOutput = false
+		b.bodyMiddleware.responseWriter = nil
+		b.bodyMiddleware.responseOutput = &jsonStreamOutput{fn: fn}
+		b.bodyMiddleware.responseDecoder = jsonStreamDecoder{}
+		b.headers.Set(httpheaders.Accept, contentTypeNDJSON)
+		return nil
+	})
+}
+
+// jsonStreamOutput is the response output configured by WithJSONStreamResponse.
+type jsonStreamOutput struct {
+	fn func(decoder *json.Decoder) error
+}
+
+// jsonStreamDecoder is the codecs.Decoder of responses consumed with WithJSONStreamResponse.
+type jsonStreamDecoder struct{}
+
+func (jsonStreamDecoder) Accept() string {
+	return contentTypeNDJSON
+}
+
+func (jsonStreamDecoder) Decode(r io.Reader, v interface{}) error {
+	output, ok := v.(*jsonStreamOutput)
+	if !ok {
+		return werror.Error("JSON stream decoder requires a JSON stream response handler")
+	}
+	return output.fn(safejson.Decoder(r))
+}
+
+func (d jsonStreamDecoder) Unmarshal(data []byte, v interface{}) error {
+	return d.Decode(bytes.NewReader(data), v)
+}
@@ -0,0 +1,90 @@
+// Copyright (c) 2026 Palantir Technologies. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpclient_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/palantir/conjure-go-runtime/v2/conjure-go-client/httpclient"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONStreamResponse(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		requests.Add(1)
+		assert.Equal(t, "application/x-ndjson", req.Header.Get("Accept"))
+		rw.Header().Set("Content-Type", "application/x-ndjson")
+		for i := 0; i < 3; i++ {
+			_, _ = fmt.Fprintf(rw, "{\"index\":%d}\n", i)
+			rw.(http.Flusher).Flush()
+		}
+	}))
+	defer server.Close()
+
+	client, err := httpclient.NewClient(httpclient.WithBaseURLs([]string{server.URL, server.URL + "/"}))
+	require.NoError(t, err)
+
+	type line struct {
+		Index json.Number `json:"index"`
+	}
+	var lines []line
+	_, err = client.Get(context.Background(), httpclient.WithJSONStreamResponse(func(decoder *json.Decoder) error {
+		for {
+			var l line
+			if err := decoder.Decode(&l); err == io.EOF {
+				return nil
+			} else if err != nil {
+				return err
+			}
+			lines = append(lines, l)
+		}
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, []line{{Index: "0"}, {Index: "1"}, {Index: "2"}}, lines)
+
+	// the request is not retried once the handler has been called.
+	requests.Store(0)
+	_, err = client.Get(context.Background(), httpclient.WithJSONStreamResponse(func(*json.Decoder) error {
+		return fmt.Errorf("handler failed")
+	}))
+	require.EqualError(t, err, "httpclient request failed: handler failed")
+	assert.Equal(t, int32(1), requests.Load())
+
+	// a streamed response only prevents retries of the call which received it, not of later calls which share the
+	// request annotations.
+	ctx := httpclient.ContextWithRequestAnnotations(context.Background())
+	for i := 0; i < 2; i++ {
+		lines = nil
+		resp, err := client.Get(ctx, httpclient.WithJSONStreamResponse(func(decoder *json.Decoder) error {
+			var l line
+			for decoder.Decode(&l) == nil {
+				lines = append(lines, l)
+			}
+			return nil
+		}))
+		require.NoError(t, err)
+		require.NotNil(t, resp)
+		assert.Len(t, lines, 3)
+	}
+}
@@ -0,0 +1,61 @@
+// Copyright (c) 2026 Palantir Technologies. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpclient
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/palantir/conjure-go-runtime/v2/conjure-go-client/httpclient/internal/refreshingclient"
+	"github.com/palantir/pkg/metrics"
+	"github.com/palantir/pkg/refreshable"
+	refreshablev2 "github.com/palantir/pkg/refreshable/v2"
+	"github.com/palantir/witchcraft-go-tracing/wtracing"
+)
+
+// latencyBudgetMiddleware compares the duration of each request attempt against the budget configured for its
+// RPC method name. Attempts which take longer than their budget mark the client.budget.exceeded meter and tag the
+// active span, if any. Requests without an RPC method name or without a configured budget are not checked.
+type latencyBudgetMiddleware struct {
+	serviceName refreshable.String
+	budgets     refreshablev2.Refreshable[refreshingclient.LatencyBudgets]
+	disabled    refreshable.Bool
+}
+
+func (m *latencyBudgetMiddleware) RoundTrip(req *http.Request, next http.RoundTripper) (*http.Response, error) {
+	budget, ok := m.budgets.Current()[getRPCMethodName(req.Context())]
+	if !ok {
+		return next.RoundTrip(req)
+	}
+	start := time.Now()
+	resp, err := next.RoundTrip(req)
+	if elapsed := time.Since(start); elapsed > budget {
+		m.markExceeded(req, budget, elapsed)
+	}
+	return resp, err
+}
+
+func (m *latencyBudgetMiddleware) markExceeded(req *http.Request, budget, elapsed time.Duration) {
+	ctx := req.Context()
+	if span := wtracing.SpanFromContext(ctx); span != nil {
+		span.Tag("latencyBudget", budget.String())
+		span.Tag("latencyBudgetExceededBy", (elapsed - budget).String())
+	}
+	if metricsDisabled(ctx, m.disabled) {
+		return
+	}
+	serviceNameTag := metrics.NewTagWithFallbackValue(MetricTagServiceName, m.serviceName.CurrentString(), "unknown")
+	metrics.FromContext(ctx).Meter(MetricLatencyBudgetExceeded, serviceNameTag, rpcMethodNameTag(ctx)).Mark(1)
+}
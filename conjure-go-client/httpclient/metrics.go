@@ -16,16 +16,23 @@ package httpclient
 
 import (
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
 	"crypto/tls"
+	"encoding/hex"
 	"errors"
 	"net"
 	"net/http"
 	"net/http/httptrace"
+	"sync"
 	"time"
 
+	gometrics "github.com/palantir/go-metrics"
 	"github.com/palantir/pkg/metrics"
 	"github.com/palantir/pkg/refreshable"
 	werror "github.com/palantir/witchcraft-go-error"
+	"github.com/palantir/witchcraft-go-logging/wlog/svclog/svc1log"
+	"github.com/palantir/witchcraft-go-tracing/wtracing"
 )
 
 const (
@@ -34,6 +41,10 @@ const (
 	metricTagFamily      = "family"
 	metricTagMethod      = "method"
 	metricRPCMethodName  = "method-name"
+	metricTagBaseURI     = "base-uri"
+
+	// histogramAlpha is the decay factor of histogram reservoirs, matching the default used by timers.
+	histogramAlpha = 0.015
 
 	MetricTLSHandshakeAttempt = "tls.handshake.attempt"
 	MetricTLSHandshakeFailure = "tls.handshake.failure"
@@ -42,8 +53,20 @@ const (
 	NextProtocolTagKey        = "next_protocol"
 	TLSVersionTagKey          = "tls_version"
 
-	MetricConnCreate      = "client.connection.create" // monotonic counter of each new request, tagged with reused:true or reused:false
-	MetricRequestInFlight = "client.request.in-flight"
+	MetricClientCall            = "client.call"              // timer of calls to Do, including every attempt, backoff and fallback, tagged like client.response
+	MetricConnCreate            = "client.connection.create" // monotonic counter of each new request, tagged with reused:true or reused:false
+	MetricRequestInFlight       = "client.request.in-flight"
+	MetricRequestTimeout        = "client.request.timeout"        // meter of requests which exceeded the timeout set by WithRequestTimeout
+	MetricAllNodesUnavailable   = "client.uri.all-unavailable"    // meter of requests made while every URI had failed recently
+	MetricConfigWarnings        = "client.config.warnings"        // gauge of the number of problems found in the client's current configuration
+	MetricConfigRefresh         = "client.config.refresh"         // timer of the time from observing a configuration update to having fully applied it
+	MetricConfigGeneration      = "client.config.generation"      // gauge of the number of distinct configurations applied by the client, starting at 1
+	MetricLatencyBudgetExceeded = "client.budget.exceeded"        // meter of request attempts which took longer than their configured latency budget
+	MetricRequestHedge          = "client.request.hedge"          // meter of additional attempts sent by WithHedgedRequests
+	MetricConcurrencyLimit      = "client.concurrency.limit"      // gauge of the in-flight request limit of each base URI set by WithConcurrencyLimiter
+	MetricResponseCacheHit      = "client.cache.hit"              // meter of GET requests answered from the cache set by WithResponseCache, including after revalidation
+	MetricResponseCacheMiss     = "client.cache.miss"             // meter of GET requests answered by the server despite the cache set by WithResponseCache
+	MetricRetryBudgetExhausted  = "client.retry.budget.exhausted" // meter of retries not sent because the retry budget set by WithRetryBudget was exhausted
 )
 
 var (
@@ -72,6 +95,27 @@ func (f TagsProviderFunc) Tags(req *http.Request, resp *http.Response, respErr e
 	return f(req, resp, respErr)
 }
 
+// baseURITagsProvider tags metrics with a hash of the base URI selected for the request, so that the base URI
+// itself, which may contain unsafe information, is not recorded.
+type baseURITagsProvider struct{}
+
+func (baseURITagsProvider) Tags(req *http.Request, _ *http.Response, _ error) metrics.Tags {
+	baseURI := getBaseURI(req.Context())
+	if baseURI == "" {
+		return nil
+	}
+	return metrics.Tags{metrics.MustNewTag(metricTagBaseURI, baseURIHash(baseURI))}
+}
+
+// baseURIHash returns a short hash of baseURI keyed with principalHashKey, so that host names can not be recovered
+// from the tag with a dictionary of likely URIs. Hashes are stable across configuration changes but not across
+// processes.
+func baseURIHash(baseURI string) string {
+	mac := hmac.New(sha256.New, principalHashKey)
+	_, _ = mac.Write([]byte(baseURI))
+	return hex.EncodeToString(mac.Sum(nil)[:8])
+}
+
 type StaticTagsProvider metrics.Tags
 
 func (s StaticTagsProvider) Tags(_ *http.Request, _ *http.Response, _ error) metrics.Tags {
@@ -84,33 +128,64 @@ func (s StaticTagsProvider) Tags(_ *http.Request, _ *http.Response, _ error) met
 // https://github.com/palantir/http-remoting/blob/develop/okhttp-clients/src/main/java/com/palantir/remoting3/okhttp/DefaultHostMetrics.java
 func MetricsMiddleware(serviceName string, tagProviders ...TagsProvider) (Middleware, error) {
 	refreshableName := refreshable.NewString(refreshable.NewDefaultRefreshable(serviceName))
-	return newMetricsMiddleware(refreshableName, tagProviders, nil), nil
+	return newMetricsMiddleware(refreshableName, tagProviders, nil, responseMetricsParams{}), nil
+}
+
+// responseMetricsParams configures how the client.response metric is recorded.
+type responseMetricsParams struct {
+	// HistogramReservoirSize, if positive, records client.response as a histogram of durations in microseconds using
+	// an exponentially decaying reservoir of this size instead of a timer.
+	HistogramReservoirSize int
+	// RPCMethodNameTagLimit, if positive, is the number of distinct method-name tag values after which requests with
+	// new method names are recorded with a single rolled-up value.
+	RPCMethodNameTagLimit int
+	// RPCMethodNames are the method names registered with WithServiceDefinition, which are always recorded and do
+	// not count towards RPCMethodNameTagLimit.
+	RPCMethodNames []string
+	// TagValueLimit, if positive, is the number of distinct values of each tag key returned by the client's
+	// TagsProviders after which new values are recorded as "other".
+	TagValueLimit int
 }
 
-func newMetricsMiddleware(serviceName refreshable.String, tagProviders []TagsProvider, disabled refreshable.Bool) Middleware {
+func newMetricsMiddleware(serviceName refreshable.String, tagProviders []TagsProvider, disabled refreshable.Bool, params responseMetricsParams) *metricsMiddleware {
+	var methodNameTags TagsProvider = TagsProviderFunc(tagRequestMethodName)
+	if params.RPCMethodNameTagLimit > 0 {
+		methodNameTags = newRPCMethodNameTagLimiter(params.RPCMethodNameTagLimit, params.RPCMethodNames)
+	}
+	if params.TagValueLimit > 0 {
+		guard := newTagCardinalityGuard(params.TagValueLimit)
+		guarded := make([]TagsProvider, len(tagProviders))
+		for i, tagProvider := range tagProviders {
+			guarded[i] = guard.wrap(tagProvider)
+		}
+		tagProviders = guarded
+	}
 	return &metricsMiddleware{
-		Disabled:    disabled,
-		ServiceName: serviceName,
+		Disabled:               disabled,
+		ServiceName:            serviceName,
+		HistogramReservoirSize: params.HistogramReservoirSize,
 		Tags: append(
 			tagProviders,
 			TagsProviderFunc(tagStatusFamily),
 			TagsProviderFunc(tagRequestMethod),
-			TagsProviderFunc(tagRequestMethodName),
+			TagsProviderFunc(tagRequestClass),
+			methodNameTags,
 		),
 	}
 }
 
 type metricsMiddleware struct {
-	Disabled    refreshable.Bool
-	ServiceName refreshable.String
-	Tags        []TagsProvider
+	Disabled               refreshable.Bool
+	ServiceName            refreshable.String
+	Tags                   []TagsProvider
+	HistogramReservoirSize int
 }
 
 // RoundTrip will emit counter and timer metrics with the name 'mariner.k8sClient.request'
 // and k8s for API group, API version, namespace, resource kind, request method, and response status code.
 func (h *metricsMiddleware) RoundTrip(req *http.Request, next http.RoundTripper) (*http.Response, error) {
-	if h.Disabled != nil && h.Disabled.CurrentBool() {
-		// If we have a Disabled refreshable and it is true, no-op.
+	if metricsDisabled(req.Context(), h.Disabled) {
+		// If we have a Disabled refreshable and it is true, or the request was made using WithoutMetrics, no-op.
 		return next.RoundTrip(req)
 	}
 	serviceNameTag := metrics.NewTagWithFallbackValue(MetricTagServiceName, h.ServiceName.CurrentString(), "unknown")
@@ -122,13 +197,100 @@ func (h *metricsMiddleware) RoundTrip(req *http.Request, next http.RoundTripper)
 	duration := time.Since(start)
 	metrics.FromContext(req.Context()).Counter(MetricRequestInFlight, serviceNameTag).Dec(1)
 
+	h.record(req.Context(), metricClientResponse, serviceNameTag, req, resp, err, duration)
+	return resp, err
+}
+
+// recordCall records the duration of a call to Client.Do in the client.call metric, tagged like client.response using
+// the request and outcome of the call's last attempt.
+func (h *metricsMiddleware) recordCall(ctx context.Context, req *http.Request, resp *http.Response, err error, duration time.Duration) {
+	if metricsDisabled(req.Context(), h.Disabled) {
+		return
+	}
+	serviceNameTag := metrics.NewTagWithFallbackValue(MetricTagServiceName, h.ServiceName.CurrentString(), "unknown")
+	h.record(ctx, MetricClientCall, serviceNameTag, req, resp, err, duration)
+}
+
+func (h *metricsMiddleware) record(ctx context.Context, name string, serviceNameTag metrics.Tag, req *http.Request, resp *http.Response, err error, duration time.Duration) {
 	tags := []metrics.Tag{serviceNameTag}
 	for _, tagProvider := range h.Tags {
 		tags = append(tags, tagProvider.Tags(req, resp, err)...)
 	}
 
-	metrics.FromContext(req.Context()).Timer(metricClientResponse, tags...).Update(duration / time.Microsecond)
-	return resp, err
+	if h.HistogramReservoirSize > 0 {
+		sample := newLazySample(func() gometrics.Sample {
+			return gometrics.NewExpDecaySample(h.HistogramReservoirSize, histogramAlpha)
+		})
+		metrics.FromContext(ctx).HistogramWithSample(name, sample, tags...).Update(int64(duration / time.Microsecond))
+	} else {
+		metrics.FromContext(ctx).Timer(name, tags...).Update(duration / time.Microsecond)
+	}
+}
+
+// callMetricsRecord holds the request of the most recent attempt of a call to Client.Do, which is used to tag the
+// call's client.call metric.
+type callMetricsRecord struct {
+	mu  sync.Mutex
+	req *http.Request
+}
+
+func contextWithCallMetricsRecord(ctx context.Context) (context.Context, *callMetricsRecord) {
+	record := &callMetricsRecord{}
+	return context.WithValue(ctx, requestCallRecord, record), record
+}
+
+// setCallRequest records req as the request of the most recent attempt of the call to Client.Do made with ctx.
+func setCallRequest(ctx context.Context, req *http.Request) {
+	if record, ok := ctx.Value(requestCallRecord).(*callMetricsRecord); ok {
+		record.mu.Lock()
+		defer record.mu.Unlock()
+		record.req = req
+	}
+}
+
+func (r *callMetricsRecord) request() *http.Request {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.req
+}
+
+// metricsDisabled returns true if metrics are disabled for the client or, using WithoutMetrics, for the request made
+// with ctx.
+func metricsDisabled(ctx context.Context, disabled refreshable.Bool) bool {
+	return (disabled != nil && disabled.CurrentBool()) || withoutMetrics(ctx)
+}
+
+// markRequestTimeout records a request which exceeded the timeout set by WithRequestTimeout by marking the
+// client.request.timeout meter and tagging the active span, if any. Timeouts configured on the client itself and
+// timeouts returned by the server are not recorded.
+func markRequestTimeout(ctx context.Context, serviceName refreshable.String, disabled refreshable.Bool, timeout time.Duration) {
+	if span := wtracing.SpanFromContext(ctx); span != nil {
+		span.Tag("timeout", "request")
+		span.Tag("requestTimeout", timeout.String())
+	}
+	if metricsDisabled(ctx, disabled) {
+		return
+	}
+	serviceNameTag := metrics.NewTagWithFallbackValue(MetricTagServiceName, serviceName.CurrentString(), "unknown")
+	metrics.FromContext(ctx).Meter(MetricRequestTimeout, serviceNameTag, rpcMethodNameTag(ctx)).Mark(1)
+}
+
+// markAllNodesUnavailable records a request made while every URI had failed recently.
+func markAllNodesUnavailable(ctx context.Context, serviceName refreshable.String, disabled refreshable.Bool) {
+	if disabled != nil && disabled.CurrentBool() {
+		return
+	}
+	serviceNameTag := metrics.NewTagWithFallbackValue(MetricTagServiceName, serviceName.CurrentString(), "unknown")
+	metrics.FromContext(ctx).Meter(MetricAllNodesUnavailable, serviceNameTag).Mark(1)
+}
+
+// markRetryBudgetExhausted records a request which was not retried because the client's retry budget was exhausted.
+func markRetryBudgetExhausted(ctx context.Context, serviceName refreshable.String, disabled refreshable.Bool) {
+	if metricsDisabled(ctx, disabled) {
+		return
+	}
+	serviceNameTag := metrics.NewTagWithFallbackValue(MetricTagServiceName, serviceName.CurrentString(), "unknown")
+	metrics.FromContext(ctx).Meter(MetricRetryBudgetExhausted, serviceNameTag).Mark(1)
 }
 
 func tagStatusFamily(_ *http.Request, resp *http.Response, respErr error) metrics.Tags {
@@ -157,15 +319,120 @@ func tagRequestMethod(req *http.Request, _ *http.Response, _ error) metrics.Tags
 }
 
 func tagRequestMethodName(req *http.Request, _ *http.Response, _ error) metrics.Tags {
-	rpcMethodName := getRPCMethodName(req.Context())
+	return metrics.Tags{rpcMethodNameTag(req.Context())}
+}
+
+// rpcMethodNameTagLimiter tags metrics with the request's method name until limit distinct method names have been
+// seen. Requests with other method names are tagged with a single rolled-up value, bounding the number of
+// client.response metrics for clients with dynamic method names. Registered method names are always recorded.
+type rpcMethodNameTagLimiter struct {
+	limit      int
+	registered map[string]struct{}
+	mu         sync.Mutex
+	seen       map[string]struct{}
+}
+
+func newRPCMethodNameTagLimiter(limit int, registered []string) *rpcMethodNameTagLimiter {
+	l := &rpcMethodNameTagLimiter{
+		limit:      limit,
+		registered: make(map[string]struct{}, len(registered)),
+		seen:       make(map[string]struct{}, limit),
+	}
+	for _, name := range registered {
+		if tag, err := metrics.NewTag(metricRPCMethodName, name); err == nil {
+			l.registered[tag.Value()] = struct{}{}
+		}
+	}
+	return l
+}
+
+func (l *rpcMethodNameTagLimiter) Tags(req *http.Request, _ *http.Response, _ error) metrics.Tags {
+	tag := rpcMethodNameTag(req.Context())
+	if _, ok := l.registered[tag.Value()]; ok {
+		return metrics.Tags{tag}
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, ok := l.seen[tag.Value()]; !ok {
+		if len(l.seen) >= l.limit {
+			return metrics.Tags{metrics.MustNewTag(metricRPCMethodName, "RPCMethodNameRolledUp")}
+		}
+		l.seen[tag.Value()] = struct{}{}
+	}
+	return metrics.Tags{tag}
+}
+
+// tagCardinalityGuard bounds the number of distinct values of each tag key returned by TagsProviders. Once limit
+// values of a key have been seen, other values are replaced by "other" and a warning is logged the first time the
+// key overflows, so that a TagsProvider returning unbounded values can not grow the metrics registry without bound.
+type tagCardinalityGuard struct {
+	limit int
+	mu    sync.Mutex
+	seen  map[string]map[string]struct{}
+	// overflowed contains the tag keys for which a warning has been logged.
+	overflowed map[string]struct{}
+}
+
+func newTagCardinalityGuard(limit int) *tagCardinalityGuard {
+	return &tagCardinalityGuard{
+		limit:      limit,
+		seen:       make(map[string]map[string]struct{}),
+		overflowed: make(map[string]struct{}),
+	}
+}
+
+func (g *tagCardinalityGuard) wrap(tagProvider TagsProvider) TagsProvider {
+	return TagsProviderFunc(func(req *http.Request, resp *http.Response, respErr error) metrics.Tags {
+		return g.guard(req.Context(), tagProvider.Tags(req, resp, respErr))
+	})
+}
+
+func (g *tagCardinalityGuard) guard(ctx context.Context, tags metrics.Tags) metrics.Tags {
+	if len(tags) == 0 {
+		return tags
+	}
+	var overflowed []string
+	guarded := make(metrics.Tags, len(tags))
+	g.mu.Lock()
+	for i, tag := range tags {
+		guarded[i] = tag
+		values, ok := g.seen[tag.Key()]
+		if !ok {
+			values = make(map[string]struct{})
+			g.seen[tag.Key()] = values
+		}
+		if _, ok := values[tag.Value()]; ok {
+			continue
+		}
+		if len(values) < g.limit {
+			values[tag.Value()] = struct{}{}
+			continue
+		}
+		guarded[i] = metrics.MustNewTag(tag.Key(), "other")
+		if _, ok := g.overflowed[tag.Key()]; !ok {
+			g.overflowed[tag.Key()] = struct{}{}
+			overflowed = append(overflowed, tag.Key())
+		}
+	}
+	g.mu.Unlock()
+	for _, key := range overflowed {
+		svc1log.FromContext(ctx).Warn("Metric tag exceeded its limit of distinct values; recording further values as \"other\"",
+			svc1log.SafeParam("tagKey", key),
+			svc1log.SafeParam("limit", g.limit))
+	}
+	return guarded
+}
+
+func rpcMethodNameTag(ctx context.Context) metrics.Tag {
+	rpcMethodName := getRPCMethodName(ctx)
 	if rpcMethodName == "" {
-		return metrics.Tags{metrics.MustNewTag(metricRPCMethodName, "RPCMethodNameMissing")}
+		return metrics.MustNewTag(metricRPCMethodName, "RPCMethodNameMissing")
 	}
 	tag, err := metrics.NewTag(metricRPCMethodName, rpcMethodName)
 	if err == nil {
-		return metrics.Tags{tag}
+		return tag
 	}
-	return metrics.Tags{metrics.MustNewTag(metricRPCMethodName, "RPCMethodNameInvalid")}
+	return metrics.MustNewTag(metricRPCMethodName, "RPCMethodNameInvalid")
 }
 
 func (h *metricsMiddleware) tlsTraceContext(ctx context.Context, serviceNameTag metrics.Tag) context.Context {
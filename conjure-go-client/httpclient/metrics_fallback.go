@@ -0,0 +1,58 @@
+// Copyright (c) 2026 Palantir Technologies. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpclient
+
+import (
+	"context"
+	"sync"
+
+	"github.com/palantir/pkg/metrics"
+	"github.com/palantir/pkg/refreshable"
+	"github.com/palantir/witchcraft-go-logging/wlog/svclog/svc1log"
+)
+
+// metricsRegistryFallback handles requests made with a context which has no metrics registry, for which
+// metrics.FromContext returns metrics.DefaultMetricsRegistry: it attaches the fallback registry, if any, and warns once
+// per client. Contexts which carry metric tags but no registry are not detected.
+type metricsRegistryFallback struct {
+	serviceName refreshable.String
+	disabled    refreshable.Bool
+	registry    metrics.Registry
+	warnOnce    sync.Once
+}
+
+func newMetricsRegistryFallback(serviceName refreshable.String, disabled refreshable.Bool, registry metrics.Registry) *metricsRegistryFallback {
+	return &metricsRegistryFallback{serviceName: serviceName, disabled: disabled, registry: registry}
+}
+
+// apply returns ctx with the fallback registry attached if it has no registry of its own.
+func (f *metricsRegistryFallback) apply(ctx context.Context) context.Context {
+	if metricsDisabled(ctx, f.disabled) || metrics.FromContext(ctx) != metrics.DefaultMetricsRegistry {
+		return ctx
+	}
+	f.warnOnce.Do(func() {
+		if f.registry != nil {
+			svc1log.FromContext(ctx).Warn("Request context has no metrics registry. Recording client metrics in the fallback registry.",
+				svc1log.SafeParam("serviceName", f.serviceName.CurrentString()))
+		} else {
+			svc1log.FromContext(ctx).Warn("Request context has no metrics registry. Recording client metrics in the default registry; use WithFallbackMetricsRegistry to choose another.",
+				svc1log.SafeParam("serviceName", f.serviceName.CurrentString()))
+		}
+	})
+	if f.registry == nil {
+		return ctx
+	}
+	return metrics.WithRegistry(ctx, f.registry)
+}
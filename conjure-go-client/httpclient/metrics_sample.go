@@ -0,0 +1,56 @@
+// Copyright (c) 2026 Palantir Technologies. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpclient
+
+import (
+	"sync"
+
+	gometrics "github.com/palantir/go-metrics"
+)
+
+// lazySample is a metrics Sample which defers creating its underlying Sample, and the reservoir it holds, until first
+// use. Registry.HistogramWithSample requires a Sample on every call even when the histogram is already registered,
+// so this avoids allocating a reservoir for each request.
+type lazySample struct {
+	once      sync.Once
+	newSample func() gometrics.Sample
+	sample    gometrics.Sample
+}
+
+func newLazySample(newSample func() gometrics.Sample) *lazySample {
+	return &lazySample{newSample: newSample}
+}
+
+func (s *lazySample) get() gometrics.Sample {
+	s.once.Do(func() {
+		s.sample = s.newSample()
+	})
+	return s.sample
+}
+
+func (s *lazySample) Clear()                             { s.get().Clear() }
+func (s *lazySample) Count() int64                       { return s.get().Count() }
+func (s *lazySample) Max() int64                         { return s.get().Max() }
+func (s *lazySample) Mean() float64                      { return s.get().Mean() }
+func (s *lazySample) Min() int64                         { return s.get().Min() }
+func (s *lazySample) Percentile(p float64) float64       { return s.get().Percentile(p) }
+func (s *lazySample) Percentiles(ps []float64) []float64 { return s.get().Percentiles(ps) }
+func (s *lazySample) Size() int                          { return s.get().Size() }
+func (s *lazySample) Snapshot() gometrics.Sample         { return s.get().Snapshot() }
+func (s *lazySample) StdDev() float64                    { return s.get().StdDev() }
+func (s *lazySample) Sum() int64                         { return s.get().Sum() }
+func (s *lazySample) Update(v int64)                     { s.get().Update(v) }
+func (s *lazySample) Values() []int64                    { return s.get().Values() }
+func (s *lazySample) Variance() float64                  { return s.get().Variance() }
@@ -16,6 +16,8 @@ package httpclient_test
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -250,6 +252,360 @@ func TestMetricsMiddleware_ClientTimeout(t *testing.T) {
 	assert.True(t, found, "did not find client.response metric")
 }
 
+func TestMetricsMiddleware_RequestTimeout(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+		w.WriteHeader(200)
+	}))
+	defer srv.Close()
+
+	for _, tc := range []struct {
+		name          string
+		clientTimeout time.Duration
+		params        []httpclient.RequestParam
+		expectMarked  bool
+	}{
+		{
+			name:          "request timeout",
+			clientTimeout: time.Minute,
+			params:        []httpclient.RequestParam{httpclient.WithRequestTimeout(time.Millisecond)},
+			expectMarked:  true,
+		},
+		{
+			name:          "client timeout",
+			clientTimeout: time.Millisecond,
+			expectMarked:  false,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			rootRegistry := metrics.NewRootMetricsRegistry()
+			ctx := metrics.WithRegistry(context.Background(), rootRegistry)
+
+			client, err := httpclient.NewClient(
+				httpclient.WithBaseURLs([]string{srv.URL}),
+				httpclient.WithServiceName("test-service"),
+				httpclient.WithHTTPTimeout(tc.clientTimeout),
+				httpclient.WithMaxRetries(0),
+				httpclient.WithMetrics())
+			require.NoError(t, err)
+
+			_, err = client.Get(ctx, append(tc.params, httpclient.WithRPCMethodName("test-endpoint"))...)
+			require.Error(t, err)
+
+			marked := false
+			rootRegistry.Each(func(name string, tags metrics.Tags, value metrics.MetricVal) {
+				if name != httpclient.MetricRequestTimeout {
+					return
+				}
+				marked = true
+				expectedTags := map[metrics.Tag]struct{}{
+					metrics.MustNewTag("method-name", "test-endpoint"): {},
+					metrics.MustNewTag("service-name", "test-service"): {},
+				}
+				assert.Equal(t, expectedTags, tags.ToSet())
+				assert.Equal(t, int64(1), value.Values()["count"])
+			})
+			assert.Equal(t, tc.expectMarked, marked)
+		})
+	}
+}
+
+func TestMetricsMiddleware_LatencyBudget(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		time.Sleep(50 * time.Millisecond)
+		w.WriteHeader(200)
+	}))
+	defer srv.Close()
+
+	rootRegistry := metrics.NewRootMetricsRegistry()
+	ctx := metrics.WithRegistry(context.Background(), rootRegistry)
+
+	client, err := httpclient.NewClient(
+		httpclient.WithBaseURLs([]string{srv.URL}),
+		httpclient.WithServiceName("test-service"),
+		httpclient.WithLatencyBudgets(map[string]time.Duration{
+			"slowEndpoint": time.Millisecond,
+			"fastEndpoint": time.Minute,
+		}),
+		httpclient.WithMetrics())
+	require.NoError(t, err)
+
+	for _, methodName := range []string{"slowEndpoint", "fastEndpoint", "unbudgetedEndpoint"} {
+		_, err = client.Get(ctx, httpclient.WithRPCMethodName(methodName))
+		require.NoError(t, err)
+	}
+
+	var markedTags []metrics.Tags
+	rootRegistry.Each(func(name string, tags metrics.Tags, value metrics.MetricVal) {
+		if name != httpclient.MetricLatencyBudgetExceeded {
+			return
+		}
+		markedTags = append(markedTags, tags)
+		assert.Equal(t, int64(1), value.Values()["count"])
+	})
+	require.Len(t, markedTags, 1)
+	assert.Equal(t, map[metrics.Tag]struct{}{
+		metrics.MustNewTag("method-name", "slowEndpoint"):  {},
+		metrics.MustNewTag("service-name", "test-service"): {},
+	}, markedTags[0].ToSet())
+
+	_, err = httpclient.NewClient(httpclient.WithLatencyBudgets(map[string]time.Duration{"endpoint": 0}))
+	assert.EqualError(t, err, "latency budgets must be positive")
+}
+
+func TestMetricsMiddleware_BaseURITag(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(200)
+	}))
+	defer srv.Close()
+
+	rootRegistry := metrics.NewRootMetricsRegistry()
+	ctx := metrics.WithRegistry(context.Background(), rootRegistry)
+
+	client, err := httpclient.NewClient(
+		httpclient.WithBaseURLs([]string{srv.URL + "/api"}),
+		httpclient.WithServiceName("test-service"),
+		httpclient.WithMetrics(),
+		httpclient.WithBaseURIMetricTag())
+	require.NoError(t, err)
+
+	_, err = client.Get(ctx, httpclient.WithPath("/foo"))
+	require.NoError(t, err)
+
+	unkeyed := sha256.Sum256([]byte(srv.URL + "/api"))
+	var baseURITag string
+	rootRegistry.Each(func(name string, tags metrics.Tags, _ metrics.MetricVal) {
+		if name != "client.response" {
+			return
+		}
+		for _, tag := range tags {
+			if tag.Key() == "base-uri" {
+				baseURITag = tag.Value()
+			}
+		}
+	})
+	require.NotEmpty(t, baseURITag, "client.response metric with base-uri tag not found")
+	_, err = hex.DecodeString(baseURITag)
+	assert.NoError(t, err)
+	assert.Len(t, baseURITag, 16)
+	assert.NotContains(t, hex.EncodeToString(unkeyed[:]), baseURITag, "base URI hash should be keyed")
+}
+
+func TestMetricsMiddleware_ResponseHistogram(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(200)
+	}))
+	defer srv.Close()
+
+	rootRegistry := metrics.NewRootMetricsRegistry()
+	ctx := metrics.WithRegistry(context.Background(), rootRegistry)
+
+	client, err := httpclient.NewClient(
+		httpclient.WithBaseURLs([]string{srv.URL}),
+		httpclient.WithServiceName("test-service"),
+		httpclient.WithMetrics(),
+		httpclient.WithResponseHistogram(16))
+	require.NoError(t, err)
+
+	for i := 0; i < 3; i++ {
+		_, err = client.Get(ctx, httpclient.WithRPCMethodName("getFoo"))
+		require.NoError(t, err)
+	}
+
+	found := false
+	rootRegistry.Each(func(name string, tags metrics.Tags, value metrics.MetricVal) {
+		if name != "client.response" {
+			return
+		}
+		found = true
+		assert.Equal(t, "histogram", value.Type())
+		assert.EqualValues(t, 3, value.Values()["count"])
+	})
+	assert.True(t, found, "client.response metric not found")
+
+	_, err = httpclient.NewClient(httpclient.WithBaseURLs([]string{srv.URL}), httpclient.WithResponseHistogram(0))
+	require.EqualError(t, err, "response histogram reservoir size must be positive")
+}
+
+func TestMetricsMiddleware_RPCMethodNameTagLimit(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(200)
+	}))
+	defer srv.Close()
+
+	rootRegistry := metrics.NewRootMetricsRegistry()
+	ctx := metrics.WithRegistry(context.Background(), rootRegistry)
+
+	client, err := httpclient.NewClient(
+		httpclient.WithBaseURLs([]string{srv.URL}),
+		httpclient.WithServiceName("test-service"),
+		httpclient.WithMetrics(),
+		httpclient.WithRPCMethodNameTagLimit(2))
+	require.NoError(t, err)
+
+	for _, name := range []string{"getA", "getB", "getC", "getA", "getD"} {
+		_, err = client.Get(ctx, httpclient.WithRPCMethodName(name))
+		require.NoError(t, err)
+	}
+
+	methodNames := map[string]bool{}
+	rootRegistry.Each(func(name string, tags metrics.Tags, _ metrics.MetricVal) {
+		if name == "client.response" {
+			methodNames[tags.ToMap()["method-name"]] = true
+		}
+	})
+	assert.Equal(t, map[string]bool{"geta": true, "getb": true, "rpcmethodnamerolledup": true}, methodNames)
+}
+
+func TestMetricsMiddleware_TagValueLimit(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(200)
+	}))
+	defer srv.Close()
+
+	rootRegistry := metrics.NewRootMetricsRegistry()
+	ctx := metrics.WithRegistry(context.Background(), rootRegistry)
+
+	client, err := httpclient.NewClient(
+		httpclient.WithBaseURLs([]string{srv.URL}),
+		httpclient.WithServiceName("test-service"),
+		httpclient.WithMetrics(httpclient.TagsProviderFunc(func(req *http.Request, _ *http.Response, _ error) metrics.Tags {
+			return metrics.Tags{metrics.MustNewTag("path", req.URL.Path)}
+		})),
+		httpclient.WithMetricsTagValueLimit(2))
+	require.NoError(t, err)
+
+	for _, path := range []string{"a", "b", "c", "a", "d"} {
+		_, err = client.Get(ctx, httpclient.WithPath(path))
+		require.NoError(t, err)
+	}
+
+	paths := map[string]bool{}
+	rootRegistry.Each(func(name string, tags metrics.Tags, _ metrics.MetricVal) {
+		if name == "client.response" {
+			paths[tags.ToMap()["path"]] = true
+		}
+	})
+	assert.Equal(t, map[string]bool{"/a": true, "/b": true, "other": true}, paths)
+
+	_, err = httpclient.NewClient(httpclient.WithBaseURLs([]string{srv.URL}), httpclient.WithMetricsTagValueLimit(0))
+	require.EqualError(t, err, "metrics tag value limit must be positive")
+}
+
+func TestMetricsMiddleware_CallTimer(t *testing.T) {
+	var requests int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		requests++
+		if requests == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	rootRegistry := metrics.NewRootMetricsRegistry()
+	ctx := metrics.WithRegistry(context.Background(), rootRegistry)
+
+	backoff := 20 * time.Millisecond
+	client, err := httpclient.NewClient(
+		httpclient.WithBaseURLs([]string{srv.URL}),
+		httpclient.WithServiceName("test-service"),
+		httpclient.WithInitialBackoff(backoff),
+		httpclient.WithMaxBackoff(backoff))
+	require.NoError(t, err)
+
+	_, err = client.Get(ctx, httpclient.WithRPCMethodName("getThing"))
+	require.NoError(t, err)
+
+	var responses, responseMax, callMax int64
+	var calls []map[string]string
+	rootRegistry.Each(func(name string, tags metrics.Tags, value metrics.MetricVal) {
+		switch name {
+		case "client.response":
+			responses += value.Values()["count"].(int64)
+			responseMax = max(responseMax, value.Values()["max"].(int64))
+		case "client.call":
+			calls = append(calls, tags.ToMap())
+			callMax = value.Values()["max"].(int64)
+		}
+	})
+	assert.Equal(t, int64(2), responses)
+	assert.Equal(t, []map[string]string{{
+		"service-name": "test-service",
+		"method":       "get",
+		"method-name":  "getthing",
+		"family":       "2xx",
+	}}, calls)
+	// the call includes the backoff between its attempts.
+	assert.Greater(t, callMax, responseMax)
+}
+
+func TestMetricsMiddleware_WithoutMetrics(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	rootRegistry := metrics.NewRootMetricsRegistry()
+	ctx := metrics.WithRegistry(context.Background(), rootRegistry)
+
+	client, err := httpclient.NewClient(
+		httpclient.WithBaseURLs([]string{srv.URL}),
+		httpclient.WithServiceName("test-service"))
+	require.NoError(t, err)
+
+	_, err = client.Get(ctx, httpclient.WithRPCMethodName("poll"), httpclient.WithoutMetrics())
+	require.NoError(t, err)
+	var names []string
+	rootRegistry.Each(func(name string, _ metrics.Tags, _ metrics.MetricVal) {
+		names = append(names, name)
+	})
+	assert.Empty(t, names)
+
+	_, err = client.Get(ctx, httpclient.WithRPCMethodName("getThing"))
+	require.NoError(t, err)
+	rootRegistry.Each(func(name string, _ metrics.Tags, _ metrics.MetricVal) {
+		names = append(names, name)
+	})
+	assert.Contains(t, names, "client.response")
+	assert.Contains(t, names, "client.call")
+}
+
+func TestMetricsMiddleware_FallbackRegistry(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	fallback := metrics.NewRootMetricsRegistry()
+	client, err := httpclient.NewClient(
+		httpclient.WithBaseURLs([]string{srv.URL}),
+		httpclient.WithServiceName("test-service"),
+		httpclient.WithFallbackMetricsRegistry(fallback))
+	require.NoError(t, err)
+	responseCount := func(registry metrics.Registry) int64 {
+		var count int64
+		registry.Each(func(name string, _ metrics.Tags, value metrics.MetricVal) {
+			if name == "client.response" {
+				count += value.Values()["count"].(int64)
+			}
+		})
+		return count
+	}
+
+	_, err = client.Get(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), responseCount(fallback))
+
+	// a registry on the context takes precedence over the fallback.
+	registry := metrics.NewRootMetricsRegistry()
+	_, err = client.Get(metrics.WithRegistry(context.Background(), registry))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), responseCount(registry))
+	assert.Equal(t, int64(1), responseCount(fallback))
+}
+
 func TestMetricsMiddleware_ContextCanceled(t *testing.T) {
 	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
 		w.WriteHeader(200)
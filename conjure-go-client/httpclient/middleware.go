@@ -16,6 +16,8 @@ package httpclient
 
 import (
 	"net/http"
+
+	"github.com/palantir/pkg/refreshable"
 )
 
 // A Middleware wraps an http client's request and is able to read or modify the request and response.
@@ -32,6 +34,28 @@ func (f MiddlewareFunc) RoundTrip(req *http.Request, next http.RoundTripper) (*h
 	return f(req, next)
 }
 
+// NewConditionalMiddleware returns a Middleware which invokes delegate unless disabled is true, in which case the
+// request is passed directly to the next round tripper. The value of disabled is checked on every request, so the
+// delegate can be toggled at runtime. A nil disabled is treated as always false.
+func NewConditionalMiddleware(disabled refreshable.Bool, delegate Middleware) Middleware {
+	return &conditionalMiddleware{
+		disabled: disabled,
+		delegate: delegate,
+	}
+}
+
+type conditionalMiddleware struct {
+	disabled refreshable.Bool
+	delegate Middleware
+}
+
+func (m *conditionalMiddleware) RoundTrip(req *http.Request, next http.RoundTripper) (*http.Response, error) {
+	if m.delegate == nil || (m.disabled != nil && m.disabled.CurrentBool()) {
+		return next.RoundTrip(req)
+	}
+	return m.delegate.RoundTrip(req, next)
+}
+
 // wrapTransport is used by clientBuilder to create the final Client's RoundTripper.
 func wrapTransport(baseTransport http.RoundTripper, middlewares ...Middleware) http.RoundTripper {
 	if baseTransport == nil {
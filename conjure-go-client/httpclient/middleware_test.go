@@ -0,0 +1,60 @@
+// Copyright (c) 2026 Palantir Technologies. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpclient_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/palantir/conjure-go-runtime/v2/conjure-go-client/httpclient"
+	"github.com/palantir/conjure-go-runtime/v2/conjure-go-contract/codecs"
+	"github.com/palantir/pkg/refreshable"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConditionalMiddleware(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		_, _ = rw.Write([]byte(req.Header.Get("X-Conditional")))
+	}))
+	defer server.Close()
+
+	disabled := refreshable.NewDefaultRefreshable(false)
+	client, err := httpclient.NewClient(
+		httpclient.WithBaseURLs([]string{server.URL}),
+		httpclient.WithMiddleware(httpclient.NewConditionalMiddleware(refreshable.NewBool(disabled),
+			httpclient.MiddlewareFunc(func(req *http.Request, next http.RoundTripper) (*http.Response, error) {
+				req.Header.Set("X-Conditional", "enabled")
+				return next.RoundTrip(req)
+			}))),
+	)
+	require.NoError(t, err)
+
+	doRequest := func() string {
+		var body string
+		_, err := client.Do(context.Background(), httpclient.WithRequestMethod(http.MethodGet), httpclient.WithResponseBody(&body, codecs.Plain))
+		require.NoError(t, err)
+		return body
+	}
+	assert.Equal(t, "enabled", doRequest())
+
+	require.NoError(t, disabled.Update(true))
+	assert.Equal(t, "", doRequest())
+
+	require.NoError(t, disabled.Update(false))
+	assert.Equal(t, "enabled", doRequest())
+}
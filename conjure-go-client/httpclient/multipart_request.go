@@ -0,0 +1,203 @@
+// Copyright (c) 2026 Palantir Technologies. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpclient
+
+import (
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/palantir/conjure-go-runtime/v2/conjure-go-contract/httpheaders"
+	werror "github.com/palantir/witchcraft-go-error"
+)
+
+// MultipartPart is a part of a multipart/form-data request body. Parts are created with MultipartField and
+// MultipartFile.
+type MultipartPart struct {
+	name        string
+	fileName    string
+	contentType string
+	value       string
+	// reader is non-nil for file parts.
+	reader io.Reader
+	// seeker is non-nil if reader implements io.Seeker, in which case start is the offset to rewind to.
+	seeker  io.Seeker
+	start   int64
+	seekErr error
+}
+
+// MultipartField returns a form field part with the provided name and value.
+func MultipartField(name, value string) MultipartPart {
+	return MultipartPart{name: name, value: value}
+}
+
+// MultipartFile returns a file part of the form field name whose content is read from r. If contentType is empty,
+// "application/octet-stream" is used. If r implements io.Seeker, it is rewound to its position at the time
+// MultipartFile was called before each attempt. The caller remains responsible for closing r after the request
+// completes.
+func MultipartFile(name, fileName, contentType string, r io.Reader) MultipartPart {
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	part := MultipartPart{name: name, fileName: fileName, contentType: contentType, reader: r}
+	if seeker, ok := r.(io.Seeker); ok {
+		part.seeker = seeker
+		part.start, part.seekErr = seeker.Seek(0, io.SeekCurrent)
+	}
+	return part
+}
+
+func (p MultipartPart) rewind() error {
+	if p.seeker == nil {
+		return nil
+	}
+	if _, err := p.seeker.Seek(p.start, io.SeekStart); err != nil {
+		return werror.Wrap(err, "failed to rewind multipart file reader", werror.SafeParam("partName", p.name))
+	}
+	return nil
+}
+
+var multipartQuoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+func (p MultipartPart) write(w *multipart.Writer) error {
+	if p.reader == nil {
+		return w.WriteField(p.name, p.value)
+	}
+	header := make(textproto.MIMEHeader)
+	header.Set(httpheaders.ContentDisposition, `form-data; name="`+multipartQuoteEscaper.Replace(p.name)+
+		`"; filename="`+multipartQuoteEscaper.Replace(p.fileName)+`"`)
+	header.Set(httpheaders.ContentType, p.contentType)
+	partWriter, err := w.CreatePart(header)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(partWriter, p.reader)
+	return err
+}
+
+// WithMultipartRequest streams parts as a multipart/form-data request body and sets the Content-Type header with the
+// body's boundary. The body is written as the transport sends it, so file parts are never buffered in memory.
+//
+// If every file part's reader implements io.Seeker, the readers are rewound before each attempt so that the request
+// can be retried and redirected. Otherwise, the body can only be sent once, so the request is not retried: the
+// result of the first attempt is returned whatever its outcome.
+func WithMultipartRequest(parts ...MultipartPart) RequestParam {
+	body := &multipartRequestBody{parts: parts, boundary: multipart.NewWriter(io.Discard).Boundary(), replayable: true}
+	for _, part := range parts {
+		if part.reader != nil && part.seeker == nil {
+			body.replayable = false
+		}
+	}
+	return requestParamFunc(func(b *requestBuilder) error {
+		for _, part := range parts {
+			if part.name == "" {
+				return werror.Error("multipart part name can not be empty")
+			}
+			if part.seekErr != nil {
+				return werror.Wrap(part.seekErr, "failed to determine multipart file reader position", werror.SafeParam("partName", part.name))
+			}
+		}
+		b.bodyMiddleware.requestInput = body
+		b.bodyMiddleware.requestEncoder = nil
+		b.headers.Set(httpheaders.ContentType, httpheaders.FormatContentType("multipart/form-data", map[string]string{"boundary": body.boundary}))
+		return nil
+	})
+}
+
+// multipartRequestBody is the request body configured by WithMultipartRequest.
+type multipartRequestBody struct {
+	parts      []MultipartPart
+	boundary   string
+	replayable bool
+	// used is set once a body which is not replayable has been attached to a request.
+	used atomic.Bool
+
+	mu sync.Mutex
+	// reader and written are the reading end of the most recent body and a channel closed once its writer returns.
+	reader  *io.PipeReader
+	written chan struct{}
+}
+
+func (b *multipartRequestBody) setRequestBody(req *http.Request) error {
+	if !b.replayable {
+		if !b.used.CompareAndSwap(false, true) {
+			return werror.WrapWithContextParams(req.Context(), ErrRequestBodyNotReplayable, "")
+		}
+		markBodyNotReplayable(req.Context())
+	}
+	body, err := b.newBody()
+	if err != nil {
+		return err
+	}
+	req.Body = body
+	req.ContentLength = -1
+	req.GetBody = func() (io.ReadCloser, error) {
+		if !b.replayable {
+			return nil, ErrRequestBodyNotReplayable
+		}
+		return b.newBody()
+	}
+	return nil
+}
+
+// newBody returns a reader of a new copy of the body. Any previous copy is abandoned first, so that its writer no
+// longer reads from the parts when they are rewound.
+func (b *multipartRequestBody) newBody() (io.ReadCloser, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.reader != nil {
+		_ = b.reader.CloseWithError(werror.Error("multipart request body replaced"))
+		<-b.written
+	}
+	for _, part := range b.parts {
+		if err := part.rewind(); err != nil {
+			return nil, err
+		}
+	}
+	reader, writer := io.Pipe()
+	written := make(chan struct{})
+	b.reader, b.written = reader, written
+	go func() {
+		defer close(written)
+		_ = writer.CloseWithError(b.write(writer))
+	}()
+	return reader, nil
+}
+
+// close closes the reader of the most recent body, so that its writer returns if the body is never read.
+func (b *multipartRequestBody) close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.reader != nil {
+		_ = b.reader.Close()
+	}
+}
+
+func (b *multipartRequestBody) write(w io.Writer) error {
+	mw := multipart.NewWriter(w)
+	if err := mw.SetBoundary(b.boundary); err != nil {
+		return err
+	}
+	for _, part := range b.parts {
+		if err := part.write(mw); err != nil {
+			return werror.Wrap(err, "failed to write multipart request body", werror.SafeParam("partName", part.name))
+		}
+	}
+	return mw.Close()
+}
@@ -0,0 +1,159 @@
+// Copyright (c) 2026 Palantir Technologies. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpclient_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/palantir/conjure-go-runtime/v2/conjure-go-client/httpclient"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMultipartRequest(t *testing.T) {
+	type upload struct {
+		field       string
+		fileName    string
+		contentType string
+		content     string
+	}
+	var received []upload
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		requests.Add(1)
+		received = nil
+		if err := req.ParseMultipartForm(1 << 20); err != nil {
+			rw.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		file, header, err := req.FormFile("file")
+		if err != nil {
+			rw.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		content, _ := io.ReadAll(file)
+		received = append(received, upload{
+			field:       req.FormValue("description"),
+			fileName:    header.Filename,
+			contentType: header.Header.Get("Content-Type"),
+			content:     string(content),
+		})
+		if req.URL.Path == "/unavailable" && requests.Load() == 1 {
+			rw.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+	client, err := httpclient.NewClient(httpclient.WithBaseURLs([]string{server.URL}), httpclient.WithMaxRetries(1))
+	require.NoError(t, err)
+
+	t.Run("sends fields and files", func(t *testing.T) {
+		requests.Store(0)
+		_, err := client.Post(context.Background(), httpclient.WithMultipartRequest(
+			httpclient.MultipartField("description", "quarterly report"),
+			httpclient.MultipartFile("file", `report "q1".csv`, "text/csv", strings.NewReader("a,b\n1,2\n")),
+		))
+		require.NoError(t, err)
+		assert.Equal(t, []upload{{
+			field:       "quarterly report",
+			fileName:    `report "q1".csv`,
+			contentType: "text/csv",
+			content:     "a,b\n1,2\n",
+		}}, received)
+	})
+
+	t.Run("retries with seekable files", func(t *testing.T) {
+		requests.Store(0)
+		_, err := client.Post(context.Background(), httpclient.WithPath("/unavailable"), httpclient.WithMultipartRequest(
+			httpclient.MultipartField("description", "retried"),
+			httpclient.MultipartFile("file", "data.bin", "", bytes.NewReader([]byte("payload"))),
+		))
+		require.NoError(t, err)
+		assert.Equal(t, int32(2), requests.Load())
+		assert.Equal(t, []upload{{
+			field:       "retried",
+			fileName:    "data.bin",
+			contentType: "application/octet-stream",
+			content:     "payload",
+		}}, received)
+	})
+
+	t.Run("does not retry other files", func(t *testing.T) {
+		requests.Store(0)
+		_, err := client.Post(context.Background(), httpclient.WithPath("/unavailable"), httpclient.WithMultipartRequest(
+			httpclient.MultipartFile("file", "data.bin", "", io.MultiReader(strings.NewReader("payload"))),
+		))
+		require.Error(t, err)
+		assert.Equal(t, int32(1), requests.Load())
+	})
+
+	t.Run("closes unread body on error", func(t *testing.T) {
+		rejectingClient, err := httpclient.NewClient(
+			httpclient.WithBaseURLs([]string{server.URL}),
+			httpclient.WithMiddleware(httpclient.MiddlewareFunc(func(req *http.Request, _ http.RoundTripper) (*http.Response, error) {
+				// read past the part headers so that the file is being copied, then fail without closing the body.
+				if _, err := io.ReadFull(req.Body, make([]byte, 1024)); err != nil {
+					return nil, err
+				}
+				return nil, errors.New("rejected")
+			})))
+		require.NoError(t, err)
+		file := &blockingWriterTo{done: make(chan error, 1)}
+		_, err = rejectingClient.Post(context.Background(), httpclient.WithMultipartRequest(
+			httpclient.MultipartFile("file", "data.bin", "", file),
+		))
+		require.Error(t, err)
+		select {
+		case err := <-file.done:
+			assert.Equal(t, io.ErrClosedPipe, err)
+		case <-time.After(5 * time.Second):
+			t.Fatal("multipart body writer should return once the request fails")
+		}
+	})
+
+	t.Run("empty part name", func(t *testing.T) {
+		_, err := client.Post(context.Background(), httpclient.WithMultipartRequest(httpclient.MultipartField("", "value")))
+		require.EqualError(t, err, "multipart part name can not be empty")
+	})
+}
+
+// blockingWriterTo writes to the multipart body until the write fails, then sends the error to done.
+type blockingWriterTo struct {
+	done chan error
+}
+
+func (*blockingWriterTo) Read([]byte) (int, error) {
+	return 0, errors.New("blockingWriterTo must be copied with WriteTo")
+}
+
+func (w *blockingWriterTo) WriteTo(dst io.Writer) (int64, error) {
+	var written int64
+	for {
+		n, err := dst.Write([]byte("payload"))
+		written += int64(n)
+		if err != nil {
+			w.done <- err
+			return written, err
+		}
+	}
+}
@@ -0,0 +1,78 @@
+// Copyright (c) 2026 Palantir Technologies. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpclient
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+
+	werror "github.com/palantir/witchcraft-go-error"
+)
+
+const onBehalfOfHeaderKey = "X-On-Behalf-Of"
+
+// OnBehalfOfProvider accepts a context and returns the principal on whose behalf the request is made.
+// An empty string and nil error indicates that no delegated identity should be sent.
+type OnBehalfOfProvider func(context.Context) (string, error)
+
+// onBehalfOfMiddleware sets the X-On-Behalf-Of header using the principal set on the request or returned by the
+// provider. Errors returned for requests carrying a delegated identity are annotated with a keyed hash of the
+// principal as a safe param so that failures can be correlated without logging the principal itself.
+type onBehalfOfMiddleware struct {
+	provider OnBehalfOfProvider
+}
+
+func (m *onBehalfOfMiddleware) RoundTrip(req *http.Request, next http.RoundTripper) (*http.Response, error) {
+	principal := getRequestOnBehalfOf(req.Context())
+	if principal == "" && m.provider != nil {
+		p, err := m.provider(req.Context())
+		if err != nil {
+			return nil, err
+		}
+		principal = p
+	}
+	if principal == "" {
+		return next.RoundTrip(req)
+	}
+	req.Header.Set(onBehalfOfHeaderKey, principal)
+	resp, err := next.RoundTrip(req)
+	if err != nil {
+		return nil, werror.WrapWithContextParams(req.Context(), err, "", werror.SafeParam("onBehalfOfHash", hashPrincipal(principal)))
+	}
+	return resp, nil
+}
+
+// principalHashKey keys the hashes of principals and of base URIs in metric tags. It is random for each process, so
+// that low-entropy values such as usernames and host names can not be recovered from their hash with a dictionary;
+// hashes can only be correlated within a process.
+var principalHashKey = newPrincipalHashKey()
+
+func newPrincipalHashKey() []byte {
+	key := make([]byte, sha256.Size)
+	if _, err := rand.Read(key); err != nil {
+		panic(werror.Wrap(err, "failed to generate on-behalf-of principal hash key"))
+	}
+	return key
+}
+
+func hashPrincipal(principal string) string {
+	mac := hmac.New(sha256.New, principalHashKey)
+	_, _ = mac.Write([]byte(principal))
+	return hex.EncodeToString(mac.Sum(nil)[:8])
+}
@@ -0,0 +1,73 @@
+// Copyright (c) 2026 Palantir Technologies. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpclient_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/palantir/conjure-go-runtime/v2/conjure-go-client/httpclient"
+	werror "github.com/palantir/witchcraft-go-error"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOnBehalfOf(t *testing.T) {
+	var header string
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		header = req.Header.Get("X-On-Behalf-Of")
+		if req.URL.Path == "/fail" {
+			rw.WriteHeader(http.StatusForbidden)
+			return
+		}
+		rw.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client, err := httpclient.NewClient(
+		httpclient.WithOnBehalfOfProvider(func(ctx context.Context) (string, error) {
+			return "client-principal", nil
+		}),
+		httpclient.WithBaseURLs([]string{server.URL}))
+	require.NoError(t, err)
+
+	_, err = client.Get(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "client-principal", header)
+
+	_, err = client.Get(context.Background(), httpclient.WithRequestOnBehalfOf("request-principal"))
+	require.NoError(t, err)
+	assert.Equal(t, "request-principal", header)
+
+	_, err = client.Get(context.Background(), httpclient.WithPath("/fail"))
+	require.Error(t, err)
+	hash, ok := werror.ParamFromError(err, "onBehalfOfHash")
+	require.True(t, ok)
+	assert.Len(t, hash, 16)
+	assert.NotContains(t, hash, "client-principal")
+	// the hash is keyed, so it can not be matched against the unkeyed hashes of candidate principals.
+	unkeyed := sha256.Sum256([]byte("client-principal"))
+	assert.NotEqual(t, hex.EncodeToString(unkeyed[:8]), hash)
+
+	// failures of the same principal can be correlated.
+	_, err = client.Get(context.Background(), httpclient.WithPath("/fail"))
+	require.Error(t, err)
+	sameHash, _ := werror.ParamFromError(err, "onBehalfOfHash")
+	assert.Equal(t, hash, sameHash)
+}
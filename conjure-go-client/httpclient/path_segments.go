@@ -0,0 +1,69 @@
+// Copyright (c) 2026 Palantir Technologies. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpclient
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// JoinPathSegments returns a path made of segments, each escaped independently, so that slashes, semicolons and other
+// reserved characters within a segment are sent percent-encoded rather than changing the structure of the path. It
+// can express repeated path parameters, e.g. JoinPathSegments(append([]string{"files"}, parts...)...). The result is
+// already escaped and is sent as is by WithPath, so it must not be escaped again.
+func JoinPathSegments(segments ...string) string {
+	var path strings.Builder
+	for _, segment := range segments {
+		path.WriteByte('/')
+		path.WriteString(url.PathEscape(segment))
+	}
+	return path.String()
+}
+
+// WithPathSegments sets the path of the request to JoinPathSegments(segments...).
+func WithPathSegments(segments ...string) RequestParam {
+	return WithPath(JoinPathSegments(segments...))
+}
+
+// MatrixParams returns params encoded as the matrix parameters of a path segment, e.g. ";color=red;color=blue;size=10",
+// to be appended to an escaped segment such as one returned by JoinPathSegments:
+//
+//	WithPath(JoinPathSegments("things", thingID) + MatrixParams(url.Values{"color": {"red", "blue"}}))
+//
+// Keys are sorted and each value of a key is written as a separate parameter. Keys and values are escaped
+// independently, so they may contain semicolons, equals signs and slashes.
+func MatrixParams(params url.Values) string {
+	keys := make([]string, 0, len(params))
+	for key := range params {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var encoded strings.Builder
+	for _, key := range keys {
+		for _, value := range params[key] {
+			encoded.WriteByte(';')
+			encoded.WriteString(escapeMatrixParam(key))
+			encoded.WriteByte('=')
+			encoded.WriteString(escapeMatrixParam(value))
+		}
+	}
+	return encoded.String()
+}
+
+// escapeMatrixParam escapes s as a path segment, also escaping the equals signs which separate keys from values.
+func escapeMatrixParam(s string) string {
+	return strings.ReplaceAll(url.PathEscape(s), "=", "%3D")
+}
@@ -0,0 +1,57 @@
+// Copyright (c) 2026 Palantir Technologies. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpclient_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/palantir/conjure-go-runtime/v2/conjure-go-client/httpclient"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJoinPathSegments(t *testing.T) {
+	assert.Equal(t, "", httpclient.JoinPathSegments())
+	assert.Equal(t, "/files/a%2Fb/c%3Bd/e%20f/%25", httpclient.JoinPathSegments("files", "a/b", "c;d", "e f", "%"))
+}
+
+func TestMatrixParams(t *testing.T) {
+	assert.Equal(t, "", httpclient.MatrixParams(nil))
+	assert.Equal(t, ";color=red;color=blue;size%3D=1%3B2",
+		httpclient.MatrixParams(url.Values{"size=": {"1;2"}, "color": {"red", "blue"}}))
+}
+
+func TestWithPathSegments(t *testing.T) {
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		paths = append(paths, req.URL.EscapedPath())
+	}))
+	defer server.Close()
+
+	client, err := httpclient.NewClient(httpclient.WithBaseURLs([]string{server.URL + "/api"}))
+	require.NoError(t, err)
+
+	parts := []string{"a/b", "c%2Fd"}
+	_, err = client.Get(context.Background(), httpclient.WithPathSegments(append([]string{"files"}, parts...)...))
+	require.NoError(t, err)
+	_, err = client.Get(context.Background(), httpclient.WithPath(
+		httpclient.JoinPathSegments("things", "x;y")+httpclient.MatrixParams(url.Values{"v": {"1", "2"}})))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"/api/files/a%2Fb/c%252Fd", "/api/things/x%3By;v=1;v=2"}, paths)
+}
@@ -0,0 +1,201 @@
+// Copyright (c) 2026 Palantir Technologies. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpclient
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/palantir/pkg/retry"
+	werror "github.com/palantir/witchcraft-go-error"
+)
+
+const (
+	defaultPollInitialBackoff = 1 * time.Second
+	defaultPollMaxBackoff     = 30 * time.Second
+)
+
+// PollCursorFunc returns the cursor to send with the next poll request given a successful response and its body.
+type PollCursorFunc func(resp *http.Response, body []byte) (string, error)
+
+// PollResult is a single result received by a Poller. Exactly one of Body and Err is set.
+type PollResult struct {
+	// Body is the body of a successful response which contained data.
+	Body []byte
+	// Cursor is the cursor sent with the request which returned this result.
+	Cursor string
+	// Err is the error returned for a failed poll.
+	Err error
+}
+
+// PollerParam configures a Poller.
+type PollerParam interface {
+	applyPoller(*Poller) error
+}
+
+type pollerParamFunc func(*Poller) error
+
+func (f pollerParamFunc) applyPoller(p *Poller) error {
+	return f(p)
+}
+
+// WithPollRequestParams provides the request params used for each poll request, e.g. the path of the change feed.
+// These are applied after the default GET method, and before the cursor query param is set.
+func WithPollRequestParams(params ...RequestParam) PollerParam {
+	return pollerParamFunc(func(p *Poller) error {
+		p.requestParams = append(p.requestParams, params...)
+		return nil
+	})
+}
+
+// WithPollInitialCursor sets the cursor sent with the first poll request. If unset, the first request has no cursor.
+func WithPollInitialCursor(cursor string) PollerParam {
+	return pollerParamFunc(func(p *Poller) error {
+		p.initialCursor = cursor
+		return nil
+	})
+}
+
+// WithPollBackoff sets the range of the exponential backoff, with jitter, used after an empty (204 No Content) or
+// failed poll. Defaults to 1 second and 30 seconds.
+func WithPollBackoff(initialBackoff, maxBackoff time.Duration) PollerParam {
+	return pollerParamFunc(func(p *Poller) error {
+		if initialBackoff <= 0 || maxBackoff < initialBackoff {
+			return werror.Error("invalid poll backoff",
+				werror.SafeParam("initialBackoff", initialBackoff.String()),
+				werror.SafeParam("maxBackoff", maxBackoff.String()))
+		}
+		p.initialBackoff = initialBackoff
+		p.maxBackoff = maxBackoff
+		return nil
+	})
+}
+
+// Poller repeatedly polls a change-feed style endpoint using a GET request with a cursor query param. Each
+// request is executed using the client's Do, so it benefits from the client's URI selection and retries. If a
+// poll returns data, the next poll is sent immediately with the cursor returned by the PollCursorFunc. If a poll
+// returns 204 No Content or fails, the next poll is sent with the same cursor after a backoff.
+type Poller struct {
+	client      Client
+	cursorParam string
+	nextCursor  PollCursorFunc
+
+	requestParams  []RequestParam
+	initialCursor  string
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+}
+
+// NewPoller returns a Poller which sends the cursor in the cursorParam query param of each request.
+func NewPoller(client Client, cursorParam string, nextCursor PollCursorFunc, params ...PollerParam) (*Poller, error) {
+	p := &Poller{
+		client:         client,
+		cursorParam:    cursorParam,
+		nextCursor:     nextCursor,
+		initialBackoff: defaultPollInitialBackoff,
+		maxBackoff:     defaultPollMaxBackoff,
+	}
+	for _, param := range params {
+		if param == nil {
+			continue
+		}
+		if err := param.applyPoller(p); err != nil {
+			return nil, err
+		}
+	}
+	return p, nil
+}
+
+// Poll starts polling and returns a channel of results. Failed polls are delivered as results with Err set, after
+// which polling resumes unless the error has a 4xx status code other than 429, in which case polling stops.
+// The channel is closed when polling stops or ctx is done. The caller must receive from the channel until it is
+// closed, or cancel ctx.
+func (p *Poller) Poll(ctx context.Context) <-chan PollResult {
+	results := make(chan PollResult)
+	go func() {
+		defer close(results)
+		send := func(result PollResult) bool {
+			select {
+			case results <- result:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		cursor := p.initialCursor
+		retrier := retry.Start(ctx, retry.WithInitialBackoff(p.initialBackoff), retry.WithMaxBackoff(p.maxBackoff))
+		for retrier.Next() {
+			body, next, hasData, err := p.pollOnce(ctx, cursor)
+			if err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				if !send(PollResult{Cursor: cursor, Err: err}) || isPermanentPollError(err) {
+					return
+				}
+				continue
+			}
+			if !hasData {
+				continue
+			}
+			if !send(PollResult{Body: body, Cursor: cursor}) {
+				return
+			}
+			cursor = next
+			retrier.Reset()
+		}
+	}()
+	return results
+}
+
+func (p *Poller) pollOnce(ctx context.Context, cursor string) (body []byte, next string, hasData bool, err error) {
+	params := append([]RequestParam{WithRequestMethod(http.MethodGet)}, p.requestParams...)
+	params = append(params, WithRawResponseBody())
+	if cursor != "" {
+		params = append(params, requestParamFunc(func(b *requestBuilder) error {
+			if b.query == nil {
+				b.query = make(url.Values)
+			}
+			b.query.Set(p.cursorParam, cursor)
+			return nil
+		}))
+	}
+	resp, err := p.client.Do(ctx, params...)
+	if err != nil {
+		return nil, "", false, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode == http.StatusNoContent {
+		return nil, "", false, nil
+	}
+	body, err = ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", false, werror.WrapWithContextParams(ctx, err, "failed to read poll response body")
+	}
+	next, err = p.nextCursor(resp, body)
+	if err != nil {
+		return nil, "", false, werror.WrapWithContextParams(ctx, err, "failed to determine next poll cursor")
+	}
+	return body, next, true, nil
+}
+
+func isPermanentPollError(err error) bool {
+	statusCode, ok := StatusCodeFromError(err)
+	return ok && statusCode >= http.StatusBadRequest && statusCode < http.StatusInternalServerError && statusCode != http.StatusTooManyRequests
+}
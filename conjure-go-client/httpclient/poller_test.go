@@ -0,0 +1,125 @@
+// Copyright (c) 2026 Palantir Technologies. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpclient_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/palantir/conjure-go-runtime/v2/conjure-go-client/httpclient"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPoller(t *testing.T) {
+	var mu sync.Mutex
+	var cursors []string
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, http.MethodGet, req.Method)
+		assert.Equal(t, "/changes", req.URL.Path)
+		cursor := req.URL.Query().Get("cursor")
+		mu.Lock()
+		cursors = append(cursors, cursor)
+		numRequests := len(cursors)
+		mu.Unlock()
+		switch numRequests {
+		case 2:
+			rw.WriteHeader(http.StatusNoContent)
+		case 3:
+			rw.WriteHeader(http.StatusInternalServerError)
+		case 5:
+			rw.WriteHeader(http.StatusNotFound)
+		default:
+			next, _ := strconv.Atoi(cursor)
+			rw.Header().Set("X-Next-Cursor", strconv.Itoa(next+1))
+			_, _ = rw.Write([]byte("data-" + cursor))
+		}
+	}))
+	defer server.Close()
+
+	client, err := httpclient.NewClient(httpclient.WithBaseURLs([]string{server.URL}), httpclient.WithMaxRetries(0))
+	require.NoError(t, err)
+	poller, err := httpclient.NewPoller(client, "cursor",
+		func(resp *http.Response, body []byte) (string, error) {
+			return resp.Header.Get("X-Next-Cursor"), nil
+		},
+		httpclient.WithPollRequestParams(httpclient.WithPath("/changes")),
+		httpclient.WithPollInitialCursor("1"),
+		httpclient.WithPollBackoff(time.Millisecond, 10*time.Millisecond))
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	var results []httpclient.PollResult
+	for result := range poller.Poll(ctx) {
+		results = append(results, result)
+	}
+	require.Len(t, results, 4)
+
+	assert.Equal(t, "data-1", string(results[0].Body))
+	assert.Equal(t, "1", results[0].Cursor)
+	assert.NoError(t, results[0].Err)
+
+	// the 204 is not surfaced; the 500 is surfaced and the same cursor is retried
+	assert.Error(t, results[1].Err)
+	assert.Equal(t, "2", results[1].Cursor)
+	assert.Equal(t, "data-2", string(results[2].Body))
+
+	// the 404 is permanent and stops polling
+	statusCode, ok := httpclient.StatusCodeFromError(results[3].Err)
+	require.True(t, ok)
+	assert.Equal(t, http.StatusNotFound, statusCode)
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []string{"1", "2", "2", "2", "3"}, cursors)
+}
+
+func TestPollerStopsOnContextCancel(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	client, err := httpclient.NewClient(httpclient.WithBaseURLs([]string{server.URL}))
+	require.NoError(t, err)
+	poller, err := httpclient.NewPoller(client, "cursor",
+		func(resp *http.Response, body []byte) (string, error) { return "", nil },
+		httpclient.WithPollBackoff(time.Millisecond, time.Millisecond))
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	results := poller.Poll(ctx)
+	time.Sleep(20 * time.Millisecond)
+	cancel()
+	select {
+	case _, ok := <-results:
+		assert.False(t, ok, "expected no results")
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "poller did not stop after context was canceled")
+	}
+}
+
+func TestPollerInvalidBackoff(t *testing.T) {
+	client, err := httpclient.NewClient(httpclient.WithBaseURLs([]string{"http://localhost"}))
+	require.NoError(t, err)
+	_, err = httpclient.NewPoller(client, "cursor", nil, httpclient.WithPollBackoff(time.Second, time.Millisecond))
+	assert.EqualError(t, err, "invalid poll backoff")
+}
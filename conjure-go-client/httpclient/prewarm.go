@@ -0,0 +1,113 @@
+// Copyright (c) 2026 Palantir Technologies. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpclient
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	werror "github.com/palantir/witchcraft-go-error"
+)
+
+// meshSchemePrefix marks URIs which are sent to a service mesh proxy. See internal.RequestRetrier.
+const meshSchemePrefix = "mesh-"
+
+// Prewarm resolves, connects and completes the TLS handshake to up to n of client's URIs, in the order they would be
+// tried, so that the first requests reuse established connections. If n is not positive, every URI is prewarmed.
+// Prewarm blocks until every connection is established or has failed; call it in a goroutine to warm connections in
+// the background. If ctx has no deadline, the client's timeout applies. An error is returned if any URI could not be
+// reached, in which case connections to the other URIs are still kept, or if client was not built by this package.
+func Prewarm(ctx context.Context, client Client, n int) error {
+	switch c := client.(type) {
+	case *clientImpl:
+		return c.prewarm(ctx, n)
+	case *dualModeClient:
+		return Prewarm(ctx, c.client(context.Background()), n)
+	default:
+		return werror.ErrorWithContextParams(ctx, "client can not be prewarmed because it was not built by this package")
+	}
+}
+
+func (c *clientImpl) prewarm(ctx context.Context, n int) error {
+	uris := c.uriScorer.CurrentURIScoringMiddleware().GetURIsInOrderOfIncreasingScore()
+	if len(uris) == 0 {
+		return werror.WrapWithContextParams(ctx, ErrEmptyURIs, "", werror.SafeParam("serviceName", c.serviceName.CurrentString()))
+	}
+	if n > 0 && n < len(uris) {
+		uris = uris[:n]
+	}
+	if _, ok := ctx.Deadline(); !ok {
+		if timeout := c.client.CurrentHTTPClient().Timeout; timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+	}
+
+	errs := make([]error, len(uris))
+	var wg sync.WaitGroup
+	for i, uri := range uris {
+		wg.Add(1)
+		go func(i int, uri string) {
+			defer wg.Done()
+			errs[i] = c.prewarmURI(ctx, uri)
+		}(i, uri)
+	}
+	wg.Wait()
+
+	var firstErr error
+	var failed int
+	for _, err := range errs {
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			failed++
+		}
+	}
+	if firstErr != nil {
+		return werror.WrapWithContextParams(ctx, firstErr, "failed to prewarm connections",
+			werror.SafeParam("serviceName", c.serviceName.CurrentString()),
+			werror.SafeParam("failedURIs", failed),
+			werror.SafeParam("prewarmedURIs", len(uris)-failed))
+	}
+	return nil
+}
+
+// prewarmURI sends an "OPTIONS *" request to the host of uri directly through the client's transport, which resolves
+// the host, connects and completes the TLS handshake, leaving an idle connection in the transport's pool. The
+// response is discarded whatever its status, and the request is not subject to middlewares, error decoding,
+// retries or metrics.
+func (c *clientImpl) prewarmURI(ctx context.Context, uri string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodOptions, strings.TrimPrefix(uri, meshSchemePrefix), nil)
+	if err != nil {
+		return werror.WrapWithContextParams(ctx, err, "failed to build prewarm request")
+	}
+	req.URL.Path = ""
+	req.URL.RawPath = ""
+	req.URL.RawQuery = ""
+	req.URL.Opaque = "*"
+	resp, err := c.transport.RoundTrip(req)
+	if err != nil {
+		return werror.WrapWithContextParams(ctx, err, "prewarm request failed", werror.SafeParam("requestHost", req.URL.Host))
+	}
+	// drain the body so that the connection is returned to the pool.
+	_, _ = io.Copy(io.Discard, resp.Body)
+	_ = resp.Body.Close()
+	return nil
+}
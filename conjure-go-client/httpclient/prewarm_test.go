@@ -0,0 +1,79 @@
+// Copyright (c) 2026 Palantir Technologies. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpclient_test
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/palantir/conjure-go-runtime/v2/conjure-go-client/httpclient"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrewarm(t *testing.T) {
+	var newConns, requests int32
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		rw.WriteHeader(http.StatusOK)
+	}))
+	server.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			atomic.AddInt32(&newConns, 1)
+		}
+	}
+	server.StartTLS()
+	defer server.Close()
+
+	trusted := x509.NewCertPool()
+	trusted.AddCert(server.Certificate())
+	client, err := httpclient.NewClient(
+		httpclient.WithBaseURLs([]string{server.URL + "/api"}),
+		httpclient.WithTLSConfig(&tls.Config{RootCAs: trusted}),
+	)
+	require.NoError(t, err)
+
+	require.NoError(t, httpclient.Prewarm(context.Background(), client, 1))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&newConns))
+	// the server answers "OPTIONS *" itself, so the handler is not invoked.
+	assert.Equal(t, int32(0), atomic.LoadInt32(&requests))
+
+	_, err = client.Get(context.Background(), httpclient.WithPath("/thing"))
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&newConns), "request should reuse the prewarmed connection")
+	assert.Equal(t, int32(1), atomic.LoadInt32(&requests))
+}
+
+func TestPrewarmUnreachableURI(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+
+	client, err := httpclient.NewClient(httpclient.WithBaseURLs([]string{server.URL, closed.URL}))
+	require.NoError(t, err)
+
+	err = httpclient.Prewarm(context.Background(), client, 0)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to prewarm connections")
+}
@@ -0,0 +1,59 @@
+// Copyright (c) 2026 Palantir Technologies. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpclient
+
+import (
+	"context"
+	"net/http"
+)
+
+// ContextWithPropagatedHeaders returns a copy of ctx carrying headers for clients configured with
+// WithPropagatedHeaders. Values are merged with those already stored on ctx, replacing values for the same header.
+// Only headers named in WithPropagatedHeaders are sent; all others are ignored.
+func ContextWithPropagatedHeaders(ctx context.Context, headers http.Header) context.Context {
+	merged := getPropagatedHeaders(ctx).Clone()
+	if merged == nil {
+		merged = make(http.Header, len(headers))
+	}
+	for name, values := range headers {
+		merged[http.CanonicalHeaderKey(name)] = append([]string(nil), values...)
+	}
+	return context.WithValue(ctx, propagatedHeaders, merged)
+}
+
+func getPropagatedHeaders(ctx context.Context) http.Header {
+	headers, _ := ctx.Value(propagatedHeaders).(http.Header)
+	return headers
+}
+
+// propagatedHeadersMiddleware copies the allowlisted headers stored on the request context by
+// ContextWithPropagatedHeaders onto the request. Headers already set on the request are not modified.
+type propagatedHeadersMiddleware struct {
+	names []string
+}
+
+func (m *propagatedHeadersMiddleware) RoundTrip(req *http.Request, next http.RoundTripper) (*http.Response, error) {
+	headers := getPropagatedHeaders(req.Context())
+	for _, name := range m.names {
+		values := headers.Values(name)
+		if len(values) == 0 || req.Header.Get(name) != "" {
+			continue
+		}
+		for _, value := range values {
+			req.Header.Add(name, value)
+		}
+	}
+	return next.RoundTrip(req)
+}
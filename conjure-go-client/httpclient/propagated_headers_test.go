@@ -0,0 +1,65 @@
+// Copyright (c) 2026 Palantir Technologies. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpclient_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/palantir/conjure-go-runtime/v2/conjure-go-client/httpclient"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPropagatedHeaders(t *testing.T) {
+	var received http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		received = req.Header.Clone()
+		rw.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client, err := httpclient.NewClient(
+		httpclient.WithBaseURLs([]string{server.URL}),
+		httpclient.WithPropagatedHeaders("x-correlation-id", "X-Tenant-Id"),
+	)
+	require.NoError(t, err)
+
+	ctx := httpclient.ContextWithPropagatedHeaders(context.Background(), http.Header{
+		"X-Correlation-Id": []string{"correlation"},
+		"X-Secret":         []string{"secret"},
+	})
+	ctx = httpclient.ContextWithPropagatedHeaders(ctx, http.Header{"x-tenant-id": []string{"tenant"}})
+
+	t.Run("allowlisted headers are propagated", func(t *testing.T) {
+		_, err := client.Get(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, "correlation", received.Get("X-Correlation-Id"))
+		assert.Equal(t, "tenant", received.Get("X-Tenant-Id"))
+		assert.Empty(t, received.Get("X-Secret"))
+	})
+	t.Run("request headers take precedence", func(t *testing.T) {
+		_, err := client.Get(ctx, httpclient.WithHeader("X-Tenant-Id", "other"))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"other"}, received.Values("X-Tenant-Id"))
+	})
+	t.Run("no headers on context", func(t *testing.T) {
+		_, err := client.Get(context.Background())
+		require.NoError(t, err)
+		assert.Empty(t, received.Get("X-Correlation-Id"))
+	})
+}
@@ -0,0 +1,167 @@
+// Copyright (c) 2026 Palantir Technologies. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package qostest provides HTTP servers which respond with scripted sequences of the quality-of-service responses
+// handled by httpclient: throttling, unavailability, redirects and connection resets. Each Server is a single URI, so
+// a client configured with several servers can be tested against a cluster whose nodes misbehave independently.
+package qostest
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Behavior writes the response to a request received by a Server.
+type Behavior func(rw http.ResponseWriter, req *http.Request)
+
+// Status responds with an empty body and the given status code.
+func Status(code int) Behavior {
+	return func(rw http.ResponseWriter, _ *http.Request) {
+		rw.WriteHeader(code)
+	}
+}
+
+// OK responds with status 200 and an empty body.
+func OK() Behavior {
+	return Status(http.StatusOK)
+}
+
+// Throttle responds with status 429. If retryAfter is positive, it is sent in the Retry-After header, rounded up to
+// the nearest second.
+func Throttle(retryAfter time.Duration) Behavior {
+	return func(rw http.ResponseWriter, _ *http.Request) {
+		if retryAfter > 0 {
+			seconds := (retryAfter + time.Second - 1) / time.Second
+			rw.Header().Set("Retry-After", strconv.FormatInt(int64(seconds), 10))
+		}
+		rw.WriteHeader(http.StatusTooManyRequests)
+	}
+}
+
+// Unavailable responds with status 503.
+func Unavailable() Behavior {
+	return Status(http.StatusServiceUnavailable)
+}
+
+// PermanentRedirect responds with status 308 and a Location header of location joined with the request's path and
+// query, so that clients retry the request against another URI. If location is empty, no Location header is sent and
+// clients try their next URI.
+func PermanentRedirect(location string) Behavior {
+	return func(rw http.ResponseWriter, req *http.Request) {
+		if location != "" {
+			rw.Header().Set("Location", location+req.URL.RequestURI())
+		}
+		rw.WriteHeader(http.StatusPermanentRedirect)
+	}
+}
+
+// ConnectionReset closes the connection without writing a response, sending a TCP reset so that the client sees a
+// network error rather than an orderly close.
+func ConnectionReset() Behavior {
+	return func(rw http.ResponseWriter, _ *http.Request) {
+		hijacker, ok := rw.(http.Hijacker)
+		if !ok {
+			panic("qostest: ConnectionReset requires a ResponseWriter which implements http.Hijacker")
+		}
+		conn, _, err := hijacker.Hijack()
+		if err != nil {
+			panic(err)
+		}
+		if tcpConn, ok := conn.(*net.TCPConn); ok {
+			_ = tcpConn.SetLinger(0)
+		}
+		_ = conn.Close()
+	}
+}
+
+// Repeat returns a script of n copies of b.
+func Repeat(n int, b Behavior) []Behavior {
+	script := make([]Behavior, n)
+	for i := range script {
+		script[i] = b
+	}
+	return script
+}
+
+// Server is an HTTP server which responds to each request with the next Behavior of its script. Once the script is
+// exhausted, requests are handled by the default Behavior, which is OK unless set with SetDefault. It is safe for
+// concurrent use.
+type Server struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	script   []Behavior
+	fallback Behavior
+	requests []*http.Request
+}
+
+// NewServer starts and returns a new Server which responds with script, followed by OK.
+func NewServer(script ...Behavior) *Server {
+	s := &Server{script: script, fallback: OK()}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// Script replaces the remaining script of the server.
+func (s *Server) Script(script ...Behavior) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.script = script
+}
+
+// SetDefault sets the Behavior used once the script is exhausted.
+func (s *Server) SetDefault(b Behavior) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.fallback = b
+}
+
+// Requests returns the requests received by the server, in the order they were received. Their bodies have been
+// closed.
+func (s *Server) Requests() []*http.Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*http.Request(nil), s.requests...)
+}
+
+// RequestCount returns the number of requests received by the server.
+func (s *Server) RequestCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.requests)
+}
+
+func (s *Server) serveHTTP(rw http.ResponseWriter, req *http.Request) {
+	s.mu.Lock()
+	s.requests = append(s.requests, req.Clone(req.Context()))
+	behavior := s.fallback
+	if len(s.script) > 0 {
+		behavior, s.script = s.script[0], s.script[1:]
+	}
+	s.mu.Unlock()
+	behavior(rw, req)
+}
+
+// URLs returns the URLs of servers, in order, e.g. to configure a client with WithBaseURLs.
+func URLs(servers ...*Server) []string {
+	urls := make([]string, len(servers))
+	for i, s := range servers {
+		urls[i] = s.URL
+	}
+	return urls
+}
@@ -0,0 +1,83 @@
+// Copyright (c) 2026 Palantir Technologies. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package qostest_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/palantir/conjure-go-runtime/v2/conjure-go-client/httpclient"
+	"github.com/palantir/conjure-go-runtime/v2/conjure-go-client/httpclient/qostest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServer(t *testing.T) {
+	s := qostest.NewServer(qostest.Unavailable(), qostest.Throttle(2*time.Second))
+	defer s.Close()
+
+	for _, expected := range []int{http.StatusServiceUnavailable, http.StatusTooManyRequests, http.StatusOK} {
+		resp, err := http.Get(s.URL + "/path")
+		require.NoError(t, err)
+		_ = resp.Body.Close()
+		assert.Equal(t, expected, resp.StatusCode)
+		if expected == http.StatusTooManyRequests {
+			assert.Equal(t, "2", resp.Header.Get("Retry-After"))
+		}
+	}
+	require.Equal(t, 3, s.RequestCount())
+	assert.Equal(t, "/path", s.Requests()[0].URL.Path)
+
+	s.SetDefault(qostest.Status(http.StatusTeapot))
+	resp, err := http.Get(s.URL)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.Equal(t, http.StatusTeapot, resp.StatusCode)
+
+	// a new connection, as the transport retries requests which fail on a reused one
+	s.Script(qostest.ConnectionReset())
+	_, err = (&http.Client{Transport: &http.Transport{}}).Get(s.URL)
+	assert.Error(t, err)
+}
+
+func TestClientFailover(t *testing.T) {
+	target := qostest.NewServer()
+	defer target.Close()
+	servers := []*qostest.Server{
+		qostest.NewServer(qostest.Repeat(2, qostest.Unavailable())...),
+		qostest.NewServer(qostest.ConnectionReset(), qostest.Throttle(0)),
+		qostest.NewServer(qostest.PermanentRedirect(target.URL)),
+	}
+	for _, s := range servers {
+		s.SetDefault(qostest.Unavailable())
+		defer s.Close()
+	}
+
+	backoff := time.Millisecond
+	client, err := httpclient.NewClient(
+		httpclient.WithBaseURLs(qostest.URLs(servers...)),
+		httpclient.WithMaxRetries(10),
+		httpclient.WithInitialBackoff(backoff),
+		httpclient.WithMaxBackoff(backoff))
+	require.NoError(t, err)
+
+	_, err = client.Get(context.Background(), httpclient.WithPath("/thing"))
+	require.NoError(t, err)
+	require.Equal(t, 1, target.RequestCount())
+	assert.Equal(t, "/thing", target.Requests()[0].URL.Path)
+	assert.Equal(t, 1, servers[2].RequestCount())
+}
@@ -0,0 +1,94 @@
+// Copyright (c) 2026 Palantir Technologies. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpclient
+
+import (
+	"context"
+	"sync"
+)
+
+// RequestAnnotations holds structured values which independent middlewares handling the same request use to share
+// data, e.g. a signing middleware publishing the signature it computed for a logging middleware, without passing
+// it through request headers. The client stores a RequestAnnotations on the context of every request which does not
+// already have one, so values set during one attempt are visible to later attempts of the same request. To read
+// annotations after Do returns, store a RequestAnnotations on the context with ContextWithRequestAnnotations before
+// calling Do.
+//
+// Values are read and written through typed AnnotationKeys. RequestAnnotations is safe for concurrent use.
+type RequestAnnotations struct {
+	mu     sync.RWMutex
+	values map[any]any
+}
+
+// AnnotationKey identifies a value of type T in RequestAnnotations. Keys are compared by identity, so each key
+// should be created once with NewAnnotationKey and stored in a package variable.
+type AnnotationKey[T any] struct {
+	name string
+}
+
+// NewAnnotationKey returns a new key for values of type T. name is used only to describe the key.
+func NewAnnotationKey[T any](name string) *AnnotationKey[T] {
+	return &AnnotationKey[T]{name: name}
+}
+
+// String returns the name of the key.
+func (k *AnnotationKey[T]) String() string {
+	return k.name
+}
+
+// Set stores value for k in the RequestAnnotations on ctx. It is a no-op if ctx has no RequestAnnotations.
+func (k *AnnotationKey[T]) Set(ctx context.Context, value T) {
+	annotations := RequestAnnotationsFromContext(ctx)
+	if annotations == nil {
+		return
+	}
+	annotations.mu.Lock()
+	defer annotations.mu.Unlock()
+	if annotations.values == nil {
+		annotations.values = make(map[any]any)
+	}
+	annotations.values[k] = value
+}
+
+// Get returns the value stored for k in the RequestAnnotations on ctx, or false if no value has been set.
+func (k *AnnotationKey[T]) Get(ctx context.Context) (T, bool) {
+	var zero T
+	annotations := RequestAnnotationsFromContext(ctx)
+	if annotations == nil {
+		return zero, false
+	}
+	annotations.mu.RLock()
+	defer annotations.mu.RUnlock()
+	value, ok := annotations.values[k]
+	if !ok {
+		return zero, false
+	}
+	return value.(T), true
+}
+
+// ContextWithRequestAnnotations returns a copy of ctx with a new, empty RequestAnnotations. If ctx already has
+// RequestAnnotations, ctx is returned unchanged.
+func ContextWithRequestAnnotations(ctx context.Context) context.Context {
+	if RequestAnnotationsFromContext(ctx) != nil {
+		return ctx
+	}
+	return context.WithValue(ctx, requestAnnotations, &RequestAnnotations{})
+}
+
+// RequestAnnotationsFromContext returns the RequestAnnotations stored on ctx, or nil if there are none.
+func RequestAnnotationsFromContext(ctx context.Context) *RequestAnnotations {
+	annotations, _ := ctx.Value(requestAnnotations).(*RequestAnnotations)
+	return annotations
+}
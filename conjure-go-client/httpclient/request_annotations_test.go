@@ -0,0 +1,71 @@
+// Copyright (c) 2026 Palantir Technologies. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpclient_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/palantir/conjure-go-runtime/v2/conjure-go-client/httpclient"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestAnnotations(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	signatureKey := httpclient.NewAnnotationKey[string]("signature")
+	var logged []string
+	client, err := httpclient.NewClient(
+		httpclient.WithBaseURLs([]string{server.URL}),
+		// middlewares are applied in order with the first innermost, so the signer runs first.
+		httpclient.WithMiddleware(httpclient.MiddlewareFunc(func(req *http.Request, next http.RoundTripper) (*http.Response, error) {
+			signatureKey.Set(req.Context(), "sig-"+req.URL.Path)
+			return next.RoundTrip(req)
+		})),
+		httpclient.WithMiddleware(httpclient.MiddlewareFunc(func(req *http.Request, next http.RoundTripper) (*http.Response, error) {
+			resp, err := next.RoundTrip(req)
+			signature, ok := signatureKey.Get(req.Context())
+			require.True(t, ok)
+			logged = append(logged, signature)
+			return resp, err
+		})),
+	)
+	require.NoError(t, err)
+
+	_, err = client.Get(context.Background(), httpclient.WithPath("/a"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"sig-/a"}, logged)
+
+	ctx := httpclient.ContextWithRequestAnnotations(context.Background())
+	_, err = client.Get(ctx, httpclient.WithPath("/b"))
+	require.NoError(t, err)
+	signature, ok := signatureKey.Get(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, "sig-/b", signature)
+
+	otherKey := httpclient.NewAnnotationKey[string]("signature")
+	_, ok = otherKey.Get(ctx)
+	assert.False(t, ok, "keys with the same name must not collide")
+
+	signatureKey.Set(context.Background(), "ignored")
+	_, ok = signatureKey.Get(context.Background())
+	assert.False(t, ok)
+}
@@ -0,0 +1,122 @@
+// Copyright (c) 2026 Palantir Technologies. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+	"sync/atomic"
+
+	"github.com/palantir/conjure-go-runtime/v2/conjure-go-contract/httpheaders"
+	werror "github.com/palantir/witchcraft-go-error"
+)
+
+// ErrRequestBodyNotReplayable is returned when a request whose body is streamed with WithRequestBodyFromChan would
+// need to be sent again after its body has been consumed, e.g. when the transport follows a redirect.
+var ErrRequestBodyNotReplayable = errors.New("request body can not be replayed")
+
+// WithRequestBodyFromChan streams the chunks received from chunks as the request body, which is sent with chunked
+// transfer encoding and a Content-Type of "application/octet-stream". Each chunk is written as the transport is
+// ready for it, so a producer sending to an unbuffered channel is paced by the upload. The body ends when chunks is
+// closed; if producerErr is non-nil and returns an error at that point, the request is aborted and Do returns an
+// error wrapping it, so the server never sees a complete body. The producer must close chunks once it is done, even
+// if it failed. If the request is canceled, the client stops receiving from chunks, so producers should also select
+// on the request context when sending.
+//
+// The body can only be sent once, so the request is not retried: the result of the first attempt is returned
+// whatever its outcome. Callers which need retries should use WithRequestBodyReader or WithRawRequestBodyProvider
+// instead.
+func WithRequestBodyFromChan(chunks <-chan []byte, producerErr func() error) RequestParam {
+	body := &chanRequestBody{chunks: chunks, producerErr: producerErr}
+	return requestParamFunc(func(b *requestBuilder) error {
+		if chunks == nil {
+			return werror.Error("request body chunks channel can not be nil")
+		}
+		b.bodyMiddleware.requestInput = body
+		b.bodyMiddleware.requestEncoder = nil
+		b.headers.Set(httpheaders.ContentType, "application/octet-stream")
+		return nil
+	})
+}
+
+// chanRequestBody is the request body configured by WithRequestBodyFromChan.
+type chanRequestBody struct {
+	chunks      <-chan []byte
+	producerErr func() error
+	// used is set once the body has been attached to a request.
+	used atomic.Bool
+}
+
+func (b *chanRequestBody) setRequestBody(req *http.Request) error {
+	if !b.used.CompareAndSwap(false, true) {
+		return werror.WrapWithContextParams(req.Context(), ErrRequestBodyNotReplayable, "")
+	}
+	markBodyNotReplayable(req.Context())
+	req.Body = &chanReader{body: b, ctx: req.Context(), closed: make(chan struct{})}
+	req.ContentLength = -1
+	req.GetBody = func() (io.ReadCloser, error) {
+		return nil, ErrRequestBodyNotReplayable
+	}
+	return nil
+}
+
+// chanReader reads the chunks of a chanRequestBody. Read blocks until a chunk is available, the channel is closed,
+// the request context is done or the reader is closed by the transport.
+type chanReader struct {
+	body *chanRequestBody
+	ctx  context.Context
+	// pending is the unread remainder of the current chunk.
+	pending []byte
+	err     error
+
+	closeOnce sync.Once
+	closed    chan struct{}
+}
+
+func (r *chanReader) Read(p []byte) (int, error) {
+	for len(r.pending) == 0 {
+		if r.err != nil {
+			return 0, r.err
+		}
+		select {
+		case chunk, ok := <-r.body.chunks:
+			if !ok {
+				r.err = io.EOF
+				if r.body.producerErr != nil {
+					if err := r.body.producerErr(); err != nil {
+						r.err = werror.WrapWithContextParams(r.ctx, err, "request body producer failed")
+					}
+				}
+				continue
+			}
+			r.pending = chunk
+		case <-r.ctx.Done():
+			r.err = r.ctx.Err()
+		case <-r.closed:
+			r.err = werror.ErrorWithContextParams(r.ctx, "request body closed")
+		}
+	}
+	n := copy(p, r.pending)
+	r.pending = r.pending[n:]
+	return n, nil
+}
+
+func (r *chanReader) Close() error {
+	r.closeOnce.Do(func() { close(r.closed) })
+	return nil
+}
@@ -0,0 +1,98 @@
+// Copyright (c) 2026 Palantir Technologies. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpclient_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/palantir/conjure-go-runtime/v2/conjure-go-client/httpclient"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestBodyFromChan(t *testing.T) {
+	var received []byte
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		body, err := io.ReadAll(req.Body)
+		if err != nil {
+			rw.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		received = body
+		requests.Add(1)
+		if req.URL.Path == "/unavailable" {
+			rw.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+	client, err := httpclient.NewClient(httpclient.WithBaseURLs([]string{server.URL}))
+	require.NoError(t, err)
+
+	produce := func(chunks ...string) <-chan []byte {
+		ch := make(chan []byte)
+		go func() {
+			defer close(ch)
+			for _, chunk := range chunks {
+				ch <- []byte(chunk)
+			}
+		}()
+		return ch
+	}
+
+	t.Run("streams chunks", func(t *testing.T) {
+		_, err := client.Post(context.Background(), httpclient.WithRequestBodyFromChan(produce("hello", ", ", "world"), nil))
+		require.NoError(t, err)
+		assert.Equal(t, "hello, world", string(received))
+	})
+
+	t.Run("producer error", func(t *testing.T) {
+		producerErr := errors.New("export failed")
+		_, err := client.Post(context.Background(), httpclient.WithRequestBodyFromChan(produce("partial"), func() error {
+			return producerErr
+		}))
+		require.Error(t, err)
+		assert.True(t, errors.Is(err, producerErr), "expected producer error, got %v", err)
+	})
+
+	t.Run("not retried", func(t *testing.T) {
+		requests.Store(0)
+		_, err := client.Post(context.Background(),
+			httpclient.WithPath("/unavailable"),
+			httpclient.WithRequestBodyFromChan(produce("data"), nil))
+		require.Error(t, err)
+		statusCode, ok := httpclient.StatusCodeFromError(err)
+		assert.True(t, ok)
+		assert.Equal(t, http.StatusServiceUnavailable, statusCode)
+		assert.Equal(t, int32(1), requests.Load())
+	})
+
+	t.Run("shared request annotations", func(t *testing.T) {
+		// each call streams its own body, even if an earlier call with the same annotations consumed its body.
+		ctx := httpclient.ContextWithRequestAnnotations(context.Background())
+		for _, chunk := range []string{"first", "second"} {
+			resp, err := client.Post(ctx, httpclient.WithRequestBodyFromChan(produce(chunk), nil))
+			require.NoError(t, err)
+			require.NotNil(t, resp)
+			assert.Equal(t, chunk, string(received))
+		}
+	})
+}
@@ -35,6 +35,10 @@ type requestBuilder struct {
 	errorDecoderMiddleware Middleware
 	configureCtx           []func(context.Context) context.Context
 	requestTimeout         *time.Duration
+	connectionClose        bool
+	acceptRedirects        bool
+	// endpoints are the endpoints registered on the client building the request with WithServiceDefinition.
+	endpoints *registeredEndpoints
 }
 
 const traceIDHeaderKey = "X-B3-TraceId"
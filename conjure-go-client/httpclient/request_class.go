@@ -0,0 +1,89 @@
+// Copyright (c) 2026 Palantir Technologies. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpclient
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/palantir/pkg/metrics"
+)
+
+const metricTagRequestClass = "class"
+
+// RequestClass classifies the traffic a request belongs to, so that a single client can treat, for example,
+// latency-sensitive interactive requests differently from background batch requests. The class of a request is
+// set on its context with ContextWithRequestClass; clients apply the RequestClassPolicy registered for the class with
+// WithRequestClassPolicy and tag request metrics with it.
+type RequestClass string
+
+const (
+	// RequestClassInteractive is the class of requests made on behalf of a user who is waiting for the result.
+	RequestClassInteractive RequestClass = "interactive"
+	// RequestClassBatch is the class of background requests which favor completing over completing quickly.
+	RequestClassBatch RequestClass = "batch"
+)
+
+// RequestClassPolicy overrides the client's behavior for requests of a RequestClass. Zero fields use the client's
+// configuration.
+type RequestClassPolicy struct {
+	// Timeout, if positive, is the timeout of each attempt. It is overridden by WithRequestTimeout.
+	Timeout time.Duration
+	// MaxRetries, if non-nil, is the maximum number of times a request is retried.
+	MaxRetries *int
+	// MaxServerBackoff, if non-nil, is the longest a request waits before being sent when the server has asked the
+	// client to slow down using the hints enabled by WithServerBackoffHints. A value of 0 sends requests immediately.
+	MaxServerBackoff *time.Duration
+}
+
+// ContextWithRequestClass returns a copy of ctx with the class of the requests made using it set to class.
+func ContextWithRequestClass(ctx context.Context, class RequestClass) context.Context {
+	return context.WithValue(ctx, requestClass, class)
+}
+
+// RequestClassFromContext returns the class set on ctx by ContextWithRequestClass, or the empty string if none was set.
+func RequestClassFromContext(ctx context.Context) RequestClass {
+	class, _ := ctx.Value(requestClass).(RequestClass)
+	return class
+}
+
+// contextWithRequestClassPolicy returns a copy of ctx carrying the policy of the client for the request's class, so
+// that middlewares can apply it.
+func contextWithRequestClassPolicy(ctx context.Context, policies map[RequestClass]RequestClassPolicy) context.Context {
+	class := RequestClassFromContext(ctx)
+	if class == "" {
+		return ctx
+	}
+	policy, ok := policies[class]
+	if !ok {
+		return ctx
+	}
+	return context.WithValue(ctx, requestClassPolicy, policy)
+}
+
+func getRequestClassPolicy(ctx context.Context) (RequestClassPolicy, bool) {
+	policy, ok := ctx.Value(requestClassPolicy).(RequestClassPolicy)
+	return policy, ok
+}
+
+// tagRequestClass tags metrics with the class of the request, if one was set.
+func tagRequestClass(req *http.Request, _ *http.Response, _ error) metrics.Tags {
+	class := RequestClassFromContext(req.Context())
+	if class == "" {
+		return nil
+	}
+	return metrics.Tags{metrics.NewTagWithFallbackValue(metricTagRequestClass, string(class), "unknown")}
+}
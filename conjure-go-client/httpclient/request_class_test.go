@@ -0,0 +1,96 @@
+// Copyright (c) 2026 Palantir Technologies. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpclient_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/palantir/conjure-go-runtime/v2/conjure-go-client/httpclient"
+	"github.com/palantir/pkg/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestClassPolicy(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		requests.Add(1)
+		switch req.URL.Path {
+		case "/unavailable":
+			rw.WriteHeader(http.StatusServiceUnavailable)
+		case "/slow":
+			time.Sleep(200 * time.Millisecond)
+		}
+	}))
+	defer server.Close()
+
+	client, err := httpclient.NewClient(
+		httpclient.WithServiceName("my-service"),
+		httpclient.WithBaseURLs([]string{server.URL}),
+		httpclient.WithInitialBackoff(time.Millisecond),
+		httpclient.WithMaxBackoff(time.Millisecond),
+		httpclient.WithRequestClassPolicy(httpclient.RequestClassInteractive, httpclient.RequestClassPolicy{
+			Timeout:    50 * time.Millisecond,
+			MaxRetries: &[]int{0}[0],
+		}),
+		httpclient.WithRequestClassPolicy(httpclient.RequestClassBatch, httpclient.RequestClassPolicy{
+			MaxRetries: &[]int{3}[0],
+		}))
+	require.NoError(t, err)
+
+	ctx := metrics.WithRegistry(context.Background(), metrics.NewRootMetricsRegistry())
+	interactiveCtx := httpclient.ContextWithRequestClass(ctx, httpclient.RequestClassInteractive)
+	batchCtx := httpclient.ContextWithRequestClass(ctx, httpclient.RequestClassBatch)
+	assert.Equal(t, httpclient.RequestClassInteractive, httpclient.RequestClassFromContext(interactiveCtx))
+	assert.Equal(t, httpclient.RequestClass(""), httpclient.RequestClassFromContext(ctx))
+
+	t.Run("retries", func(t *testing.T) {
+		requests.Store(0)
+		_, err := client.Get(interactiveCtx, httpclient.WithPath("/unavailable"))
+		require.Error(t, err)
+		assert.Equal(t, int32(1), requests.Load())
+
+		requests.Store(0)
+		_, err = client.Get(batchCtx, httpclient.WithPath("/unavailable"))
+		require.Error(t, err)
+		assert.Equal(t, int32(4), requests.Load())
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		_, err := client.Get(interactiveCtx, httpclient.WithPath("/slow"))
+		require.Error(t, err)
+		_, err = client.Get(batchCtx, httpclient.WithPath("/slow"))
+		require.NoError(t, err)
+	})
+
+	t.Run("metrics", func(t *testing.T) {
+		classes := make(map[string]struct{})
+		metrics.FromContext(ctx).Each(func(name string, tags metrics.Tags, _ metrics.MetricVal) {
+			if name == "client.response" {
+				for _, tag := range tags {
+					if tag.Key() == "class" {
+						classes[tag.Value()] = struct{}{}
+					}
+				}
+			}
+		})
+		assert.Equal(t, map[string]struct{}{"interactive": {}, "batch": {}}, classes)
+	})
+}
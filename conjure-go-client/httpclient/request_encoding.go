@@ -0,0 +1,160 @@
+// Copyright (c) 2026 Palantir Technologies. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpclient
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/palantir/conjure-go-runtime/v2/conjure-go-contract/httpheaders"
+	werror "github.com/palantir/witchcraft-go-error"
+)
+
+// requestEncodingPreference lists the content codings used to compress request bodies, most preferred first.
+var requestEncodingPreference = []string{"gzip", "deflate"}
+
+// requestEncodings holds the content coding of the request bodies compressed because of
+// WithRequestCompressionThreshold. It starts as the most preferred coding and is renegotiated from the
+// Accept-Encoding header of responses, with which servers list the codings they accept in requests (RFC 7694).
+type requestEncodings struct {
+	// current is the coding of the next compressed request body, or "" if request bodies are sent uncompressed.
+	current atomic.Value
+}
+
+func newRequestEncodings() *requestEncodings {
+	e := &requestEncodings{}
+	e.current.Store(requestEncodingPreference[0])
+	return e
+}
+
+func (e *requestEncodings) Current() string {
+	return e.current.Load().(string)
+}
+
+// negotiate sets the coding to the most preferred one accepted by the Accept-Encoding header values and returns it.
+func (e *requestEncodings) negotiate(acceptEncoding []string) string {
+	encoding := selectRequestEncoding(acceptEncoding)
+	e.current.Store(encoding)
+	return encoding
+}
+
+// selectRequestEncoding returns the most preferred coding accepted by the Accept-Encoding header values, or "" if
+// none is accepted.
+func selectRequestEncoding(acceptEncoding []string) string {
+	qualities := make(map[string]float64)
+	for _, value := range acceptEncoding {
+		for _, element := range strings.Split(value, ",") {
+			params := strings.Split(element, ";")
+			coding := strings.ToLower(strings.TrimSpace(params[0]))
+			if coding == "" {
+				continue
+			}
+			quality := 1.0
+			for _, param := range params[1:] {
+				if q, ok := strings.CutPrefix(strings.TrimSpace(param), "q="); ok {
+					parsed, err := strconv.ParseFloat(q, 64)
+					if err != nil {
+						parsed = 0
+					}
+					quality = parsed
+				}
+			}
+			qualities[coding] = quality
+		}
+	}
+	for _, coding := range requestEncodingPreference {
+		quality, ok := qualities[coding]
+		if !ok {
+			quality, ok = qualities["*"]
+		}
+		if ok && quality > 0 {
+			return coding
+		}
+	}
+	return ""
+}
+
+// compressRequestBody returns body compressed with the content coding encoding, which is one of
+// requestEncodingPreference.
+func compressRequestBody(encoding string, body []byte) (*bytes.Buffer, error) {
+	compressed := new(bytes.Buffer)
+	var writer io.WriteCloser
+	switch encoding {
+	case "gzip":
+		writer = gzip.NewWriter(compressed)
+	case "deflate":
+		writer = zlib.NewWriter(compressed)
+	default:
+		return nil, werror.Error("unsupported request content coding", werror.SafeParam("contentEncoding", encoding))
+	}
+	if _, err := writer.Write(body); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return compressed, nil
+}
+
+// requestEncodingMiddleware renegotiates the coding of compressed request bodies from the Accept-Encoding header of
+// responses. If the server rejects a compressed body with 415 Unsupported Media Type, the request is sent once more
+// with the body compressed with the renegotiated coding, or uncompressed if the server accepts none of them.
+type requestEncodingMiddleware struct {
+	encodings *requestEncodings
+	body      *bodyMiddleware
+}
+
+func (m *requestEncodingMiddleware) RoundTrip(req *http.Request, next http.RoundTripper) (*http.Response, error) {
+	resp, err := next.RoundTrip(req)
+	if err != nil || resp == nil {
+		return resp, err
+	}
+	acceptEncoding := resp.Header.Values(httpheaders.AcceptEncoding)
+	if resp.StatusCode != http.StatusUnsupportedMediaType || m.body.requestEncoding == "" {
+		if len(acceptEncoding) > 0 {
+			m.encodings.negotiate(acceptEncoding)
+		}
+		return resp, nil
+	}
+	// a 415 response without an Accept-Encoding header accepts no codings.
+	encoding := m.encodings.negotiate(acceptEncoding)
+	if encoding == m.body.requestEncoding {
+		// the server accepts the coding, so the body was rejected for another reason.
+		return resp, nil
+	}
+	body := bytes.NewBuffer(m.body.uncompressedBody)
+	if encoding != "" {
+		if body, err = compressRequestBody(encoding, m.body.uncompressedBody); err != nil {
+			return resp, nil
+		}
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	_ = resp.Body.Close()
+
+	retry := req.Clone(req.Context())
+	if encoding != "" {
+		retry.Header.Set(httpheaders.ContentEncoding, encoding)
+	} else {
+		retry.Header.Del(httpheaders.ContentEncoding)
+	}
+	setBufferedRequestBody(retry, body)
+	return next.RoundTrip(retry)
+}
@@ -23,6 +23,7 @@ import (
 	"time"
 
 	"github.com/palantir/conjure-go-runtime/v2/conjure-go-contract/codecs"
+	"github.com/palantir/conjure-go-runtime/v2/conjure-go-contract/httpheaders"
 	werror "github.com/palantir/witchcraft-go-error"
 )
 
@@ -49,7 +50,8 @@ func WithRequestMethod(method string) RequestParam {
 }
 
 // WithPath sets the path for the request. This will be joined with
-// one of the BaseURLs set on the client
+// one of the BaseURLs set on the client. Percent-encoded sequences in path are sent as is, so values which may
+// contain reserved characters should be escaped, e.g. with JoinPathSegments.
 func WithPath(path string) RequestParam {
 	return requestParamFunc(func(b *requestBuilder) error {
 		b.path = path
@@ -90,7 +92,7 @@ func WithRequestBody(input interface{}, encoder codecs.Encoder) RequestParam {
 	return requestParamFunc(func(b *requestBuilder) error {
 		b.bodyMiddleware.requestInput = input
 		b.bodyMiddleware.requestEncoder = encoder
-		b.headers.Set("Content-Type", encoder.ContentType())
+		b.headers.Set(httpheaders.ContentType, encoder.ContentType())
 		return nil
 	})
 }
@@ -126,7 +128,43 @@ func WithRawRequestBodyProvider(getBody func() io.ReadCloser) RequestParam {
 		}
 		b.bodyMiddleware.requestInput = getBody()
 		b.bodyMiddleware.requestEncoder = nil
-		b.headers.Set("Content-Type", "application/octet-stream")
+		b.headers.Set(httpheaders.ContentType, "application/octet-stream")
+		return nil
+	})
+}
+
+// WithRequestBodyReader uses r as the request body with the provided content length and Content-Type header.
+// A negative contentLength indicates that the length is unknown. If contentType is empty, "application/octet-stream"
+// is used.
+//
+// If r implements io.Seeker, it is rewound to its position at the time WithRequestBodyReader was called before each
+// attempt so that the request can be retried and redirected. Otherwise, the request body can only be sent once and the
+// request is not retried. The caller remains responsible for closing r after the request completes.
+// Example:
+//
+//	f, _ := os.Open("file.txt")
+//	defer f.Close()
+//	info, _ := f.Stat()
+//	resp, err := client.Do(..., WithRequestBodyReader(f, info.Size(), "text/plain"), ...)
+func WithRequestBodyReader(r io.Reader, contentLength int64, contentType string) RequestParam {
+	body := &readerRequestBody{reader: r, contentLength: contentLength}
+	if seeker, ok := r.(io.Seeker); ok {
+		body.seeker = seeker
+		body.start, body.seekErr = seeker.Seek(0, io.SeekCurrent)
+	}
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	return requestParamFunc(func(b *requestBuilder) error {
+		if body.seekErr != nil {
+			return werror.Wrap(body.seekErr, "failed to determine request body reader position")
+		}
+		if err := body.rewind(); err != nil {
+			return err
+		}
+		b.bodyMiddleware.requestInput = body
+		b.bodyMiddleware.requestEncoder = nil
+		b.headers.Set(httpheaders.ContentType, contentType)
 		return nil
 	})
 }
@@ -150,7 +188,7 @@ func WithResponseBody(output interface{}, decoder codecs.Decoder) RequestParam {
 	return requestParamFunc(func(b *requestBuilder) error {
 		b.bodyMiddleware.responseOutput = output
 		b.bodyMiddleware.responseDecoder = decoder
-		b.headers.Set("Accept", decoder.Accept())
+		b.headers.Set(httpheaders.Accept, decoder.Accept())
 		return nil
 	})
 }
@@ -169,9 +207,42 @@ func WithResponseBody(output interface{}, decoder codecs.Decoder) RequestParam {
 func WithRawResponseBody() RequestParam {
 	return requestParamFunc(func(b *requestBuilder) error {
 		b.bodyMiddleware.rawOutput = true
+		b.bodyMiddleware.decompressRawOutput = false
+		b.bodyMiddleware.responseWriter = nil
 		b.bodyMiddleware.responseOutput = nil
 		b.bodyMiddleware.responseDecoder = nil
-		b.headers.Set("Accept", "application/octet-stream")
+		b.headers.Set(httpheaders.Accept, "application/octet-stream")
+		return nil
+	})
+}
+
+// WithResponseWriter copies the body of a successful response to w before Do returns, and then closes the body.
+// This is simpler than WithRawResponseBody when the body should be streamed somewhere, e.g. downloaded to a file.
+// If copying fails, Do returns an error with the number of bytes written as the "responseBytesWritten" safe param.
+// Because part of the body may already have been written to w, the request is not retried in that case.
+func WithResponseWriter(w io.Writer) RequestParam {
+	return requestParamFunc(func(b *requestBuilder) error {
+		if err := WithRawResponseBody().apply(b); err != nil {
+			return err
+		}
+		b.bodyMiddleware.responseWriter = w
+		return nil
+	})
+}
+
+// WithDecompressedRawResponseBody behaves like WithRawResponseBody, but additionally requests a gzip-encoded
+// response and transparently decompresses the returned body as it is read. The Content-Encoding and Content-Length
+// headers of a decompressed response are removed. Responses which are not gzip-encoded are returned unmodified.
+//
+// This is useful because the transport only decompresses responses automatically when it adds the Accept-Encoding
+// header itself, which is not the case if compression is disabled or the header is set explicitly.
+func WithDecompressedRawResponseBody() RequestParam {
+	return requestParamFunc(func(b *requestBuilder) error {
+		if err := WithRawResponseBody().apply(b); err != nil {
+			return err
+		}
+		b.bodyMiddleware.decompressRawOutput = true
+		b.headers.Set("Accept-Encoding", "gzip")
 		return nil
 	})
 }
@@ -182,13 +253,70 @@ func WithJSONResponse(output interface{}) RequestParam {
 	return WithResponseBody(output, codecs.JSON)
 }
 
+// WithStatusResponseBody decodes the body of responses with the given status code into output using decoder, instead
+// of the output provided by WithResponseBody, so that endpoints with several successful outcomes can be called without
+// handling the raw body. For example:
+//
+//	var result api.Result
+//	var job api.AsyncJob
+//	resp, err := client.Do(...,
+//		WithJSONResponse(&result),
+//		WithStatusResponseBody(http.StatusAccepted, &job, codecs.JSON),
+//	...)
+//	if resp.StatusCode == http.StatusAccepted {
+//		// poll job
+//	}
+//
+// The decoder's Accept value is added to the Accept header of the request. Responses which the client's error decoder
+// turns into errors, by default those with a status of 400 or above, are returned as errors and are not decoded.
+// WithStatusResponseBody may be used several times with different status codes; the last call for a status code wins.
+func WithStatusResponseBody(status int, output interface{}, decoder codecs.Decoder) RequestParam {
+	return requestParamFunc(func(b *requestBuilder) error {
+		if output == nil {
+			return werror.Error("status response body output can not be nil", werror.SafeParam("status", status))
+		}
+		if decoder == nil {
+			return werror.Error("status response body decoder can not be nil", werror.SafeParam("status", status))
+		}
+		if b.bodyMiddleware.statusResponseOutputs == nil {
+			b.bodyMiddleware.statusResponseOutputs = make(map[int]responseOutput)
+		}
+		b.bodyMiddleware.statusResponseOutputs[status] = responseOutput{output: output, decoder: decoder}
+		if accept := decoder.Accept(); accept != "" && !containsAccept(b.headers.Values(httpheaders.Accept), accept) {
+			b.headers.Add(httpheaders.Accept, accept)
+		}
+		return nil
+	})
+}
+
+// containsAccept returns true if one of the Accept header values is accept.
+func containsAccept(values []string, accept string) bool {
+	for _, v := range values {
+		if v == accept {
+			return true
+		}
+	}
+	return false
+}
+
 // WithCompressedRequest wraps the 'codec'-encoded request body in zlib compression.
 func WithCompressedRequest(input interface{}, codec codecs.Codec) RequestParam {
 	return requestParamFunc(func(b *requestBuilder) error {
-		b.headers.Set("Content-Encoding", "deflate")
+		b.headers.Set(httpheaders.ContentEncoding, "deflate")
 		b.bodyMiddleware.requestInput = input
 		b.bodyMiddleware.requestEncoder = codecs.ZLIB(codec)
-		b.headers.Set("Content-Type", codec.ContentType())
+		b.headers.Set(httpheaders.ContentType, codec.ContentType())
+		return nil
+	})
+}
+
+// WithGzipRequest wraps the 'codec'-encoded request body in gzip compression.
+func WithGzipRequest(input interface{}, codec codecs.Codec) RequestParam {
+	return requestParamFunc(func(b *requestBuilder) error {
+		b.headers.Set(httpheaders.ContentEncoding, "gzip")
+		b.bodyMiddleware.requestInput = input
+		b.bodyMiddleware.requestEncoder = codecs.GZIP(codec)
+		b.headers.Set(httpheaders.ContentType, codec.ContentType())
 		return nil
 	})
 }
@@ -196,10 +324,51 @@ func WithCompressedRequest(input interface{}, codec codecs.Codec) RequestParam {
 // WithSnappyCompressedRequest wraps the 'codec'-encoded request body in snappy compression.
 func WithSnappyCompressedRequest(input interface{}, codec codecs.Codec) RequestParam {
 	return requestParamFunc(func(b *requestBuilder) error {
-		b.headers.Set("Content-Encoding", "snappy")
+		b.headers.Set(httpheaders.ContentEncoding, "snappy")
 		b.bodyMiddleware.requestInput = input
 		b.bodyMiddleware.requestEncoder = codecs.Snappy(codec)
-		b.headers.Set("Content-Type", codec.ContentType())
+		b.headers.Set(httpheaders.ContentType, codec.ContentType())
+		return nil
+	})
+}
+
+// WithConnectionClose closes the connection after the request completes rather than returning it to the
+// connection pool, by setting Request.Close. This is useful for endpoints behind layer 4 load balancers, where
+// long-lived connections prevent requests from being balanced across backends.
+func WithConnectionClose() RequestParam {
+	return requestParamFunc(func(b *requestBuilder) error {
+		b.connectionClose = true
+		return nil
+	})
+}
+
+// WithAcceptRedirects returns 3xx responses to the caller as-is: redirects are not followed, and neither the client's
+// nor the request's ErrorDecoder converts them to errors. This is useful with WithRawResponseBody when the caller
+// handles redirects itself.
+func WithAcceptRedirects() RequestParam {
+	return requestParamFunc(func(b *requestBuilder) error {
+		b.acceptRedirects = true
+		b.configureCtx = append(b.configureCtx, contextWithAcceptRedirects)
+		return nil
+	})
+}
+
+// WithoutMetrics disables the client's request metrics for this request: the client.response and client.call timers,
+// the in-flight counter, the TLS handshake and connection metrics, and the meters marked when the request exceeds its
+// latency budget or times out. This is useful for high-volume calls, such as polling loops, which would
+// otherwise dominate the client's metrics.
+func WithoutMetrics() RequestParam {
+	return requestParamFunc(func(b *requestBuilder) error {
+		b.configureCtx = append(b.configureCtx, contextWithoutMetrics)
+		return nil
+	})
+}
+
+// WithoutTracing disables the client's tracing middleware for this request: no span is created for it, and no trace
+// headers are sent to the server.
+func WithoutTracing() RequestParam {
+	return requestParamFunc(func(b *requestBuilder) error {
+		b.configureCtx = append(b.configureCtx, contextWithoutTracing)
 		return nil
 	})
 }
@@ -214,16 +383,62 @@ func WithRequestErrorDecoder(errorDecoder ErrorDecoder) RequestParam {
 	})
 }
 
+// WithConjureErrorsOnly decodes error responses for this request using the default error decoder, but returns
+// responses which are not Conjure errors, such as HTML or plaintext error pages generated by proxies, as an error
+// caused by a *GatewayError which includes the status code and content type but not the response body. This is
+// useful for callers which log errors and must never log proxy-generated content. Like WithRequestErrorDecoder, it
+// takes precedence over the client's ErrorDecoder and replaces any request-scoped ErrorDecoder set before it.
+func WithConjureErrorsOnly() RequestParam {
+	return requestParamFunc(func(b *requestBuilder) error {
+		b.errorDecoderMiddleware = errorDecoderMiddleware{errorDecoder: restErrorDecoder{conjureErrorsOnly: true}}
+		return nil
+	})
+}
+
 // WithRequestBasicAuth sets the request's Authorization header to use HTTP Basic Authentication with the provided
 // username and password for this request only and takes precedence over any client-scoped authorization.
 func WithRequestBasicAuth(username, password string) RequestParam {
 	return requestParamFunc(func(b *requestBuilder) error {
 		setBasicAuth(b.headers, username, password)
+		b.configureCtx = append(b.configureCtx, contextWithRequestAuthOverride)
+		return nil
+	})
+}
+
+// WithRequestAuthToken sets the request's Authorization header to the provided bearer token for this request only
+// and takes precedence over any client-scoped authorization.
+func WithRequestAuthToken(bearerToken string) RequestParam {
+	return requestParamFunc(func(b *requestBuilder) error {
+		b.headers.Set(httpheaders.Authorization, fmt.Sprintf("Bearer %s", bearerToken))
+		b.configureCtx = append(b.configureCtx, contextWithRequestAuthOverride)
+		return nil
+	})
+}
+
+// WithNoAuth suppresses any client-scoped authorization for this request only, so that no Authorization header is
+// sent. This is useful for endpoints such as token exchanges which must not receive the client's current credentials.
+func WithNoAuth() RequestParam {
+	return requestParamFunc(func(b *requestBuilder) error {
+		b.headers.Del(httpheaders.Authorization)
+		b.configureCtx = append(b.configureCtx, contextWithRequestAuthOverride)
+		return nil
+	})
+}
+
+// WithRequestOnBehalfOf sets the X-On-Behalf-Of header to the provided principal for this request only and
+// takes precedence over any client-scoped OnBehalfOfProvider.
+func WithRequestOnBehalfOf(principal string) RequestParam {
+	return requestParamFunc(func(b *requestBuilder) error {
+		b.headers.Set(onBehalfOfHeaderKey, principal)
+		b.configureCtx = append(b.configureCtx, func(ctx context.Context) context.Context {
+			return context.WithValue(ctx, requestOnBehalfOf, principal)
+		})
 		return nil
 	})
 }
 
-// WithRequestTimeout uses the provided value instead of the client's configured timeout.
+// WithRequestTimeout uses the provided value instead of the client's configured timeout and any timeout configured for
+// the request's RPC method name or RequestClass.
 func WithRequestTimeout(timeout time.Duration) RequestParam {
 	return requestParamFunc(func(b *requestBuilder) error {
 		b.requestTimeout = &timeout
@@ -0,0 +1,50 @@
+// Copyright (c) 2026 Palantir Technologies. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpclient
+
+import (
+	"io"
+	"net/http"
+)
+
+// ResponseBodyWrapper wraps the body of successful responses, e.g. to count bytes read or enforce read deadlines.
+// Unlike a Middleware replacing resp.Body, a wrapper is applied by the client after error decoding and before the
+// body is decoded or returned, so it composes with the client's handling of the body: the wrapped body is read to
+// completion and closed by the client, or returned to the caller when using WithRawResponseBody, in which case the
+// caller is responsible for closing it.
+//
+// The returned ReadCloser must close body when it is closed.
+type ResponseBodyWrapper interface {
+	WrapResponseBody(resp *http.Response, body io.ReadCloser) io.ReadCloser
+}
+
+// ResponseBodyWrapperFunc is a convenience type that implements ResponseBodyWrapper.
+type ResponseBodyWrapperFunc func(resp *http.Response, body io.ReadCloser) io.ReadCloser
+
+func (f ResponseBodyWrapperFunc) WrapResponseBody(resp *http.Response, body io.ReadCloser) io.ReadCloser {
+	return f(resp, body)
+}
+
+// wrapResponseBody applies wrappers to the body of resp in order, so the last wrapper is outermost.
+func wrapResponseBody(resp *http.Response, wrappers []ResponseBodyWrapper) {
+	if resp == nil || resp.Body == nil || resp.Body == http.NoBody {
+		return
+	}
+	for _, w := range wrappers {
+		if w != nil {
+			resp.Body = w.WrapResponseBody(resp, resp.Body)
+		}
+	}
+}
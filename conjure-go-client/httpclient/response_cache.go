@@ -0,0 +1,362 @@
+// Copyright (c) 2026 Palantir Technologies. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpclient
+
+import (
+	"bytes"
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/palantir/conjure-go-runtime/v2/conjure-go-client/httpclient/internal"
+	"github.com/palantir/conjure-go-runtime/v2/conjure-go-contract/httpheaders"
+	"github.com/palantir/pkg/metrics"
+	"github.com/palantir/pkg/refreshable"
+)
+
+// maxCachedResponseBytes is the size of the largest response body stored by WithResponseCache.
+const maxCachedResponseBytes = 1 << 20
+
+// Cache stores the responses cached by WithResponseCache. Implementations must be safe for concurrent use. Stored
+// responses are never modified, so implementations may share them between callers.
+type Cache interface {
+	// Get returns the response stored for key, if any.
+	Get(key string) (*CachedResponse, bool)
+	// Set stores resp for key, replacing any existing response.
+	Set(key string, resp *CachedResponse)
+	// Delete removes the response stored for key, if any.
+	Delete(key string)
+}
+
+// CachedResponse is a response stored in a Cache.
+type CachedResponse struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+	// RequestHeader holds the values of the request headers named by the Vary header of the response, which must
+	// match for the response to be reused.
+	RequestHeader http.Header
+	// StoredAt is when the response was received or last revalidated.
+	StoredAt time.Time
+}
+
+// size returns the approximate number of bytes of memory used by r.
+func (r *CachedResponse) size() int64 {
+	size := int64(len(r.Body))
+	for _, header := range []http.Header{r.Header, r.RequestHeader} {
+		for k, values := range header {
+			size += int64(len(k))
+			for _, v := range values {
+				size += int64(len(v))
+			}
+		}
+	}
+	return size
+}
+
+// response returns a new *http.Response for req with the status, headers and body of r.
+func (r *CachedResponse) response(req *http.Request) *http.Response {
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", r.StatusCode, http.StatusText(r.StatusCode)),
+		StatusCode:    r.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        r.Header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(r.Body)),
+		ContentLength: int64(len(r.Body)),
+		Request:       req,
+	}
+}
+
+// revalidated returns a copy of r updated with the headers of a 304 Not Modified response received at now.
+func (r *CachedResponse) revalidated(header http.Header, now time.Time) *CachedResponse {
+	updated := *r
+	updated.Header = r.Header.Clone()
+	for _, k := range []string{httpheaders.CacheControl, httpheaders.ETag, httpheaders.LastModified, httpheaders.Date, httpheaders.Age} {
+		if values := header.Values(k); len(values) != 0 {
+			updated.Header[k] = values
+		}
+	}
+	updated.StoredAt = now
+	return &updated
+}
+
+// fresh returns true if r can be reused at now without revalidation.
+func (r *CachedResponse) fresh(now time.Time) bool {
+	directives := cacheControlDirectives(r.Header)
+	if _, ok := directives["no-cache"]; ok {
+		return false
+	}
+	maxAge, err := strconv.ParseInt(directives["max-age"], 10, 64)
+	if err != nil {
+		return false
+	}
+	age := now.Sub(r.StoredAt)
+	if ageHeader, err := strconv.ParseInt(r.Header.Get(httpheaders.Age), 10, 64); err == nil && ageHeader > 0 {
+		age += time.Duration(ageHeader) * time.Second
+	}
+	return age < time.Duration(maxAge)*time.Second
+}
+
+// matches returns true if the request headers named by the Vary header of r have the same values in header.
+func (r *CachedResponse) matches(header http.Header) bool {
+	for k, values := range r.RequestHeader {
+		if strings.Join(header.Values(k), ",") != strings.Join(values, ",") {
+			return false
+		}
+	}
+	return true
+}
+
+// NewLRUCache returns an in-memory Cache which holds responses using up to approximately maxBytes bytes, evicting
+// the least recently used responses when it is full.
+func NewLRUCache(maxBytes int64) Cache {
+	return &lruCache{
+		maxBytes: maxBytes,
+		entries:  make(map[string]*list.Element),
+		lru:      list.New(),
+	}
+}
+
+type lruCache struct {
+	maxBytes int64
+
+	mu      sync.Mutex
+	size    int64
+	entries map[string]*list.Element
+	lru     *list.List
+}
+
+type lruCacheEntry struct {
+	key  string
+	resp *CachedResponse
+	size int64
+}
+
+func (c *lruCache) Get(key string) (*CachedResponse, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	c.lru.MoveToFront(elem)
+	return elem.Value.(*lruCacheEntry).resp, true
+}
+
+func (c *lruCache) Set(key string, resp *CachedResponse) {
+	size := int64(len(key)) + resp.size()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.remove(key)
+	if size > c.maxBytes {
+		return
+	}
+	c.entries[key] = c.lru.PushFront(&lruCacheEntry{key: key, resp: resp, size: size})
+	c.size += size
+	for c.size > c.maxBytes {
+		c.remove(c.lru.Back().Value.(*lruCacheEntry).key)
+	}
+}
+
+func (c *lruCache) Delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.remove(key)
+}
+
+func (c *lruCache) remove(key string) {
+	if elem, ok := c.entries[key]; ok {
+		c.lru.Remove(elem)
+		delete(c.entries, key)
+		c.size -= elem.Value.(*lruCacheEntry).size
+	}
+}
+
+// responseCacheMiddleware serves GET requests from a Cache. See WithResponseCache.
+type responseCacheMiddleware struct {
+	cache       Cache
+	serviceName refreshable.String
+	disabled    refreshable.Bool
+	now         func() time.Time
+}
+
+func newResponseCacheMiddleware(serviceName refreshable.String, disabled refreshable.Bool, cache Cache) *responseCacheMiddleware {
+	return &responseCacheMiddleware{cache: cache, serviceName: serviceName, disabled: disabled, now: time.Now}
+}
+
+func (m *responseCacheMiddleware) RoundTrip(req *http.Request, next http.RoundTripper) (*http.Response, error) {
+	if !cacheableRequest(req) {
+		return next.RoundTrip(req)
+	}
+	key := responseCacheKey(req)
+	cached, ok := m.cache.Get(key)
+	if ok && !cached.matches(req.Header) {
+		cached, ok = nil, false
+	}
+	if _, noCache := cacheControlDirectives(req.Header)["no-cache"]; ok && !noCache && cached.fresh(m.now()) {
+		m.mark(req, MetricResponseCacheHit)
+		return cached.response(req), nil
+	}
+
+	outbound := req
+	if ok {
+		outbound = req.Clone(req.Context())
+		if etag := cached.Header.Get(httpheaders.ETag); etag != "" {
+			outbound.Header.Set(httpheaders.IfNoneMatch, etag)
+		}
+		if lastModified := cached.Header.Get(httpheaders.LastModified); lastModified != "" {
+			outbound.Header.Set(httpheaders.IfModifiedSince, lastModified)
+		}
+	}
+	resp, err := next.RoundTrip(outbound)
+	if err != nil || resp == nil {
+		return resp, err
+	}
+	if ok && resp.StatusCode == http.StatusNotModified {
+		internal.DrainBody(req.Context(), resp)
+		updated := cached.revalidated(resp.Header, m.now())
+		m.cache.Set(key, updated)
+		m.mark(req, MetricResponseCacheHit)
+		return updated.response(req), nil
+	}
+	m.mark(req, MetricResponseCacheMiss)
+	return m.store(key, req, resp)
+}
+
+// store adds resp to the cache if it can be reused, replacing its body with one reading the buffered contents.
+func (m *responseCacheMiddleware) store(key string, req *http.Request, resp *http.Response) (*http.Response, error) {
+	if resp.StatusCode != http.StatusOK || !cacheableResponse(resp.Header) || resp.ContentLength > maxCachedResponseBytes {
+		return resp, nil
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxCachedResponseBytes+1))
+	if err != nil {
+		_ = resp.Body.Close()
+		return nil, err
+	}
+	if len(body) > maxCachedResponseBytes {
+		resp.Body = struct {
+			io.Reader
+			io.Closer
+		}{Reader: io.MultiReader(bytes.NewReader(body), resp.Body), Closer: resp.Body}
+		return resp, nil
+	}
+	_ = resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	requestHeader := make(http.Header)
+	for _, k := range varyHeaders(resp.Header) {
+		if values := req.Header.Values(k); len(values) != 0 {
+			requestHeader[http.CanonicalHeaderKey(k)] = values
+		} else {
+			// an absent header must also be absent when the response is reused.
+			requestHeader[http.CanonicalHeaderKey(k)] = nil
+		}
+	}
+	m.cache.Set(key, &CachedResponse{
+		StatusCode:    resp.StatusCode,
+		Header:        resp.Header.Clone(),
+		Body:          body,
+		RequestHeader: requestHeader,
+		StoredAt:      m.now(),
+	})
+	return resp, nil
+}
+
+func (m *responseCacheMiddleware) mark(req *http.Request, name string) {
+	if metricsDisabled(req.Context(), m.disabled) {
+		return
+	}
+	serviceNameTag := metrics.NewTagWithFallbackValue(MetricTagServiceName, m.serviceName.CurrentString(), "unknown")
+	metrics.FromContext(req.Context()).Meter(name, serviceNameTag).Mark(1)
+}
+
+// cacheableRequest returns true if the response to req may be served from or stored in the cache. Requests which
+// set their own validators or ranges bypass the cache, as do requests which ask for the response not to be stored.
+func cacheableRequest(req *http.Request) bool {
+	if req.Method != http.MethodGet {
+		return false
+	}
+	for _, k := range []string{httpheaders.IfNoneMatch, httpheaders.IfModifiedSince, httpheaders.Range} {
+		if req.Header.Get(k) != "" {
+			return false
+		}
+	}
+	_, noStore := cacheControlDirectives(req.Header)["no-store"]
+	return !noStore
+}
+
+// cacheableResponse returns true if a response with header may be stored: it must allow storage, have a max-age or a
+// validator, and not vary on every request.
+func cacheableResponse(header http.Header) bool {
+	directives := cacheControlDirectives(header)
+	if _, noStore := directives["no-store"]; noStore {
+		return false
+	}
+	for _, k := range varyHeaders(header) {
+		if k == "*" {
+			return false
+		}
+	}
+	_, maxAge := directives["max-age"]
+	return maxAge || header.Get(httpheaders.ETag) != "" || header.Get(httpheaders.LastModified) != ""
+}
+
+// responseCacheKey returns the key of the response to req: its URL and, if the request is authenticated, a hash of
+// its credentials so that responses are never shared between callers.
+func responseCacheKey(req *http.Request) string {
+	auth, cookie := req.Header.Get(httpheaders.Authorization), req.Header.Get("Cookie")
+	if auth == "" && cookie == "" {
+		return req.URL.String()
+	}
+	sum := sha256.Sum256([]byte(auth + "\x00" + cookie))
+	return req.URL.String() + " " + hex.EncodeToString(sum[:])
+}
+
+// cacheControlDirectives returns the lowercase directives of the Cache-Control header in header, mapped to their
+// unquoted values.
+func cacheControlDirectives(header http.Header) map[string]string {
+	directives := make(map[string]string)
+	for _, value := range header.Values(httpheaders.CacheControl) {
+		for _, directive := range strings.Split(value, ",") {
+			name, arg, _ := strings.Cut(strings.TrimSpace(directive), "=")
+			if name != "" {
+				directives[strings.ToLower(name)] = strings.Trim(arg, `"`)
+			}
+		}
+	}
+	return directives
+}
+
+// varyHeaders returns the names of the request headers listed by the Vary header in header.
+func varyHeaders(header http.Header) []string {
+	var names []string
+	for _, value := range header.Values(httpheaders.Vary) {
+		for _, name := range strings.Split(value, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
@@ -0,0 +1,118 @@
+// Copyright (c) 2026 Palantir Technologies. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpclient_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/palantir/conjure-go-runtime/v2/conjure-go-client/httpclient"
+	"github.com/palantir/pkg/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResponseCache(t *testing.T) {
+	for _, tc := range []struct {
+		name           string
+		cacheControl   string
+		wantRequests   int
+		wantRevalidate bool
+	}{
+		{name: "fresh", cacheControl: "max-age=60", wantRequests: 1},
+		{name: "revalidated", cacheControl: "no-cache", wantRequests: 2, wantRevalidate: true},
+		{name: "not stored", cacheControl: "no-store", wantRequests: 2},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var requests atomic.Int32
+			var revalidated bool
+			server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				requests.Add(1)
+				rw.Header().Set("Cache-Control", tc.cacheControl)
+				rw.Header().Set("ETag", `"v1"`)
+				if req.Header.Get("If-None-Match") == `"v1"` {
+					revalidated = true
+					rw.WriteHeader(http.StatusNotModified)
+					return
+				}
+				_, _ = rw.Write([]byte("cached body"))
+			}))
+			defer server.Close()
+
+			registry := metrics.NewRootMetricsRegistry()
+			ctx := metrics.WithRegistry(context.Background(), registry)
+			client, err := httpclient.NewClient(
+				httpclient.WithBaseURLs([]string{server.URL}),
+				httpclient.WithServiceName("my-service"),
+				httpclient.WithResponseCache(httpclient.NewLRUCache(1<<20)))
+			require.NoError(t, err)
+
+			for i := 0; i < 2; i++ {
+				resp, err := client.Get(ctx, httpclient.WithPath("/resource"), httpclient.WithRawResponseBody())
+				require.NoError(t, err)
+				assert.Equal(t, http.StatusOK, resp.StatusCode)
+				body, err := io.ReadAll(resp.Body)
+				require.NoError(t, err)
+				_ = resp.Body.Close()
+				assert.Equal(t, "cached body", string(body))
+			}
+			assert.Equal(t, tc.wantRequests, int(requests.Load()))
+			assert.Equal(t, tc.wantRevalidate, revalidated)
+
+			counts := map[string]int64{}
+			registry.Each(func(name string, _ metrics.Tags, value metrics.MetricVal) {
+				if name == "client.cache.hit" || name == "client.cache.miss" {
+					counts[name] = value.Values()["count"].(int64)
+				}
+			})
+			wantHits := int64(1)
+			if tc.cacheControl == "no-store" {
+				wantHits = 0
+			}
+			assert.Equal(t, wantHits, counts["client.cache.hit"])
+			assert.Equal(t, 2-wantHits, counts["client.cache.miss"])
+		})
+	}
+}
+
+func TestLRUCache(t *testing.T) {
+	cache := httpclient.NewLRUCache(30)
+	entry := func(body string) *httpclient.CachedResponse {
+		return &httpclient.CachedResponse{StatusCode: http.StatusOK, Body: []byte(body)}
+	}
+	cache.Set("a", entry("0123456789"))
+	cache.Set("b", entry("0123456789"))
+	_, ok := cache.Get("a")
+	require.True(t, ok)
+	// "b" is the least recently used entry, so it is evicted to make room for "c".
+	cache.Set("c", entry("0123456789"))
+	_, ok = cache.Get("b")
+	assert.False(t, ok)
+	_, ok = cache.Get("a")
+	assert.True(t, ok)
+
+	// entries larger than the cache are not stored.
+	cache.Set("d", entry("0123456789012345678901234567890"))
+	_, ok = cache.Get("d")
+	assert.False(t, ok)
+
+	cache.Delete("a")
+	_, ok = cache.Get("a")
+	assert.False(t, ok)
+}
@@ -0,0 +1,114 @@
+// Copyright (c) 2026 Palantir Technologies. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpclient
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"hash"
+	"io"
+	"net/http"
+	"strings"
+
+	werror "github.com/palantir/witchcraft-go-error"
+)
+
+// ErrResponseChecksumMismatch is returned when WithResponseChecksumVerification is used and the digest of a response
+// body does not match the checksum header sent by the server.
+var ErrResponseChecksumMismatch = errors.New("response body checksum does not match header")
+
+// responseChecksum verifies response bodies against the checksum in header. See WithResponseChecksumVerification.
+type responseChecksum struct {
+	header  string
+	newHash func() hash.Hash
+}
+
+// wrap replaces the body of resp with a reader which computes its digest. It returns nil if resp has no body or no
+// checksum header.
+func (c *responseChecksum) wrap(resp *http.Response) (*checksumBody, error) {
+	if resp == nil || resp.Body == nil || resp.Body == http.NoBody {
+		return nil, nil
+	}
+	value := strings.TrimSpace(resp.Header.Get(c.header))
+	if value == "" {
+		return nil, nil
+	}
+	h := c.newHash()
+	expected, err := decodeChecksum(value, h.Size())
+	if err != nil {
+		_ = resp.Body.Close()
+		return nil, werror.Wrap(err, "invalid response checksum header",
+			werror.SafeParam("checksumHeader", c.header),
+			werror.SafeParam("checksum", value))
+	}
+	body := &checksumBody{ReadCloser: resp.Body, header: c.header, hash: h, expected: expected}
+	resp.Body = body
+	return body, nil
+}
+
+// decodeChecksum decodes a hex or base64 encoded checksum of size bytes.
+func decodeChecksum(value string, size int) ([]byte, error) {
+	if len(value) == hex.EncodedLen(size) {
+		if decoded, err := hex.DecodeString(value); err == nil {
+			return decoded, nil
+		}
+	}
+	for _, encoding := range []*base64.Encoding{base64.StdEncoding, base64.RawStdEncoding, base64.URLEncoding, base64.RawURLEncoding} {
+		if decoded, err := encoding.DecodeString(value); err == nil && len(decoded) == size {
+			return decoded, nil
+		}
+	}
+	return nil, werror.Error("checksum is not a hex or base64 encoded digest", werror.SafeParam("digestSize", size))
+}
+
+// checksumBody computes the digest of the body as it is read and returns an error wrapping
+// ErrResponseChecksumMismatch instead of io.EOF if the digest does not match the expected checksum.
+type checksumBody struct {
+	io.ReadCloser
+	header   string
+	hash     hash.Hash
+	expected []byte
+	// err is the result of verification, set once the body has been read to EOF.
+	err error
+}
+
+func (b *checksumBody) Read(p []byte) (int, error) {
+	if b.err != nil {
+		return 0, b.err
+	}
+	n, err := b.ReadCloser.Read(p)
+	_, _ = b.hash.Write(p[:n])
+	if err == io.EOF {
+		if actual := b.hash.Sum(nil); !bytes.Equal(actual, b.expected) {
+			b.err = werror.Wrap(ErrResponseChecksumMismatch, "",
+				werror.SafeParam("checksumHeader", b.header),
+				werror.SafeParam("expectedChecksum", hex.EncodeToString(b.expected)),
+				werror.SafeParam("actualChecksum", hex.EncodeToString(actual)))
+			return n, b.err
+		}
+	}
+	return n, err
+}
+
+// verify reads body, which wraps b, to EOF and returns an error if it could not be read or its checksum does not
+// match.
+func (b *checksumBody) verify(body io.Reader) error {
+	if _, err := io.Copy(io.Discard, body); err != nil {
+		return err
+	}
+	return b.err
+}
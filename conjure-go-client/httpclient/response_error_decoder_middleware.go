@@ -15,13 +15,21 @@
 package httpclient
 
 import (
-	"io/ioutil"
+	"bytes"
+	"context"
+	"fmt"
+	"html"
+	"io"
 	"net/http"
+	"regexp"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/palantir/conjure-go-runtime/v2/conjure-go-client/httpclient/internal"
 	"github.com/palantir/conjure-go-runtime/v2/conjure-go-contract/codecs"
 	"github.com/palantir/conjure-go-runtime/v2/conjure-go-contract/errors"
+	"github.com/palantir/conjure-go-runtime/v2/conjure-go-contract/httpheaders"
 	werror "github.com/palantir/witchcraft-go-error"
 )
 
@@ -48,6 +56,9 @@ func (e errorDecoderMiddleware) RoundTrip(req *http.Request, next http.RoundTrip
 	if resp == nil || err != nil {
 		return nil, err
 	}
+	if isRedirectStatus(resp.StatusCode) && acceptsRedirects(req.Context()) {
+		return resp, nil
+	}
 	if e.errorDecoder.Handles(resp) {
 		defer internal.DrainBody(req.Context(), resp)
 		return nil, e.errorDecoder.DecodeError(resp)
@@ -55,6 +66,31 @@ func (e errorDecoderMiddleware) RoundTrip(req *http.Request, next http.RoundTrip
 	return resp, nil
 }
 
+func isRedirectStatus(statusCode int) bool {
+	return statusCode >= http.StatusMultipleChoices && statusCode < http.StatusBadRequest
+}
+
+// errorDecoderChain is an ErrorDecoder which decodes a response using the first of its decoders which handles it.
+type errorDecoderChain []ErrorDecoder
+
+func (c errorDecoderChain) Handles(resp *http.Response) bool {
+	for _, d := range c {
+		if d.Handles(resp) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c errorDecoderChain) DecodeError(resp *http.Response) error {
+	for _, d := range c {
+		if d.Handles(resp) {
+			return d.DecodeError(resp)
+		}
+	}
+	return werror.Error("no error decoder handles response", werror.SafeParam("statusCode", resp.StatusCode))
+}
+
 // restErrorDecoder is our default error decoder.
 // It handles responses of status code >= 307. In this case,
 // we create and return a werror with the 'statusCode' parameter
@@ -63,13 +99,34 @@ func (e errorDecoderMiddleware) RoundTrip(req *http.Request, next http.RoundTrip
 // Use StatusCodeFromError(err) to retrieve the code from the error,
 // and WithDisableRestErrors() to disable this middleware on your client.
 //
-// If the response has a Content-Type containing 'application/json', we attempt
+// If the response has a Content-Type of 'application/json', we attempt
 // to unmarshal the error as a conjure error. See TestErrorDecoderMiddlewares for
 // example error messages and parameters.
-type restErrorDecoder struct{}
+type restErrorDecoder struct {
+	// if conjureErrorsOnly is true, responses which are not conjure errors are returned as a *GatewayError without
+	// their body. See WithConjureErrorsOnly.
+	conjureErrorsOnly bool
+	// if includeHTMLBodies is true, the bodies of HTML error responses are included as an unsafe param. See
+	// WithHTMLErrorBodies.
+	includeHTMLBodies bool
+}
 
 var _ ErrorDecoder = restErrorDecoder{}
 
+// GatewayError is the cause of errors returned for requests made using WithConjureErrorsOnly when an error response
+// is not a Conjure error, e.g. an HTML error page generated by a proxy or load balancer. The response body is not
+// included. Use errors.As to retrieve it.
+type GatewayError struct {
+	// StatusCode is the status code of the response.
+	StatusCode int
+	// ContentType is the media type of the response, without parameters, or empty if it had none.
+	ContentType string
+}
+
+func (e *GatewayError) Error() string {
+	return fmt.Sprintf("non-conjure error response with status %d", e.StatusCode)
+}
+
 func (d restErrorDecoder) Handles(resp *http.Response) bool {
 	return resp.StatusCode >= http.StatusTemporaryRedirect
 }
@@ -86,29 +143,167 @@ func (d restErrorDecoder) DecodeError(resp *http.Response) error {
 			unsafeParams["location"] = location.String()
 		}
 	}
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable {
+		if retryAfter := resp.Header.Get(httpheaders.RetryAfter); retryAfter != "" {
+			unsafeParams["retryAfter"] = retryAfter
+		}
+	}
 	wSafeParams := werror.SafeParams(safeParams)
 	wUnsafeParams := werror.UnsafeParams(unsafeParams)
 
 	// TODO(#98): If a byte buffer pool is configured, use it to avoid an allocation.
-	body, err := ioutil.ReadAll(resp.Body)
+	body, truncated, err := readErrorBody(resp)
 	if err != nil {
 		return werror.Wrap(err, "server returned an error and failed to read body", wSafeParams, wUnsafeParams)
 	}
+	if d.conjureErrorsOnly {
+		if conjureErr, ok := unmarshalConjureError(resp, body, truncated); ok {
+			return werror.Wrap(conjureErr, "", wSafeParams, wUnsafeParams)
+		}
+		contentType := httpheaders.MediaType(resp.Header.Get(httpheaders.ContentType))
+		return werror.Wrap(&GatewayError{StatusCode: resp.StatusCode, ContentType: contentType}, "",
+			wSafeParams, wUnsafeParams, werror.SafeParam("contentType", contentType))
+	}
+	if !d.includeHTMLBodies && isHTMLMediaType(httpheaders.MediaType(resp.Header.Get(httpheaders.ContentType))) {
+		// HTML error pages are usually generated by load balancers and ingresses and only add noise to logs, so only
+		// their title is kept.
+		params := []werror.Param{wSafeParams, wUnsafeParams, werror.SafeParam("contentType", "text/html")}
+		if summary := htmlErrorSummary(body); summary != "" {
+			params = append(params, werror.SafeParam("responseSummary", summary))
+		}
+		return werror.Error(resp.Status, params...)
+	}
+	if truncated {
+		return werror.Error(resp.Status, wSafeParams, wUnsafeParams,
+			werror.SafeParam("responseBodyTruncated", true),
+			werror.UnsafeParam("responseBody", string(body)))
+	}
 	if len(body) == 0 {
 		return werror.Error(resp.Status, wSafeParams, wUnsafeParams)
 	}
-
-	// If JSON, try to unmarshal as conjure error
-	if isJSON := strings.Contains(resp.Header.Get("Content-Type"), codecs.JSON.ContentType()); !isJSON {
-		return werror.Error(resp.Status, wSafeParams, wUnsafeParams, werror.UnsafeParam("responseBody", string(body)))
-	}
-	conjureErr, jsonErr := errors.UnmarshalError(body)
-	if jsonErr != nil {
+	conjureErr, ok := unmarshalConjureError(resp, body, truncated)
+	if !ok {
 		return werror.Error(resp.Status, wSafeParams, wUnsafeParams, werror.UnsafeParam("responseBody", string(body)))
 	}
 	return werror.Wrap(conjureErr, "", wSafeParams, wUnsafeParams)
 }
 
+// unmarshalConjureError returns the conjure error in body, or false if the response is not JSON or body is not a
+// complete conjure error.
+func unmarshalConjureError(resp *http.Response, body []byte, truncated bool) (errors.Error, bool) {
+	if truncated || len(body) == 0 {
+		return nil, false
+	}
+	if httpheaders.MediaType(resp.Header.Get(httpheaders.ContentType)) != codecs.JSON.ContentType() {
+		return nil, false
+	}
+	conjureErr, err := errors.UnmarshalError(body)
+	if err != nil {
+		return nil, false
+	}
+	return conjureErr, true
+}
+
+// maxHTMLErrorSummaryLength is the maximum number of characters of the summary of an HTML error page.
+const maxHTMLErrorSummaryLength = 128
+
+var (
+	htmlTitlePattern   = regexp.MustCompile(`(?is)<title[^>]*>(.*?)</title>`)
+	htmlHeadingPattern = regexp.MustCompile(`(?is)<h1[^>]*>(.*?)</h1>`)
+	htmlTagPattern     = regexp.MustCompile(`<[^>]*>`)
+)
+
+func isHTMLMediaType(mediaType string) bool {
+	return mediaType == "text/html" || mediaType == "application/xhtml+xml"
+}
+
+// htmlErrorSummary returns the text of the title of the HTML page in body, or of its first heading if it has no title,
+// e.g. "502 Bad Gateway". The text is unescaped, its whitespace collapsed and it is truncated to
+// maxHTMLErrorSummaryLength characters.
+func htmlErrorSummary(body []byte) string {
+	var text string
+	for _, pattern := range []*regexp.Regexp{htmlTitlePattern, htmlHeadingPattern} {
+		if match := pattern.FindSubmatch(body); match != nil {
+			text = html.UnescapeString(htmlTagPattern.ReplaceAllString(string(match[1]), " "))
+			text = strings.Join(strings.Fields(text), " ")
+			if text != "" {
+				break
+			}
+		}
+	}
+	if runes := []rune(text); len(runes) > maxHTMLErrorSummaryLength {
+		text = string(runes[:maxHTMLErrorSummaryLength])
+	}
+	return text
+}
+
+const (
+	// maxErrorBodyBytes is the maximum number of bytes of an error response body read by the default error decoder.
+	maxErrorBodyBytes = 1 << 20
+	// maxErrorBodyReadTime is the maximum time spent reading an error response body by the default error decoder,
+	// if the request's context has no earlier deadline.
+	maxErrorBodyReadTime = 10 * time.Second
+)
+
+// readErrorBody reads up to maxErrorBodyBytes of the response body. Reading stops at the deadline of the request's
+// context or after maxErrorBodyReadTime, whichever is sooner, so that a server which stops sending the body after
+// the headers does not block the caller. In this case the body is closed and replaced with http.NoBody so that it is
+// not drained. If the body was not read completely, truncated is true and the bytes read so far are returned.
+func readErrorBody(resp *http.Response) (body []byte, truncated bool, err error) {
+	ctx := context.Background()
+	if resp.Request != nil {
+		ctx = resp.Request.Context()
+	}
+	ctx, cancel := context.WithTimeout(ctx, maxErrorBodyReadTime)
+	defer cancel()
+
+	respBody := resp.Body
+	buf := &lockedBuffer{}
+	done := make(chan error, 1)
+	go func() {
+		_, err := io.Copy(buf, io.LimitReader(respBody, maxErrorBodyBytes+1))
+		done <- err
+	}()
+	select {
+	case err = <-done:
+		body = buf.Bytes()
+	case <-ctx.Done():
+		// closing the body unblocks the pending read for the standard transport; don't wait for it in case it does not.
+		go func() { _ = respBody.Close() }()
+		resp.Body = http.NoBody
+		return buf.Bytes(), true, nil
+	}
+	if err != nil {
+		if ctx.Err() != nil {
+			// the read was interrupted by the deadline
+			return body, true, nil
+		}
+		return nil, false, err
+	}
+	if len(body) > maxErrorBodyBytes {
+		return body[:maxErrorBodyBytes], true, nil
+	}
+	return body, false, nil
+}
+
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+// Bytes returns a copy of the buffer's contents.
+func (b *lockedBuffer) Bytes() []byte {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]byte(nil), b.buf.Bytes()...)
+}
+
 // StatusCodeFromError wraps the internal StatusCodeFromError func. For behavior details, see its docs.
 func StatusCodeFromError(err error) (statusCode int, ok bool) {
 	return internal.StatusCodeFromError(err)
@@ -15,6 +15,7 @@
 package httpclient_test
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"io/ioutil"
@@ -22,6 +23,7 @@ import (
 	"net/http/httptest"
 	"net/url"
 	"testing"
+	"time"
 
 	"github.com/palantir/conjure-go-runtime/v2/conjure-go-client/httpclient"
 	"github.com/palantir/conjure-go-runtime/v2/conjure-go-contract/errors"
@@ -188,8 +190,12 @@ func TestErrorDecoderMiddlewares(t *testing.T) {
 			decoderParam: httpclient.WithErrorDecoder(fooErrorDecoder{}),
 			verify: func(t *testing.T, u *url.URL, err error) {
 				assert.EqualError(t, err, "httpclient request failed: foo error")
+				// errors without a status code are retried, so the outcome of each attempt is included
+				attempts, ok := httpclient.AttemptsFromError(err)
+				require.True(t, ok)
+				assert.Len(t, attempts, 2)
 				safeParams, unsafeParams := werror.ParamsFromError(err)
-				assert.Equal(t, map[string]interface{}{"requestHost": u.Host, "requestMethod": "Get"}, safeParams)
+				assert.Equal(t, map[string]interface{}{"requestHost": u.Host, "requestMethod": "Get", "attempts": attempts}, safeParams)
 				assert.Equal(t, map[string]interface{}{"requestPath": "/path"}, unsafeParams)
 			},
 		},
@@ -199,8 +205,12 @@ func TestErrorDecoderMiddlewares(t *testing.T) {
 			decoderParam: httpclient.WithErrorDecoder(bodyReadingErrorDecoder{}),
 			verify: func(t *testing.T, u *url.URL, err error) {
 				assert.EqualError(t, err, "httpclient request failed: error from body: 404 page not found\n")
+				// errors without a status code are retried, so the outcome of each attempt is included
+				attempts, ok := httpclient.AttemptsFromError(err)
+				require.True(t, ok)
+				assert.Len(t, attempts, 2)
 				safeParams, unsafeParams := werror.ParamsFromError(err)
-				assert.Equal(t, map[string]interface{}{"requestHost": u.Host, "requestMethod": "Get"}, safeParams)
+				assert.Equal(t, map[string]interface{}{"requestHost": u.Host, "requestMethod": "Get", "attempts": attempts}, safeParams)
 				assert.Equal(t, map[string]interface{}{"requestPath": "/path"}, unsafeParams)
 			},
 		},
@@ -220,6 +230,234 @@ func TestErrorDecoderMiddlewares(t *testing.T) {
 	}
 }
 
+func TestErrorDecoderBoundedBodyRead(t *testing.T) {
+	t.Run("size", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			rw.WriteHeader(http.StatusInternalServerError)
+			_, _ = rw.Write(bytes.Repeat([]byte("a"), 2<<20))
+		}))
+		defer ts.Close()
+		client, err := httpclient.NewClient(httpclient.WithBaseURLs([]string{ts.URL}), httpclient.WithMaxRetries(0))
+		require.NoError(t, err)
+
+		_, err = client.Get(context.Background())
+		require.Error(t, err)
+		safeParams, unsafeParams := werror.ParamsFromError(err)
+		assert.Equal(t, true, safeParams["responseBodyTruncated"])
+		assert.Len(t, unsafeParams["responseBody"], 1<<20)
+	})
+	t.Run("deadline", func(t *testing.T) {
+		unblock := make(chan struct{})
+		ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			rw.WriteHeader(http.StatusInternalServerError)
+			_, _ = rw.Write([]byte("partial"))
+			rw.(http.Flusher).Flush()
+			<-unblock
+		}))
+		defer ts.Close()
+		defer close(unblock)
+		client, err := httpclient.NewClient(httpclient.WithBaseURLs([]string{ts.URL}), httpclient.WithMaxRetries(0))
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+		defer cancel()
+		start := time.Now()
+		_, err = client.Get(ctx)
+		require.Error(t, err)
+		assert.Less(t, time.Since(start), 5*time.Second)
+		statusCode, _ := httpclient.StatusCodeFromError(err)
+		assert.Equal(t, http.StatusInternalServerError, statusCode)
+		safeParams, unsafeParams := werror.ParamsFromError(err)
+		assert.Equal(t, true, safeParams["responseBodyTruncated"])
+		assert.Equal(t, "partial", unsafeParams["responseBody"])
+	})
+}
+
+func TestErrorDecoderContextParams(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusNotFound)
+	}))
+	defer ts.Close()
+	client, err := httpclient.NewClient(httpclient.WithBaseURLs([]string{ts.URL}), httpclient.WithMaxRetries(0))
+	require.NoError(t, err)
+
+	ctx := wparams.ContextWithSafeAndUnsafeParams(context.Background(),
+		map[string]interface{}{"requestId": "abc"},
+		map[string]interface{}{"userName": "jdoe"})
+	_, err = client.Get(ctx)
+	require.Error(t, err)
+	statusCode, ok := httpclient.StatusCodeFromError(err)
+	require.True(t, ok)
+	assert.Equal(t, http.StatusNotFound, statusCode)
+	safeParams, unsafeParams := werror.ParamsFromError(err)
+	assert.Equal(t, "abc", safeParams["requestId"])
+	assert.Equal(t, "jdoe", unsafeParams["userName"])
+}
+
+func TestAdditionalErrorDecoders(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/html":
+			rw.Header().Set("Content-Type", "text/html")
+			rw.WriteHeader(http.StatusBadGateway)
+		case "/teapot":
+			rw.WriteHeader(http.StatusTeapot)
+		default:
+			rw.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	client, err := httpclient.NewClient(
+		httpclient.WithBaseURLs([]string{ts.URL}),
+		httpclient.WithMaxRetries(0),
+		httpclient.WithAdditionalErrorDecoder(htmlErrorDecoder{}),
+		httpclient.WithAdditionalErrorDecoder(statusErrorDecoder{statusCode: http.StatusTeapot}),
+		httpclient.WithAdditionalErrorDecoder(statusErrorDecoder{statusCode: http.StatusBadGateway}),
+	)
+	require.NoError(t, err)
+
+	_, err = client.Get(context.Background(), httpclient.WithPath("/html"))
+	assert.EqualError(t, err, "httpclient request failed: html error page")
+
+	_, err = client.Get(context.Background(), httpclient.WithPath("/teapot"))
+	assert.EqualError(t, err, "httpclient request failed: status 418")
+
+	_, err = client.Get(context.Background(), httpclient.WithPath("/missing"))
+	require.Error(t, err)
+	statusCode, ok := httpclient.StatusCodeFromError(err)
+	require.True(t, ok)
+	assert.Equal(t, http.StatusNotFound, statusCode)
+}
+
+func TestAcceptRedirects(t *testing.T) {
+	var requests int
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		requests++
+		switch req.URL.Path {
+		case "/location":
+			rw.Header().Set("Location", "/other")
+			rw.WriteHeader(http.StatusTemporaryRedirect)
+		case "/no-location":
+			rw.WriteHeader(http.StatusTemporaryRedirect)
+		default:
+			rw.WriteHeader(http.StatusOK)
+		}
+	}))
+	defer ts.Close()
+
+	client, err := httpclient.NewClient(httpclient.WithBaseURLs([]string{ts.URL}))
+	require.NoError(t, err)
+
+	for _, path := range []string{"/location", "/no-location"} {
+		t.Run(path, func(t *testing.T) {
+			requests = 0
+			resp, err := client.Get(context.Background(), httpclient.WithPath(path), httpclient.WithAcceptRedirects())
+			require.NoError(t, err)
+			assert.Equal(t, http.StatusTemporaryRedirect, resp.StatusCode)
+			assert.Equal(t, 1, requests)
+		})
+	}
+
+	_, err = client.Get(context.Background(), httpclient.WithPath("/no-location"))
+	require.Error(t, err)
+	statusCode, ok := httpclient.StatusCodeFromError(err)
+	require.True(t, ok)
+	assert.Equal(t, http.StatusTemporaryRedirect, statusCode)
+}
+
+func TestConjureErrorsOnly(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/conjure":
+			errors.WriteErrorResponse(rw, errors.NewNotFound())
+		default:
+			rw.Header().Set("Content-Type", "text/html; charset=utf-8")
+			rw.WriteHeader(http.StatusBadGateway)
+			_, _ = rw.Write([]byte("<html><body>secret proxy details</body></html>"))
+		}
+	}))
+	defer ts.Close()
+
+	client, err := httpclient.NewClient(httpclient.WithBaseURLs([]string{ts.URL}), httpclient.WithMaxRetries(0))
+	require.NoError(t, err)
+
+	_, err = client.Get(context.Background(), httpclient.WithPath("/html"), httpclient.WithConjureErrorsOnly())
+	require.Error(t, err)
+	var gatewayErr *httpclient.GatewayError
+	require.ErrorAs(t, err, &gatewayErr)
+	assert.Equal(t, &httpclient.GatewayError{StatusCode: http.StatusBadGateway, ContentType: "text/html"}, gatewayErr)
+	statusCode, ok := httpclient.StatusCodeFromError(err)
+	require.True(t, ok)
+	assert.Equal(t, http.StatusBadGateway, statusCode)
+	safeParams, unsafeParams := werror.ParamsFromError(err)
+	assert.Equal(t, "text/html", safeParams["contentType"])
+	assert.NotContains(t, unsafeParams, "responseBody")
+	assert.NotContains(t, err.Error(), "secret")
+
+	_, err = client.Get(context.Background(), httpclient.WithPath("/conjure"), httpclient.WithConjureErrorsOnly())
+	require.Error(t, err)
+	assert.True(t, errors.IsNotFound(errors.GetConjureError(err)))
+
+	_, err = client.Get(context.Background(), httpclient.WithPath("/html"))
+	require.Error(t, err)
+	_, unsafeParams = werror.ParamsFromError(err)
+	assert.NotContains(t, unsafeParams, "responseBody")
+}
+
+func TestHTMLErrorResponses(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("Content-Type", "text/html; charset=utf-8")
+		rw.WriteHeader(http.StatusBadGateway)
+		_, _ = rw.Write([]byte("<html><head><TITLE>502 Bad\n  Gateway &amp; more</TITLE></head><body>secret proxy details</body></html>"))
+	}))
+	defer ts.Close()
+
+	client, err := httpclient.NewClient(httpclient.WithBaseURLs([]string{ts.URL}), httpclient.WithMaxRetries(0))
+	require.NoError(t, err)
+	_, err = client.Get(context.Background())
+	require.Error(t, err)
+	statusCode, ok := httpclient.StatusCodeFromError(err)
+	require.True(t, ok)
+	assert.Equal(t, http.StatusBadGateway, statusCode)
+	safeParams, unsafeParams := werror.ParamsFromError(err)
+	assert.Equal(t, "502 Bad Gateway & more", safeParams["responseSummary"])
+	assert.Equal(t, "text/html", safeParams["contentType"])
+	assert.NotContains(t, unsafeParams, "responseBody")
+
+	client, err = httpclient.NewClient(
+		httpclient.WithBaseURLs([]string{ts.URL}),
+		httpclient.WithMaxRetries(0),
+		httpclient.WithHTMLErrorBodies())
+	require.NoError(t, err)
+	_, err = client.Get(context.Background())
+	require.Error(t, err)
+	_, unsafeParams = werror.ParamsFromError(err)
+	assert.Contains(t, unsafeParams["responseBody"], "secret proxy details")
+}
+
+type htmlErrorDecoder struct{}
+
+func (htmlErrorDecoder) Handles(resp *http.Response) bool {
+	return resp.StatusCode >= http.StatusBadRequest && resp.Header.Get("Content-Type") == "text/html"
+}
+
+func (htmlErrorDecoder) DecodeError(resp *http.Response) error {
+	return fmt.Errorf("html error page")
+}
+
+type statusErrorDecoder struct {
+	statusCode int
+}
+
+func (d statusErrorDecoder) Handles(resp *http.Response) bool {
+	return resp.StatusCode == d.statusCode
+}
+
+func (d statusErrorDecoder) DecodeError(resp *http.Response) error {
+	return fmt.Errorf("status %d", resp.StatusCode)
+}
+
 type fooErrorDecoder struct{}
 
 func (d fooErrorDecoder) Handles(resp *http.Response) bool {
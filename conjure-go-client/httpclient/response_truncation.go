@@ -0,0 +1,65 @@
+// Copyright (c) 2026 Palantir Technologies. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpclient
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	werror "github.com/palantir/witchcraft-go-error"
+)
+
+// ErrResponseTruncated is returned when a response body ends before the number of bytes declared by its
+// Content-Length, or the connection is closed in the middle of a chunked or compressed body. It wraps
+// io.ErrUnexpectedEOF. Requests which fail with ErrResponseTruncated while decoding the response are retried.
+// The error includes the "contentLength" and "responseBytesRead" safe params.
+var ErrResponseTruncated = fmt.Errorf("response body truncated: %w", io.ErrUnexpectedEOF)
+
+// truncationCheckBody returns an error wrapping ErrResponseTruncated instead of io.EOF or io.ErrUnexpectedEOF if
+// the body ends early.
+type truncationCheckBody struct {
+	io.ReadCloser
+	contentLength int64
+	bytesRead     int64
+	// err is set once the body is found to be truncated.
+	err error
+}
+
+// wrapTruncationCheck replaces the body of resp with a truncationCheckBody. It returns nil if resp has no body.
+func wrapTruncationCheck(resp *http.Response) *truncationCheckBody {
+	if resp == nil || resp.Body == nil || resp.Body == http.NoBody {
+		return nil
+	}
+	body := &truncationCheckBody{ReadCloser: resp.Body, contentLength: resp.ContentLength}
+	resp.Body = body
+	return body
+}
+
+func (b *truncationCheckBody) Read(p []byte) (int, error) {
+	if b.err != nil {
+		return 0, b.err
+	}
+	n, err := b.ReadCloser.Read(p)
+	b.bytesRead += int64(n)
+	if errors.Is(err, io.ErrUnexpectedEOF) || (err == io.EOF && b.contentLength > 0 && b.bytesRead < b.contentLength) {
+		b.err = werror.Wrap(ErrResponseTruncated, "",
+			werror.SafeParam("contentLength", b.contentLength),
+			werror.SafeParam("responseBytesRead", b.bytesRead))
+		return n, b.err
+	}
+	return n, err
+}
@@ -0,0 +1,53 @@
+// Copyright (c) 2026 Palantir Technologies. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpclient
+
+import (
+	"context"
+	"time"
+
+	"github.com/palantir/conjure-go-runtime/v2/conjure-go-client/httpclient/internal"
+)
+
+// RetryObserver is notified of the backoff decisions made while retrying a request. It is intended for tests and
+// tooling which validate retry behavior, e.g. against a simulated failure schedule, without depending on timing.
+type RetryObserver interface {
+	// OnBackoff is called before the client waits to retry a request. retry is the one-based number of the retry
+	// since the last reset, nominal is the exponential backoff before jitter is applied, and delay is the time the
+	// client will wait.
+	OnBackoff(ctx context.Context, retry int, nominal, delay time.Duration)
+	// OnReset is called when the backoff is reset, e.g. because the request was redirected to a new URI. The next
+	// retry is made without waiting.
+	OnReset(ctx context.Context)
+}
+
+// backoffObserver returns an internal.BackoffObserver which notifies observers with ctx.
+func backoffObserver(ctx context.Context, observers []RetryObserver) internal.BackoffObserver {
+	if len(observers) == 0 {
+		return internal.BackoffObserver{}
+	}
+	return internal.BackoffObserver{
+		OnBackoff: func(retry int, nominal, delay time.Duration) {
+			for _, o := range observers {
+				o.OnBackoff(ctx, retry, nominal, delay)
+			}
+		},
+		OnReset: func() {
+			for _, o := range observers {
+				o.OnReset(ctx)
+			}
+		},
+	}
+}
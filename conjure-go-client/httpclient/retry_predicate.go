@@ -0,0 +1,56 @@
+// Copyright (c) 2026 Palantir Technologies. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpclient
+
+import (
+	"net/http"
+)
+
+// RetryDecision is the result of a RetryPredicate.
+type RetryDecision int
+
+const (
+	// RetryDecisionDefault leaves the decision to the next predicate, or to the client's default handling: 429, 503,
+	// 307 and 308 responses and connection errors are retried, and other responses are not.
+	RetryDecisionDefault RetryDecision = iota
+	// RetryDecisionRetry retries the request on the next URI, backing off as for a 503 (Service Unavailable) response.
+	RetryDecisionRetry
+	// RetryDecisionNoRetry returns the response or error without retrying the request.
+	RetryDecisionNoRetry
+)
+
+// RetryPredicate decides whether a request is retried after an attempt which returned resp and err. When the error
+// decoder converted the response into an error, resp is nil and its status code is available with
+// StatusCodeFromError.
+type RetryPredicate func(resp *http.Response, err error) RetryDecision
+
+// retryPredicates returns a predicate for internal.RequestRetrier which returns the first decision other than
+// RetryDecisionDefault made by predicates, or nil if there are no predicates.
+func retryPredicates(predicates []RetryPredicate) func(resp *http.Response, err error) (retry bool, decided bool) {
+	if len(predicates) == 0 {
+		return nil
+	}
+	return func(resp *http.Response, err error) (bool, bool) {
+		for _, predicate := range predicates {
+			switch predicate(resp, err) {
+			case RetryDecisionRetry:
+				return true, true
+			case RetryDecisionNoRetry:
+				return false, true
+			}
+		}
+		return false, false
+	}
+}
@@ -0,0 +1,69 @@
+// Copyright (c) 2026 Palantir Technologies. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpclient
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/palantir/conjure-go-runtime/v2/conjure-go-client/httpclient/internal/refreshingclient"
+)
+
+// RetryScheduleFromConfig returns the nominal backoff, before jitter, which a client built from cfg waits before each
+// retry that backs off. The schedule has one entry per retry allowed by cfg, so its length is one less than the
+// maximum number of attempts; it is nil if retries are unlimited or disabled. Retries which fail over to a URI that
+// has not yet failed are sent immediately and do not use an entry, so the waits of a request are always a prefix of
+// the schedule. Each wait is jittered by up to 15% in either direction.
+//
+// RetryScheduleFromConfig is intended for documenting and testing a client's configuration. It returns an error if
+// cfg is not valid.
+func RetryScheduleFromConfig(cfg ClientConfig) ([]time.Duration, error) {
+	params, err := newValidatedClientParamsFromConfig(context.Background(), cfg)
+	if err != nil {
+		return nil, err
+	}
+	maxAttempts := 2 * len(params.URIs)
+	if params.MaxAttempts != nil {
+		maxAttempts = *params.MaxAttempts
+	}
+	return retrySchedule(params.Retry, maxAttempts), nil
+}
+
+// retrySchedule returns the nominal backoff, excluding jitter, before each of the retries between maxAttempts attempts
+// using the exponential backoff of internal.NewBackoffRetrier. maxAttempts of 0 means no limit, for which no schedule
+// is computed.
+func retrySchedule(p refreshingclient.RetryParams, maxAttempts int) []time.Duration {
+	if maxAttempts <= 1 {
+		return nil
+	}
+	maxBackoff := p.MaxBackoff
+	if maxBackoff != 0 && p.InitialBackoff > maxBackoff {
+		// As in internal.NewBackoffRetrier, the initial backoff takes precedence.
+		maxBackoff = p.InitialBackoff
+	}
+	schedule := make([]time.Duration, 0, maxAttempts-1)
+	backoff := p.InitialBackoff
+	for i := 1; i < maxAttempts; i++ {
+		if maxBackoff > 0 && backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+		schedule = append(schedule, backoff)
+		if backoff < math.MaxInt64/2 {
+			backoff *= 2
+		}
+	}
+	return schedule
+}
@@ -0,0 +1,49 @@
+// Copyright (c) 2026 Palantir Technologies. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpclient
+
+import (
+	"errors"
+	"net"
+	"net/http"
+)
+
+// safeMethodRetryPredicate returns the predicate used for requests with non-idempotent methods by clients built with
+// WithSafeMethodRetriesOnly. Errors without a response are only retried if the request is known not to have been sent;
+// other attempts are decided by next, which may be nil.
+func safeMethodRetryPredicate(next func(resp *http.Response, err error) (bool, bool)) func(resp *http.Response, err error) (bool, bool) {
+	return func(resp *http.Response, err error) (bool, bool) {
+		if resp == nil && err != nil && !isUnsentRequestError(err) {
+			if _, ok := StatusCodeFromError(err); !ok {
+				return false, true
+			}
+		}
+		if next == nil {
+			return false, false
+		}
+		return next(resp, err)
+	}
+}
+
+// isUnsentRequestError returns true if err occurred before any part of the request was written, i.e. because the
+// server's address could not be resolved or a connection to it could not be established.
+func isUnsentRequestError(err error) bool {
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return true
+	}
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
@@ -0,0 +1,77 @@
+// Copyright (c) 2026 Palantir Technologies. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpclient
+
+import (
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// serverBackoffMiddleware delays requests according to the BackpressureHint of successful responses, so that a server
+// can slow its clients down before it needs to reject requests.
+//
+// Hints apply to every subsequent request sent by the client and are capped at maxDelay and at the MaxServerBackoff of
+// the request's RequestClassPolicy.
+type serverBackoffMiddleware struct {
+	maxDelay time.Duration
+	now      func() time.Time
+	// pausedUntil is the Unix time in nanoseconds before which requests are delayed.
+	pausedUntil atomic.Int64
+}
+
+func newServerBackoffMiddleware(maxDelay time.Duration) *serverBackoffMiddleware {
+	return &serverBackoffMiddleware{maxDelay: maxDelay, now: time.Now}
+}
+
+func (m *serverBackoffMiddleware) RoundTrip(req *http.Request, next http.RoundTripper) (*http.Response, error) {
+	wait := time.Duration(m.pausedUntil.Load() - m.now().UnixNano())
+	if policy, ok := getRequestClassPolicy(req.Context()); ok && policy.MaxServerBackoff != nil && wait > *policy.MaxServerBackoff {
+		wait = *policy.MaxServerBackoff
+	}
+	if wait > 0 {
+		timer := time.NewTimer(wait)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+	}
+	resp, err := next.RoundTrip(req)
+	if err == nil && resp != nil && resp.StatusCode/100 == 2 {
+		now := m.now()
+		if hint, ok := backpressureHint(resp.Header, now); ok {
+			if delay := hint.Delay(now); delay > 0 {
+				m.pause(delay)
+			}
+		}
+	}
+	return resp, err
+}
+
+// pause delays requests for delay, capped at maxDelay, unless they are already delayed for longer.
+func (m *serverBackoffMiddleware) pause(delay time.Duration) {
+	if delay > m.maxDelay {
+		delay = m.maxDelay
+	}
+	until := m.now().Add(delay).UnixNano()
+	for {
+		current := m.pausedUntil.Load()
+		if current >= until || m.pausedUntil.CompareAndSwap(current, until) {
+			return
+		}
+	}
+}
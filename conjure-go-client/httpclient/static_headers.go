@@ -0,0 +1,55 @@
+// Copyright (c) 2026 Palantir Technologies. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpclient
+
+import (
+	"net/http"
+
+	"github.com/palantir/conjure-go-runtime/v2/conjure-go-client/httpclient/internal/refreshingclient"
+	refreshablev2 "github.com/palantir/pkg/refreshable/v2"
+	werror "github.com/palantir/witchcraft-go-error"
+	"golang.org/x/net/http/httpguts"
+)
+
+// staticHeadersMiddleware sets the headers configured by ClientConfig.Headers on each request which does not
+// already have them.
+type staticHeadersMiddleware struct {
+	headers refreshablev2.Refreshable[refreshingclient.StaticHeaders]
+}
+
+func (m *staticHeadersMiddleware) RoundTrip(req *http.Request, next http.RoundTripper) (*http.Response, error) {
+	for name, value := range m.headers.Current() {
+		if _, ok := req.Header[name]; !ok {
+			req.Header.Set(name, value)
+		}
+	}
+	return next.RoundTrip(req)
+}
+
+// newStaticHeaders copies headers with canonical names, returning an error if any name or value is not valid in an
+// HTTP header.
+func newStaticHeaders(headers map[string]string) (refreshingclient.StaticHeaders, error) {
+	validHeaders := make(refreshingclient.StaticHeaders, len(headers))
+	for name, value := range headers {
+		if !httpguts.ValidHeaderFieldName(name) {
+			return nil, werror.Error("invalid header name", werror.SafeParam("headerName", name))
+		}
+		if !httpguts.ValidHeaderFieldValue(value) {
+			return nil, werror.Error("invalid header value", werror.SafeParam("headerName", name))
+		}
+		validHeaders[http.CanonicalHeaderKey(name)] = value
+	}
+	return validHeaders, nil
+}
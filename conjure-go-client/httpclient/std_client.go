@@ -0,0 +1,105 @@
+// Copyright (c) 2026 Palantir Technologies. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpclient
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"net/http"
+
+	werror "github.com/palantir/witchcraft-go-error"
+)
+
+// StdClient returns an *http.Client which sends requests using client, for use with libraries which require the
+// standard library interface. Only the path and query of a request's URL are used: they are joined with the base
+// URIs of client, so requests benefit from its URI selection, retries, middleware and metrics.
+//
+// Request bodies are read into memory so that they can be replayed when the request is retried. Responses with a
+// status code of 400 or greater are returned as responses rather than errors, with the body limited to the first
+// 1MiB; as with Do, they may be retried depending on the status code before the final response is returned.
+func StdClient(client Client) *http.Client {
+	return &http.Client{Transport: &stdClientTransport{client: client}}
+}
+
+type stdClientTransport struct {
+	client Client
+}
+
+func (t *stdClientTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	params := []RequestParam{
+		WithRequestMethod(req.Method),
+		WithPath(req.URL.EscapedPath()),
+		WithQueryValues(req.URL.Query()),
+		WithRawResponseBody(),
+		WithRequestErrorDecoder(stdClientErrorDecoder{}),
+	}
+	if req.Body != nil && req.Body != http.NoBody {
+		body, err := ioutil.ReadAll(req.Body)
+		_ = req.Body.Close()
+		if err != nil {
+			return nil, werror.WrapWithContextParams(req.Context(), err, "failed to read request body")
+		}
+		params = append(params, WithRequestBodyReader(bytes.NewReader(body), int64(len(body)), ""))
+	}
+	// copy the request headers last so that they replace any set by the params above
+	header := req.Header.Clone()
+	params = append(params, requestParamFunc(func(b *requestBuilder) error {
+		if header != nil {
+			b.headers = header
+		}
+		return nil
+	}))
+
+	resp, err := t.client.Do(req.Context(), params...)
+	if err != nil {
+		var respErr *stdClientResponseError
+		if !errors.As(err, &respErr) {
+			return nil, err
+		}
+		resp = respErr.resp
+	}
+	resp.Request = req
+	return resp, nil
+}
+
+// stdClientErrorDecoder buffers the body of error responses and returns them as a *stdClientResponseError.
+type stdClientErrorDecoder struct{}
+
+func (stdClientErrorDecoder) Handles(resp *http.Response) bool {
+	return resp.StatusCode >= http.StatusBadRequest
+}
+
+func (stdClientErrorDecoder) DecodeError(resp *http.Response) error {
+	statusCode := werror.SafeParam("statusCode", resp.StatusCode)
+	body, _, err := readErrorBody(resp)
+	if err != nil {
+		return werror.Wrap(err, "server returned an error and failed to read body", statusCode)
+	}
+	respCopy := *resp
+	respCopy.Body = ioutil.NopCloser(bytes.NewReader(body))
+	respCopy.ContentLength = int64(len(body))
+	return werror.Wrap(&stdClientResponseError{resp: &respCopy}, "", statusCode)
+}
+
+// stdClientResponseError carries an error response so that it can be returned by stdClientTransport. It includes
+// the statusCode param so that the response is retried in the same way as errors returned by the default decoder.
+type stdClientResponseError struct {
+	resp *http.Response
+}
+
+func (e *stdClientResponseError) Error() string {
+	return e.resp.Status
+}
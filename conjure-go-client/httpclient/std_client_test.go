@@ -0,0 +1,86 @@
+// Copyright (c) 2026 Palantir Technologies. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpclient_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/palantir/conjure-go-runtime/v2/conjure-go-client/httpclient"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStdClient(t *testing.T) {
+	unavailable := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer unavailable.Close()
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.URL.Path == "/missing" {
+			rw.WriteHeader(http.StatusNotFound)
+			_, _ = rw.Write([]byte("not found"))
+			return
+		}
+		body, err := ioutil.ReadAll(req.Body)
+		assert.NoError(t, err)
+		rw.Header().Set("Content-Type", "text/plain")
+		_, _ = rw.Write([]byte(strings.Join([]string{req.Method, req.URL.EscapedPath(), req.URL.Query().Get("q"), req.Header.Get("X-Foo"), string(body)}, " ")))
+	}))
+	defer server.Close()
+
+	client, err := httpclient.NewClient(
+		httpclient.WithBaseURLs([]string{unavailable.URL, server.URL}),
+		httpclient.WithInitialBackoff(time.Millisecond),
+		httpclient.WithMaxBackoff(time.Millisecond),
+	)
+	require.NoError(t, err)
+	stdClient := httpclient.StdClient(client)
+
+	t.Run("success with retry", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodPost, "http://ignored/path?q=bar", strings.NewReader("body"))
+		require.NoError(t, err)
+		req.Header.Set("X-Foo", "foo")
+		resp, err := stdClient.Do(req)
+		require.NoError(t, err)
+		defer func() { _ = resp.Body.Close() }()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "text/plain", resp.Header.Get("Content-Type"))
+		body, err := ioutil.ReadAll(resp.Body)
+		require.NoError(t, err)
+		assert.Equal(t, "POST /path bar foo body", string(body))
+	})
+	t.Run("escaped path", func(t *testing.T) {
+		resp, err := stdClient.Get("http://ignored/files/a%2Fb/100%25")
+		require.NoError(t, err)
+		defer func() { _ = resp.Body.Close() }()
+		body, err := ioutil.ReadAll(resp.Body)
+		require.NoError(t, err)
+		assert.Equal(t, "GET /files/a%2Fb/100%25   ", string(body))
+	})
+	t.Run("error response", func(t *testing.T) {
+		resp, err := stdClient.Get("http://ignored/missing")
+		require.NoError(t, err)
+		defer func() { _ = resp.Body.Close() }()
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+		body, err := ioutil.ReadAll(resp.Body)
+		require.NoError(t, err)
+		assert.Equal(t, "not found", string(body))
+	})
+}
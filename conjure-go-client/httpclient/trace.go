@@ -42,6 +42,9 @@ func newTraceMiddleware(serviceName refreshable.String, disableRequestSpan, disa
 
 func (t traceMiddleware) RoundTrip(req *http.Request, next http.RoundTripper) (*http.Response, error) {
 	ctx := req.Context()
+	if withoutTracing(ctx) {
+		return next.RoundTrip(req)
+	}
 	span := wtracing.SpanFromContext(ctx)
 
 	if !t.DisableRequestSpan {
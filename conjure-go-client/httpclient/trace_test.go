@@ -67,6 +67,16 @@ func TestTracing(t *testing.T) {
 			},
 			shouldPropagateTrace: true,
 		},
+		{
+			name: "ongoing span, without tracing",
+			requestParams: []httpclient.RequestParam{
+				httpclient.WithRequestMethod(http.MethodGet),
+				httpclient.WithRPCMethodName("myname"),
+				httpclient.WithoutTracing(),
+			},
+			ongoingSpanName:      "operation",
+			shouldPropagateTrace: false,
+		},
 	} {
 		t.Run(testCase.name, func(t *testing.T) {
 			tracer := mustNewTracer()
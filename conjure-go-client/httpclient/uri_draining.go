@@ -0,0 +1,160 @@
+// Copyright (c) 2026 Palantir Technologies. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpclient
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/palantir/pkg/refreshable"
+)
+
+// uriDrainer releases the resources held for URIs which are removed from a client's configuration. It is only built
+// for clients using WithRemovedURIGracePeriod. When a URI is removed, the idle connections of the client's transport
+// are closed, as the transport can not close the connections of a single host; connections to the remaining URIs are
+// re-established as needed. The requests to a removed URI which are still in flight once gracePeriod has passed are
+// canceled, unless the URI was added back.
+//
+// The uriDrainer is shared by the clients derived from the same builder. It stops following the updates of the URIs
+// once it is garbage collected, so the subscriptions to the URIs must not reference it: the subscription of the
+// uriDrainer only references its uriDrainState.
+type uriDrainer struct {
+	*uriDrainState
+	unsubscribe func()
+}
+
+type uriDrainState struct {
+	gracePeriod time.Duration
+	closeIdle   func()
+
+	mu      sync.Mutex
+	current map[string]struct{}
+	// inFlight holds the cancel functions of the in-flight requests to each URI.
+	inFlight map[string]map[*inFlightRequest]struct{}
+}
+
+type inFlightRequest struct {
+	cancel context.CancelFunc
+}
+
+// newURIDrainer returns a uriDrainer which follows the updates of uris until it is garbage collected.
+func newURIDrainer(uris refreshable.StringSlice, transport http.RoundTripper, gracePeriod time.Duration) *uriDrainer {
+	state := &uriDrainState{
+		gracePeriod: gracePeriod,
+		closeIdle:   func() {},
+		current:     uriSet(uris.CurrentStringSlice()),
+		inFlight:    make(map[string]map[*inFlightRequest]struct{}),
+	}
+	if closer, ok := transport.(interface{ CloseIdleConnections() }); ok {
+		state.closeIdle = closer.CloseIdleConnections
+	}
+	d := &uriDrainer{uriDrainState: state}
+	d.unsubscribe = uris.SubscribeToStringSlice(state.update)
+	runtime.SetFinalizer(d, (*uriDrainer).release)
+	return d
+}
+
+// release stops following the updates of the client's URIs.
+func (d *uriDrainer) release() {
+	d.unsubscribe()
+}
+
+func uriSet(uris []string) map[string]struct{} {
+	set := make(map[string]struct{}, len(uris))
+	for _, uri := range uris {
+		set[uri] = struct{}{}
+	}
+	return set
+}
+
+func (d *uriDrainState) update(uris []string) {
+	updated := uriSet(uris)
+	d.mu.Lock()
+	var removed []string
+	for uri := range d.current {
+		if _, ok := updated[uri]; !ok {
+			removed = append(removed, uri)
+		}
+	}
+	d.current = updated
+	d.mu.Unlock()
+	if len(removed) == 0 {
+		return
+	}
+	d.closeIdle()
+	time.AfterFunc(d.gracePeriod, func() { d.cancelRemoved(removed) })
+}
+
+// cancelRemoved cancels the in-flight requests to the URIs in removed which have not been added back.
+func (d *uriDrainState) cancelRemoved(removed []string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, uri := range removed {
+		if _, ok := d.current[uri]; ok {
+			continue
+		}
+		for req := range d.inFlight[uri] {
+			req.cancel()
+		}
+	}
+}
+
+func (d *uriDrainState) RoundTrip(req *http.Request, next http.RoundTripper) (*http.Response, error) {
+	baseURI := getBaseURI(req.Context())
+	if baseURI == "" {
+		return next.RoundTrip(req)
+	}
+	ctx, cancel := context.WithCancel(req.Context())
+	tracked := &inFlightRequest{cancel: cancel}
+	d.mu.Lock()
+	if d.inFlight[baseURI] == nil {
+		d.inFlight[baseURI] = make(map[*inFlightRequest]struct{})
+	}
+	d.inFlight[baseURI][tracked] = struct{}{}
+	d.mu.Unlock()
+	done := func() {
+		d.mu.Lock()
+		delete(d.inFlight[baseURI], tracked)
+		if len(d.inFlight[baseURI]) == 0 {
+			delete(d.inFlight, baseURI)
+		}
+		d.mu.Unlock()
+		cancel()
+	}
+
+	resp, err := next.RoundTrip(req.WithContext(ctx))
+	if err != nil || resp == nil || resp.Body == nil {
+		done()
+		return resp, err
+	}
+	// the request is in flight until its body has been read and closed.
+	resp.Body = &drainTrackingBody{ReadCloser: resp.Body, done: done}
+	return resp, nil
+}
+
+type drainTrackingBody struct {
+	io.ReadCloser
+	once sync.Once
+	done func()
+}
+
+func (b *drainTrackingBody) Close() error {
+	defer b.once.Do(b.done)
+	return b.ReadCloser.Close()
+}
@@ -0,0 +1,112 @@
+// Copyright (c) 2026 Palantir Technologies. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpclient_test
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/palantir/conjure-go-runtime/v2/conjure-go-client/httpclient"
+	"github.com/palantir/pkg/refreshable"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRemovedURIDraining(t *testing.T) {
+	started := make(chan struct{}, 1)
+	slow := httptest.NewUnstartedServer(http.HandlerFunc(func(_ http.ResponseWriter, req *http.Request) {
+		if req.URL.Path == "/slow" {
+			started <- struct{}{}
+			<-req.Context().Done()
+		}
+	}))
+	var closedConns atomic.Int32
+	slow.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateClosed {
+			closedConns.Add(1)
+		}
+	}
+	slow.Start()
+	defer slow.Close()
+	other := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	defer other.Close()
+
+	uris := refreshable.NewDefaultRefreshable([]string{slow.URL})
+	client, err := httpclient.NewClient(
+		httpclient.WithRefreshableBaseURLs(refreshable.NewStringSlice(uris)),
+		httpclient.WithMaxRetries(0),
+		httpclient.WithRemovedURIGracePeriod(50*time.Millisecond))
+	require.NoError(t, err)
+
+	// the idle connection to a removed URI is closed.
+	_, err = client.Get(context.Background())
+	require.NoError(t, err)
+	require.NoError(t, uris.Update([]string{other.URL}))
+	assert.Eventually(t, func() bool { return closedConns.Load() == 1 }, time.Second, 10*time.Millisecond)
+
+	// the in-flight request to a removed URI is canceled after the grace period.
+	require.NoError(t, uris.Update([]string{slow.URL}))
+	done := make(chan error, 1)
+	go func() {
+		_, err := client.Get(context.Background(), httpclient.WithPath("/slow"))
+		done <- err
+	}()
+	<-started
+	require.NoError(t, uris.Update([]string{other.URL}))
+	select {
+	case err := <-done:
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "context canceled")
+	case <-time.After(5 * time.Second):
+		t.Fatal("in-flight request to removed URI was not canceled")
+	}
+}
+
+func TestRemovedURIDrainerReleased(t *testing.T) {
+	var unsubscribed atomic.Bool
+	uris := unsubscribeTrackingURIs{
+		StringSlice:  refreshable.NewStringSlice(refreshable.NewDefaultRefreshable([]string{"https://localhost"})),
+		unsubscribed: &unsubscribed,
+	}
+	_, err := httpclient.NewClient(
+		httpclient.WithRefreshableBaseURLs(uris),
+		httpclient.WithRemovedURIGracePeriod(time.Second))
+	require.NoError(t, err)
+
+	// the client is unreachable, so its drainer stops following the URIs once it is garbage collected.
+	assert.Eventually(t, func() bool {
+		runtime.GC()
+		return unsubscribed.Load()
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+type unsubscribeTrackingURIs struct {
+	refreshable.StringSlice
+	unsubscribed *atomic.Bool
+}
+
+func (u unsubscribeTrackingURIs) SubscribeToStringSlice(consumer func([]string)) (unsubscribe func()) {
+	unsubscribeURIs := u.StringSlice.SubscribeToStringSlice(consumer)
+	return func() {
+		u.unsubscribed.Store(true)
+		unsubscribeURIs()
+	}
+}
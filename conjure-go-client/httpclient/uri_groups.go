@@ -0,0 +1,224 @@
+// Copyright (c) 2026 Palantir Technologies. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpclient
+
+import (
+	"math/rand"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/palantir/conjure-go-runtime/v2/conjure-go-client/httpclient/internal"
+	"github.com/palantir/conjure-go-runtime/v2/conjure-go-client/httpclient/internal/refreshingclient"
+	"github.com/palantir/pkg/metrics"
+	refreshablev2 "github.com/palantir/pkg/refreshable/v2"
+)
+
+const (
+	metricTagURIGroup = "uri-group"
+
+	// uriGroupFailbackDelay is how long the URIs of a more preferred priority level must stay healthy before
+	// requests fail back to it, so that a level which is failing intermittently does not cause requests to flap
+	// between levels.
+	uriGroupFailbackDelay = time.Minute
+)
+
+// uriGroupSelector orders a client's URIs according to its URI groups. Groups with the lowest priority are used
+// while any of their URIs is healthy; when all of them are unavailable, requests fail over to the next priority
+// level which has a healthy URI. Within a level, a group is selected according to the groups' percentages.
+type uriGroupSelector struct {
+	groups refreshablev2.Refreshable[[]refreshingclient.URIGroup]
+	now    func() time.Time
+
+	mu sync.Mutex
+	// active is the priority level currently receiving requests. It is only valid if hasActive is true.
+	active    int
+	hasActive bool
+	// healthySince is when each priority level was first seen with a healthy URI since it was last seen without one.
+	healthySince map[int]time.Time
+}
+
+func newURIGroupSelector(groups refreshablev2.Refreshable[[]refreshingclient.URIGroup]) *uriGroupSelector {
+	return &uriGroupSelector{
+		groups:       groups,
+		now:          time.Now,
+		healthySince: make(map[int]time.Time),
+	}
+}
+
+// orderURIs returns uris, as ordered by scorer, reordered so that the URIs of the active priority level come first
+// followed by the other levels in priority order. URIs which are unavailable according to scorer are considered
+// unhealthy; scorers which do not track availability never cause a failover.
+func (s *uriGroupSelector) orderURIs(uris []string, scorer internal.URIScoringMiddleware) []string {
+	groups := s.groups.Current()
+	if len(groups) == 0 {
+		return uris
+	}
+	levels := uriGroupPriorities(groups)
+	if len(levels) == 1 {
+		return orderURIsByGroup(uris, groups)
+	}
+
+	unavailable := make(map[string]struct{})
+	if reporter, ok := scorer.(internal.URIStateReporter); ok {
+		for _, state := range reporter.URIStates() {
+			if state.Unavailable {
+				unavailable[state.URI] = struct{}{}
+			}
+		}
+	}
+	healthy := make(map[int]bool, len(levels))
+	for _, group := range groups {
+		for _, uri := range group.URIs {
+			if _, ok := unavailable[uri]; !ok {
+				healthy[group.Priority] = true
+			}
+		}
+	}
+	active := s.selectPriority(levels, healthy)
+
+	ordered := make([]string, 0, len(uris))
+	ordered = append(ordered, orderURIsByGroup(urisInPriority(uris, groups, active), groupsInPriority(groups, active))...)
+	for _, level := range levels {
+		if level != active {
+			ordered = append(ordered, orderURIsByGroup(urisInPriority(uris, groups, level), groupsInPriority(groups, level))...)
+		}
+	}
+	return ordered
+}
+
+// selectPriority returns the priority level which should receive requests given the health of each level in levels,
+// which must be sorted. It fails over from an unhealthy active level to the first healthy level immediately, but
+// only fails back to a more preferred level once it has been healthy for uriGroupFailbackDelay.
+func (s *uriGroupSelector) selectPriority(levels []int, healthy map[int]bool) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	for _, level := range levels {
+		if !healthy[level] {
+			delete(s.healthySince, level)
+		} else if _, ok := s.healthySince[level]; !ok {
+			s.healthySince[level] = now
+		}
+	}
+	if !s.hasActive || !slices.Contains(levels, s.active) {
+		s.active, s.hasActive = levels[0], true
+	}
+	for _, level := range levels {
+		if level == s.active && healthy[level] {
+			break
+		}
+		if healthy[level] && (level > s.active || now.Sub(s.healthySince[level]) >= uriGroupFailbackDelay) {
+			s.active = level
+			break
+		}
+	}
+	return s.active
+}
+
+// uriGroupPriorities returns the distinct priorities of groups in increasing order.
+func uriGroupPriorities(groups []refreshingclient.URIGroup) []int {
+	levels := make([]int, 0, len(groups))
+	for _, group := range groups {
+		levels = append(levels, group.Priority)
+	}
+	slices.Sort(levels)
+	return slices.Compact(levels)
+}
+
+func groupsInPriority(groups []refreshingclient.URIGroup, priority int) []refreshingclient.URIGroup {
+	var filtered []refreshingclient.URIGroup
+	for _, group := range groups {
+		if group.Priority == priority {
+			filtered = append(filtered, group)
+		}
+	}
+	return filtered
+}
+
+// urisInPriority returns the elements of uris which belong to a group with the given priority, preserving their order.
+func urisInPriority(uris []string, groups []refreshingclient.URIGroup, priority int) []string {
+	inLevel := make(map[string]struct{})
+	for _, group := range groupsInPriority(groups, priority) {
+		for _, uri := range group.URIs {
+			inLevel[uri] = struct{}{}
+		}
+	}
+	var filtered []string
+	for _, uri := range uris {
+		if _, ok := inLevel[uri]; ok {
+			filtered = append(filtered, uri)
+		}
+	}
+	return filtered
+}
+
+// orderURIsByGroup selects one of groups according to the groups' percentages and returns uris reordered so that the
+// URIs of the selected group come first. The relative order of uris is otherwise preserved, so the scorer's
+// preference is respected within each group, and the URIs of the other groups remain available for retries.
+func orderURIsByGroup(uris []string, groups []refreshingclient.URIGroup) []string {
+	group, ok := selectURIGroup(groups, rand.Intn(100))
+	if !ok {
+		return uris
+	}
+	inGroup := make(map[string]struct{}, len(group.URIs))
+	for _, uri := range group.URIs {
+		inGroup[uri] = struct{}{}
+	}
+	ordered := make([]string, 0, len(uris))
+	for _, uri := range uris {
+		if _, ok := inGroup[uri]; ok {
+			ordered = append(ordered, uri)
+		}
+	}
+	for _, uri := range uris {
+		if _, ok := inGroup[uri]; !ok {
+			ordered = append(ordered, uri)
+		}
+	}
+	return ordered
+}
+
+// selectURIGroup returns the group whose percentage range contains roll, which must be in [0, 100).
+func selectURIGroup(groups []refreshingclient.URIGroup, roll int) (refreshingclient.URIGroup, bool) {
+	for _, group := range groups {
+		if roll < group.Percentage {
+			return group, true
+		}
+		roll -= group.Percentage
+	}
+	return refreshingclient.URIGroup{}, false
+}
+
+// uriGroupTagsProvider tags metrics with the name of the URI group containing the base URI selected for the request.
+type uriGroupTagsProvider struct {
+	groups refreshablev2.Refreshable[[]refreshingclient.URIGroup]
+}
+
+func (p uriGroupTagsProvider) Tags(req *http.Request, _ *http.Response, _ error) metrics.Tags {
+	baseURI := getBaseURI(req.Context())
+	if baseURI == "" {
+		return nil
+	}
+	for _, group := range p.groups.Current() {
+		for _, uri := range group.URIs {
+			if uri == baseURI {
+				return metrics.Tags{metrics.NewTagWithFallbackValue(metricTagURIGroup, group.Name, "unknown")}
+			}
+		}
+	}
+	return nil
+}
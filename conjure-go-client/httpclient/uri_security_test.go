@@ -0,0 +1,276 @@
+// Copyright (c) 2026 Palantir Technologies. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpclient_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/palantir/conjure-go-runtime/v2/conjure-go-client/httpclient"
+	"github.com/palantir/pkg/refreshable"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestURISecurity(t *testing.T) {
+	dir := t.TempDir()
+	handler := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusOK)
+	})
+
+	// oldServer uses the default httptest certificate, valid for 127.0.0.1.
+	oldServer := httptest.NewTLSServer(handler)
+	defer oldServer.Close()
+	oldCAFile := writeCertFile(t, dir, "old-ca.pem", oldServer.Certificate().Raw)
+
+	// newServer uses a certificate which is only valid for new.example.com.
+	newCert := newSelfSignedCert(t, "new.example.com")
+	newServer := httptest.NewUnstartedServer(handler)
+	newServer.TLS = &tls.Config{Certificates: []tls.Certificate{newCert}}
+	newServer.StartTLS()
+	defer newServer.Close()
+	newCAFile := writeCertFile(t, dir, "new-ca.pem", newCert.Certificate[0])
+
+	newClient := func(t *testing.T, uriSecurity map[string]httpclient.SecurityConfig) *http.Client {
+		client, err := httpclient.NewHTTPClientFromRefreshableConfig(context.Background(), httpclient.NewRefreshingClientConfig(refreshable.NewDefaultRefreshable(httpclient.ClientConfig{
+			ServiceName: "my-service",
+			URIs:        []string{oldServer.URL, newServer.URL},
+			Security:    httpclient.SecurityConfig{CAFiles: []string{oldCAFile}},
+			URISecurity: uriSecurity,
+		})))
+		require.NoError(t, err)
+		return client.CurrentHTTPClient()
+	}
+
+	t.Run("without overrides", func(t *testing.T) {
+		client := newClient(t, nil)
+		resp, err := client.Get(oldServer.URL)
+		require.NoError(t, err)
+		_ = resp.Body.Close()
+		_, err = client.Get(newServer.URL)
+		require.Error(t, err)
+	})
+
+	t.Run("with overrides", func(t *testing.T) {
+		client := newClient(t, map[string]httpclient.SecurityConfig{
+			newServer.URL: {CAFiles: []string{newCAFile}, ServerName: "new.example.com"},
+		})
+		for _, uri := range []string{oldServer.URL, newServer.URL} {
+			resp, err := client.Get(uri)
+			require.NoError(t, err, uri)
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+			_ = resp.Body.Close()
+		}
+	})
+
+	t.Run("invalid override", func(t *testing.T) {
+		_, err := httpclient.NewHTTPClientFromRefreshableConfig(context.Background(), httpclient.NewRefreshingClientConfig(refreshable.NewDefaultRefreshable(httpclient.ClientConfig{
+			ServiceName: "my-service",
+			URIs:        []string{newServer.URL},
+			URISecurity: map[string]httpclient.SecurityConfig{
+				newServer.URL: {CAFiles: []string{filepath.Join(dir, "missing.pem")}},
+			},
+		})))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid uri-security configuration")
+	})
+}
+
+func TestTLSRefreshInterval(t *testing.T) {
+	dir := t.TempDir()
+	oldCert := newSelfSignedCert(t, "rotating.example.com")
+	newCert := newSelfSignedCert(t, "rotating.example.com")
+	var serverCert atomic.Pointer[tls.Certificate]
+	serverCert.Store(&oldCert)
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusOK)
+	}))
+	server.TLS = &tls.Config{GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+		return serverCert.Load(), nil
+	}}
+	server.StartTLS()
+	defer server.Close()
+	caFile := writeCertFile(t, dir, "ca.pem", oldCert.Certificate[0])
+
+	refreshInterval := 10 * time.Millisecond
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	client, err := httpclient.NewHTTPClientFromRefreshableConfig(ctx, httpclient.NewRefreshingClientConfig(refreshable.NewDefaultRefreshable(httpclient.ClientConfig{
+		ServiceName: "my-service",
+		URIs:        []string{server.URL},
+		Security: httpclient.SecurityConfig{
+			CAFiles:            []string{caFile},
+			ServerName:         "rotating.example.com",
+			TLSRefreshInterval: &refreshInterval,
+		},
+	})), httpclient.WithDisableKeepAlives())
+	require.NoError(t, err)
+	get := func() error {
+		resp, err := client.CurrentHTTPClient().Get(server.URL)
+		if err != nil {
+			return err
+		}
+		return resp.Body.Close()
+	}
+
+	require.NoError(t, get())
+	// the server's certificate is rotated before the client's CA file
+	serverCert.Store(&newCert)
+	require.Error(t, get())
+	writeCertFile(t, dir, "ca.pem", newCert.Certificate[0])
+	require.Eventually(t, func() bool {
+		return get() == nil
+	}, 5*time.Second, refreshInterval)
+}
+
+func TestURISecurityTLSRefreshInterval(t *testing.T) {
+	dir := t.TempDir()
+	oldCert := newSelfSignedCert(t, "rotating.example.com")
+	newCert := newSelfSignedCert(t, "rotating.example.com")
+	var serverCert atomic.Pointer[tls.Certificate]
+	serverCert.Store(&oldCert)
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusOK)
+	}))
+	server.TLS = &tls.Config{GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+		return serverCert.Load(), nil
+	}}
+	server.StartTLS()
+	defer server.Close()
+	caFile := writeCertFile(t, dir, "ca.pem", oldCert.Certificate[0])
+
+	refreshInterval := 10 * time.Millisecond
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	client, err := httpclient.NewHTTPClientFromRefreshableConfig(ctx, httpclient.NewRefreshingClientConfig(refreshable.NewDefaultRefreshable(httpclient.ClientConfig{
+		ServiceName: "my-service",
+		URIs:        []string{server.URL},
+		URISecurity: map[string]httpclient.SecurityConfig{
+			server.URL: {
+				CAFiles:            []string{caFile},
+				ServerName:         "rotating.example.com",
+				TLSRefreshInterval: &refreshInterval,
+			},
+		},
+	})), httpclient.WithDisableKeepAlives())
+	require.NoError(t, err)
+	get := func() error {
+		resp, err := client.CurrentHTTPClient().Get(server.URL)
+		if err != nil {
+			return err
+		}
+		return resp.Body.Close()
+	}
+
+	require.NoError(t, get())
+	// the server's certificate is rotated before the override's CA file
+	serverCert.Store(&newCert)
+	require.Error(t, get())
+	writeCertFile(t, dir, "ca.pem", newCert.Certificate[0])
+	require.Eventually(t, func() bool {
+		return get() == nil
+	}, 5*time.Second, refreshInterval)
+}
+
+func TestSecurityPEM(t *testing.T) {
+	serverCert := newSelfSignedCert(t, "pem.example.com")
+	clientCert := newSelfSignedCert(t, "client.example.com")
+	var presented atomic.Bool
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		presented.Store(len(req.TLS.PeerCertificates) == 1)
+		rw.WriteHeader(http.StatusOK)
+	}))
+	server.TLS = &tls.Config{Certificates: []tls.Certificate{serverCert}, ClientAuth: tls.RequireAnyClientCert}
+	server.StartTLS()
+	defer server.Close()
+
+	keyDER, err := x509.MarshalECPrivateKey(clientCert.PrivateKey.(*ecdsa.PrivateKey))
+	require.NoError(t, err)
+	security := httpclient.SecurityConfig{
+		CAPEM:      string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: serverCert.Certificate[0]})),
+		CertPEM:    string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: clientCert.Certificate[0]})),
+		KeyPEM:     string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})),
+		ServerName: "pem.example.com",
+	}
+	client, err := httpclient.NewHTTPClientFromRefreshableConfig(context.Background(), httpclient.NewRefreshingClientConfig(refreshable.NewDefaultRefreshable(httpclient.ClientConfig{
+		ServiceName: "my-service",
+		URIs:        []string{server.URL},
+		Security:    security,
+	})))
+	require.NoError(t, err)
+	resp, err := client.CurrentHTTPClient().Get(server.URL)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.True(t, presented.Load())
+
+	// PEM values are also used when the SecurityConfig is provided by WithRefreshableTLSConfig.
+	presented.Store(false)
+	refreshingClient, err := httpclient.NewClient(
+		httpclient.WithBaseURLs([]string{server.URL}),
+		httpclient.WithMaxRetries(0),
+		httpclient.WithRefreshableTLSConfig(refreshable.NewDefaultRefreshable(security)),
+	)
+	require.NoError(t, err)
+	_, err = refreshingClient.Get(context.Background())
+	require.NoError(t, err)
+	assert.True(t, presented.Load())
+
+	security.CAPEM = "not a certificate"
+	_, err = httpclient.NewHTTPClientFromRefreshableConfig(context.Background(), httpclient.NewRefreshingClientConfig(refreshable.NewDefaultRefreshable(httpclient.ClientConfig{
+		ServiceName: "my-service",
+		URIs:        []string{server.URL},
+		Security:    security,
+	})))
+	require.Error(t, err)
+}
+
+func newSelfSignedCert(t *testing.T, dnsName string) tls.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: dnsName},
+		DNSNames:              []string{dnsName},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
+
+func writeCertFile(t *testing.T, dir, name string, der []byte) string {
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0644))
+	return path
+}
@@ -0,0 +1,95 @@
+// Copyright (c) 2026 Palantir Technologies. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpclient
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/palantir/conjure-go-runtime/v2/conjure-go-client/httpclient/internal"
+)
+
+// URIPool tracks the state of a client's URIs and orders them by preference. A URIPool replaces the client's default
+// URI scoring, which balances requests between URIs based on in-flight requests and recent failures. A new pool is
+// built by the function passed to WithURIPool each time the client's URIs change.
+type URIPool interface {
+	// URIsInOrder returns the pool's URIs, most preferred first. It is called once per request.
+	URIsInOrder() []string
+	// RoundTrip is called for each attempt, so that the pool can track the in-flight requests and outcomes of each
+	// URI. The base URI of the attempt is available from req.URL. Implementations must call next.RoundTrip(req).
+	RoundTrip(req *http.Request, next http.RoundTripper) (*http.Response, error)
+}
+
+// URISelector chooses the URIs to try for a request, e.g. to prefer URIs in the caller's zone. uris are the client's
+// URIs in the order preferred by its URIPool or URI scoring, along with their current state. The request is sent to
+// the returned URIs in order, and retried only among them; returned URIs which are not in uris are ignored, and if
+// none remain, uris is used unchanged.
+//
+// Per-URI state is only tracked by the default URI scoring; other pools report their URIs with zero values.
+type URISelector interface {
+	SelectURIs(ctx context.Context, uris []URISnapshot) []string
+}
+
+// URISelectorFunc is a convenience type that implements URISelector.
+type URISelectorFunc func(ctx context.Context, uris []URISnapshot) []string
+
+func (f URISelectorFunc) SelectURIs(ctx context.Context, uris []URISnapshot) []string {
+	return f(ctx, uris)
+}
+
+// uriPoolScorer adapts a URIPool to the internal.URIScoringMiddleware interface.
+type uriPoolScorer struct {
+	URIPool
+}
+
+func (s uriPoolScorer) GetURIsInOrderOfIncreasingScore() []string {
+	return s.URIsInOrder()
+}
+
+// selectURIs applies selector to uris, which are ordered as preferred by scorer.
+func selectURIs(ctx context.Context, selector URISelector, scorer internal.URIScoringMiddleware, uris []string) []string {
+	states := make(map[string]internal.URIState, len(uris))
+	if reporter, ok := scorer.(internal.URIStateReporter); ok {
+		for _, state := range reporter.URIStates() {
+			states[state.URI] = state
+		}
+	}
+	snapshots := make([]URISnapshot, 0, len(uris))
+	known := make(map[string]struct{}, len(uris))
+	for _, uri := range uris {
+		known[uri] = struct{}{}
+		state := states[uri]
+		snapshots = append(snapshots, URISnapshot{
+			URI:         uri,
+			InFlight:    state.InFlight,
+			Unavailable: state.Unavailable,
+			Requests:    state.Requests,
+			Failures:    state.Failures,
+			LastSuccess: state.LastSuccess,
+		})
+	}
+	var selected []string
+	for _, uri := range selector.SelectURIs(ctx, snapshots) {
+		if _, ok := known[uri]; ok {
+			selected = append(selected, uri)
+			// each URI is only tried once per pass.
+			delete(known, uri)
+		}
+	}
+	if len(selected) == 0 {
+		return uris
+	}
+	return selected
+}
@@ -0,0 +1,34 @@
+// Copyright (c) 2026 Palantir Technologies. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpclient
+
+import (
+	"context"
+)
+
+// Validator validates request and response bodies, e.g. by running struct validators or JSON schema checks.
+// Configure a client's Validator using WithValidator.
+//
+// ValidateRequest is called with the input provided to WithRequestBody (or a similar param) before it is encoded.
+// If it returns an error, the request is not sent and Do returns a conjure InvalidArgument error wrapping it.
+// Raw request bodies (io.ReadCloser inputs and WithRequestBodyReader) are not validated.
+//
+// ValidateResponse is called with the output provided to WithResponseBody (or a similar param) after the response
+// body is decoded. If it returns an error, Do returns a conjure Internal error wrapping it. Empty and raw response
+// bodies are not validated.
+type Validator interface {
+	ValidateRequest(ctx context.Context, input interface{}) error
+	ValidateResponse(ctx context.Context, output interface{}) error
+}
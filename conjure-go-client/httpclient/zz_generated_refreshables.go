@@ -435,7 +435,12 @@ type RefreshableSecurityConfig interface {
 	CAFiles() refreshable.StringSlice
 	CertFile() refreshable.String
 	KeyFile() refreshable.String
+	CAPEM() refreshable.String
+	CertPEM() refreshable.String
+	KeyPEM() refreshable.String
 	InsecureSkipVerify() refreshable.BoolPtr
+	ServerName() refreshable.String
+	TLSRefreshInterval() refreshable.DurationPtr
 }
 
 type RefreshingSecurityConfig struct {
@@ -480,12 +485,42 @@ func (r RefreshingSecurityConfig) KeyFile() refreshable.String {
 	}))
 }
 
+func (r RefreshingSecurityConfig) CAPEM() refreshable.String {
+	return refreshable.NewString(r.MapSecurityConfig(func(i SecurityConfig) interface{} {
+		return i.CAPEM
+	}))
+}
+
+func (r RefreshingSecurityConfig) CertPEM() refreshable.String {
+	return refreshable.NewString(r.MapSecurityConfig(func(i SecurityConfig) interface{} {
+		return i.CertPEM
+	}))
+}
+
+func (r RefreshingSecurityConfig) KeyPEM() refreshable.String {
+	return refreshable.NewString(r.MapSecurityConfig(func(i SecurityConfig) interface{} {
+		return i.KeyPEM
+	}))
+}
+
 func (r RefreshingSecurityConfig) InsecureSkipVerify() refreshable.BoolPtr {
 	return refreshable.NewBoolPtr(r.MapSecurityConfig(func(i SecurityConfig) interface{} {
 		return i.InsecureSkipVerify
 	}))
 }
 
+func (r RefreshingSecurityConfig) ServerName() refreshable.String {
+	return refreshable.NewString(r.MapSecurityConfig(func(i SecurityConfig) interface{} {
+		return i.ServerName
+	}))
+}
+
+func (r RefreshingSecurityConfig) TLSRefreshInterval() refreshable.DurationPtr {
+	return refreshable.NewDurationPtr(r.MapSecurityConfig(func(i SecurityConfig) interface{} {
+		return i.TLSRefreshInterval
+	}))
+}
+
 type RefreshableStringToClientConfig interface {
 	refreshable.Refreshable
 	CurrentStringToClientConfig() map[string]ClientConfig
@@ -0,0 +1,227 @@
+// Copyright (c) 2026 Palantir Technologies. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package grpcstatus translates Conjure errors to and from gRPC statuses, so that services which speak both
+// protocols can forward errors without losing their Conjure error name, instance ID or parameters.
+//
+// The package does not depend on gRPC. Code values are identical to those of google.golang.org/grpc/codes, and
+// Status and ErrorInfo mirror the google.rpc.Status and google.rpc.ErrorInfo messages, so converting to and from
+// the gRPC types is a field-by-field copy.
+package grpcstatus
+
+import (
+	"encoding/json"
+
+	"github.com/palantir/conjure-go-runtime/v2/conjure-go-contract/codecs"
+	"github.com/palantir/conjure-go-runtime/v2/conjure-go-contract/errors"
+	"github.com/palantir/pkg/uuid"
+	werror "github.com/palantir/witchcraft-go-error"
+	wparams "github.com/palantir/witchcraft-go-params"
+)
+
+// Code is a gRPC status code.
+type Code uint32
+
+// Code values are identical to those of google.golang.org/grpc/codes.
+const (
+	OK                 Code = 0
+	Canceled           Code = 1
+	Unknown            Code = 2
+	InvalidArgument    Code = 3
+	DeadlineExceeded   Code = 4
+	NotFound           Code = 5
+	AlreadyExists      Code = 6
+	PermissionDenied   Code = 7
+	ResourceExhausted  Code = 8
+	FailedPrecondition Code = 9
+	Aborted            Code = 10
+	OutOfRange         Code = 11
+	Unimplemented      Code = 12
+	Internal           Code = 13
+	Unavailable        Code = 14
+	DataLoss           Code = 15
+	Unauthenticated    Code = 16
+)
+
+// ErrorInfoDomain is the ErrorInfo domain of statuses created from Conjure errors.
+const ErrorInfoDomain = "conjure"
+
+// Metadata keys of ErrorInfo details created from Conjure errors. The Conjure error name is the ErrorInfo reason.
+const (
+	MetadataErrorCode       = "errorCode"
+	MetadataErrorInstanceID = "errorInstanceId"
+	MetadataParameters      = "parameters"
+)
+
+// Status mirrors the google.rpc.Status message. ErrorInfo is nil unless the status carries a google.rpc.ErrorInfo
+// detail.
+type Status struct {
+	Code      Code
+	Message   string
+	ErrorInfo *ErrorInfo
+}
+
+// ErrorInfo mirrors the google.rpc.ErrorInfo message.
+type ErrorInfo struct {
+	Reason   string
+	Domain   string
+	Metadata map[string]string
+}
+
+// CodeFromErrorCode returns the gRPC code closest to the Conjure error code.
+func CodeFromErrorCode(code errors.ErrorCode) Code {
+	switch code {
+	case errors.Unauthorized:
+		return Unauthenticated
+	case errors.PermissionDenied:
+		return PermissionDenied
+	case errors.InvalidArgument, errors.CustomClient:
+		return InvalidArgument
+	case errors.NotFound:
+		return NotFound
+	case errors.Conflict:
+		return AlreadyExists
+	case errors.RequestEntityTooLarge:
+		return ResourceExhausted
+	case errors.FailedPrecondition:
+		return FailedPrecondition
+	case errors.Timeout:
+		return DeadlineExceeded
+	}
+	return Internal
+}
+
+// ErrorTypeFromCode returns the default Conjure error type closest to the gRPC code. The second return value is
+// false for OK, which does not describe an error.
+func ErrorTypeFromCode(code Code) (errors.ErrorType, bool) {
+	switch code {
+	case OK:
+		return errors.ErrorType{}, false
+	case Unauthenticated:
+		return errors.DefaultUnauthorized, true
+	case PermissionDenied:
+		return errors.DefaultPermissionDenied, true
+	case InvalidArgument, OutOfRange:
+		return errors.DefaultInvalidArgument, true
+	case NotFound:
+		return errors.DefaultNotFound, true
+	case AlreadyExists, Aborted:
+		return errors.DefaultConflict, true
+	case ResourceExhausted:
+		return errors.DefaultRequestEntityTooLarge, true
+	case FailedPrecondition:
+		return errors.DefaultFailedPrecondition, true
+	case DeadlineExceeded:
+		return errors.DefaultTimeout, true
+	}
+	return errors.DefaultInternal, true
+}
+
+// FromError returns the status for a Conjure error. The status carries an ErrorInfo detail with the error's name,
+// code, instance ID and parameters, so that ToError can restore the original error. Parameters are serialized as a
+// JSON object regardless of whether they are safe or unsafe, as they are in Conjure error responses.
+func FromError(e errors.Error) (Status, error) {
+	serializableError, err := toSerializableError(e)
+	if err != nil {
+		return Status{}, err
+	}
+	metadata := map[string]string{
+		MetadataErrorCode:       serializableError.ErrorCode.String(),
+		MetadataErrorInstanceID: serializableError.ErrorInstanceID.String(),
+	}
+	if len(serializableError.Parameters) > 0 {
+		metadata[MetadataParameters] = string(serializableError.Parameters)
+	}
+	return Status{
+		Code:    CodeFromErrorCode(e.Code()),
+		Message: e.Error(),
+		ErrorInfo: &ErrorInfo{
+			Reason:   serializableError.ErrorName,
+			Domain:   ErrorInfoDomain,
+			Metadata: metadata,
+		},
+	}, nil
+}
+
+// ToError returns the Conjure error for a status. Statuses created by FromError are unmarshaled using the error
+// type registry, so registered error types are restored with their parameters. Other statuses are converted to the
+// default error type for their code with the status message as an unsafe "grpcMessage" parameter. ToError returns
+// nil for an OK status.
+func ToError(s Status) (errors.Error, error) {
+	if s.ErrorInfo != nil && s.ErrorInfo.Domain == ErrorInfoDomain {
+		return fromErrorInfo(*s.ErrorInfo)
+	}
+	errorType, ok := ErrorTypeFromCode(s.Code)
+	if !ok {
+		return nil, nil
+	}
+	return errors.NewError(errorType, wparams.NewUnsafeParamStorer(map[string]interface{}{"grpcMessage": s.Message})), nil
+}
+
+func fromErrorInfo(info ErrorInfo) (errors.Error, error) {
+	var errorCode errors.ErrorCode
+	if err := errorCode.UnmarshalText([]byte(info.Metadata[MetadataErrorCode])); err != nil {
+		return nil, werror.Wrap(err, "invalid conjure error code in gRPC status")
+	}
+	instanceID, err := uuid.ParseUUID(info.Metadata[MetadataErrorInstanceID])
+	if err != nil {
+		return nil, werror.Wrap(err, "invalid conjure error instance ID in gRPC status")
+	}
+	serializableError := errors.SerializableError{
+		ErrorCode:       errorCode,
+		ErrorName:       info.Reason,
+		ErrorInstanceID: instanceID,
+	}
+	if params := info.Metadata[MetadataParameters]; params != "" {
+		serializableError.Parameters = json.RawMessage(params)
+	}
+	body, err := codecs.JSON.Marshal(serializableError)
+	if err != nil {
+		return nil, werror.Wrap(err, "failed to marshal conjure error from gRPC status")
+	}
+	return errors.UnmarshalError(body)
+}
+
+// toSerializableError marshals e as it would be written by errors.WriteErrorResponse.
+func toSerializableError(e errors.Error) (errors.SerializableError, error) {
+	var body []byte
+	var err error
+	if marshaler, ok := e.(json.Marshaler); ok {
+		body, err = codecs.JSON.Marshal(marshaler)
+	}
+	if body == nil || err != nil {
+		params := make(map[string]interface{})
+		for k, v := range e.UnsafeParams() {
+			params[k] = v
+		}
+		for k, v := range e.SafeParams() {
+			params[k] = v
+		}
+		paramsJSON, err := codecs.JSON.Marshal(params)
+		if err != nil {
+			return errors.SerializableError{}, werror.Wrap(err, "failed to marshal conjure error parameters")
+		}
+		return errors.SerializableError{
+			ErrorCode:       e.Code(),
+			ErrorName:       e.Name(),
+			ErrorInstanceID: e.InstanceID(),
+			Parameters:      paramsJSON,
+		}, nil
+	}
+	var serializableError errors.SerializableError
+	if err := codecs.JSON.Unmarshal(body, &serializableError); err != nil {
+		return errors.SerializableError{}, werror.Wrap(err, "failed to unmarshal conjure error")
+	}
+	return serializableError, nil
+}
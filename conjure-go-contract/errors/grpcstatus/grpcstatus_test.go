@@ -0,0 +1,98 @@
+// Copyright (c) 2026 Palantir Technologies. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpcstatus_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/palantir/conjure-go-runtime/v2/conjure-go-contract/errors"
+	"github.com/palantir/conjure-go-runtime/v2/conjure-go-contract/errors/grpcstatus"
+	wparams "github.com/palantir/witchcraft-go-params"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRoundTrip(t *testing.T) {
+	errorType, err := errors.NewErrorType(errors.Conflict, "Facebook:LikeAlreadyGiven")
+	require.NoError(t, err)
+	original := errors.NewError(errorType,
+		wparams.NewSafeParamStorer(map[string]interface{}{"postId": "5aa734gs3579"}),
+		wparams.NewUnsafeParamStorer(map[string]interface{}{"userId": 642764872364}))
+
+	status, err := grpcstatus.FromError(original)
+	require.NoError(t, err)
+	assert.Equal(t, grpcstatus.AlreadyExists, status.Code)
+	assert.Equal(t, original.Error(), status.Message)
+	require.NotNil(t, status.ErrorInfo)
+	assert.Equal(t, grpcstatus.ErrorInfo{
+		Reason: "Facebook:LikeAlreadyGiven",
+		Domain: grpcstatus.ErrorInfoDomain,
+		Metadata: map[string]string{
+			grpcstatus.MetadataErrorCode:       "CONFLICT",
+			grpcstatus.MetadataErrorInstanceID: original.InstanceID().String(),
+			grpcstatus.MetadataParameters:      `{"postId":"5aa734gs3579","userId":642764872364}`,
+		},
+	}, *status.ErrorInfo)
+
+	restored, err := grpcstatus.ToError(status)
+	require.NoError(t, err)
+	assert.Equal(t, errors.Conflict, restored.Code())
+	assert.Equal(t, "Facebook:LikeAlreadyGiven", restored.Name())
+	assert.Equal(t, original.InstanceID(), restored.InstanceID())
+	assert.Equal(t, map[string]interface{}{"postId": "5aa734gs3579", "userId": json.Number("642764872364")}, restored.UnsafeParams())
+}
+
+func TestToErrorNativeStatus(t *testing.T) {
+	restored, err := grpcstatus.ToError(grpcstatus.Status{Code: grpcstatus.Unavailable, Message: "connection refused"})
+	require.NoError(t, err)
+	assert.Equal(t, errors.Internal, restored.Code())
+	assert.Equal(t, "Default:Internal", restored.Name())
+	assert.Equal(t, map[string]interface{}{"grpcMessage": "connection refused"}, restored.UnsafeParams())
+
+	restored, err = grpcstatus.ToError(grpcstatus.Status{Code: grpcstatus.OK})
+	require.NoError(t, err)
+	assert.Nil(t, restored)
+
+	_, err = grpcstatus.ToError(grpcstatus.Status{
+		Code:      grpcstatus.NotFound,
+		ErrorInfo: &grpcstatus.ErrorInfo{Reason: "Default:NotFound", Domain: grpcstatus.ErrorInfoDomain},
+	})
+	assert.EqualError(t, err, "invalid conjure error code in gRPC status: errors: unknown error code string")
+}
+
+func TestCodeMapping(t *testing.T) {
+	for _, tc := range []struct {
+		errorCode errors.ErrorCode
+		code      grpcstatus.Code
+	}{
+		{errors.Unauthorized, grpcstatus.Unauthenticated},
+		{errors.PermissionDenied, grpcstatus.PermissionDenied},
+		{errors.InvalidArgument, grpcstatus.InvalidArgument},
+		{errors.NotFound, grpcstatus.NotFound},
+		{errors.Conflict, grpcstatus.AlreadyExists},
+		{errors.RequestEntityTooLarge, grpcstatus.ResourceExhausted},
+		{errors.FailedPrecondition, grpcstatus.FailedPrecondition},
+		{errors.Internal, grpcstatus.Internal},
+		{errors.Timeout, grpcstatus.DeadlineExceeded},
+	} {
+		t.Run(tc.errorCode.String(), func(t *testing.T) {
+			assert.Equal(t, tc.code, grpcstatus.CodeFromErrorCode(tc.errorCode))
+			errorType, ok := grpcstatus.ErrorTypeFromCode(tc.code)
+			require.True(t, ok)
+			assert.Equal(t, tc.errorCode, errorType.Code())
+		})
+	}
+}
@@ -19,12 +19,20 @@ import (
 	"net/http"
 
 	"github.com/palantir/conjure-go-runtime/v2/conjure-go-contract/codecs"
+	"github.com/palantir/conjure-go-runtime/v2/conjure-go-contract/httpheaders"
 )
 
 // WriteErrorResponse writes error to the response writer.
 //
 // TODO This function is subject to change.
 func WriteErrorResponse(w http.ResponseWriter, e Error) {
+	w.Header().Set(httpheaders.ContentType, "application/json; charset=utf-8")
+	w.WriteHeader(e.Code().StatusCode())
+	_, _ = w.Write(marshalError(e)) // There is nothing we can do on write failure.
+}
+
+// marshalError returns the JSON encoding of e written by WriteErrorResponse.
+func marshalError(e Error) []byte {
 	var marshaledError []byte
 	var err error
 
@@ -47,8 +55,5 @@ func WriteErrorResponse(w http.ResponseWriter, e Error) {
 			Parameters:      params,
 		})
 	}
-
-	w.Header().Set("Content-Type", "application/json; charset=utf-8")
-	w.WriteHeader(e.Code().StatusCode())
-	_, _ = w.Write(marshaledError) // There is nothing we can do on write failure.
+	return marshaledError
 }
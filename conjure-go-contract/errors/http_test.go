@@ -61,3 +61,34 @@ func TestWriteErrorResponse_ValidateJSON(t *testing.T) {
 	require.NoError(t, json.Indent(&buffer, body, "", "  "))
 	assert.Equal(t, testErrorJSON, buffer.String())
 }
+
+func TestWriteProblemResponse(t *testing.T) {
+	testError := errors.NewError(errors.MustErrorType(errors.Timeout, "MyApplication:Timeout"),
+		wparams.NewSafeParamStorer(map[string]interface{}{"key": "value"}))
+
+	testErrorJSON := fmt.Sprintf(`{
+  "type": "about:blank",
+  "title": "Internal Server Error",
+  "status": 500,
+  "instance": "urn:uuid:%s",
+  "errorCode": "TIMEOUT",
+  "errorName": "MyApplication:Timeout",
+  "errorInstanceId": "%s",
+  "parameters": {
+    "key": "value"
+  }
+}`, testError.InstanceID(), testError.InstanceID())
+
+	recorder := httptest.NewRecorder()
+	errors.WriteProblemResponse(recorder, testError)
+	response := recorder.Result()
+
+	assert.Equal(t, 500, response.StatusCode)
+	assert.Equal(t, "application/problem+json", response.Header.Get("Content-Type"))
+	body, err := ioutil.ReadAll(response.Body)
+	require.NoError(t, err)
+
+	var buffer bytes.Buffer
+	require.NoError(t, json.Indent(&buffer, bytes.TrimSpace(body), "", "  "))
+	assert.Equal(t, testErrorJSON, buffer.String())
+}
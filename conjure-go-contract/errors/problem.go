@@ -0,0 +1,62 @@
+// Copyright (c) 2026 Palantir Technologies. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import (
+	"net/http"
+
+	"github.com/palantir/conjure-go-runtime/v2/conjure-go-contract/codecs"
+	"github.com/palantir/conjure-go-runtime/v2/conjure-go-contract/httpheaders"
+)
+
+// ProblemContentType is the media type of RFC 7807 problem details written by WriteProblemResponse.
+const ProblemContentType = "application/problem+json"
+
+// ProblemDetails is an RFC 7807 problem details object describing a conjure error. The standard members describe
+// the HTTP status of the error, and the members of the SerializableError written by WriteErrorResponse are included
+// as extension members, so that consumers which understand conjure errors can still decode them.
+type ProblemDetails struct {
+	// Type is always "about:blank", as conjure errors are identified by their ErrorName.
+	Type string `json:"type"`
+	// Title is the reason phrase of the HTTP status code.
+	Title  string `json:"title"`
+	Status int    `json:"status"`
+	// Instance is a URN identifying the occurrence of the error, "urn:uuid:" followed by the ErrorInstanceID.
+	Instance string `json:"instance"`
+	SerializableError
+}
+
+// WriteProblemResponse writes e to the response writer as RFC 7807 problem details with the ProblemContentType
+// Content-Type. It is intended for consumers which do not understand conjure errors; conjure clients expect the
+// format written by WriteErrorResponse.
+func WriteProblemResponse(w http.ResponseWriter, e Error) {
+	var serializable SerializableError
+	if err := codecs.JSON.Unmarshal(marshalError(e), &serializable); err != nil {
+		// Custom marshalers should produce a SerializableError, but fall back to the error's own fields if not.
+		serializable = SerializableError{ErrorCode: e.Code(), ErrorName: e.Name(), ErrorInstanceID: e.InstanceID()}
+	}
+	status := e.Code().StatusCode()
+	// This should never fail, since the parameters were already marshaled above.
+	body, _ := codecs.JSON.Marshal(ProblemDetails{
+		Type:              "about:blank",
+		Title:             http.StatusText(status),
+		Status:            status,
+		Instance:          "urn:uuid:" + serializable.ErrorInstanceID.String(),
+		SerializableError: serializable,
+	})
+	w.Header().Set(httpheaders.ContentType, ProblemContentType)
+	w.WriteHeader(status)
+	_, _ = w.Write(body) // There is nothing we can do on write failure.
+}
@@ -0,0 +1,188 @@
+// Copyright (c) 2026 Palantir Technologies. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package httpheaders defines the names of the HTTP headers used by Conjure clients and servers, along with helpers
+// to parse and format their values.
+package httpheaders
+
+import (
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Canonical names of the headers read or written by Conjure clients and servers.
+const (
+	Accept             = "Accept"
+	AcceptEncoding     = "Accept-Encoding"
+	Age                = "Age"
+	Authorization      = "Authorization"
+	BackoffMillis      = "X-Backoff-Millis"
+	CacheControl       = "Cache-Control"
+	ContentDisposition = "Content-Disposition"
+	ContentEncoding    = "Content-Encoding"
+	ContentLength      = "Content-Length"
+	ContentType        = "Content-Type"
+	Date               = "Date"
+	DeadlineMillis     = "X-Deadline-Millis"
+	Deprecation        = "Deprecation"
+	ETag               = "Etag"
+	IfModifiedSince    = "If-Modified-Since"
+	IfNoneMatch        = "If-None-Match"
+	LastModified       = "Last-Modified"
+	Location           = "Location"
+	Range              = "Range"
+	RateLimitRemaining = "X-Ratelimit-Remaining"
+	RateLimitReset     = "X-Ratelimit-Reset"
+	RetryAfter         = "Retry-After"
+	Vary               = "Vary"
+)
+
+// ParseRetryAfter parses a Retry-After header value, which is either a number of seconds or an HTTP date, and
+// returns the delay relative to now. Dates in the past return a delay of 0. It returns false if value is empty or
+// can not be parsed.
+func ParseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	date, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+	if delay := date.Sub(now); delay > 0 {
+		return delay, true
+	}
+	return 0, true
+}
+
+// FormatRetryAfter formats delay as a Retry-After header value in whole seconds, rounding up so that clients never
+// retry early. Negative delays are formatted as 0.
+func FormatRetryAfter(delay time.Duration) string {
+	if delay <= 0 {
+		return "0"
+	}
+	seconds := (delay + time.Second - 1) / time.Second
+	return strconv.FormatInt(int64(seconds), 10)
+}
+
+// ParseDeadlineMillis parses an X-Deadline-Millis header value, which is the number of milliseconds remaining until the
+// caller's deadline when the request was sent. It returns false if value is empty, negative or can not be parsed.
+func ParseDeadlineMillis(value string) (time.Duration, bool) {
+	millis, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+	if err != nil || millis < 0 {
+		return 0, false
+	}
+	return time.Duration(millis) * time.Millisecond, true
+}
+
+// FormatDeadlineMillis formats the time remaining until a deadline as an X-Deadline-Millis header value in whole
+// milliseconds, rounding down so that servers never work past the caller's deadline. Negative durations are formatted
+// as 0.
+func FormatDeadlineMillis(remaining time.Duration) string {
+	if remaining <= 0 {
+		return "0"
+	}
+	return strconv.FormatInt(int64(remaining/time.Millisecond), 10)
+}
+
+// ParseLocation parses a Location header value. Relative references are resolved against base if it is non-nil.
+// It returns false if value is empty or is not a valid URI reference.
+func ParseLocation(value string, base *url.URL) (*url.URL, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, false
+	}
+	location, err := url.Parse(value)
+	if err != nil {
+		return nil, false
+	}
+	if base != nil {
+		location = base.ResolveReference(location)
+	}
+	return location, true
+}
+
+// ParseDeprecation parses a Deprecation header value. RFC 9745 values are a structured date, e.g. "@1688169599",
+// but earlier drafts used "true" or an HTTP date, so both are accepted. It returns whether the resource is
+// deprecated and, if known, when it was or will be deprecated.
+func ParseDeprecation(value string) (deprecated bool, at time.Time) {
+	value = strings.TrimSpace(value)
+	switch {
+	case value == "":
+		return false, time.Time{}
+	case strings.EqualFold(value, "true"):
+		return true, time.Time{}
+	case strings.EqualFold(value, "false"):
+		return false, time.Time{}
+	case strings.HasPrefix(value, "@"):
+		seconds, err := strconv.ParseInt(value[1:], 10, 64)
+		if err != nil {
+			return true, time.Time{}
+		}
+		return true, time.Unix(seconds, 0).UTC()
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return true, date
+	}
+	// An unrecognized value still signals deprecation.
+	return true, time.Time{}
+}
+
+// FormatDeprecation formats at as an RFC 9745 Deprecation header value. The zero time is formatted as "true".
+func FormatDeprecation(at time.Time) string {
+	if at.IsZero() {
+		return "true"
+	}
+	return "@" + strconv.FormatInt(at.Unix(), 10)
+}
+
+// MediaType returns the lower-cased media type of a Content-Type or Accept value without its parameters, e.g.
+// "application/json" for "Application/JSON; charset=utf-8". Unlike ParseContentType, it does not fail on malformed
+// parameters.
+func MediaType(value string) string {
+	mediaType, _, _ := strings.Cut(value, ";")
+	return strings.ToLower(strings.TrimSpace(mediaType))
+}
+
+// ParseContentType parses a Content-Type header value into its lower-cased media type and parameters.
+func ParseContentType(value string) (mediaType string, params map[string]string, err error) {
+	return mime.ParseMediaType(value)
+}
+
+// FormatContentType formats a media type and optional parameters as a Content-Type header value, e.g.
+// FormatContentType("application/json", map[string]string{"charset": "utf-8"}) returns
+// "application/json; charset=utf-8". It returns the empty string if mediaType or a parameter is invalid.
+func FormatContentType(mediaType string, params map[string]string) string {
+	return mime.FormatMediaType(mediaType, params)
+}
+
+// ContentTypeCharset returns the charset parameter of a Content-Type header value, or the empty string if it has
+// none.
+func ContentTypeCharset(value string) string {
+	_, params, err := ParseContentType(value)
+	if err != nil {
+		return ""
+	}
+	return params["charset"]
+}
@@ -0,0 +1,119 @@
+// Copyright (c) 2026 Palantir Technologies. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpheaders_test
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/palantir/conjure-go-runtime/v2/conjure-go-contract/httpheaders"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	for _, test := range []struct {
+		Name     string
+		Value    string
+		Expected time.Duration
+		OK       bool
+	}{
+		{Name: "seconds", Value: "120", Expected: 2 * time.Minute, OK: true},
+		{Name: "date", Value: now.Add(time.Minute).Format(http.TimeFormat), Expected: time.Minute, OK: true},
+		{Name: "past date", Value: now.Add(-time.Minute).Format(http.TimeFormat), Expected: 0, OK: true},
+		{Name: "empty", Value: ""},
+		{Name: "negative", Value: "-1"},
+		{Name: "invalid", Value: "soon"},
+	} {
+		t.Run(test.Name, func(t *testing.T) {
+			actual, ok := httpheaders.ParseRetryAfter(test.Value, now)
+			assert.Equal(t, test.OK, ok)
+			assert.Equal(t, test.Expected, actual)
+		})
+	}
+	assert.Equal(t, "0", httpheaders.FormatRetryAfter(-time.Second))
+	assert.Equal(t, "2", httpheaders.FormatRetryAfter(1500*time.Millisecond))
+	assert.Equal(t, "60", httpheaders.FormatRetryAfter(time.Minute))
+}
+
+func TestDeadlineMillis(t *testing.T) {
+	deadline, ok := httpheaders.ParseDeadlineMillis(" 1500 ")
+	require.True(t, ok)
+	assert.Equal(t, 1500*time.Millisecond, deadline)
+	deadline, ok = httpheaders.ParseDeadlineMillis("0")
+	require.True(t, ok)
+	assert.Equal(t, time.Duration(0), deadline)
+	for _, value := range []string{"", "-1", "soon"} {
+		_, ok = httpheaders.ParseDeadlineMillis(value)
+		assert.False(t, ok, value)
+	}
+	assert.Equal(t, "0", httpheaders.FormatDeadlineMillis(-time.Second))
+	assert.Equal(t, "1", httpheaders.FormatDeadlineMillis(1999*time.Microsecond))
+	assert.Equal(t, "60000", httpheaders.FormatDeadlineMillis(time.Minute))
+}
+
+func TestParseLocation(t *testing.T) {
+	location, ok := httpheaders.ParseLocation("https://host-b/api", nil)
+	require.True(t, ok)
+	assert.Equal(t, "https://host-b/api", location.String())
+
+	base, err := url.Parse("https://host-a/api/resource")
+	require.NoError(t, err)
+	location, ok = httpheaders.ParseLocation("/other", base)
+	require.True(t, ok)
+	assert.Equal(t, "https://host-a/other", location.String())
+
+	_, ok = httpheaders.ParseLocation("", nil)
+	assert.False(t, ok)
+	_, ok = httpheaders.ParseLocation("http://[::1", nil)
+	assert.False(t, ok)
+}
+
+func TestDeprecation(t *testing.T) {
+	at := time.Date(2023, 6, 30, 23, 59, 59, 0, time.UTC)
+	assert.Equal(t, "@1688169599", httpheaders.FormatDeprecation(at))
+	assert.Equal(t, "true", httpheaders.FormatDeprecation(time.Time{}))
+
+	deprecated, actual := httpheaders.ParseDeprecation("@1688169599")
+	assert.True(t, deprecated)
+	assert.Equal(t, at, actual)
+
+	deprecated, actual = httpheaders.ParseDeprecation(at.Format(http.TimeFormat))
+	assert.True(t, deprecated)
+	assert.Equal(t, at, actual)
+
+	deprecated, actual = httpheaders.ParseDeprecation("true")
+	assert.True(t, deprecated)
+	assert.True(t, actual.IsZero())
+
+	deprecated, _ = httpheaders.ParseDeprecation("")
+	assert.False(t, deprecated)
+}
+
+func TestContentType(t *testing.T) {
+	assert.Equal(t, "application/json", httpheaders.MediaType("Application/JSON; charset=utf-8"))
+	assert.Equal(t, "application/json", httpheaders.MediaType("application/json; charset"))
+	assert.Equal(t, "utf-8", httpheaders.ContentTypeCharset("application/json; charset=utf-8"))
+	assert.Equal(t, "", httpheaders.ContentTypeCharset("application/json"))
+	assert.Equal(t, "application/json; charset=utf-8", httpheaders.FormatContentType("application/json", map[string]string{"charset": "utf-8"}))
+
+	mediaType, params, err := httpheaders.ParseContentType(`text/plain; charset="us-ascii"`)
+	require.NoError(t, err)
+	assert.Equal(t, "text/plain", mediaType)
+	assert.Equal(t, map[string]string{"charset": "us-ascii"}, params)
+}
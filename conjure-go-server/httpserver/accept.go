@@ -0,0 +1,74 @@
+// Copyright (c) 2026 Palantir Technologies. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpserver
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/palantir/conjure-go-runtime/v2/conjure-go-contract/errors"
+	"github.com/palantir/conjure-go-runtime/v2/conjure-go-contract/httpheaders"
+)
+
+// prefersProblemJSON returns true if an Accept header value gives application/problem+json a higher quality than
+// application/json. Each media type takes the quality of the most specific range matching it.
+func prefersProblemJSON(accept string) bool {
+	if accept == "" {
+		return false
+	}
+	return acceptQuality(accept, errors.ProblemContentType) > acceptQuality(accept, "application/json")
+}
+
+// acceptQuality returns the quality given to mediaType by the most specific matching range in an Accept header value,
+// or 0 if no range matches.
+func acceptQuality(accept, mediaType string) float64 {
+	mainType, _, _ := strings.Cut(mediaType, "/")
+	quality, specificity := 0.0, -1
+	for _, accepted := range strings.Split(accept, ",") {
+		rangeType := httpheaders.MediaType(accepted)
+		var rangeSpecificity int
+		switch rangeType {
+		case mediaType:
+			rangeSpecificity = 2
+		case mainType + "/*":
+			rangeSpecificity = 1
+		case "*/*":
+			rangeSpecificity = 0
+		default:
+			continue
+		}
+		if rangeSpecificity > specificity {
+			quality, specificity = acceptRangeQuality(accepted), rangeSpecificity
+		}
+	}
+	return quality
+}
+
+// acceptRangeQuality returns the q parameter of a single Accept range, defaulting to 1.
+func acceptRangeQuality(accepted string) float64 {
+	_, params, _ := strings.Cut(accepted, ";")
+	for _, param := range strings.Split(params, ";") {
+		name, value, ok := strings.Cut(strings.TrimSpace(param), "=")
+		if !ok || !strings.EqualFold(strings.TrimSpace(name), "q") {
+			continue
+		}
+		q, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil || q < 0 {
+			return 0
+		}
+		return q
+	}
+	return 1
+}
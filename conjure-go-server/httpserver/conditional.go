@@ -0,0 +1,113 @@
+// Copyright (c) 2026 Palantir Technologies. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpserver
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/palantir/conjure-go-runtime/v2/conjure-go-contract/httpheaders"
+	"github.com/palantir/pkg/safejson"
+)
+
+// ETag returns a strong entity tag for content: a quoted, truncated SHA-256 digest of its bytes, suitable as the value
+// of an ETag header.
+func ETag(content []byte) string {
+	sum := sha256.Sum256(content)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// JSONETag returns the entity tag of the JSON encoding of obj, as written by WriteJSONResponse.
+func JSONETag(obj interface{}) (string, error) {
+	var buf bytes.Buffer
+	if err := safejson.Encoder(&buf).Encode(obj); err != nil {
+		return "", err
+	}
+	return ETag(buf.Bytes()), nil
+}
+
+// CheckNotModified sets the ETag and Last-Modified headers of the response to etag and lastModified, omitting empty
+// and zero values, and evaluates the If-None-Match and If-Modified-Since headers of a GET or HEAD request against
+// them. If the representation the caller has is current, it writes a 304 Not Modified response and returns true, in
+// which case the handler must not write a body. As specified by RFC 9110, If-Modified-Since is ignored when the
+// request has an If-None-Match header.
+func CheckNotModified(w http.ResponseWriter, r *http.Request, etag string, lastModified time.Time) bool {
+	if etag != "" {
+		w.Header().Set(httpheaders.ETag, etag)
+	}
+	if !lastModified.IsZero() {
+		w.Header().Set(httpheaders.LastModified, lastModified.UTC().Format(http.TimeFormat))
+	}
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false
+	}
+	if !notModified(r.Header, etag, lastModified) {
+		return false
+	}
+	w.WriteHeader(http.StatusNotModified)
+	return true
+}
+
+// WriteJSONResponseWithETag behaves like WriteJSONResponse, additionally setting an ETag header computed from the JSON
+// encoding of obj. If status is 200 and the If-None-Match header of a GET or HEAD request matches the tag, a 304 Not
+// Modified response is written instead, without a body.
+func WriteJSONResponseWithETag(w http.ResponseWriter, r *http.Request, obj interface{}, status int) {
+	var buf bytes.Buffer
+	if err := safejson.Encoder(&buf).Encode(obj); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if status == http.StatusOK {
+		if CheckNotModified(w, r, ETag(buf.Bytes()), time.Time{}) {
+			return
+		}
+	}
+	w.Header().Set(httpheaders.ContentType, "application/json")
+	w.WriteHeader(status)
+	_, _ = w.Write(buf.Bytes())
+}
+
+// notModified returns true if the conditional headers in header show the caller's representation is current.
+func notModified(header http.Header, etag string, lastModified time.Time) bool {
+	if ifNoneMatch := header.Get(httpheaders.IfNoneMatch); ifNoneMatch != "" {
+		return etag != "" && etagListMatches(ifNoneMatch, etag)
+	}
+	if lastModified.IsZero() {
+		return false
+	}
+	since, err := http.ParseTime(header.Get(httpheaders.IfModifiedSince))
+	if err != nil {
+		return false
+	}
+	// HTTP dates have a resolution of one second
+	return !lastModified.Truncate(time.Second).After(since)
+}
+
+// etagListMatches returns true if an If-None-Match header value is "*" or lists a tag which weakly matches etag.
+func etagListMatches(list, etag string) bool {
+	if strings.TrimSpace(list) == "*" {
+		return true
+	}
+	for _, candidate := range strings.Split(list, ",") {
+		if strings.TrimPrefix(strings.TrimSpace(candidate), "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,96 @@
+// Copyright (c) 2026 Palantir Technologies. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpserver_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/palantir/conjure-go-runtime/v2/conjure-go-server/httpserver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteJSONResponseWithETag(t *testing.T) {
+	obj := map[string]string{"key": "value"}
+	etag, err := httpserver.JSONETag(obj)
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		name        string
+		method      string
+		ifNoneMatch string
+		status      int
+		wantStatus  int
+	}{
+		{name: "no condition", method: http.MethodGet, status: http.StatusOK, wantStatus: http.StatusOK},
+		{name: "matching tag", method: http.MethodGet, ifNoneMatch: etag, status: http.StatusOK, wantStatus: http.StatusNotModified},
+		{name: "weak matching tag in list", method: http.MethodGet, ifNoneMatch: `"other", W/` + etag, status: http.StatusOK, wantStatus: http.StatusNotModified},
+		{name: "wildcard", method: http.MethodHead, ifNoneMatch: "*", status: http.StatusOK, wantStatus: http.StatusNotModified},
+		{name: "different tag", method: http.MethodGet, ifNoneMatch: `"other"`, status: http.StatusOK, wantStatus: http.StatusOK},
+		{name: "unsafe method", method: http.MethodPost, ifNoneMatch: etag, status: http.StatusOK, wantStatus: http.StatusOK},
+		{name: "non-200 status", method: http.MethodGet, ifNoneMatch: etag, status: http.StatusAccepted, wantStatus: http.StatusAccepted},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, "/", nil)
+			if tc.ifNoneMatch != "" {
+				req.Header.Set("If-None-Match", tc.ifNoneMatch)
+			}
+			rec := httptest.NewRecorder()
+			httpserver.WriteJSONResponseWithETag(rec, req, obj, tc.status)
+			assert.Equal(t, tc.wantStatus, rec.Code)
+			if tc.wantStatus == http.StatusNotModified {
+				assert.Equal(t, etag, rec.Header().Get("ETag"))
+				assert.Empty(t, rec.Body.String())
+			} else {
+				assert.JSONEq(t, `{"key":"value"}`, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestCheckNotModified_LastModified(t *testing.T) {
+	lastModified := time.Date(2026, 1, 2, 3, 4, 5, 600, time.UTC)
+	for _, tc := range []struct {
+		name        string
+		header      map[string]string
+		notModified bool
+	}{
+		{name: "no condition"},
+		{name: "modified since", header: map[string]string{"If-Modified-Since": "Fri, 02 Jan 2026 03:04:04 GMT"}},
+		{name: "not modified since", header: map[string]string{"If-Modified-Since": "Fri, 02 Jan 2026 03:04:05 GMT"}, notModified: true},
+		{name: "invalid date", header: map[string]string{"If-Modified-Since": "yesterday"}},
+		{name: "If-None-Match takes precedence", header: map[string]string{
+			"If-Modified-Since": "Fri, 02 Jan 2026 03:04:05 GMT",
+			"If-None-Match":     `"other"`,
+		}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			for k, v := range tc.header {
+				req.Header.Set(k, v)
+			}
+			rec := httptest.NewRecorder()
+			assert.Equal(t, tc.notModified, httpserver.CheckNotModified(rec, req, `"tag"`, lastModified))
+			assert.Equal(t, "Fri, 02 Jan 2026 03:04:05 GMT", rec.Header().Get("Last-Modified"))
+			assert.Equal(t, `"tag"`, rec.Header().Get("ETag"))
+			if tc.notModified {
+				assert.Equal(t, http.StatusNotModified, rec.Code)
+			}
+		})
+	}
+}
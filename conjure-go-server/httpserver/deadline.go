@@ -0,0 +1,78 @@
+// Copyright (c) 2026 Palantir Technologies. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpserver
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/palantir/conjure-go-runtime/v2/conjure-go-contract/errors"
+	"github.com/palantir/conjure-go-runtime/v2/conjure-go-contract/httpheaders"
+)
+
+// DeadlineHandler returns a http.Handler which applies the deadline sent by the caller in the X-Deadline-Millis header,
+// e.g. by clients using httpclient.WithDeadlinePropagation, to the context of the request before calling next.
+// Requests whose deadline has already passed are rejected with a Conjure Timeout error without calling next, and if
+// next returns without writing a response after the deadline has passed, a Timeout error is written. Requests without
+// a valid header are passed to next unchanged.
+func DeadlineHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		remaining, ok := httpheaders.ParseDeadlineMillis(r.Header.Get(httpheaders.DeadlineMillis))
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if remaining == 0 {
+			errors.WriteErrorResponse(w, errors.NewTimeout())
+			return
+		}
+		ctx, cancel := context.WithTimeout(r.Context(), remaining)
+		defer cancel()
+		tw := &writeTrackingResponseWriter{ResponseWriter: w}
+		next.ServeHTTP(tw, r.WithContext(ctx))
+		if !tw.written && ctx.Err() == context.DeadlineExceeded {
+			errors.WriteErrorResponse(w, errors.NewTimeout())
+		}
+	})
+}
+
+// writeTrackingResponseWriter records whether a response has been written.
+type writeTrackingResponseWriter struct {
+	http.ResponseWriter
+	written bool
+}
+
+func (w *writeTrackingResponseWriter) WriteHeader(statusCode int) {
+	w.written = true
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (w *writeTrackingResponseWriter) Write(p []byte) (int, error) {
+	w.written = true
+	return w.ResponseWriter.Write(p)
+}
+
+// Written returns whether the response has been written. It implements the interface used by NewJSONHandler to
+// detect errors returned after the response was written.
+func (w *writeTrackingResponseWriter) Written() bool {
+	return w.written
+}
+
+func (w *writeTrackingResponseWriter) Flush() {
+	w.written = true
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
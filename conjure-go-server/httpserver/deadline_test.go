@@ -0,0 +1,79 @@
+// Copyright (c) 2026 Palantir Technologies. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpserver_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/palantir/conjure-go-runtime/v2/conjure-go-client/httpclient"
+	"github.com/palantir/conjure-go-runtime/v2/conjure-go-contract/errors"
+	"github.com/palantir/conjure-go-runtime/v2/conjure-go-server/httpserver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeadlineHandler(t *testing.T) {
+	// the handler records what it observes so that assertions are made on the test goroutine.
+	var hasDeadline atomic.Bool
+	var remaining atomic.Int64
+	server := httptest.NewServer(httpserver.DeadlineHandler(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		deadline, ok := req.Context().Deadline()
+		hasDeadline.Store(ok)
+		remaining.Store(int64(time.Until(deadline)))
+		if req.URL.Path == "/slow" {
+			<-req.Context().Done()
+		}
+	})))
+	defer server.Close()
+
+	client, err := httpclient.NewClient(
+		httpclient.WithBaseURLs([]string{server.URL}),
+		httpclient.WithMaxRetries(0),
+		httpclient.WithDeadlinePropagation())
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	_, err = client.Get(ctx)
+	require.NoError(t, err)
+	require.True(t, hasDeadline.Load(), "handler context should have a deadline")
+	assert.Greater(t, time.Duration(remaining.Load()), 50*time.Second)
+	assert.LessOrEqual(t, time.Duration(remaining.Load()), time.Minute)
+
+	req, err := http.NewRequest(http.MethodGet, server.URL+"/slow", nil)
+	require.NoError(t, err)
+	req.Header.Set("X-Deadline-Millis", "50")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer func() { _ = resp.Body.Close() }()
+	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	conjureErr, err := errors.UnmarshalError(body)
+	require.NoError(t, err)
+	assert.True(t, errors.IsTimeout(conjureErr))
+
+	req.Header.Set("X-Deadline-Millis", "0")
+	resp, err = http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer func() { _ = resp.Body.Close() }()
+	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+}
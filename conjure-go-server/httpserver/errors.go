@@ -14,7 +14,54 @@
 
 package httpserver
 
+import (
+	"context"
+
+	"github.com/palantir/conjure-go-runtime/v2/conjure-go-contract/errors"
+	werror "github.com/palantir/witchcraft-go-error"
+	"github.com/palantir/witchcraft-go-logging/wlog/svclog/svc1log"
+)
+
 const (
 	// legacyHTTPStatusCodeParamKey is the legacy parameter set by github.com/palantir/witchcraft-go-server/rest.NewError
 	legacyHTTPStatusCodeParamKey = "httpStatusCode"
 )
+
+// WithLegacyStatusCode returns err wrapped with the status code parameter set by the deprecated
+// witchcraft-go-server/rest package, which StatusCodeMapper uses as the response status for errors that are not
+// conjure errors. It exists so that services migrating from that package can keep their status codes while moving
+// to conjure errors; new code should return conjure errors instead.
+//
+// If err already carries a legacy status code, that code takes precedence over the one set here.
+// WithLegacyStatusCode returns nil if err is nil.
+func WithLegacyStatusCode(err error, statusCode int) error {
+	if err == nil {
+		return nil
+	}
+	return werror.Wrap(err, "", werror.SafeParam(legacyHTTPStatusCodeParamKey, statusCode))
+}
+
+// LegacyStatusCode returns the status code set on err by WithLegacyStatusCode or the deprecated
+// witchcraft-go-server/rest package. It returns false if err does not carry one.
+func LegacyStatusCode(err error) (int, bool) {
+	statusCode := legacyErrorCode(err)
+	return statusCode, statusCode != 0
+}
+
+// LegacyStatusCodeDeprecationHandler returns an ErrorHandler which logs a warning for each error whose status code
+// was taken from the legacy status code parameter, then calls next if it is non-nil. Services migrating from
+// witchcraft-go-server/rest can use it to find the errors which still rely on the legacy parameter.
+func LegacyStatusCodeDeprecationHandler(next ErrorHandler) ErrorHandler {
+	return func(ctx context.Context, statusCode int, err error) {
+		if errors.GetConjureError(err) == nil {
+			if legacyCode, ok := LegacyStatusCode(err); ok && legacyCode == statusCode {
+				svc1log.FromContext(ctx).Warn("Error response status set by deprecated legacy status code parameter. Return a conjure error instead.",
+					svc1log.SafeParam("statusCode", statusCode),
+					svc1log.Stacktrace(err))
+			}
+		}
+		if next != nil {
+			next(ctx, statusCode, err)
+		}
+	}
+}
@@ -15,11 +15,14 @@
 package httpserver
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"net/http"
 
 	"github.com/palantir/conjure-go-runtime/v2/conjure-go-contract/errors"
+	"github.com/palantir/conjure-go-runtime/v2/conjure-go-contract/httpheaders"
+	"github.com/palantir/pkg/safejson"
 	werror "github.com/palantir/witchcraft-go-error"
 	"github.com/palantir/witchcraft-go-logging/wlog/svclog/svc1log"
 )
@@ -31,6 +34,41 @@ type handler struct {
 	handleFn func(http.ResponseWriter, *http.Request) error
 	statusFn StatusMapper
 	errorFn  ErrorHandler
+	// maxBufferedErrorBytes is set by WithBufferedErrorEncoding. 0 disables buffering.
+	maxBufferedErrorBytes int
+	// problemErrors is set by WithProblemErrorEncoding.
+	problemErrors bool
+}
+
+// JSONHandlerParam configures a handler returned by NewJSONHandler.
+type JSONHandlerParam interface {
+	apply(*handler)
+}
+
+type jsonHandlerParamFunc func(*handler)
+
+func (f jsonHandlerParamFunc) apply(h *handler) {
+	f(h)
+}
+
+// WithBufferedErrorEncoding encodes errors which implement json.Marshaler into a buffer of up to maxBytes before
+// writing the response headers. If encoding fails, a text/plain response is written with the error's status code
+// instead of a partial application/json response. Errors whose encoding exceeds maxBytes are encoded directly to
+// the response, as they are without this param. Conjure errors are always encoded before the headers are written.
+func WithBufferedErrorEncoding(maxBytes int) JSONHandlerParam {
+	return jsonHandlerParamFunc(func(h *handler) {
+		h.maxBufferedErrorBytes = maxBytes
+	})
+}
+
+// WithProblemErrorEncoding writes conjure errors as RFC 7807 problem details, with the conjure error fields included
+// as extension members, to requests whose Accept header prefers application/problem+json over application/json.
+// This lets consumers which do not understand conjure errors call the same endpoints. Other requests receive the
+// usual conjure error encoding.
+func WithProblemErrorEncoding() JSONHandlerParam {
+	return jsonHandlerParamFunc(func(h *handler) {
+		h.problemErrors = true
+	})
 }
 
 // trackingResponseWriter is a wrapper around http.ResponseWriter implemented in witchcraft-go-server.
@@ -45,12 +83,18 @@ type trackingResponseWriter interface {
 // handle the error according to the provided ErrorHandler. The provided 'fn' function is not expected to write
 // a response in the http.ResponseWriter if it returns a non-nil error. If a non-nil error is returned, the
 // mapped status code from the provided StatusMapper will be returned.
-func NewJSONHandler(fn func(http.ResponseWriter, *http.Request) error, statusFn StatusMapper, errorFn ErrorHandler) http.Handler {
-	return &handler{
+func NewJSONHandler(fn func(http.ResponseWriter, *http.Request) error, statusFn StatusMapper, errorFn ErrorHandler, params ...JSONHandlerParam) http.Handler {
+	h := &handler{
 		handleFn: fn,
 		statusFn: statusFn,
 		errorFn:  errorFn,
 	}
+	for _, p := range params {
+		if p != nil {
+			p.apply(h)
+		}
+	}
+	return h
 }
 
 // ServeHTTP implements the http.Handler interface
@@ -66,10 +110,18 @@ func (h handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		switch e := cause.(type) {
 		case errors.Error:
 			// if error is a conjure error, use WriteErrorResponse utility
-			errors.WriteErrorResponse(w, e)
+			if h.problemErrors && prefersProblemJSON(r.Header.Get(httpheaders.Accept)) {
+				errors.WriteProblemResponse(w, e)
+			} else {
+				errors.WriteErrorResponse(w, e)
+			}
 		case json.Marshaler:
 			// else if error is a json marshaler, write as json
-			WriteJSONResponse(w, e, status)
+			if h.maxBufferedErrorBytes > 0 {
+				h.writeBufferedJSONResponse(w, e, status)
+			} else {
+				WriteJSONResponse(w, e, status)
+			}
 		default:
 			// Fall back to string encoding
 			http.Error(w, err.Error(), status)
@@ -77,6 +129,39 @@ func (h handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// writeBufferedJSONResponse encodes obj before writing the response headers so that an encoding failure can be
+// reported with the correct Content-Type. If the encoding exceeds maxBufferedErrorBytes, obj is encoded directly to
+// the response instead.
+func (h handler) writeBufferedJSONResponse(w http.ResponseWriter, obj interface{}, status int) {
+	buf := &limitedBuffer{limit: h.maxBufferedErrorBytes}
+	if err := safejson.Encoder(buf).Encode(obj); err != nil {
+		if err == errBufferLimitExceeded {
+			WriteJSONResponse(w, obj, status)
+			return
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+	w.Header().Set(httpheaders.ContentType, "application/json")
+	w.WriteHeader(status)
+	_, _ = w.Write(buf.Bytes())
+}
+
+var errBufferLimitExceeded = werror.Error("buffer limit exceeded")
+
+// limitedBuffer is a bytes.Buffer which returns errBufferLimitExceeded instead of growing beyond limit bytes.
+type limitedBuffer struct {
+	bytes.Buffer
+	limit int
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if b.Len()+len(p) > b.limit {
+		return 0, errBufferLimitExceeded
+	}
+	return b.Buffer.Write(p)
+}
+
 // status returns the http status code from the provided err
 func (h handler) status(err error) int {
 	if h.statusFn != nil {
@@ -94,7 +179,7 @@ func (h handler) handleError(ctx context.Context, statusCode int, err error) {
 
 // StatusCodeMapper maps a provided error to an HTTP status code.
 // If the error's RootCause is a conjure error, the status mapping to the errorCode field is used.
-// If the provided error is a contains the legacy httpStatusCode parameter, that value is used.
+// If the provided error contains the legacy httpStatusCode parameter (see WithLegacyStatusCode), that value is used.
 // Otherwise, returns http.StatusInternalServerError (500).
 func StatusCodeMapper(err error) int {
 	if conjureErr := errors.GetConjureError(err); conjureErr != nil {
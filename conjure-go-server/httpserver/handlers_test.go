@@ -40,6 +40,7 @@ func TestHandler_ServeHTTP(t *testing.T) {
 	for _, tc := range []struct {
 		name       string
 		handler    func(http.ResponseWriter, *http.Request) error
+		params     []JSONHandlerParam
 		rwCreator  func(w http.ResponseWriter) http.ResponseWriter
 		verifyResp func(*testing.T, *http.Response)
 		verifyLog  func(*testing.T, []byte)
@@ -148,6 +149,46 @@ func TestHandler_ServeHTTP(t *testing.T) {
 				assert.Equal(t, map[string]interface{}{"httpStatusCode": json.Number("404")}, logLine["params"])
 			},
 		},
+		{
+			name: "404 non-conjure broken json marshaler with buffered encoding",
+			handler: func(rw http.ResponseWriter, req *http.Request) error {
+				return werror.Wrap(testJSONErrorMarshalFails{"a bad thing"}, "some reason", werror.SafeParam(legacyHTTPStatusCodeParamKey, http.StatusNotFound))
+			},
+			params: []JSONHandlerParam{WithBufferedErrorEncoding(1024)},
+			verifyResp: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+				assert.Equal(t, "text/plain; charset=utf-8", resp.Header.Get("Content-Type"))
+				body, err := ioutil.ReadAll(resp.Body)
+				assert.NoError(t, err)
+				assert.Contains(t, string(body), "failed to marshal json")
+			},
+			verifyLog: func(t *testing.T, i []byte) {
+				logLine := map[string]interface{}{}
+				err := codecs.JSON.Unmarshal(i, &logLine)
+				require.NoError(t, err)
+				assert.Equal(t, "Error handling request", logLine["message"])
+			},
+		},
+		{
+			name: "404 non-conjure json marshaler exceeding buffered encoding limit",
+			handler: func(rw http.ResponseWriter, req *http.Request) error {
+				return werror.Wrap(testJSONError{"a bad thing"}, "some reason", werror.SafeParam(legacyHTTPStatusCodeParamKey, http.StatusNotFound))
+			},
+			params: []JSONHandlerParam{WithBufferedErrorEncoding(8)},
+			verifyResp: func(t *testing.T, resp *http.Response) {
+				assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+				assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+				body, err := ioutil.ReadAll(resp.Body)
+				assert.NoError(t, err)
+				assert.Equal(t, "{\"message\":\"a bad thing\"}\n", string(body))
+			},
+			verifyLog: func(t *testing.T, i []byte) {
+				logLine := map[string]interface{}{}
+				err := codecs.JSON.Unmarshal(i, &logLine)
+				require.NoError(t, err)
+				assert.Equal(t, "Error handling request", logLine["message"])
+			},
+		},
 		{
 			name: "500 conjure error",
 			handler: func(rw http.ResponseWriter, req *http.Request) error {
@@ -261,7 +302,7 @@ func TestHandler_ServeHTTP(t *testing.T) {
 			))
 
 			recorder := httptest.NewRecorder()
-			handler := NewJSONHandler(tc.handler, StatusCodeMapper, ErrHandler)
+			handler := NewJSONHandler(tc.handler, StatusCodeMapper, ErrHandler, tc.params...)
 			rw := http.ResponseWriter(recorder)
 			if tc.rwCreator != nil {
 				rw = tc.rwCreator(rw)
@@ -364,3 +405,67 @@ func (rw *testResponseWriter) Write(b []byte) (int, error) {
 func (rw *testResponseWriter) Written() bool {
 	return rw.status != 0
 }
+
+func TestLegacyStatusCode(t *testing.T) {
+	assert.NoError(t, WithLegacyStatusCode(nil, http.StatusNotFound))
+
+	err := WithLegacyStatusCode(werror.Error("not found"), http.StatusNotFound)
+	assert.EqualError(t, err, "not found")
+	code, ok := LegacyStatusCode(err)
+	assert.True(t, ok)
+	assert.Equal(t, http.StatusNotFound, code)
+	assert.Equal(t, http.StatusNotFound, StatusCodeMapper(err))
+
+	_, ok = LegacyStatusCode(werror.Error("no code"))
+	assert.False(t, ok)
+
+	var logBuf bytes.Buffer
+	ctx := svc1log.WithLogger(context.Background(),
+		svc1log.NewFromCreator(&logBuf, wlog.InfoLevel, wlog.NewJSONMarshalLoggerProvider().NewLeveledLogger, svc1log.Origin("")))
+	var handled []int
+	errHandler := LegacyStatusCodeDeprecationHandler(func(ctx context.Context, statusCode int, err error) {
+		handled = append(handled, statusCode)
+	})
+	errHandler(ctx, http.StatusInternalServerError, errors.NewInternal())
+	assert.Empty(t, logBuf.Bytes(), "conjure errors should not be logged")
+	errHandler(ctx, http.StatusNotFound, err)
+	assert.Equal(t, []int{http.StatusInternalServerError, http.StatusNotFound}, handled)
+
+	logLine := map[string]interface{}{}
+	require.NoError(t, codecs.JSON.Unmarshal(logBuf.Bytes(), &logLine))
+	assert.Equal(t, "WARN", logLine["level"])
+	assert.Equal(t, "Error response status set by deprecated legacy status code parameter. Return a conjure error instead.", logLine["message"])
+}
+
+func TestProblemErrorEncoding(t *testing.T) {
+	handler := NewJSONHandler(func(http.ResponseWriter, *http.Request) error {
+		return errors.NewNotFound()
+	}, StatusCodeMapper, nil, WithProblemErrorEncoding())
+
+	for _, tc := range []struct {
+		accept      string
+		contentType string
+	}{
+		{accept: "", contentType: "application/json; charset=utf-8"},
+		{accept: "application/json", contentType: "application/json; charset=utf-8"},
+		{accept: "*/*", contentType: "application/json; charset=utf-8"},
+		{accept: "application/problem+json", contentType: "application/problem+json"},
+		{accept: "application/json;q=0.5, application/problem+json", contentType: "application/problem+json"},
+		{accept: "application/problem+json;q=0.5, application/json", contentType: "application/json; charset=utf-8"},
+		{accept: "application/problem+json, application/*;q=0.1", contentType: "application/problem+json"},
+	} {
+		t.Run(tc.accept, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tc.accept != "" {
+				req.Header.Set("Accept", tc.accept)
+			}
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+			assert.Equal(t, http.StatusNotFound, recorder.Code)
+			assert.Equal(t, tc.contentType, recorder.Header().Get("Content-Type"))
+			var body map[string]interface{}
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+			assert.Equal(t, "NOT_FOUND", body["errorCode"])
+		})
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"net/http"
 	"strings"
 
+	"github.com/palantir/conjure-go-runtime/v2/conjure-go-contract/httpheaders"
 	"github.com/palantir/pkg/safejson"
 	werror "github.com/palantir/witchcraft-go-error"
 )
@@ -28,7 +29,7 @@ import (
 // resulting JSON as a JSON response to the provided http.ResponseWriter with the provided status code. If marshaling
 // the provided object as JSON results in an error, writes a 500 response with the text content of the error.
 func WriteJSONResponse(w http.ResponseWriter, obj interface{}, status int) {
-	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set(httpheaders.ContentType, "application/json")
 	w.WriteHeader(status)
 
 	if err := safejson.Encoder(w).Encode(obj); err != nil {
@@ -42,7 +43,7 @@ func WriteJSONResponse(w http.ResponseWriter, obj interface{}, status int) {
 // of 'Authorization' and a value of 'bearer {token}'. ParseBearerTokenHeader will return the token value, or an error
 // if the Authorization header is missing, an empty string, or is not in the format expected.
 func ParseBearerTokenHeader(req *http.Request) (string, error) {
-	authHeader := req.Header.Get("Authorization")
+	authHeader := req.Header.Get(httpheaders.Authorization)
 	if authHeader == "" {
 		return "", werror.Error("Authorization header not found")
 	}
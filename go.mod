@@ -4,10 +4,12 @@ go 1.22.0
 
 require (
 	github.com/golang/snappy v0.0.4
+	github.com/palantir/go-metrics v1.1.1
 	github.com/palantir/pkg/bytesbuffers v1.2.0
 	github.com/palantir/pkg/httpserver v1.1.0
 	github.com/palantir/pkg/metrics v1.7.0
 	github.com/palantir/pkg/refreshable v1.5.0
+	github.com/palantir/pkg/refreshable/v2 v2.0.0
 	github.com/palantir/pkg/retry v1.2.0
 	github.com/palantir/pkg/safejson v1.1.0
 	github.com/palantir/pkg/tlsconfig v1.3.0
@@ -26,9 +28,7 @@ require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/kr/pretty v0.1.0 // indirect
 	github.com/openzipkin/zipkin-go v0.2.2 // indirect
-	github.com/palantir/go-metrics v1.1.1 // indirect
 	github.com/palantir/pkg v1.1.0 // indirect
-	github.com/palantir/pkg/refreshable/v2 v2.0.0 // indirect
 	github.com/pkg/errors v0.9.1 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
 	golang.org/x/text v0.19.0 // indirect